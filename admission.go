@@ -0,0 +1,315 @@
+package quic
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	list "github.com/quic-go/quic-go/internal/utils/linkedlist"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// An AdmissionPolicy limits the resources that a single source can consume on a server.
+// Sources are grouped by the prefix of their IP address, such that a client can't
+// circumvent the limits by spreading its connection attempts over multiple addresses
+// that it controls.
+// Note that the source address of a connection attempt is unvalidated, and might be spoofed.
+// Transport.VerifySourceAddress can be used to validate addresses before admission.
+// To bound its memory usage, the server keeps state for at most 65536 sources at the same time.
+// Once this limit is reached, the state of the least recently used source that doesn't hold
+// any handshakes or connections is replaced. If all sources hold resources, further sources
+// share the state (and therefore the limits) of a single source.
+type AdmissionPolicy struct {
+	// IPv4PrefixLen is the prefix length used to group IPv4 addresses.
+	// If unset, each IPv4 address is its own source (i.e. a /32).
+	IPv4PrefixLen int
+	// IPv6PrefixLen is the prefix length used to group IPv6 addresses.
+	// If unset, addresses are grouped by their /64 prefix.
+	IPv6PrefixLen int
+
+	// InitialPacketRate is the number of Initial packets per second that the server processes
+	// for a single source, if these packets would create a new connection.
+	// Initial packets exceeding this rate are dropped.
+	// If unset, the rate is not limited.
+	InitialPacketRate float64
+	// InitialPacketBurst is the maximum number of Initial packets that can be processed at once,
+	// before InitialPacketRate applies.
+	// If unset, it defaults to InitialPacketRate (rounded up).
+	InitialPacketBurst int
+
+	// MaxHandshakesPerSource is the maximum number of handshakes that can be in progress
+	// for a single source at the same time.
+	// Further connection attempts are refused with a CONNECTION_REFUSED error.
+	// If unset, the number is not limited.
+	MaxHandshakesPerSource int
+	// MaxConnectionsPerSource is the maximum number of connections (including connections
+	// that are still handshaking) that a single source can have at the same time.
+	// Further connection attempts are refused with a CONNECTION_REFUSED error.
+	// If unset, the number is not limited.
+	MaxConnectionsPerSource int
+
+	// MaxAcceptQueueLen is the maximum number of connections that have completed the handshake,
+	// but haven't been accepted by the application yet.
+	// Once the queue is full, connections are closed with a CONNECTION_REFUSED error.
+	// If unset, it defaults to 32.
+	MaxAcceptQueueLen int
+}
+
+func (p *AdmissionPolicy) acceptQueueLen() int {
+	if p == nil || p.MaxAcceptQueueLen <= 0 {
+		return protocol.MaxAcceptQueueSize
+	}
+	return p.MaxAcceptQueueLen
+}
+
+// sourceCleanupInterval is the interval at which the state of sources that
+// don't hold any resources is removed.
+const sourceCleanupInterval = 10 * time.Second
+
+// maxSources is the maximum number of sources that are tracked at the same time.
+// Since source addresses can be spoofed, the number of sources needs to be limited.
+const maxSources = 1 << 16
+
+// overflowSource is the source used for all new sources once maxSources sources
+// that hold resources are tracked.
+var overflowSource = netip.PrefixFrom(netip.IPv6Unspecified(), 0)
+
+type sourceState struct {
+	prefix netip.Prefix
+
+	tokens     float64
+	lastRefill time.Time
+
+	handshakes  int
+	connections int
+	// idleElement is the element in the list of idle sources,
+	// and nil if the source holds any resources.
+	idleElement *list.Element[*sourceState]
+}
+
+// The admissionController keeps track of the resources held by every source.
+// It is safe for concurrent use.
+type admissionController struct {
+	policy AdmissionPolicy
+	burst  float64
+
+	mutex   sync.Mutex
+	sources map[netip.Prefix]*sourceState
+	// idleSources are the sources that don't hold any resources,
+	// ordered from most to least recently used.
+	idleSources *list.List[*sourceState]
+	maxSources  int
+	nextCleanup time.Time
+}
+
+func newAdmissionController(policy AdmissionPolicy) *admissionController {
+	if policy.IPv4PrefixLen <= 0 || policy.IPv4PrefixLen > 32 {
+		policy.IPv4PrefixLen = 32
+	}
+	if policy.IPv6PrefixLen <= 0 || policy.IPv6PrefixLen > 128 {
+		policy.IPv6PrefixLen = 64
+	}
+	burst := float64(policy.InitialPacketBurst)
+	if burst <= 0 {
+		burst = max(math.Ceil(policy.InitialPacketRate), 1)
+	}
+	return &admissionController{
+		policy:      policy,
+		burst:       burst,
+		sources:     make(map[netip.Prefix]*sourceState),
+		idleSources: list.New[*sourceState](),
+		maxSources:  maxSources,
+	}
+}
+
+// sourcePrefix determines the source that an address belongs to.
+// It returns false if the address is not an IP address.
+func (c *admissionController) sourcePrefix(addr net.Addr) (netip.Prefix, bool) {
+	var ip netip.Addr
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		var ok bool
+		ip, ok = netip.AddrFromSlice(a.IP)
+		if !ok {
+			return netip.Prefix{}, false
+		}
+	default:
+		ap, err := netip.ParseAddrPort(addr.String())
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		ip = ap.Addr()
+	}
+	ip = ip.Unmap()
+	bits := c.policy.IPv6PrefixLen
+	if ip.Is4() {
+		bits = c.policy.IPv4PrefixLen
+	}
+	prefix, err := ip.Prefix(bits)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return prefix, true
+}
+
+// getSource returns the state of a source, creating it if necessary.
+// If the maximum number of sources is reached, the least recently used idle source is removed.
+// If there's no idle source, the state of the overflow source is returned.
+func (c *admissionController) getSource(prefix netip.Prefix, now time.Time) *sourceState {
+	if s, ok := c.sources[prefix]; ok {
+		if s.idleElement != nil {
+			c.idleSources.MoveToFront(s.idleElement)
+		}
+		return s
+	}
+	if len(c.sources) >= c.maxSources {
+		if el := c.idleSources.Back(); el != nil {
+			c.removeSource(el.Value)
+		} else {
+			prefix = overflowSource
+			if s, ok := c.sources[prefix]; ok {
+				return s
+			}
+		}
+	}
+	s := &sourceState{prefix: prefix, tokens: c.burst, lastRefill: now}
+	s.idleElement = c.idleSources.PushFront(s)
+	c.sources[prefix] = s
+	return s
+}
+
+func (c *admissionController) removeSource(s *sourceState) {
+	if s.idleElement != nil {
+		c.idleSources.Remove(s.idleElement)
+	}
+	delete(c.sources, s.prefix)
+}
+
+// updateIdle adds the source to the list of idle sources if it doesn't hold any resources,
+// and removes it from that list otherwise.
+func (c *admissionController) updateIdle(s *sourceState) {
+	idle := s.handshakes == 0 && s.connections == 0
+	switch {
+	case idle && s.idleElement == nil:
+		s.idleElement = c.idleSources.PushFront(s)
+	case !idle && s.idleElement != nil:
+		c.idleSources.Remove(s.idleElement)
+		s.idleElement = nil
+	}
+}
+
+// allowInitial is called for Initial packets that would create a new connection.
+// It consumes a token from the source's token bucket.
+func (c *admissionController) allowInitial(addr net.Addr, now time.Time) (logging.ConnectionRejectReason, bool) {
+	if c.policy.InitialPacketRate <= 0 {
+		return 0, true
+	}
+	prefix, ok := c.sourcePrefix(addr)
+	if !ok {
+		return 0, true
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maybeCleanup(now)
+	s := c.getSource(prefix, now)
+	if elapsed := now.Sub(s.lastRefill); elapsed > 0 {
+		s.tokens = min(c.burst, s.tokens+elapsed.Seconds()*c.policy.InitialPacketRate)
+		s.lastRefill = now
+	}
+	if s.tokens < 1 {
+		return logging.ConnectionRejectRateLimited, false
+	}
+	s.tokens--
+	return 0, true
+}
+
+// admit is called before a new connection is created.
+// If the connection is admitted, the caller must call handshakeDone and connectionClosed once
+// the handshake has finished and the connection has been closed, respectively.
+func (c *admissionController) admit(addr net.Addr, now time.Time) (netip.Prefix, logging.ConnectionRejectReason, bool) {
+	prefix, ok := c.sourcePrefix(addr)
+	if !ok {
+		return netip.Prefix{}, 0, true
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maybeCleanup(now)
+	s := c.getSource(prefix, now)
+	if c.policy.MaxConnectionsPerSource > 0 && s.connections >= c.policy.MaxConnectionsPerSource {
+		return netip.Prefix{}, logging.ConnectionRejectTooManyConnections, false
+	}
+	if c.policy.MaxHandshakesPerSource > 0 && s.handshakes >= c.policy.MaxHandshakesPerSource {
+		return netip.Prefix{}, logging.ConnectionRejectTooManyHandshakes, false
+	}
+	s.handshakes++
+	s.connections++
+	c.updateIdle(s)
+	return s.prefix, 0, true
+}
+
+func (c *admissionController) handshakeDone(prefix netip.Prefix) {
+	if !prefix.IsValid() {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if s, ok := c.sources[prefix]; ok {
+		s.handshakes--
+		c.updateIdle(s)
+	}
+}
+
+func (c *admissionController) connectionClosed(prefix netip.Prefix) {
+	if !prefix.IsValid() {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if s, ok := c.sources[prefix]; ok {
+		s.connections--
+		c.updateIdle(s)
+	}
+}
+
+// trackConnection decrements the number of handshakes once the handshake of conn completes,
+// and the number of connections once conn is closed.
+// It must be called before the connection is run.
+func (c *admissionController) trackConnection(prefix netip.Prefix, conn quicConn) {
+	if !prefix.IsValid() {
+		return
+	}
+	var once sync.Once
+	handshakeDone := func() { once.Do(func() { c.handshakeDone(prefix) }) }
+	conn.setHandshakeCompleteHook(handshakeDone)
+	context.AfterFunc(conn.Context(), func() {
+		handshakeDone() // no-op if the handshake completed
+		c.connectionClosed(prefix)
+	})
+}
+
+// maybeCleanup removes sources that don't hold any resources and whose token bucket is full.
+func (c *admissionController) maybeCleanup(now time.Time) {
+	if now.Before(c.nextCleanup) {
+		return
+	}
+	c.nextCleanup = now.Add(sourceCleanupInterval)
+	for _, s := range c.sources {
+		if s.handshakes > 0 || s.connections > 0 {
+			continue
+		}
+		if c.policy.InitialPacketRate > 0 && s.tokens+now.Sub(s.lastRefill).Seconds()*c.policy.InitialPacketRate < c.burst {
+			continue
+		}
+		c.removeSource(s)
+	}
+}
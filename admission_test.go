@@ -0,0 +1,233 @@
+package quic
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+)
+
+func isAllowed(_ logging.ConnectionRejectReason, ok bool) bool { return ok }
+
+var _ = Describe("Admission Control", func() {
+	It("uses the default accept queue length", func() {
+		var p *AdmissionPolicy
+		Expect(p.acceptQueueLen()).To(Equal(protocol.MaxAcceptQueueSize))
+		Expect((&AdmissionPolicy{}).acceptQueueLen()).To(Equal(protocol.MaxAcceptQueueSize))
+		Expect((&AdmissionPolicy{MaxAcceptQueueLen: 5}).acceptQueueLen()).To(Equal(5))
+	})
+
+	It("groups addresses by their prefix", func() {
+		c := newAdmissionController(AdmissionPolicy{IPv4PrefixLen: 24})
+		prefix, ok := c.sourcePrefix(&net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234})
+		Expect(ok).To(BeTrue())
+		Expect(prefix).To(Equal(netip.MustParsePrefix("1.2.3.0/24")))
+		prefix, ok = c.sourcePrefix(&net.UDPAddr{IP: net.ParseIP("2001:db8:1:2:3:4:5:6"), Port: 1234})
+		Expect(ok).To(BeTrue())
+		Expect(prefix).To(Equal(netip.MustParsePrefix("2001:db8:1:2::/64")))
+		// non-UDP addresses are parsed from their string representation
+		prefix, ok = c.sourcePrefix(&net.TCPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 1234})
+		Expect(ok).To(BeTrue())
+		Expect(prefix).To(Equal(netip.MustParsePrefix("5.6.7.0/24")))
+		_, ok = c.sourcePrefix(&net.UnixAddr{Name: "/tmp/socket", Net: "unix"})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("rate limits Initial packets per source", func() {
+		c := newAdmissionController(AdmissionPolicy{InitialPacketRate: 10, InitialPacketBurst: 3})
+		addr1 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+		addr2 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 4321}
+		addr3 := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1), Port: 1234}
+		now := time.Now()
+		for i := 0; i < 3; i++ {
+			Expect(isAllowed(c.allowInitial(addr1, now))).To(BeTrue())
+		}
+		Expect(isAllowed(c.allowInitial(addr2, now))).To(BeFalse())
+		Expect(isAllowed(c.allowInitial(addr3, now))).To(BeTrue())
+		// 10 packets per second means that a token is added every 100ms
+		Expect(isAllowed(c.allowInitial(addr1, now.Add(50*time.Millisecond)))).To(BeFalse())
+		Expect(isAllowed(c.allowInitial(addr1, now.Add(100*time.Millisecond)))).To(BeTrue())
+		Expect(isAllowed(c.allowInitial(addr1, now.Add(100*time.Millisecond)))).To(BeFalse())
+		// the bucket doesn't fill up beyond the burst size
+		now = now.Add(time.Hour)
+		for i := 0; i < 3; i++ {
+			Expect(isAllowed(c.allowInitial(addr1, now))).To(BeTrue())
+		}
+		Expect(isAllowed(c.allowInitial(addr1, now))).To(BeFalse())
+	})
+
+	It("uses the rate as the default burst size", func() {
+		c := newAdmissionController(AdmissionPolicy{InitialPacketRate: 2.5})
+		addr := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+		now := time.Now()
+		for i := 0; i < 3; i++ {
+			Expect(isAllowed(c.allowInitial(addr, now))).To(BeTrue())
+		}
+		Expect(isAllowed(c.allowInitial(addr, now))).To(BeFalse())
+	})
+
+	It("limits the number of handshakes per source", func() {
+		c := newAdmissionController(AdmissionPolicy{MaxHandshakesPerSource: 2})
+		addr := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+		now := time.Now()
+		prefix, _, ok := c.admit(addr, now)
+		Expect(ok).To(BeTrue())
+		_, _, ok = c.admit(addr, now)
+		Expect(ok).To(BeTrue())
+		_, reason, ok := c.admit(addr, now)
+		Expect(ok).To(BeFalse())
+		Expect(reason).To(Equal(logging.ConnectionRejectTooManyHandshakes))
+		c.handshakeDone(prefix)
+		_, _, ok = c.admit(addr, now)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("limits the number of connections per source", func() {
+		c := newAdmissionController(AdmissionPolicy{MaxConnectionsPerSource: 2})
+		addr := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+		now := time.Now()
+		for i := 0; i < 2; i++ {
+			prefix, _, ok := c.admit(addr, now)
+			Expect(ok).To(BeTrue())
+			c.handshakeDone(prefix)
+		}
+		prefix, reason, ok := c.admit(addr, now)
+		Expect(ok).To(BeFalse())
+		Expect(reason).To(Equal(logging.ConnectionRejectTooManyConnections))
+		Expect(prefix.IsValid()).To(BeFalse())
+		c.connectionClosed(netip.MustParsePrefix("1.2.3.4/32"))
+		_, _, ok = c.admit(addr, now)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("removes sources that don't hold any resources", func() {
+		c := newAdmissionController(AdmissionPolicy{InitialPacketRate: 1, MaxConnectionsPerSource: 1})
+		addr1 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+		addr2 := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1), Port: 1234}
+		now := time.Now()
+		Expect(isAllowed(c.allowInitial(addr1, now))).To(BeTrue())
+		Expect(isAllowed(c.allowInitial(addr2, now))).To(BeTrue())
+		_, _, ok := c.admit(addr2, now)
+		Expect(ok).To(BeTrue())
+		Expect(c.sources).To(HaveLen(2))
+		// the token bucket of addr1 is full again, but addr2 still holds a connection
+		now = now.Add(sourceCleanupInterval)
+		Expect(isAllowed(c.allowInitial(&net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 1234}, now))).To(BeTrue())
+		Expect(c.sources).To(HaveLen(2))
+		Expect(c.sources).To(HaveKey(netip.MustParsePrefix("4.3.2.1/32")))
+		Expect(c.sources).To(HaveKey(netip.MustParsePrefix("5.6.7.8/32")))
+	})
+
+	It("replaces the least recently used idle source once the maximum number of sources is reached", func() {
+		c := newAdmissionController(AdmissionPolicy{InitialPacketRate: 1, MaxHandshakesPerSource: 1})
+		c.maxSources = 2
+		addr1 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+		addr2 := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1), Port: 1234}
+		addr3 := &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 1234}
+		now := time.Now()
+		Expect(isAllowed(c.allowInitial(addr1, now))).To(BeTrue())
+		Expect(isAllowed(c.allowInitial(addr2, now))).To(BeTrue())
+		// use addr1 again, so that addr2 is the least recently used source
+		Expect(isAllowed(c.allowInitial(addr1, now))).To(BeFalse())
+		// a fresh source is not blocked
+		Expect(isAllowed(c.allowInitial(addr3, now))).To(BeTrue())
+		prefix, _, ok := c.admit(addr3, now)
+		Expect(ok).To(BeTrue())
+		Expect(prefix).To(Equal(netip.MustParsePrefix("5.6.7.8/32")))
+		Expect(c.sources).To(HaveLen(2))
+		Expect(c.sources).To(HaveKey(netip.MustParsePrefix("1.2.3.4/32")))
+		Expect(c.sources).To(HaveKey(netip.MustParsePrefix("5.6.7.8/32")))
+	})
+
+	It("uses a shared source once the maximum number of sources holding resources is reached", func() {
+		c := newAdmissionController(AdmissionPolicy{MaxHandshakesPerSource: 1})
+		c.maxSources = 2
+		addr1 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+		addr2 := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1), Port: 1234}
+		addr3 := &net.UDPAddr{IP: net.IPv4(5, 6, 7, 8), Port: 1234}
+		addr4 := &net.UDPAddr{IP: net.IPv4(8, 7, 6, 5), Port: 1234}
+		now := time.Now()
+		prefix1, _, ok := c.admit(addr1, now)
+		Expect(ok).To(BeTrue())
+		_, _, ok = c.admit(addr2, now)
+		Expect(ok).To(BeTrue())
+		// sources holding resources are not replaced
+		prefix3, _, ok := c.admit(addr3, now)
+		Expect(ok).To(BeTrue())
+		Expect(prefix3).To(Equal(overflowSource))
+		Expect(c.sources).To(HaveKey(netip.MustParsePrefix("1.2.3.4/32")))
+		Expect(c.sources).To(HaveKey(netip.MustParsePrefix("4.3.2.1/32")))
+		// addr4 shares the limits with addr3
+		_, reason, ok := c.admit(addr4, now)
+		Expect(ok).To(BeFalse())
+		Expect(reason).To(Equal(logging.ConnectionRejectTooManyHandshakes))
+		c.handshakeDone(prefix3)
+		_, _, ok = c.admit(addr4, now)
+		Expect(ok).To(BeTrue())
+		// once addr1 is idle, its state can be replaced
+		c.handshakeDone(prefix1)
+		c.connectionClosed(prefix1)
+		prefix, _, ok := c.admit(&net.UDPAddr{IP: net.IPv4(9, 9, 9, 9), Port: 1234}, now)
+		Expect(ok).To(BeTrue())
+		Expect(prefix).To(Equal(netip.MustParsePrefix("9.9.9.9/32")))
+		Expect(c.sources).ToNot(HaveKey(netip.MustParsePrefix("1.2.3.4/32")))
+	})
+
+	Context("tracking connections", func() {
+		var (
+			c      *admissionController
+			conn   *MockQUICConn
+			ctx    context.Context
+			cancel context.CancelFunc
+			hook   func()
+			prefix netip.Prefix
+		)
+
+		getSource := func() sourceState {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			if s, ok := c.sources[prefix]; ok {
+				return *s
+			}
+			return sourceState{}
+		}
+
+		BeforeEach(func() {
+			c = newAdmissionController(AdmissionPolicy{MaxHandshakesPerSource: 1})
+			mockCtrl := gomock.NewController(GinkgoT())
+			conn = NewMockQUICConn(mockCtrl)
+			ctx, cancel = context.WithCancel(context.Background())
+			conn.EXPECT().Context().Return(ctx).AnyTimes()
+			conn.EXPECT().setHandshakeCompleteHook(gomock.Any()).Do(func(f func()) { hook = f })
+			var ok bool
+			prefix, _, ok = c.admit(&net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}, time.Now())
+			Expect(ok).To(BeTrue())
+			c.trackConnection(prefix, conn)
+			Expect(hook).ToNot(BeNil())
+		})
+
+		AfterEach(func() { cancel() })
+
+		It("releases the handshake when the handshake completes", func() {
+			hook()
+			Expect(getSource().handshakes).To(BeZero())
+			Expect(getSource().connections).To(Equal(1))
+			cancel()
+			Eventually(func() int { return getSource().connections }).Should(BeZero())
+			Expect(getSource().handshakes).To(BeZero())
+		})
+
+		It("releases the handshake when the connection is closed during the handshake", func() {
+			cancel()
+			Eventually(func() int { return getSource().connections }).Should(BeZero())
+			Expect(getSource().handshakes).To(BeZero())
+		})
+	})
+})
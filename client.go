@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net"
 
+	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
@@ -23,6 +24,7 @@ type client struct {
 	config  *Config
 
 	connIDGenerator ConnectionIDGenerator
+	memoryBudget    *flowcontrol.MemoryBudget
 	srcConnID       protocol.ConnectionID
 	destConnID      protocol.ConnectionID
 
@@ -138,6 +140,7 @@ func dial(
 	conn sendConn,
 	connIDGenerator ConnectionIDGenerator,
 	packetHandlers packetHandlerManager,
+	memoryBudget *flowcontrol.MemoryBudget,
 	tlsConf *tls.Config,
 	config *Config,
 	onClose func(),
@@ -148,6 +151,7 @@ func dial(
 		return nil, err
 	}
 	c.packetHandlers = packetHandlers
+	c.memoryBudget = memoryBudget
 
 	c.tracingID = nextConnTracingID()
 	if c.config.Tracer != nil {
@@ -172,7 +176,7 @@ func newClient(sendConn sendConn, connIDGenerator ConnectionIDGenerator, config
 		return nil, err
 	}
 	c := &client{
-		connIDGenerator: connIDGenerator,
+		connIDGenerator: connIDGeneratorForConn(connIDGenerator, srcConnID),
 		srcConnID:       srcConnID,
 		destConnID:      destConnID,
 		sendConn:        sendConn,
@@ -199,6 +203,7 @@ func (c *client) dial(ctx context.Context) error {
 		c.connIDGenerator,
 		c.config,
 		c.tlsConf,
+		c.memoryBudget,
 		c.initialPacketNumber,
 		c.use0RTT,
 		c.hasNegotiatedVersion,
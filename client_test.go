@@ -7,6 +7,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/flowcontrol"
 	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
@@ -41,6 +42,7 @@ var _ = Describe("Client", func() {
 			connIDGenerator ConnectionIDGenerator,
 			conf *Config,
 			tlsConf *tls.Config,
+			memoryBudget *flowcontrol.MemoryBudget,
 			initialPacketNumber protocol.PacketNumber,
 			enable0RTT bool,
 			hasNegotiatedVersion bool,
@@ -120,6 +122,7 @@ var _ = Describe("Client", func() {
 				_ ConnectionIDGenerator,
 				_ *Config,
 				_ *tls.Config,
+				_ *flowcontrol.MemoryBudget,
 				_ protocol.PacketNumber,
 				enable0RTT bool,
 				_ bool,
@@ -157,6 +160,7 @@ var _ = Describe("Client", func() {
 				_ ConnectionIDGenerator,
 				_ *Config,
 				_ *tls.Config,
+				_ *flowcontrol.MemoryBudget,
 				_ protocol.PacketNumber,
 				enable0RTT bool,
 				_ bool,
@@ -194,6 +198,7 @@ var _ = Describe("Client", func() {
 				_ ConnectionIDGenerator,
 				_ *Config,
 				_ *tls.Config,
+				_ *flowcontrol.MemoryBudget,
 				_ protocol.PacketNumber,
 				_ bool,
 				_ bool,
@@ -279,6 +284,7 @@ var _ = Describe("Client", func() {
 				_ ConnectionIDGenerator,
 				configP *Config,
 				_ *tls.Config,
+				_ *flowcontrol.MemoryBudget,
 				_ protocol.PacketNumber,
 				_ bool,
 				_ bool,
@@ -322,6 +328,7 @@ var _ = Describe("Client", func() {
 				_ ConnectionIDGenerator,
 				configP *Config,
 				_ *tls.Config,
+				_ *flowcontrol.MemoryBudget,
 				pn protocol.PacketNumber,
 				_ bool,
 				hasNegotiatedVersion bool,
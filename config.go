@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -45,6 +46,20 @@ func validateConfig(config *Config) error {
 	if config.InitialPacketSize > protocol.MaxPacketBufferSize {
 		config.InitialPacketSize = protocol.MaxPacketBufferSize
 	}
+	if pa := config.PreferredAddress; pa != nil {
+		if pa.Transport == nil {
+			return errors.New("invalid preferred address: Transport not set")
+		}
+		if !pa.IPv4.IsValid() && !pa.IPv6.IsValid() {
+			return errors.New("invalid preferred address: neither IPv4 nor IPv6 address set")
+		}
+		if pa.IPv4.IsValid() && !pa.IPv4.Addr().Is4() {
+			return fmt.Errorf("invalid preferred address: %s is not an IPv4 address", pa.IPv4)
+		}
+		if pa.IPv6.IsValid() && !pa.IPv6.Addr().Is6() {
+			return fmt.Errorf("invalid preferred address: %s is not an IPv6 address", pa.IPv6)
+		}
+	}
 	// check that all QUIC versions are actually supported
 	for _, v := range config.Versions {
 		if !protocol.IsValidVersion(v) {
@@ -100,29 +115,40 @@ func populateConfig(config *Config) *Config {
 	} else if maxIncomingUniStreams < 0 {
 		maxIncomingUniStreams = 0
 	}
+	datagramReceiveQueueLen := config.DatagramReceiveQueueLen
+	if datagramReceiveQueueLen <= 0 {
+		datagramReceiveQueueLen = protocol.DefaultMaxDatagramReceiveQueueLen
+	}
 	initialPacketSize := config.InitialPacketSize
 	if initialPacketSize == 0 {
 		initialPacketSize = protocol.InitialPacketSize
 	}
 
 	return &Config{
-		GetConfigForClient:             config.GetConfigForClient,
-		Versions:                       versions,
-		HandshakeIdleTimeout:           handshakeIdleTimeout,
-		MaxIdleTimeout:                 idleTimeout,
-		KeepAlivePeriod:                config.KeepAlivePeriod,
-		InitialStreamReceiveWindow:     initialStreamReceiveWindow,
-		MaxStreamReceiveWindow:         maxStreamReceiveWindow,
-		InitialConnectionReceiveWindow: initialConnectionReceiveWindow,
-		MaxConnectionReceiveWindow:     maxConnectionReceiveWindow,
-		AllowConnectionWindowIncrease:  config.AllowConnectionWindowIncrease,
-		MaxIncomingStreams:             maxIncomingStreams,
-		MaxIncomingUniStreams:          maxIncomingUniStreams,
-		TokenStore:                     config.TokenStore,
-		EnableDatagrams:                config.EnableDatagrams,
-		InitialPacketSize:              initialPacketSize,
-		DisablePathMTUDiscovery:        config.DisablePathMTUDiscovery,
-		Allow0RTT:                      config.Allow0RTT,
-		Tracer:                         config.Tracer,
+		GetConfigForClient:               config.GetConfigForClient,
+		Versions:                         versions,
+		HandshakeIdleTimeout:             handshakeIdleTimeout,
+		MaxIdleTimeout:                   idleTimeout,
+		KeepAlivePeriod:                  config.KeepAlivePeriod,
+		InitialStreamReceiveWindow:       initialStreamReceiveWindow,
+		MaxStreamReceiveWindow:           maxStreamReceiveWindow,
+		InitialConnectionReceiveWindow:   initialConnectionReceiveWindow,
+		MaxConnectionReceiveWindow:       maxConnectionReceiveWindow,
+		AllowConnectionWindowIncrease:    config.AllowConnectionWindowIncrease,
+		MaxIncomingStreams:               maxIncomingStreams,
+		MaxIncomingUniStreams:            maxIncomingUniStreams,
+		TokenStore:                       config.TokenStore,
+		EnableCarefulResume:              config.EnableCarefulResume,
+		EnableDatagrams:                  config.EnableDatagrams,
+		DatagramReceiveQueueLen:          datagramReceiveQueueLen,
+		EnableStreamResetPartialDelivery: config.EnableStreamResetPartialDelivery,
+		EnableAckFrequency:               config.EnableAckFrequency,
+		InitialPacketSize:                initialPacketSize,
+		DisablePathMTUDiscovery:          config.DisablePathMTUDiscovery,
+		DisableActiveMigration:           config.DisableActiveMigration,
+		Allow0RTT:                        config.Allow0RTT,
+		PreferredAddress:                 config.PreferredAddress,
+		CongestionControl:                config.CongestionControl,
+		Tracer:                           config.Tracer,
 	}
 }
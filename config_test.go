@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 	"reflect"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/logging"
 	"github.com/quic-go/quic-go/quicvarint"
@@ -68,6 +70,28 @@ var _ = Describe("Config", func() {
 			Expect(validateConfig(conf)).To(Succeed())
 			Expect(conf.InitialPacketSize).To(BeZero())
 		})
+
+		It("validates the preferred address", func() {
+			tr := &Transport{}
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{
+				IPv4: netip.MustParseAddrPort("127.0.0.1:1234"),
+			}})).To(MatchError("invalid preferred address: Transport not set"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{
+				Transport: tr,
+			}})).To(MatchError("invalid preferred address: neither IPv4 nor IPv6 address set"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{
+				IPv4:      netip.MustParseAddrPort("[::1]:1234"),
+				Transport: tr,
+			}})).To(MatchError("invalid preferred address: [::1]:1234 is not an IPv4 address"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{
+				IPv6:      netip.MustParseAddrPort("127.0.0.1:1234"),
+				Transport: tr,
+			}})).To(MatchError("invalid preferred address: 127.0.0.1:1234 is not an IPv6 address"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{
+				IPv6:      netip.MustParseAddrPort("[::1]:1234"),
+				Transport: tr,
+			}})).To(Succeed())
+		})
 	})
 
 	configWithNonZeroNonFunctionFields := func() *Config {
@@ -83,7 +107,7 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "Tracer":
+			case "GetConfigForClient", "RequireAddressValidation", "GetLogWriter", "AllowConnectionWindowIncrease", "CongestionControl", "Tracer":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]Version{1, 2, 3}))
@@ -115,14 +139,29 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(time.Second))
 			case "EnableDatagrams":
 				f.Set(reflect.ValueOf(true))
+			case "DatagramReceiveQueueLen":
+				f.Set(reflect.ValueOf(64))
+			case "EnableStreamResetPartialDelivery":
+				f.Set(reflect.ValueOf(true))
+			case "EnableAckFrequency":
+				f.Set(reflect.ValueOf(true))
+			case "EnableCarefulResume":
+				f.Set(reflect.ValueOf(true))
 			case "DisableVersionNegotiationPackets":
 				f.Set(reflect.ValueOf(true))
 			case "InitialPacketSize":
 				f.Set(reflect.ValueOf(uint16(1350)))
 			case "DisablePathMTUDiscovery":
 				f.Set(reflect.ValueOf(true))
+			case "DisableActiveMigration":
+				f.Set(reflect.ValueOf(true))
 			case "Allow0RTT":
 				f.Set(reflect.ValueOf(true))
+			case "PreferredAddress":
+				f.Set(reflect.ValueOf(&PreferredAddress{
+					IPv4:      netip.MustParseAddrPort("127.0.0.1:1234"),
+					Transport: &Transport{},
+				}))
 			default:
 				Fail(fmt.Sprintf("all fields must be accounted for, but saw unknown field %q", fn))
 			}
@@ -137,10 +176,14 @@ var _ = Describe("Config", func() {
 
 	Context("cloning", func() {
 		It("clones function fields", func() {
-			var calledAllowConnectionWindowIncrease, calledTracer bool
+			var calledAllowConnectionWindowIncrease, calledCongestionControl, calledTracer bool
 			c1 := &Config{
 				GetConfigForClient:            func(info *ClientHelloInfo) (*Config, error) { return nil, errors.New("nope") },
 				AllowConnectionWindowIncrease: func(Connection, uint64) bool { calledAllowConnectionWindowIncrease = true; return true },
+				CongestionControl: func(*congestion.ConnectionInfo) congestion.SendAlgorithm {
+					calledCongestionControl = true
+					return nil
+				},
 				Tracer: func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer {
 					calledTracer = true
 					return nil
@@ -151,6 +194,8 @@ var _ = Describe("Config", func() {
 			Expect(calledAllowConnectionWindowIncrease).To(BeTrue())
 			_, err := c2.GetConfigForClient(&ClientHelloInfo{})
 			Expect(err).To(MatchError("nope"))
+			c2.CongestionControl(&congestion.ConnectionInfo{})
+			Expect(calledCongestionControl).To(BeTrue())
 			c2.Tracer(context.Background(), logging.PerspectiveClient, protocol.ConnectionID{})
 			Expect(calledTracer).To(BeTrue())
 		})
@@ -185,6 +230,7 @@ var _ = Describe("Config", func() {
 			Expect(c.MaxConnectionReceiveWindow).To(BeEquivalentTo(protocol.DefaultMaxReceiveConnectionFlowControlWindow))
 			Expect(c.MaxIncomingStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingStreams))
 			Expect(c.MaxIncomingUniStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingUniStreams))
+			Expect(c.DatagramReceiveQueueLen).To(Equal(protocol.DefaultMaxDatagramReceiveQueueLen))
 			Expect(c.DisablePathMTUDiscovery).To(BeFalse())
 			Expect(c.GetConfigForClient).To(BeNil())
 		})
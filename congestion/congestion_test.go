@@ -0,0 +1,92 @@
+package congestion_test
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/congestion"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Congestion Control", func() {
+	for _, tc := range []struct {
+		name             string
+		newSendAlgorithm func(*congestion.ConnectionInfo) congestion.SendAlgorithm
+	}{
+		{name: "NewReno", newSendAlgorithm: congestion.NewReno},
+		{name: "CUBIC", newSendAlgorithm: congestion.NewCubic},
+		{name: "BBR", newSendAlgorithm: congestion.NewBBR},
+		{name: "Prague", newSendAlgorithm: congestion.NewPrague},
+		{name: "CUBIC with HyStart++", newSendAlgorithm: congestion.NewCubicWithConfig(&congestion.CubicConfig{HyStartPlusPlus: true})},
+		{name: "NewReno with HyStart++", newSendAlgorithm: congestion.NewCubicWithConfig(&congestion.CubicConfig{Reno: true, HyStartPlusPlus: true})},
+	} {
+		name := tc.name
+		newSendAlgorithm := tc.newSendAlgorithm
+
+		It(name+" starts in slow start", func() {
+			c := newSendAlgorithm(&congestion.ConnectionInfo{
+				RTTStats:               &utils.RTTStats{},
+				InitialMaxDatagramSize: 1000,
+			})
+			Expect(c.InSlowStart()).To(BeTrue())
+			Expect(c.InRecovery()).To(BeFalse())
+			Expect(c.GetCongestionWindow()).To(Equal(congestion.ByteCount(32 * 1000)))
+			Expect(c.CanSend(31 * 1000)).To(BeTrue())
+			Expect(c.CanSend(32 * 1000)).To(BeFalse())
+		})
+	}
+
+	It("uses L4S for Prague", func() {
+		info := &congestion.ConnectionInfo{RTTStats: &utils.RTTStats{}, InitialMaxDatagramSize: 1000}
+		_, isL4S := congestion.NewPrague(info).(congestion.L4SSendAlgorithm)
+		Expect(isL4S).To(BeTrue())
+		_, isL4S = congestion.NewCubic(info).(congestion.L4SSendAlgorithm)
+		Expect(isL4S).To(BeFalse())
+	})
+
+	It("creates a delay-based congestion controller for media", func() {
+		type report struct {
+			info    *congestion.ConnectionInfo
+			bitrate congestion.Bandwidth
+		}
+		var reported []report
+		newSendAlgorithm := congestion.NewMedia(&congestion.MediaConfig{
+			MinBitrate:     100 * 1000 * congestion.BitsPerSecond,
+			InitialBitrate: 400 * 1000 * congestion.BitsPerSecond,
+			OnTargetBitrate: func(info *congestion.ConnectionInfo, bw congestion.Bandwidth) {
+				reported = append(reported, report{info: info, bitrate: bw})
+			},
+		})
+		info1 := &congestion.ConnectionInfo{RTTStats: &utils.RTTStats{}, InitialMaxDatagramSize: 1000}
+		info2 := &congestion.ConnectionInfo{RTTStats: &utils.RTTStats{}, InitialMaxDatagramSize: 1000}
+		c1 := newSendAlgorithm(info1)
+		c2 := newSendAlgorithm(info2)
+		Expect(c1.InSlowStart()).To(BeFalse())
+		c2.OnRetransmissionTimeout(true)
+		c1.OnRetransmissionTimeout(true)
+		Expect(reported).To(Equal([]report{
+			{info: info2, bitrate: 100 * 1000 * congestion.BitsPerSecond},
+			{info: info1, bitrate: 100 * 1000 * congestion.BitsPerSecond},
+		}))
+	})
+
+	It("paces packets", func() {
+		const bandwidth = 1e6 * congestion.BytesPerSecond // 1 MB/s
+		p := congestion.NewPacer(func() congestion.Bandwidth { return bandwidth })
+		p.SetMaxDatagramSize(1000)
+		now := time.Now()
+		Expect(p.TimeUntilSend()).To(BeZero())
+		// use up the initial burst budget
+		for p.Budget(now) >= 1000 {
+			p.SentPacket(now, 1000)
+		}
+		Expect(p.TimeUntilSend()).To(BeTemporally(">", now))
+		Expect(p.Budget(p.TimeUntilSend())).To(BeNumerically(">=", 1000))
+	})
+
+	It("calculates the bandwidth", func() {
+		Expect(congestion.BandwidthFromDelta(1000, time.Millisecond)).To(Equal(1e6 * congestion.BytesPerSecond))
+	})
+})
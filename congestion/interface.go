@@ -0,0 +1,149 @@
+// Package congestion defines the interface for pluggable congestion controllers.
+// This package should not be considered stable.
+package congestion
+
+import (
+	"context"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/congestion"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+)
+
+type (
+	// A ByteCount is used to count bytes.
+	ByteCount = protocol.ByteCount
+	// The PacketNumber is the packet number of a packet.
+	PacketNumber = protocol.PacketNumber
+	// The EncryptionLevel is the encryption level of a packet.
+	EncryptionLevel = protocol.EncryptionLevel
+	// Bandwidth is a bandwidth, in bits per second.
+	Bandwidth = congestion.Bandwidth
+)
+
+const (
+	// BitsPerSecond is 1 bit per second
+	BitsPerSecond = congestion.BitsPerSecond
+	// BytesPerSecond is 1 byte per second
+	BytesPerSecond = congestion.BytesPerSecond
+)
+
+const (
+	// EncryptionInitial is the Initial encryption level
+	EncryptionInitial EncryptionLevel = protocol.EncryptionInitial
+	// EncryptionHandshake is the Handshake encryption level
+	EncryptionHandshake EncryptionLevel = protocol.EncryptionHandshake
+	// Encryption0RTT is the 0-RTT encryption level
+	Encryption0RTT EncryptionLevel = protocol.Encryption0RTT
+	// Encryption1RTT is the 1-RTT encryption level
+	Encryption1RTT EncryptionLevel = protocol.Encryption1RTT
+)
+
+// The RTTStats contain the RTT measurements of the path.
+// They are maintained by the connection, and updated before the SendAlgorithm is notified about acknowledged packets.
+type RTTStats interface {
+	// MinRTT returns the minimum RTT, or 0 if no RTT sample was taken yet.
+	MinRTT() time.Duration
+	// LatestRTT returns the most recent RTT sample.
+	LatestRTT() time.Duration
+	// SmoothedRTT returns the smoothed RTT (RFC 9002, section 5.3).
+	SmoothedRTT() time.Duration
+	// MeanDeviation returns the mean deviation of the RTT samples (rttvar).
+	MeanDeviation() time.Duration
+	// PTO returns the probe timeout duration (RFC 9002, section 6.2.1).
+	PTO(includeMaxAckDelay bool) time.Duration
+}
+
+var _ congestion.RTTStats = RTTStats(nil)
+
+// A SendAlgorithm performs congestion control.
+// It is used from a single goroutine, and doesn't need to be safe for concurrent use.
+//
+// Packet numbers are only unique within a packet number space.
+// Initial, Handshake and application data (0-RTT and 1-RTT) packets each use a separate packet number space,
+// so the encryption level is passed along with every packet number.
+type SendAlgorithm interface {
+	// TimeUntilSend returns when the next packet should be sent.
+	// It returns the zero value of time.Time if a packet can be sent immediately.
+	TimeUntilSend(bytesInFlight ByteCount) time.Time
+	// HasPacingBudget says if the pacer allows sending a packet at this time.
+	HasPacingBudget(now time.Time) bool
+	// OnPacketSent is called for every packet sent.
+	// Only packets that are retransmittable count towards bytes in flight.
+	OnPacketSent(sentTime time.Time, bytesInFlight ByteCount, encLevel EncryptionLevel, packetNumber PacketNumber, bytes ByteCount, isRetransmittable bool)
+	// CanSend says if the congestion window allows sending more data.
+	CanSend(bytesInFlight ByteCount) bool
+	// MaybeExitSlowStart is called after an ACK was processed that increased the RTT sample.
+	MaybeExitSlowStart()
+	// OnPacketAcked is called for every packet that is acknowledged.
+	OnPacketAcked(encLevel EncryptionLevel, number PacketNumber, ackedBytes ByteCount, priorInFlight ByteCount, eventTime time.Time)
+	// OnCongestionEvent is called when a packet is declared lost,
+	// or when the peer reports an increase in the number of ECN-CE marked packets (in that case, lostBytes is 0).
+	OnCongestionEvent(encLevel EncryptionLevel, number PacketNumber, lostBytes ByteCount, priorInFlight ByteCount)
+	// OnPacketDiscarded is called for a packet that will neither be acknowledged nor declared lost,
+	// e.g. for a lost Path MTU probe packet.
+	OnPacketDiscarded(encLevel EncryptionLevel, number PacketNumber)
+	// OnPacketsDropped is called when all outstanding packets of an encryption level are dropped,
+	// i.e. when the Initial or Handshake keys are dropped, or when 0-RTT is rejected.
+	// None of these packets will be acknowledged or declared lost.
+	OnPacketsDropped(encLevel EncryptionLevel)
+	// OnRetransmissionTimeout is called when the probe timeout (PTO) fires.
+	OnRetransmissionTimeout(packetsRetransmitted bool)
+	// SetMaxDatagramSize is called when the maximum datagram size changes, e.g. as a result of Path MTU discovery.
+	SetMaxDatagramSize(ByteCount)
+	// InSlowStart says if the congestion controller is in slow start.
+	InSlowStart() bool
+	// InRecovery says if the congestion controller is in recovery.
+	InRecovery() bool
+	// GetCongestionWindow returns the congestion window.
+	GetCongestionWindow() ByteCount
+}
+
+var _ congestion.SendAlgorithmWithDebugInfos = SendAlgorithm(nil)
+
+// An L4SSendAlgorithm is a SendAlgorithm for Low Latency, Low Loss, and Scalable Throughput (L4S, RFC 9330).
+// If the congestion controller implements this interface, packets are sent with the ECT(1) codepoint
+// instead of ECT(0) (RFC 9331).
+type L4SSendAlgorithm interface {
+	SendAlgorithm
+	// OnECNFeedback is called for every ACK frame that increases the largest acknowledged packet number,
+	// before OnPacketAcked is called for the newly acknowledged packets.
+	// It reports the number of newly acknowledged packets and the increase of the ECN-CE counter.
+	// Increases of the ECN-CE counter are not reported via OnCongestionEvent.
+	OnECNFeedback(ackedPackets, newECNCE int64)
+}
+
+var _ congestion.L4SSendAlgorithm = L4SSendAlgorithm(nil)
+
+// A CarefulResumeSendAlgorithm is a SendAlgorithm that can use the congestion state of a previous connection
+// to the same peer to ramp up faster (draft-ietf-tsvwg-careful-resume).
+// NewReno and CUBIC implement this interface. BBR, Prague and the congestion controller for interactive media don't,
+// so Config.EnableCarefulResume has no effect when using them.
+type CarefulResumeSendAlgorithm interface {
+	SendAlgorithm
+	// SetResumeState is called before any packet is sent, with the minimum RTT and the congestion window
+	// saved at the end of the previous connection.
+	// The saved congestion window must only be used after validating that the path didn't change.
+	SetResumeState(savedRTT time.Duration, savedCongestionWindow ByteCount)
+}
+
+var _ congestion.CarefulResumeSendAlgorithm = CarefulResumeSendAlgorithm(nil)
+
+// ConnectionInfo contains the information needed to create a SendAlgorithm.
+type ConnectionInfo struct {
+	// Context is the context of the connection (see Connection.Context).
+	// It can be used to associate the congestion controller with the connection.
+	Context context.Context
+	// Perspective is the role of the endpoint (client or server).
+	Perspective logging.Perspective
+	// RTTStats are the RTT measurements.
+	// They are maintained by the connection and can be accessed by the SendAlgorithm at any time.
+	RTTStats RTTStats
+	// InitialMaxDatagramSize is the maximum datagram size when the SendAlgorithm is created.
+	// Later changes are reported using SendAlgorithm.SetMaxDatagramSize.
+	InitialMaxDatagramSize ByteCount
+	// Tracer is the tracer of the connection. It may be nil.
+	// It can be used to report congestion state changes and metrics.
+	Tracer *logging.ConnectionTracer
+}
@@ -0,0 +1,45 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/congestion"
+)
+
+// A Pacer implements a token bucket pacing algorithm.
+// It can be used by SendAlgorithm implementations to spread out packets over the RTT.
+type Pacer struct {
+	p congestion.Pacer
+}
+
+// NewPacer creates a new Pacer.
+// The pacing rate is derived from the bandwidth estimate returned by getBandwidth.
+func NewPacer(getBandwidth func() Bandwidth) *Pacer {
+	return &Pacer{p: congestion.NewPacer(getBandwidth)}
+}
+
+// SentPacket must be called for every packet sent.
+func (p *Pacer) SentPacket(sendTime time.Time, size ByteCount) {
+	p.p.SentPacket(sendTime, size)
+}
+
+// Budget returns the number of bytes that can be sent at the given time.
+func (p *Pacer) Budget(now time.Time) ByteCount {
+	return p.p.Budget(now)
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+// It returns the zero value of time.Time if a packet can be sent immediately.
+func (p *Pacer) TimeUntilSend() time.Time {
+	return p.p.TimeUntilSend()
+}
+
+// SetMaxDatagramSize sets the maximum datagram size.
+func (p *Pacer) SetMaxDatagramSize(s ByteCount) {
+	p.p.SetMaxDatagramSize(s)
+}
+
+// BandwidthFromDelta calculates the bandwidth from a number of bytes and a time delta.
+func BandwidthFromDelta(bytes ByteCount, delta time.Duration) Bandwidth {
+	return congestion.BandwidthFromDelta(bytes, delta)
+}
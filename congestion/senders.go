@@ -0,0 +1,105 @@
+package congestion
+
+import "github.com/quic-go/quic-go/internal/congestion"
+
+// NewCubic creates a congestion controller implementing CUBIC (RFC 9438).
+func NewCubic(info *ConnectionInfo) SendAlgorithm {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, info.RTTStats, info.InitialMaxDatagramSize, false, false, info.Tracer)
+}
+
+// NewReno creates a congestion controller implementing NewReno (RFC 9002).
+// This is the congestion controller used if Config.CongestionControl is not set.
+func NewReno(info *ConnectionInfo) SendAlgorithm {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, info.RTTStats, info.InitialMaxDatagramSize, true, false, info.Tracer)
+}
+
+// CubicConfig configures the loss-based congestion controllers created by NewCubicWithConfig.
+type CubicConfig struct {
+	// Reno selects NewReno instead of CUBIC for the congestion avoidance phase.
+	Reno bool
+	// HyStartPlusPlus selects HyStart++ (RFC 9406) to exit slow start.
+	// When it detects an increase in RTT, HyStart++ continues to grow the congestion window more slowly
+	// for a few round trips (Conservative Slow Start), instead of exiting slow start right away.
+	// This improves the utilization of paths with a large bandwidth-delay product.
+	// If false, HyStart is used, as in NewCubic and NewReno.
+	HyStartPlusPlus bool
+}
+
+// NewCubicWithConfig returns a function that creates a CUBIC or NewReno congestion controller,
+// to be used as Config.CongestionControl.
+func NewCubicWithConfig(conf *CubicConfig) func(*ConnectionInfo) SendAlgorithm {
+	if conf == nil {
+		conf = &CubicConfig{}
+	}
+	return func(info *ConnectionInfo) SendAlgorithm {
+		return congestion.NewCubicSender(
+			congestion.DefaultClock{},
+			info.RTTStats,
+			info.InitialMaxDatagramSize,
+			conf.Reno,
+			conf.HyStartPlusPlus,
+			info.Tracer,
+		)
+	}
+}
+
+// NewBBR creates a congestion controller implementing BBR (version 1).
+// Instead of reacting to packet loss, BBR estimates the bottleneck bandwidth and the minimum RTT of the path,
+// and paces packets at the estimated bandwidth. This avoids filling deep buffers on the path.
+func NewBBR(info *ConnectionInfo) SendAlgorithm {
+	return congestion.NewBBRSender(info.RTTStats, info.InitialMaxDatagramSize, info.Tracer)
+}
+
+// NewPrague creates a congestion controller implementing Prague, the reference congestion controller for L4S.
+// Packets are sent with the ECT(1) codepoint. Prague reduces the congestion window in proportion to
+// the fraction of ECN-CE marked packets, which allows L4S bottlenecks to keep the queue very short.
+// On paths that don't support L4S, it reacts to packet loss like NewReno.
+func NewPrague(info *ConnectionInfo) SendAlgorithm {
+	return congestion.NewPragueSender(info.RTTStats, info.InitialMaxDatagramSize, info.Tracer)
+}
+
+// MediaConfig configures the delay-based congestion controller for interactive media.
+type MediaConfig struct {
+	// MinBitrate is the minimum target bitrate.
+	// If zero, 100 kbit/s is used.
+	MinBitrate Bandwidth
+	// MaxBitrate is the maximum target bitrate.
+	// If zero, 50 Mbit/s is used.
+	MaxBitrate Bandwidth
+	// InitialBitrate is the target bitrate used at the beginning of the connection.
+	// If zero, 1 Mbit/s is used.
+	InitialBitrate Bandwidth
+	// OnTargetBitrate is called when the target bitrate of a connection changes by more than 1%.
+	// The ConnectionInfo is the one passed to the congestion controller of that connection,
+	// its Context identifies the connection.
+	// Media encoders should adapt their output to the target bitrate.
+	// It is called from the connection's run loop and must not block.
+	OnTargetBitrate func(*ConnectionInfo, Bandwidth)
+}
+
+// NewMedia returns a function that creates a delay-based congestion controller for interactive media,
+// to be used as Config.CongestionControl.
+// In the spirit of Google Congestion Control and SCReAM (RFC 8298), it detects the build-up of a queue
+// from the gradient of the queueing delay, before packets are lost, and adjusts a target bitrate.
+// Packets are paced at the target bitrate.
+// When the connection migrates to a new path, a new congestion controller is created, starting at the initial bitrate.
+func NewMedia(conf *MediaConfig) func(*ConnectionInfo) SendAlgorithm {
+	if conf == nil {
+		conf = &MediaConfig{}
+	}
+	return func(info *ConnectionInfo) SendAlgorithm {
+		var onTargetBitrate func(Bandwidth)
+		if conf.OnTargetBitrate != nil {
+			onTargetBitrate = func(bw Bandwidth) { conf.OnTargetBitrate(info, bw) }
+		}
+		return congestion.NewMediaSender(
+			info.RTTStats,
+			info.InitialMaxDatagramSize,
+			conf.MinBitrate,
+			conf.MaxBitrate,
+			conf.InitialBitrate,
+			onTargetBitrate,
+			info.Tracer,
+		)
+	}
+}
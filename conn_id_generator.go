@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -21,6 +22,16 @@ type connIDGenerator struct {
 	retireConnectionID     func(protocol.ConnectionID)
 	replaceWithClosed      func([]protocol.ConnectionID, []byte)
 	queueControlFrame      func(wire.Frame)
+
+	// additional connection runners, one for each Transport used for a path other than the original path
+	connRunners []connRunnerCallbacks
+}
+
+type connRunnerCallbacks struct {
+	AddConnectionID    func(protocol.ConnectionID)
+	RemoveConnectionID func(protocol.ConnectionID)
+	RetireConnectionID func(protocol.ConnectionID)
+	ReplaceWithClosed  func([]protocol.ConnectionID, []byte)
 }
 
 func newConnIDGenerator(
@@ -57,8 +68,8 @@ func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
 	// connection IDs the peer will store. This limit includes the connection ID
 	// used during the handshake, and the one sent in the preferred_address
 	// transport parameter.
-	// We currently don't send the preferred_address transport parameter,
-	// so we can issue (limit - 1) connection IDs.
+	// If we sent the preferred_address transport parameter, we can issue (limit - 2)
+	// connection IDs, otherwise (limit - 1).
 	for i := uint64(len(m.activeSrcConnIDs)); i < min(limit, protocol.MaxIssuedConnectionIDs); i++ {
 		if err := m.issueNewConnID(); err != nil {
 			return err
@@ -86,6 +97,9 @@ func (m *connIDGenerator) Retire(seq uint64, sentWithDestConnID protocol.Connect
 		}
 	}
 	m.retireConnectionID(connID)
+	for _, r := range m.connRunners {
+		r.RetireConnectionID(connID)
+	}
 	delete(m.activeSrcConnIDs, seq)
 	// Don't issue a replacement for the initial connection ID.
 	if seq == 0 {
@@ -94,6 +108,26 @@ func (m *connIDGenerator) Retire(seq uint64, sentWithDestConnID protocol.Connect
 	return m.issueNewConnID()
 }
 
+// IssuePreferredAddressConnID issues the connection ID sent in the preferred_address transport parameter.
+// This connection ID has the sequence number 1, see section 5.1.1 of RFC 9000.
+// It must be called before any other connection IDs are issued.
+func (m *connIDGenerator) IssuePreferredAddressConnID() (protocol.ConnectionID, protocol.StatelessResetToken, error) {
+	if m.highestSeq != 0 {
+		return protocol.ConnectionID{}, protocol.StatelessResetToken{}, errors.New("connection IDs were already issued")
+	}
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return protocol.ConnectionID{}, protocol.StatelessResetToken{}, err
+	}
+	m.activeSrcConnIDs[1] = connID
+	m.addConnectionID(connID)
+	for _, r := range m.connRunners {
+		r.AddConnectionID(connID)
+	}
+	m.highestSeq = 1
+	return connID, m.getStatelessResetToken(connID), nil
+}
+
 func (m *connIDGenerator) issueNewConnID() error {
 	connID, err := m.generator.GenerateConnectionID()
 	if err != nil {
@@ -101,6 +135,9 @@ func (m *connIDGenerator) issueNewConnID() error {
 	}
 	m.activeSrcConnIDs[m.highestSeq+1] = connID
 	m.addConnectionID(connID)
+	for _, r := range m.connRunners {
+		r.AddConnectionID(connID)
+	}
 	m.queueControlFrame(&wire.NewConnectionIDFrame{
 		SequenceNumber:      m.highestSeq + 1,
 		ConnectionID:        connID,
@@ -123,6 +160,9 @@ func (m *connIDGenerator) RemoveAll() {
 	}
 	for _, connID := range m.activeSrcConnIDs {
 		m.removeConnectionID(connID)
+		for _, r := range m.connRunners {
+			r.RemoveConnectionID(connID)
+		}
 	}
 }
 
@@ -135,4 +175,17 @@ func (m *connIDGenerator) ReplaceWithClosed(connClose []byte) {
 		connIDs = append(connIDs, connID)
 	}
 	m.replaceWithClosed(connIDs, connClose)
+	for _, r := range m.connRunners {
+		r.ReplaceWithClosed(connIDs, connClose)
+	}
+}
+
+// AddConnRunner registers an additional connection runner.
+// All currently active connection IDs are added to this runner,
+// and it is informed about all future changes to the set of active connection IDs.
+func (m *connIDGenerator) AddConnRunner(r connRunnerCallbacks) {
+	for _, connID := range m.activeSrcConnIDs {
+		r.AddConnectionID(connID)
+	}
+	m.connRunners = append(m.connRunners, r)
 }
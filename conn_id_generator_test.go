@@ -72,6 +72,25 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(queuedFrames).To(HaveLen(protocol.MaxIssuedConnectionIDs - 1))
 	})
 
+	It("issues the connection ID for the preferred address", func() {
+		connID, token, err := g.IssuePreferredAddressConnID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Len()).To(Equal(7))
+		Expect(token).To(Equal(connIDToToken(connID)))
+		Expect(addedConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+		// the preferred address connection ID is not sent in a NEW_CONNECTION_ID frame
+		Expect(queuedFrames).To(BeEmpty())
+		// the preferred address connection ID counts towards the limit
+		Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+		Expect(addedConnIDs).To(HaveLen(3))
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(queuedFrames[0].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(2))
+		Expect(queuedFrames[1].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(3))
+		// it can only be issued before any other connection IDs
+		_, _, err = g.IssuePreferredAddressConnID()
+		Expect(err).To(HaveOccurred())
+	})
+
 	// SetMaxActiveConnIDs is called twice when dialing a 0-RTT connection:
 	// once for the restored from the old connections, once when we receive the transport parameters
 	Context("dealing with 0-RTT", func() {
@@ -184,4 +203,26 @@ var _ = Describe("Connection ID Generator", func() {
 			Expect(replacedWithClosed).To(ContainElement(nf.ConnectionID))
 		}
 	})
+
+	It("informs additional connection runners", func() {
+		Expect(g.SetMaxActiveConnIDs(3)).To(Succeed())
+		var added, retired, removed, closed []protocol.ConnectionID
+		g.AddConnRunner(connRunnerCallbacks{
+			AddConnectionID:    func(c protocol.ConnectionID) { added = append(added, c) },
+			RemoveConnectionID: func(c protocol.ConnectionID) { removed = append(removed, c) },
+			RetireConnectionID: func(c protocol.ConnectionID) { retired = append(retired, c) },
+			ReplaceWithClosed:  func(cs []protocol.ConnectionID, _ []byte) { closed = append(closed, cs...) },
+		})
+		// all active connection IDs are added right away
+		Expect(added).To(HaveLen(3))
+		Expect(added).To(ContainElement(initialConnID))
+		Expect(g.Retire(1, protocol.ConnectionID{})).To(Succeed())
+		Expect(retired).To(Equal(retiredConnIDs))
+		Expect(added).To(HaveLen(4))
+		Expect(added[3]).To(Equal(addedConnIDs[len(addedConnIDs)-1]))
+		g.RemoveAll()
+		Expect(removed).To(HaveLen(3))
+		g.ReplaceWithClosed([]byte("foobar"))
+		Expect(closed).To(Equal(replacedWithClosed))
+	})
 })
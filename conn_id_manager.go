@@ -25,6 +25,9 @@ type connIDManager struct {
 	activeConnectionID        protocol.ConnectionID
 	activeStatelessResetToken *protocol.StatelessResetToken
 
+	// connection IDs reserved for probing new paths
+	pathProbing map[pathID]newConnID
+
 	// We change the connection ID after sending on average
 	// protocol.PacketsPerConnectionID packets. The actual value is randomized
 	// hide the packet loss rate from on-path observers.
@@ -89,6 +92,16 @@ func (h *connIDManager) add(f *wire.NewConnectionIDFrame) error {
 			})
 			h.queue.Remove(el)
 		}
+		for id, entry := range h.pathProbing {
+			if entry.SequenceNumber >= f.RetirePriorTo {
+				continue
+			}
+			h.queueControlFrame(&wire.RetireConnectionIDFrame{
+				SequenceNumber: entry.SequenceNumber,
+			})
+			h.removeStatelessResetToken(entry.StatelessResetToken)
+			delete(h.pathProbing, id)
+		}
 		h.highestRetired = f.RetirePriorTo
 	}
 
@@ -163,6 +176,9 @@ func (h *connIDManager) Close() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
 	}
+	for _, entry := range h.pathProbing {
+		h.removeStatelessResetToken(entry.StatelessResetToken)
+	}
 }
 
 // is called when the server performs a Retry
@@ -212,3 +228,71 @@ func (h *connIDManager) Get() protocol.ConnectionID {
 func (h *connIDManager) SetHandshakeComplete() {
 	h.handshakeComplete = true
 }
+
+// GetConnIDForPath returns the connection ID used for probing a new path.
+// Every path uses a different connection ID, such that the new path can't be linked to the old one.
+// Repeated calls for the same path return the same connection ID.
+func (h *connIDManager) GetConnIDForPath(id pathID) (protocol.ConnectionID, bool) {
+	// If the peer uses zero-length connection IDs, there's nothing to link.
+	if h.activeConnectionID.Len() == 0 {
+		return h.activeConnectionID, true
+	}
+	if entry, ok := h.pathProbing[id]; ok {
+		return entry.ConnectionID, true
+	}
+	if h.queue.Len() == 0 {
+		return protocol.ConnectionID{}, false
+	}
+	if h.pathProbing == nil {
+		h.pathProbing = make(map[pathID]newConnID)
+	}
+	front := h.queue.Remove(h.queue.Front())
+	h.pathProbing[id] = front
+	h.addStatelessResetToken(front.StatelessResetToken)
+	return front.ConnectionID, true
+}
+
+// RetireConnIDForPath retires the connection ID used for probing a path that was abandoned.
+func (h *connIDManager) RetireConnIDForPath(id pathID) {
+	entry, ok := h.pathProbing[id]
+	if !ok {
+		return
+	}
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{
+		SequenceNumber: entry.SequenceNumber,
+	})
+	h.highestRetired = max(h.highestRetired, entry.SequenceNumber)
+	h.removeStatelessResetToken(entry.StatelessResetToken)
+	delete(h.pathProbing, id)
+}
+
+// SwitchToPath makes the connection ID used for probing a path the active connection ID.
+// The previously active connection ID is retired.
+// It returns false if there's no connection ID available for this path.
+func (h *connIDManager) SwitchToPath(id pathID) bool {
+	if h.activeConnectionID.Len() == 0 {
+		return true
+	}
+	entry, ok := h.pathProbing[id]
+	if !ok {
+		// The connection ID might have been retired by a NEW_CONNECTION_ID frame's Retire Prior To field.
+		if _, ok := h.GetConnIDForPath(id); !ok {
+			return false
+		}
+		entry = h.pathProbing[id]
+	}
+	delete(h.pathProbing, id)
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{
+		SequenceNumber: h.activeSequenceNumber,
+	})
+	h.highestRetired = max(h.highestRetired, h.activeSequenceNumber)
+	if h.activeStatelessResetToken != nil {
+		h.removeStatelessResetToken(*h.activeStatelessResetToken)
+	}
+	h.activeSequenceNumber = entry.SequenceNumber
+	h.activeConnectionID = entry.ConnectionID
+	h.activeStatelessResetToken = &entry.StatelessResetToken
+	h.packetsSinceLastChange = 0
+	h.packetsPerConnectionID = protocol.PacketsPerConnectionID/2 + uint32(h.rand.Int31n(protocol.PacketsPerConnectionID))
+	return true
+}
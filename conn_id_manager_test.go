@@ -361,4 +361,88 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(removedTokens).To(HaveLen(1))
 		Expect(removedTokens[0]).To(Equal(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}))
 	})
+
+	Context("connection IDs for path probing", func() {
+		BeforeEach(func() {
+			for i := uint8(1); i <= 3; i++ {
+				Expect(m.Add(&wire.NewConnectionIDFrame{
+					SequenceNumber:      uint64(i),
+					ConnectionID:        protocol.ParseConnectionID([]byte{i, i, i, i}),
+					StatelessResetToken: protocol.StatelessResetToken{i, i, i, i, i, i, i, i, i, i, i, i, i, i, i, i},
+				})).To(Succeed())
+			}
+			m.SetHandshakeComplete()
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			frameQueue = nil
+			removedTokens = nil
+		})
+
+		It("uses a different connection ID for every path", func() {
+			connID1, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID1).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}))
+			// repeated calls return the same connection ID
+			connID, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(connID1))
+			connID2, ok := m.GetConnIDForPath(2)
+			Expect(ok).To(BeTrue())
+			Expect(connID2).To(Equal(protocol.ParseConnectionID([]byte{3, 3, 3, 3})))
+			_, ok = m.GetConnIDForPath(3)
+			Expect(ok).To(BeFalse())
+			// the active connection ID is not affected
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+			Expect(frameQueue).To(BeEmpty())
+		})
+
+		It("retires the connection ID of an abandoned path", func() {
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			m.RetireConnIDForPath(1)
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 2}}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}}))
+			// retiring a path that doesn't have a connection ID is a no-op
+			m.RetireConnIDForPath(1)
+			Expect(frameQueue).To(HaveLen(1))
+		})
+
+		It("switches to the connection ID of a path", func() {
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(m.SwitchToPath(1)).To(BeTrue())
+			Expect(m.Get()).To(Equal(protocol.ParseConnectionID([]byte{2, 2, 2, 2})))
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			Expect(removedTokens).To(Equal([]protocol.StatelessResetToken{{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}}))
+			// the path's connection ID is now the active connection ID
+			m.RetireConnIDForPath(1)
+			Expect(frameQueue).To(HaveLen(1))
+		})
+
+		It("retires connection IDs used for path probing when the peer requests it", func() {
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      4,
+				ConnectionID:        protocol.ParseConnectionID([]byte{4, 4, 4, 4}),
+				StatelessResetToken: protocol.StatelessResetToken{4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4},
+				RetirePriorTo:       3,
+			})).To(Succeed())
+			Expect(frameQueue).To(ContainElement(&wire.RetireConnectionIDFrame{SequenceNumber: 2}))
+			Expect(removedTokens).To(ContainElement(protocol.StatelessResetToken{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}))
+			connID, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{4, 4, 4, 4})))
+		})
+
+		It("removes the stateless reset tokens of paths when it is closed", func() {
+			_, ok := m.GetConnIDForPath(1)
+			Expect(ok).To(BeTrue())
+			m.Close()
+			Expect(removedTokens).To(ConsistOf(
+				protocol.StatelessResetToken{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+				protocol.StatelessResetToken{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2},
+			))
+		})
+	})
 })
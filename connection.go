@@ -8,11 +8,13 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/netip"
 	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/ackhandler"
 	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/handshake"
@@ -22,6 +24,7 @@ import (
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 	"github.com/quic-go/quic-go/logging"
+	"github.com/quic-go/quic-go/quicvarint"
 )
 
 type unpacker interface {
@@ -37,6 +40,8 @@ type streamGetter interface {
 type streamManager interface {
 	GetOrOpenSendStream(protocol.StreamID) (sendStreamI, error)
 	GetOrOpenReceiveStream(protocol.StreamID) (receiveStreamI, error)
+	GetReceiveStream(protocol.StreamID) (receiveStreamI, bool)
+	SetMaxIncomingStreams(protocol.StreamType, uint64)
 	OpenStream() (Stream, error)
 	OpenUniStream() (SendStream, error)
 	OpenStreamSync(context.Context) (Stream, error)
@@ -73,18 +78,21 @@ type receivedPacket struct {
 	ecn protocol.ECN
 
 	info packetInfo // only valid if the contained IP address is valid
+
+	onPreferredAddress bool // only used by the server: was the packet received on the preferred address
 }
 
 func (p *receivedPacket) Size() protocol.ByteCount { return protocol.ByteCount(len(p.data)) }
 
 func (p *receivedPacket) Clone() *receivedPacket {
 	return &receivedPacket{
-		remoteAddr: p.remoteAddr,
-		rcvTime:    p.rcvTime,
-		data:       p.data,
-		buffer:     p.buffer,
-		ecn:        p.ecn,
-		info:       p.info,
+		remoteAddr:         p.remoteAddr,
+		rcvTime:            p.rcvTime,
+		data:               p.data,
+		buffer:             p.buffer,
+		ecn:                p.ecn,
+		info:               p.info,
+		onPreferredAddress: p.onPreferredAddress,
 	}
 }
 
@@ -138,6 +146,16 @@ type connection struct {
 	connIDManager   *connIDManager
 	connIDGenerator *connIDGenerator
 
+	pathManager         *pathManager         // only set for the server
+	pathManagerOutgoing *pathManagerOutgoing // only set for the client
+
+	// only set for the server, if a preferred address is configured
+	preferredAddressConn rawConn
+	// only used by the server: did the client migrate to the preferred address
+	onPreferredAddress bool
+	// only set for the client, if the server sent a preferred address
+	preferredAddressPath *Path
+
 	rttStats *utils.RTTStats
 
 	cryptoStreamManager   *cryptoStreamManager
@@ -147,7 +165,8 @@ type connection struct {
 	framer                framer
 	windowUpdateQueue     *windowUpdateQueue
 	connFlowController    flowcontrol.ConnectionFlowController
-	tokenStoreKey         string                    // only set for the client
+	memoryBudget          *flowcontrol.MemoryBudget // shared by all connections of a Transport
+	tokenStoreKey         string                    // for the server: the token sent in the NEW_TOKEN frame
 	tokenGenerator        *handshake.TokenGenerator // only set for the server
 
 	unpacker      unpacker
@@ -172,6 +191,7 @@ type connection struct {
 	ctx                   context.Context
 	ctxCancel             context.CancelCauseFunc
 	handshakeCompleteChan chan struct{}
+	handshakeCompleteHook func() // called when the handshake completes, may be nil
 
 	undecryptablePackets          []receivedPacket // undecryptable packets, waiting for a change in encryption level
 	undecryptablePacketsToProcess []receivedPacket
@@ -182,6 +202,9 @@ type connection struct {
 	handshakeComplete  bool
 	handshakeConfirmed bool
 
+	// the largest packet number received in a 1-RTT packet
+	largestRcvdAppData protocol.PacketNumber
+
 	receivedRetry       bool
 	versionNegotiated   bool
 	receivedFirstPacket bool
@@ -209,6 +232,9 @@ type connection struct {
 	connStateMutex sync.Mutex
 	connState      ConnectionState
 
+	packetsReceived atomic.Uint64
+	bytesReceived   atomic.Uint64
+
 	logID  string
 	tracer *logging.ConnectionTracer
 	logger utils.Logger
@@ -235,7 +261,9 @@ var newConnection = func(
 	conf *Config,
 	tlsConf *tls.Config,
 	tokenGenerator *handshake.TokenGenerator,
+	memoryBudget *flowcontrol.MemoryBudget,
 	clientAddressValidated bool,
+	clientToken []byte,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
 	v protocol.Version,
@@ -248,6 +276,7 @@ var newConnection = func(
 		handshakeDestConnID: destConnID,
 		srcConnIDLen:        srcConnID.Len(),
 		tokenGenerator:      tokenGenerator,
+		memoryBudget:        memoryBudget,
 		oneRTTStream:        newCryptoStream(),
 		perspective:         protocol.PerspectiveServer,
 		tracer:              tracer,
@@ -276,7 +305,12 @@ var newConnection = func(
 		s.queueControlFrame,
 		connIDGenerator,
 	)
+	var preferredAddress *wire.PreferredAddress
+	if conf.PreferredAddress != nil {
+		preferredAddress = s.setupPreferredAddress(conf.PreferredAddress)
+	}
 	s.preSetup()
+	s.pathManager = newPathManager(s.connIDManager.GetConnIDForPath, s.connIDManager.RetireConnIDForPath, s.rttStats, s.logger)
 	s.sentPacketHandler, s.receivedPacketHandler = ackhandler.NewAckHandler(
 		0,
 		protocol.ByteCount(s.config.InitialPacketSize),
@@ -284,9 +318,13 @@ var newConnection = func(
 		clientAddressValidated,
 		s.conn.capabilities().ECN,
 		s.perspective,
+		s.newCongestionControllerFactory(),
 		s.tracer,
 		s.logger,
 	)
+	if len(clientToken) > 0 {
+		s.maybeUseCongestionState(string(clientToken))
+	}
 	s.maxPayloadSizeEstimate.Store(uint32(estimateMaxPayloadSize(protocol.ByteCount(s.config.InitialPacketSize))))
 	params := &wire.TransportParameters{
 		InitialMaxStreamDataBidiLocal:   protocol.ByteCount(s.config.InitialStreamReceiveWindow),
@@ -299,7 +337,7 @@ var newConnection = func(
 		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:                protocol.AckDelayExponent,
 		MaxUDPPayloadSize:               protocol.MaxPacketBufferSize,
-		DisableActiveMigration:          true,
+		DisableActiveMigration:          s.config.DisableActiveMigration,
 		StatelessResetToken:             &statelessResetToken,
 		OriginalDestinationConnectionID: origDestConnID,
 		// For interoperability with quic-go versions before May 2023, this value must be set to a value
@@ -310,6 +348,12 @@ var newConnection = func(
 		ActiveConnectionIDLimit:   protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID: srcConnID,
 		RetrySourceConnectionID:   retrySrcConnID,
+		PreferredAddress:          preferredAddress,
+		EnableResetStreamAt:       s.config.EnableStreamResetPartialDelivery,
+	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = wire.MaxDatagramSize
@@ -338,6 +382,64 @@ var newConnection = func(
 	return s
 }
 
+// preferredAddressHandler is the packetHandler registered with the Transport used for the preferred address.
+type preferredAddressHandler struct {
+	*connection
+}
+
+func (h *preferredAddressHandler) handlePacket(p receivedPacket) {
+	p.onPreferredAddress = true
+	h.connection.handlePacket(p)
+}
+
+// setupPreferredAddress issues the connection ID for the preferred address,
+// and registers the connection with the Transport used for the preferred address.
+// It returns nil if the preferred address can't be used for this connection.
+func (s *connection) setupPreferredAddress(pa *PreferredAddress) *wire.PreferredAddress {
+	// A server using zero-length connection IDs must not send a preferred address,
+	// see section 18.2 of RFC 9000.
+	if s.srcConnIDLen == 0 {
+		return nil
+	}
+	tr := pa.Transport
+	if err := tr.init(false); err != nil {
+		s.logger.Errorf("Not using preferred address: failed to initialize Transport: %s", err)
+		return nil
+	}
+	if tr.connIDLen != s.srcConnIDLen {
+		s.logger.Errorf("Not using preferred address: connection ID length mismatch: Transport uses %d bytes, connection uses %d bytes", tr.connIDLen, s.srcConnIDLen)
+		return nil
+	}
+	connID, resetToken, err := s.connIDGenerator.IssuePreferredAddressConnID()
+	if err != nil {
+		s.logger.Errorf("Not using preferred address: %s", err)
+		return nil
+	}
+	handler := &preferredAddressHandler{connection: s}
+	s.connIDGenerator.AddConnRunner(connRunnerCallbacks{
+		AddConnectionID:    func(connID protocol.ConnectionID) { tr.handlerMap.Add(connID, handler) },
+		RemoveConnectionID: tr.handlerMap.Remove,
+		RetireConnectionID: tr.handlerMap.Retire,
+		ReplaceWithClosed:  tr.handlerMap.ReplaceWithClosed,
+	})
+	s.preferredAddressConn = tr.conn
+	preferredAddress := &wire.PreferredAddress{
+		IPv4:                pa.IPv4,
+		IPv6:                pa.IPv6,
+		ConnectionID:        connID,
+		StatelessResetToken: resetToken,
+	}
+	// The transport parameter always contains both an IPv4 and an IPv6 address.
+	// Unused addresses are encoded as all zeros.
+	if !preferredAddress.IPv4.IsValid() {
+		preferredAddress.IPv4 = netip.AddrPortFrom(netip.IPv4Unspecified(), 0)
+	}
+	if !preferredAddress.IPv6.IsValid() {
+		preferredAddress.IPv6 = netip.AddrPortFrom(netip.IPv6Unspecified(), 0)
+	}
+	return preferredAddress
+}
+
 // declare this as a variable, such that we can it mock it in the tests
 var newClientConnection = func(
 	ctx context.Context,
@@ -348,6 +450,7 @@ var newClientConnection = func(
 	connIDGenerator ConnectionIDGenerator,
 	conf *Config,
 	tlsConf *tls.Config,
+	memoryBudget *flowcontrol.MemoryBudget,
 	initialPacketNumber protocol.PacketNumber,
 	enable0RTT bool,
 	hasNegotiatedVersion bool,
@@ -361,6 +464,7 @@ var newClientConnection = func(
 		origDestConnID:      destConnID,
 		handshakeDestConnID: destConnID,
 		srcConnIDLen:        srcConnID.Len(),
+		memoryBudget:        memoryBudget,
 		perspective:         protocol.PerspectiveClient,
 		logID:               destConnID.String(),
 		logger:              logger,
@@ -387,6 +491,7 @@ var newClientConnection = func(
 	)
 	s.ctx, s.ctxCancel = context.WithCancelCause(ctx)
 	s.preSetup()
+	s.pathManagerOutgoing = newPathManagerOutgoing(s.ctx, s.scheduleSending)
 	s.sentPacketHandler, s.receivedPacketHandler = ackhandler.NewAckHandler(
 		initialPacketNumber,
 		protocol.ByteCount(s.config.InitialPacketSize),
@@ -394,6 +499,7 @@ var newClientConnection = func(
 		false, // has no effect
 		s.conn.capabilities().ECN,
 		s.perspective,
+		s.newCongestionControllerFactory(),
 		s.tracer,
 		s.logger,
 	)
@@ -418,6 +524,11 @@ var newClientConnection = func(
 		// See https://github.com/quic-go/quic-go/pull/3806.
 		ActiveConnectionIDLimit:   protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID: srcConnID,
+		EnableResetStreamAt:       s.config.EnableStreamResetPartialDelivery,
+	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = wire.MaxDatagramSize
@@ -450,16 +561,35 @@ var newClientConnection = func(
 		if token := s.config.TokenStore.Pop(s.tokenStoreKey); token != nil {
 			s.packer.SetToken(token.data)
 		}
+		s.maybeUseCongestionState(s.tokenStoreKey)
 	}
 	return s
 }
 
+// newCongestionControllerFactory returns the factory for the congestion controller configured by the application.
+// It returns nil if the default congestion controller should be used.
+func (s *connection) newCongestionControllerFactory() ackhandler.CongestionControllerFactory {
+	if s.config.CongestionControl == nil {
+		return nil
+	}
+	return func(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithm {
+		return s.config.CongestionControl(&congestion.ConnectionInfo{
+			Context:                s.ctx,
+			Perspective:            s.perspective,
+			RTTStats:               s.rttStats,
+			InitialMaxDatagramSize: initialMaxDatagramSize,
+			Tracer:                 s.tracer,
+		})
+	}
+}
+
 func (s *connection) preSetup() {
 	s.initialStream = newCryptoStream()
 	s.handshakeStream = newCryptoStream()
 	s.sendQueue = newSendQueue(s.conn)
+	s.largestRcvdAppData = protocol.InvalidPacketNumber
 	s.retransmissionQueue = newRetransmissionQueue()
-	s.frameParser = *wire.NewFrameParser(s.config.EnableDatagrams)
+	s.frameParser = *wire.NewFrameParser(s.config.EnableDatagrams, s.config.EnableStreamResetPartialDelivery, s.config.EnableAckFrequency)
 	s.rttStats = &utils.RTTStats{}
 	s.connFlowController = flowcontrol.NewConnectionFlowController(
 		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
@@ -471,6 +601,7 @@ func (s *connection) preSetup() {
 			}
 			return s.config.AllowConnectionWindowIncrease(s, uint64(size))
 		},
+		s.memoryBudget,
 		s.rttStats,
 		s.logger,
 	)
@@ -494,7 +625,7 @@ func (s *connection) preSetup() {
 	s.creationTime = now
 
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
-	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.logger)
+	s.datagramQueue = newDatagramQueue(s.scheduleSending, s.config.DatagramReceiveQueueLen, s.logger)
 	s.connState.Version = s.version
 }
 
@@ -511,11 +642,7 @@ func (s *connection) run() error {
 	if err := s.handleHandshakeEvents(); err != nil {
 		return err
 	}
-	go func() {
-		if err := s.sendQueue.Run(); err != nil {
-			s.destroyImpl(err)
-		}
-	}()
+	go s.runSendQueue(s.sendQueue)
 
 	if s.perspective == protocol.PerspectiveClient {
 		s.scheduleSending() // so the ClientHello actually gets sent
@@ -631,6 +758,12 @@ runLoop:
 			}
 		}
 
+		if s.pathManagerOutgoing != nil {
+			if id, tr, remoteAddr, ok := s.pathManagerOutgoing.ShouldSwitchPath(); ok {
+				s.switchToPath(id, tr, remoteAddr)
+			}
+		}
+
 		if s.sendQueue.WouldBlock() {
 			// The send queue is still busy sending out packets.
 			// Wait until there's space to enqueue new packets.
@@ -650,6 +783,9 @@ runLoop:
 	s.cryptoStreamHandler.Close()
 	s.sendQueue.Close() // close the send queue before sending the CONNECTION_CLOSE
 	s.handleCloseError(&closeErr)
+	if e := (&errCloseForRecreating{}); !errors.As(closeErr.err, &e) {
+		s.maybeSaveCongestionState()
+	}
 	if s.tracer != nil && s.tracer.Close != nil {
 		if e := (&errCloseForRecreating{}); !errors.As(closeErr.err, &e) {
 			s.tracer.Close()
@@ -660,6 +796,58 @@ runLoop:
 	return closeErr.err
 }
 
+func (s *connection) runSendQueue(q sender) {
+	if err := q.Run(); err != nil {
+		s.destroyImpl(err)
+	}
+}
+
+// switchToPath switches to a path that was validated using a PATH_CHALLENGE.
+// If tr is nil, the connection keeps using the current Transport.
+// If remoteAddr is nil, the connection keeps using the current remote address.
+// Following section 9.4 of RFC 9000, the congestion controller and the RTT estimate are reset,
+// since the new path might have vastly different characteristics.
+func (s *connection) switchToPath(id pathID, tr *Transport, remoteAddr net.Addr) {
+	if !s.connIDManager.SwitchToPath(id) {
+		s.logger.Debugf("Not switching to path %d: no connection ID available", id)
+		return
+	}
+	oldAddr := s.conn.RemoteAddr()
+	if remoteAddr == nil {
+		remoteAddr = oldAddr
+	}
+	s.logger.Debugf("Switching to path %d (remote address: %s)", id, remoteAddr)
+	// make sure that all packets that were already queued are sent out on the old path
+	s.sendQueue.Close()
+	s.connStateMutex.Lock()
+	if tr != nil {
+		s.conn = newSendConn(tr.conn, remoteAddr, packetInfo{}, s.logger)
+	} else {
+		s.conn.ChangeRemoteAddr(remoteAddr, packetInfo{})
+	}
+	s.connStateMutex.Unlock()
+	s.startSendQueue()
+	s.resetPathState()
+	if !addrsEqual(oldAddr, remoteAddr) && s.tracer != nil && s.tracer.MigratedPath != nil {
+		s.tracer.MigratedPath(oldAddr, remoteAddr)
+	}
+}
+
+func (s *connection) startSendQueue() {
+	s.sendQueue = newSendQueue(s.conn)
+	go s.runSendQueue(s.sendQueue)
+}
+
+// resetPathState resets the congestion controller, the RTT estimate and the MTU after migrating to a new path.
+func (s *connection) resetPathState() {
+	s.sentPacketHandler.MigratedPath(protocol.ByteCount(s.config.InitialPacketSize))
+	s.maxPayloadSizeEstimate.Store(uint32(estimateMaxPayloadSize(protocol.ByteCount(s.config.InitialPacketSize))))
+	s.mtuDiscoverer = s.newMTUDiscoverer()
+	if !s.config.DisablePathMTUDiscovery && s.conn.capabilities().DF {
+		s.mtuDiscoverer.Start()
+	}
+}
+
 // blocks until the early connection can be used
 func (s *connection) earlyConnReady() <-chan struct{} {
 	return s.earlyConnReadyChan
@@ -677,6 +865,14 @@ func (s *connection) supportsDatagrams() bool {
 	return s.peerParams.MaxDatagramFrameSize > 0
 }
 
+func (s *connection) supportsResetStreamAt() bool {
+	return s.config.EnableStreamResetPartialDelivery && s.peerParams != nil && s.peerParams.EnableResetStreamAt
+}
+
+func (s *connection) supportsAckFrequency() bool {
+	return s.config.EnableAckFrequency && s.peerParams != nil && s.peerParams.MinAckDelay != nil
+}
+
 func (s *connection) ConnectionState() ConnectionState {
 	s.connStateMutex.Lock()
 	defer s.connStateMutex.Unlock()
@@ -684,9 +880,29 @@ func (s *connection) ConnectionState() ConnectionState {
 	s.connState.TLS = cs.ConnectionState
 	s.connState.Used0RTT = cs.Used0RTT
 	s.connState.GSO = s.conn.capabilities().GSO
+	s.connState.DatagramsDropped = s.datagramQueue.NumDropped()
 	return s.connState
 }
 
+func (s *connection) Stats() ConnectionStats {
+	stats := s.sentPacketHandler.Stats()
+	return ConnectionStats{
+		MinRTT:               s.rttStats.MinRTT(),
+		LatestRTT:            s.rttStats.LatestRTT(),
+		SmoothedRTT:          s.rttStats.SmoothedRTT(),
+		MeanDeviation:        s.rttStats.MeanDeviation(),
+		CongestionWindow:     uint64(stats.CongestionWindow),
+		BytesInFlight:        uint64(stats.BytesInFlight),
+		PacketsSent:          stats.PacketsSent,
+		BytesSent:            uint64(stats.BytesSent),
+		PacketsReceived:      s.packetsReceived.Load(),
+		BytesReceived:        s.bytesReceived.Load(),
+		PacketsLost:          stats.PacketsLost,
+		BytesLost:            uint64(stats.BytesLost),
+		PacketsRetransmitted: stats.PacketsRetransmitted,
+	}
+}
+
 // Time when the connection should time out
 func (s *connection) nextIdleTimeoutTime() time.Time {
 	idleTimeout := max(s.idleTimeout, s.rttStats.PTO(true)*3)
@@ -732,6 +948,9 @@ func (s *connection) idleTimeoutStartTime() time.Time {
 
 func (s *connection) handleHandshakeComplete() error {
 	defer close(s.handshakeCompleteChan)
+	if s.handshakeCompleteHook != nil {
+		defer s.handshakeCompleteHook()
+	}
 	// Once the handshake completes, we have derived 1-RTT keys.
 	// There's no point in queueing undecryptable packets for later decryption anymore.
 	s.undecryptablePackets = nil
@@ -770,6 +989,7 @@ func (s *connection) handleHandshakeComplete() error {
 		return err
 	}
 	s.queueControlFrame(&wire.NewTokenFrame{Token: token})
+	s.tokenStoreKey = string(token)
 	s.queueControlFrame(&wire.HandshakeDoneFrame{})
 	return nil
 }
@@ -786,11 +1006,30 @@ func (s *connection) handleHandshakeConfirmed() error {
 	if !s.config.DisablePathMTUDiscovery && s.conn.capabilities().DF {
 		s.mtuDiscoverer.Start()
 	}
+	if s.preferredAddressPath != nil {
+		go s.migrateToPreferredAddress(s.preferredAddressPath)
+	}
 	return nil
 }
 
+// migrateToPreferredAddress validates the server's preferred address, and migrates the connection to it.
+func (s *connection) migrateToPreferredAddress(p *Path) {
+	if err := p.Probe(s.ctx); err != nil {
+		s.logger.Debugf("Failed to validate the preferred address: %s", err)
+		p.Close()
+		return
+	}
+	if err := p.Switch(); err != nil {
+		s.logger.Debugf("Failed to migrate to the preferred address: %s", err)
+	}
+}
+
 func (s *connection) handlePacketImpl(rp receivedPacket) bool {
-	s.sentPacketHandler.ReceivedBytes(rp.Size())
+	// Packets received on other paths don't count towards the anti-amplification limit of the current path.
+	if s.perspective == protocol.PerspectiveClient || !s.handshakeConfirmed || s.isOnCurrentPath(rp) {
+		s.sentPacketHandler.ReceivedBytes(rp.Size())
+	}
+	s.bytesReceived.Add(uint64(rp.Size()))
 
 	if wire.IsVersionNegotiationPacket(rp.data) {
 		s.handleVersionNegotiationPacket(rp)
@@ -923,13 +1162,122 @@ func (s *connection) handleShortHeaderPacket(p receivedPacket, destConnID protoc
 			)
 		}
 	}
-	if err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log); err != nil {
+	isNonProbing, pathChallenge, err := s.handleUnpackedShortHeaderPacket(destConnID, pn, data, p.ecn, p.rcvTime, log)
+	if err != nil {
 		s.closeLocal(err)
 		return false
 	}
+	if pn > s.largestRcvdAppData {
+		s.largestRcvdAppData = pn
+	}
+
+	// Only the client can migrate, and only after the handshake is confirmed (see section 9 of RFC 9000).
+	if s.perspective == protocol.PerspectiveClient || !s.handshakeConfirmed || s.isOnCurrentPath(p) {
+		if pathChallenge != nil {
+			s.handlePathChallengeFrame(pathChallenge)
+		}
+		if s.perspective == protocol.PerspectiveServer && s.handshakeConfirmed {
+			// the server might have switched to this path before validating it
+			if f := s.pathManager.HandlePacketOnCurrentPath(p.rcvTime); f != nil {
+				s.queueControlFrame(f)
+			}
+		}
+		return true
+	}
+	// Migration to the preferred address is allowed even if active migration is disabled,
+	// see section 18.2 of RFC 9000.
+	// If active migration is disabled, a client sending from a new address violates the
+	// disable_active_migration transport parameter.
+	// We process the packet, but don't migrate the connection.
+	if p.onPreferredAddress == s.onPreferredAddress && s.config.DisableActiveMigration {
+		if pathChallenge != nil {
+			s.handlePathChallengeFrame(pathChallenge)
+		}
+		return true
+	}
+	s.handlePacketOnNewPath(p, pn, pathChallenge, isNonProbing)
 	return true
 }
 
+func (s *connection) isOnCurrentPath(p receivedPacket) bool {
+	return p.onPreferredAddress == s.onPreferredAddress && addrsEqual(p.remoteAddr, s.conn.RemoteAddr())
+}
+
+// handlePacketOnNewPath handles a packet that was received from a new remote address,
+// or on the preferred address (before the connection migrated to the preferred address).
+// The new path is validated using a PATH_CHALLENGE, and the connection switches to it
+// when the peer sends non-probing packets on it, see section 9.3 of RFC 9000.
+// If the path hasn't been validated yet, the anti-amplification limit applies until it is.
+// When migrating to the preferred address, the client first validates the preferred address
+// using a PATH_CHALLENGE, and then starts sending non-probing packets to it, see section 9.6 of RFC 9000.
+// The server still needs to validate the client's address on this path.
+func (s *connection) handlePacketOnNewPath(p receivedPacket, pn protocol.PacketNumber, pathChallenge *wire.PathChallengeFrame, isNonProbing bool) {
+	conn := s.conn
+	toPreferredAddress := p.onPreferredAddress && !s.onPreferredAddress
+	if p.onPreferredAddress != s.onPreferredAddress {
+		// This is a delayed packet that was sent to the original address before the client migrated.
+		if !p.onPreferredAddress {
+			return
+		}
+		conn = newSendConn(s.preferredAddressConn, p.remoteAddr, p.info, s.logger)
+	}
+	connID, frames, probeSize, shouldSwitch := s.pathManager.HandlePacket(p.remoteAddr, p.onPreferredAddress, p.rcvTime, p.Size(), pathChallenge, isNonProbing)
+	if len(frames) > 0 {
+		probe, buf, err := s.packer.PackPathProbePacket(connID, frames, probeSize, s.version)
+		if err != nil {
+			s.closeLocal(err)
+			return
+		}
+		s.logger.Debugf("Sending path probe packet to %s", p.remoteAddr)
+		s.logShortHeaderPacket(probe.DestConnID, probe.Ack, probe.Frames, probe.StreamFrames, probe.PacketNumber, probe.PacketNumberLen, probe.KeyPhase, protocol.ECNNon, buf.Len(), false)
+		s.registerPackedShortHeaderPacket(probe, protocol.ECNNon, p.rcvTime)
+		if err := conn.WriteTo(buf.Data, p.remoteAddr); err != nil {
+			s.logger.Debugf("Sending path probe packet failed: %s", err)
+		}
+		buf.Release()
+	}
+	// We only switch paths in response to the highest-numbered non-probing packet,
+	// see section 9.3 of RFC 9000.
+	if !shouldSwitch || pn != s.largestRcvdAppData {
+		return
+	}
+	path, ok := s.pathManager.SwitchToPath(p.remoteAddr, p.onPreferredAddress)
+	if !ok || !s.connIDManager.SwitchToPath(path.id) {
+		return
+	}
+	oldAddr := s.conn.RemoteAddr()
+	if toPreferredAddress {
+		s.logger.Debugf("Client migrated to the preferred address (remote address: %s)", p.remoteAddr)
+	} else {
+		s.logger.Debugf("Migrating connection from %s to %s", oldAddr, p.remoteAddr)
+	}
+	// make sure that all packets that were already queued are sent out on the old path
+	s.sendQueue.Close()
+	s.connStateMutex.Lock()
+	if toPreferredAddress {
+		s.conn = conn
+		s.onPreferredAddress = true
+	} else {
+		s.conn.ChangeRemoteAddr(p.remoteAddr, p.info)
+	}
+	s.connStateMutex.Unlock()
+	s.startSendQueue()
+	// If only the port changed (as is the case for most NAT rebindings),
+	// the congestion controller and the RTT estimate are kept, see section 9.4 of RFC 9000.
+	if toPreferredAddress || !sameIP(oldAddr, p.remoteAddr) {
+		s.resetPathState()
+	}
+	if !path.validated {
+		s.sentPacketHandler.MigratedToUnvalidatedPath(path.bytesSent, path.bytesReceived)
+		if f := s.pathManager.HandlePacketOnCurrentPath(p.rcvTime); f != nil {
+			s.queueControlFrame(f)
+		}
+	}
+	if s.tracer != nil && s.tracer.MigratedPath != nil {
+		s.tracer.MigratedPath(oldAddr, p.remoteAddr)
+	}
+}
+
 func (s *connection) handleLongHeaderPacket(p receivedPacket, hdr *wire.Header) bool /* was the packet successfully processed */ {
 	var wasQueued bool
 
@@ -1201,6 +1549,7 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 	s.lastPacketReceivedTime = rcvTime
 	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
 	s.keepAlivePingSent = false
+	s.packetsReceived.Add(1)
 
 	var log func([]logging.Frame)
 	if s.tracer != nil && s.tracer.ReceivedLongHeaderPacket != nil {
@@ -1208,10 +1557,13 @@ func (s *connection) handleUnpackedLongHeaderPacket(
 			s.tracer.ReceivedLongHeaderPacket(packet.hdr, packetSize, ecn, frames)
 		}
 	}
-	isAckEliciting, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
+	isAckEliciting, _, pathChallenge, err := s.handleFrames(packet.data, packet.hdr.DestConnectionID, packet.encryptionLevel, log)
 	if err != nil {
 		return err
 	}
+	if pathChallenge != nil {
+		s.handlePathChallengeFrame(pathChallenge)
+	}
 	return s.receivedPacketHandler.ReceivedPacket(packet.hdr.PacketNumber, ecn, packet.encryptionLevel, rcvTime, isAckEliciting)
 }
 
@@ -1222,16 +1574,20 @@ func (s *connection) handleUnpackedShortHeaderPacket(
 	ecn protocol.ECN,
 	rcvTime time.Time,
 	log func([]logging.Frame),
-) error {
+) (isNonProbing bool, pathChallenge *wire.PathChallengeFrame, _ error) {
 	s.lastPacketReceivedTime = rcvTime
 	s.firstAckElicitingPacketAfterIdleSentTime = time.Time{}
 	s.keepAlivePingSent = false
+	s.packetsReceived.Add(1)
 
-	isAckEliciting, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
+	isAckEliciting, isNonProbing, pathChallenge, err := s.handleFrames(data, destConnID, protocol.Encryption1RTT, log)
 	if err != nil {
-		return err
+		return false, nil, err
+	}
+	if err := s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting); err != nil {
+		return false, nil, err
 	}
-	return s.receivedPacketHandler.ReceivedPacket(pn, ecn, protocol.Encryption1RTT, rcvTime, isAckEliciting)
+	return isNonProbing, pathChallenge, nil
 }
 
 func (s *connection) handleFrames(
@@ -1239,7 +1595,7 @@ func (s *connection) handleFrames(
 	destConnID protocol.ConnectionID,
 	encLevel protocol.EncryptionLevel,
 	log func([]logging.Frame),
-) (isAckEliciting bool, _ error) {
+) (isAckEliciting, isNonProbing bool, pathChallenge *wire.PathChallengeFrame, _ error) {
 	// Only used for tracing.
 	// If we're not tracing, this slice will always remain empty.
 	var frames []logging.Frame
@@ -1251,7 +1607,7 @@ func (s *connection) handleFrames(
 	for len(data) > 0 {
 		l, frame, err := s.frameParser.ParseNext(data, encLevel, s.version)
 		if err != nil {
-			return false, err
+			return false, false, nil, err
 		}
 		data = data[l:]
 		if frame == nil {
@@ -1260,6 +1616,9 @@ func (s *connection) handleFrames(
 		if ackhandler.IsFrameAckEliciting(frame) {
 			isAckEliciting = true
 		}
+		if !wire.IsProbingFrame(frame) {
+			isNonProbing = true
+		}
 		if log != nil {
 			frames = append(frames, logutils.ConvertFrame(frame))
 		}
@@ -1268,9 +1627,16 @@ func (s *connection) handleFrames(
 		if handleErr != nil {
 			continue
 		}
+		// PATH_CHALLENGE frames are handled by the caller,
+		// since the PATH_RESPONSE needs to be sent on the path that the PATH_CHALLENGE was received on.
+		if pc, ok := frame.(*wire.PathChallengeFrame); ok {
+			wire.LogFrame(s.logger, frame, false)
+			pathChallenge = pc
+			continue
+		}
 		if err := s.handleFrame(frame, encLevel, destConnID); err != nil {
 			if log == nil {
-				return false, err
+				return false, false, nil, err
 			}
 			// If we're logging, we need to keep parsing (but not handling) all frames.
 			handleErr = err
@@ -1280,7 +1646,7 @@ func (s *connection) handleFrames(
 	if log != nil {
 		log(frames)
 		if handleErr != nil {
-			return false, handleErr
+			return false, false, nil, handleErr
 		}
 	}
 
@@ -1290,7 +1656,7 @@ func (s *connection) handleFrames(
 	// and an ACK serialized after that CRYPTO frame. In this case, we still want to process the ACK frame.
 	if !handshakeWasComplete && s.handshakeComplete {
 		if err := s.handleHandshakeComplete(); err != nil {
-			return false, err
+			return false, false, nil, err
 		}
 	}
 
@@ -1326,8 +1692,7 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 	case *wire.PathChallengeFrame:
 		s.handlePathChallengeFrame(frame)
 	case *wire.PathResponseFrame:
-		// since we don't send PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
-		err = errors.New("unexpected PATH_RESPONSE frame")
+		err = s.handlePathResponseFrame(frame)
 	case *wire.NewTokenFrame:
 		err = s.handleNewTokenFrame(frame)
 	case *wire.NewConnectionIDFrame:
@@ -1338,6 +1703,10 @@ func (s *connection) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel
 		err = s.handleHandshakeDoneFrame()
 	case *wire.DatagramFrame:
 		err = s.handleDatagramFrame(frame)
+	case *wire.AckFrequencyFrame:
+		err = s.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		s.receivedPacketHandler.ReceivedImmediateAckFrame()
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -1477,6 +1846,17 @@ func (s *connection) handlePathChallengeFrame(frame *wire.PathChallengeFrame) {
 	s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
 }
 
+func (s *connection) handlePathResponseFrame(frame *wire.PathResponseFrame) error {
+	if s.perspective == protocol.PerspectiveServer {
+		if s.pathManager.HandlePathResponseFrame(frame) {
+			s.sentPacketHandler.PathValidated()
+		}
+		return nil
+	}
+	s.pathManagerOutgoing.HandlePathResponseFrame(frame)
+	return nil
+}
+
 func (s *connection) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
 	if s.perspective == protocol.PerspectiveServer {
 		return &qerr.TransportError{
@@ -1490,6 +1870,42 @@ func (s *connection) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
 	return nil
 }
 
+// maybeSaveCongestionState saves the congestion state of the path in the token store,
+// such that it can be used for careful resume by the next connection to the same server.
+// The server saves the state keyed by the token it sent in the NEW_TOKEN frame,
+// and uses it when the client presents that token on its next connection.
+func (s *connection) maybeSaveCongestionState() {
+	if !s.config.EnableCarefulResume || s.tokenStoreKey == "" {
+		return
+	}
+	store, ok := s.config.TokenStore.(CongestionStateStore)
+	if !ok || !s.handshakeComplete || s.rttStats.MinRTT() == 0 {
+		return
+	}
+	store.PutCongestionState(s.tokenStoreKey, &CongestionState{
+		RTT:              s.rttStats.MinRTT(),
+		CongestionWindow: s.sentPacketHandler.CongestionWindow(),
+		SavedAt:          time.Now(),
+	})
+}
+
+// maybeUseCongestionState uses the congestion state of a previous connection, if careful resume is enabled.
+func (s *connection) maybeUseCongestionState(key string) {
+	if !s.config.EnableCarefulResume {
+		return
+	}
+	store, ok := s.config.TokenStore.(CongestionStateStore)
+	if !ok {
+		return
+	}
+	state := store.PopCongestionState(key)
+	if state == nil || state.RTT <= 0 || state.CongestionWindow <= 0 || time.Since(state.SavedAt) > protocol.CarefulResumeMaxAge {
+		return
+	}
+	s.logger.Debugf("Using congestion state of a previous connection: RTT %s, congestion window %d", state.RTT, state.CongestionWindow)
+	s.sentPacketHandler.SetResumeState(state.RTT, state.CongestionWindow)
+}
+
 func (s *connection) handleNewConnectionIDFrame(f *wire.NewConnectionIDFrame) error {
 	return s.connIDManager.Add(f)
 }
@@ -1527,9 +1943,24 @@ func (s *connection) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encr
 			return err
 		}
 	}
+	// The congestion window might have changed, which might require an update of the ACK frequency.
+	if f := s.sentPacketHandler.GetAckFrequencyFrame(s.lastPacketReceivedTime); f != nil {
+		s.framer.QueueControlFrame(f)
+	}
 	return s.cryptoStreamHandler.SetLargest1RTTAcked(frame.LargestAcked())
 }
 
+func (s *connection) handleAckFrequencyFrame(frame *wire.AckFrequencyFrame) error {
+	if frame.RequestMaxAckDelay < protocol.MinAckDelay {
+		return &qerr.TransportError{
+			ErrorCode:    qerr.ProtocolViolation,
+			ErrorMessage: fmt.Sprintf("requested max_ack_delay (%s) smaller than min_ack_delay (%s)", frame.RequestMaxAckDelay, protocol.MinAckDelay),
+		}
+	}
+	s.receivedPacketHandler.ReceivedAckFrequencyFrame(frame)
+	return nil
+}
+
 func (s *connection) handleDatagramFrame(f *wire.DatagramFrame) error {
 	if f.Length(s.version) > wire.MaxDatagramSize {
 		return &qerr.TransportError{
@@ -1537,7 +1968,11 @@ func (s *connection) handleDatagramFrame(f *wire.DatagramFrame) error {
 			ErrorMessage: "DATAGRAM frame too large",
 		}
 	}
-	s.datagramQueue.HandleDatagramFrame(f)
+	if !s.datagramQueue.HandleDatagramFrame(f, s.lastPacketReceivedTime) {
+		if s.tracer != nil && s.tracer.DroppedDatagram != nil {
+			s.tracer.DroppedDatagram(protocol.ByteCount(len(f.Data)))
+		}
+	}
 	return nil
 }
 
@@ -1591,6 +2026,14 @@ func (s *connection) closeWithTransportError(code TransportErrorCode) {
 	<-s.ctx.Done()
 }
 
+func (s *connection) setHandshakeCompleteHook(f func()) {
+	s.handshakeCompleteHook = f
+}
+
+func (s *connection) releaseFlowControlMemory() {
+	s.connFlowController.Close()
+}
+
 func (s *connection) handleCloseError(closeErr *closeError) {
 	e := closeErr.err
 	if e == nil {
@@ -1624,6 +2067,7 @@ func (s *connection) handleCloseError(closeErr *closeError) {
 	}
 
 	s.streamsMap.CloseWithError(e)
+	s.connFlowController.Close()
 	s.connIDManager.Close()
 	if s.datagramQueue != nil {
 		s.datagramQueue.CloseWithError(e)
@@ -1721,6 +2165,7 @@ func (s *connection) handleTransportParameters(params *wire.TransportParameters)
 
 	s.connStateMutex.Lock()
 	s.connState.SupportsDatagrams = s.supportsDatagrams()
+	s.connState.SupportsStreamResetPartialDelivery = s.supportsResetStreamAt()
 	s.connStateMutex.Unlock()
 	return nil
 }
@@ -1764,20 +2209,46 @@ func (s *connection) applyTransportParameters() {
 	s.frameParser.SetAckDelayExponent(params.AckDelayExponent)
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	s.rttStats.SetMaxAckDelay(params.MaxAckDelay)
+	if s.supportsAckFrequency() {
+		s.sentPacketHandler.EnableAckFrequency(*params.MinAckDelay)
+	}
 	s.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
 	if params.StatelessResetToken != nil {
 		s.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
 		s.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
+		if s.perspective == protocol.PerspectiveClient {
+			s.preferredAddressPath = s.newPreferredAddressPath(params.PreferredAddress)
+		}
 	}
+	s.mtuDiscoverer = s.newMTUDiscoverer()
+}
+
+// newPreferredAddressPath creates the path to the server's preferred address.
+// It returns nil if the server didn't provide an address of the same address family as the current remote address.
+// If no path is created, the connection ID can still be used for probing new paths created using AddPath.
+func (s *connection) newPreferredAddressPath(pa *wire.PreferredAddress) *Path {
+	addr := pa.IPv6
+	if remoteAddr, ok := s.conn.RemoteAddr().(*net.UDPAddr); ok && remoteAddr.IP.To4() != nil {
+		addr = pa.IPv4
+	}
+	if !addr.IsValid() || addr.Addr().IsUnspecified() || addr.Port() == 0 {
+		return nil
+	}
+	p := s.pathManagerOutgoing.NewPath(nil, net.UDPAddrFromAddrPort(addr), nil)
+	// The connection ID from the preferred_address transport parameter is used on this path.
+	// Reserve it now, before we switch to it on the original path.
+	s.connIDManager.GetConnIDForPath(p.id)
+	return p
+}
+
+func (s *connection) newMTUDiscoverer() mtuDiscoverer {
 	maxPacketSize := protocol.ByteCount(protocol.MaxPacketBufferSize)
-	if params.MaxUDPPayloadSize > 0 && params.MaxUDPPayloadSize < maxPacketSize {
-		maxPacketSize = params.MaxUDPPayloadSize
+	if s.peerParams.MaxUDPPayloadSize > 0 && s.peerParams.MaxUDPPayloadSize < maxPacketSize {
+		maxPacketSize = s.peerParams.MaxUDPPayloadSize
 	}
-	s.mtuDiscoverer = newMTUDiscoverer(
+	return newMTUDiscoverer(
 		s.rttStats,
 		protocol.ByteCount(s.config.InitialPacketSize),
 		maxPacketSize,
@@ -1863,6 +2334,13 @@ func (s *connection) sendPackets(now time.Time) error {
 		return nil
 	}
 
+	if s.handshakeConfirmed && s.pathManagerOutgoing != nil {
+		connID, frame, tr, remoteAddr, ok := s.pathManagerOutgoing.NextPathToProbe(s.connIDManager.GetConnIDForPath, s.connIDManager.RetireConnIDForPath)
+		if ok {
+			return s.sendPathProbePacket(connID, frame, tr, remoteAddr, now)
+		}
+	}
+
 	if isBlocked, offset := s.connFlowController.IsNewlyBlocked(); isBlocked {
 		s.framer.QueueControlFrame(&wire.DataBlockedFrame{MaximumData: offset})
 	}
@@ -2024,6 +2502,10 @@ func (s *connection) maybeSendAckOnlyPacket(now time.Time) error {
 }
 
 func (s *connection) sendProbePacket(encLevel protocol.EncryptionLevel, now time.Time) error {
+	// Ask the peer to acknowledge the probe packet right away, instead of delaying the ACK.
+	if encLevel == protocol.Encryption1RTT && s.supportsAckFrequency() {
+		s.framer.QueueControlFrame(&wire.ImmediateAckFrame{})
+	}
 	// Queue probe packets until we actually send out a packet,
 	// or until there are no more packets to queue.
 	var packet *coalescedPacket
@@ -2068,6 +2550,34 @@ func (s *connection) appendOneShortHeaderPacket(buf *packetBuffer, maxSize proto
 	return size, nil
 }
 
+// sendPathProbePacket sends a PATH_CHALLENGE on a new path.
+// The packet is sent directly on the Transport of the new path, bypassing the send queue.
+// If tr is nil, the current Transport is used. If remoteAddr is nil, the current remote address is used.
+func (s *connection) sendPathProbePacket(connID protocol.ConnectionID, f ackhandler.Frame, tr *Transport, remoteAddr net.Addr, now time.Time) error {
+	p, buf, err := s.packer.PackPathProbePacket(connID, []ackhandler.Frame{f}, protocol.MinInitialPacketSize, s.version)
+	if err != nil {
+		return err
+	}
+	if remoteAddr == nil {
+		remoteAddr = s.conn.RemoteAddr()
+	}
+	s.logger.Debugf("Sending path probe packet to %s", remoteAddr)
+	s.logShortHeaderPacket(p.DestConnID, p.Ack, p.Frames, p.StreamFrames, p.PacketNumber, p.PacketNumberLen, p.KeyPhase, protocol.ECNNon, buf.Len(), false)
+	s.registerPackedShortHeaderPacket(p, protocol.ECNNon, now)
+	if tr != nil {
+		_, err = tr.WriteTo(buf.Data, remoteAddr)
+	} else {
+		err = s.conn.WriteTo(buf.Data, remoteAddr)
+	}
+	if err != nil {
+		s.logger.Debugf("Sending path probe packet failed: %s", err)
+	}
+	buf.Release()
+	// There might be more paths to probe, or other packets to send.
+	s.pacingDeadline = deadlineSendImmediately
+	return nil
+}
+
 func (s *connection) registerPackedShortHeaderPacket(p shortHeaderPacket, ecn protocol.ECN, now time.Time) {
 	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && (len(p.StreamFrames) > 0 || ackhandler.HasAckElicitingFrames(p.Frames)) {
 		s.firstAckElicitingPacketAfterIdleSentTime = now
@@ -2077,7 +2587,7 @@ func (s *connection) registerPackedShortHeaderPacket(p shortHeaderPacket, ecn pr
 	if p.Ack != nil {
 		largestAcked = p.Ack.LargestAcked()
 	}
-	s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket)
+	s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket, p.IsPathProbePacket)
 	s.connIDManager.SentPacket()
 }
 
@@ -2091,7 +2601,7 @@ func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn prot
 		if p.ack != nil {
 			largestAcked = p.ack.LargestAcked()
 		}
-		s.sentPacketHandler.SentPacket(now, p.header.PacketNumber, largestAcked, p.streamFrames, p.frames, p.EncryptionLevel(), ecn, p.length, false)
+		s.sentPacketHandler.SentPacket(now, p.header.PacketNumber, largestAcked, p.streamFrames, p.frames, p.EncryptionLevel(), ecn, p.length, false, false)
 		if s.perspective == protocol.PerspectiveClient && p.EncryptionLevel() == protocol.EncryptionHandshake &&
 			!s.droppedInitialKeys {
 			// On the client side, Initial keys are dropped as soon as the first Handshake packet is sent.
@@ -2109,7 +2619,7 @@ func (s *connection) sendPackedCoalescedPacket(packet *coalescedPacket, ecn prot
 		if p.Ack != nil {
 			largestAcked = p.Ack.LargestAcked()
 		}
-		s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket)
+		s.sentPacketHandler.SentPacket(now, p.PacketNumber, largestAcked, p.StreamFrames, p.Frames, protocol.Encryption1RTT, ecn, p.Length, p.IsPathMTUProbePacket, p.IsPathProbePacket)
 	}
 	s.connIDManager.SentPacket()
 	s.sendQueue.Send(packet.buffer, 0, ecn)
@@ -2305,6 +2815,30 @@ func (s *connection) OpenUniStreamSync(ctx context.Context) (SendStream, error)
 	return s.streamsMap.OpenUniStreamSync(ctx)
 }
 
+func (s *connection) SetMaxIncomingStreams(num int64) {
+	s.streamsMap.SetMaxIncomingStreams(protocol.StreamTypeBidi, clipMaxIncomingStreams(num))
+}
+
+func (s *connection) SetMaxIncomingUniStreams(num int64) {
+	s.streamsMap.SetMaxIncomingStreams(protocol.StreamTypeUni, clipMaxIncomingStreams(num))
+}
+
+func clipMaxIncomingStreams(num int64) uint64 {
+	return uint64(min(max(num, 0), int64(protocol.MaxStreamCount)))
+}
+
+func (s *connection) SetStreamReceiveWindow(id StreamID, size uint64) error {
+	if size == 0 {
+		return errors.New("invalid receive window: 0")
+	}
+	str, ok := s.streamsMap.GetReceiveStream(id)
+	if !ok {
+		return fmt.Errorf("no receive stream with ID %d", id)
+	}
+	str.setReceiveWindowSize(protocol.ByteCount(min(size, quicvarint.Max)))
+	return nil
+}
+
 func (s *connection) newFlowController(id protocol.StreamID) flowcontrol.StreamFlowController {
 	initialSendWindow := s.peerParams.InitialMaxStreamDataUni
 	if id.Type() == protocol.StreamTypeBidi {
@@ -2374,7 +2908,13 @@ func (s *connection) onHasStreamData(id protocol.StreamID) {
 	s.scheduleSending()
 }
 
+func (s *connection) onStreamPriorityChanged(id protocol.StreamID, p StreamPriority) {
+	s.framer.SetStreamPriority(id, p)
+	s.scheduleSending()
+}
+
 func (s *connection) onStreamCompleted(id protocol.StreamID) {
+	s.framer.RemoveStream(id)
 	if err := s.streamsMap.DeleteStream(id); err != nil {
 		s.closeLocal(err)
 	}
@@ -2386,9 +2926,16 @@ func (s *connection) onMTUIncreased(mtu protocol.ByteCount) {
 }
 
 func (s *connection) SendDatagram(p []byte) error {
+	return s.SendDatagramWithOptions(p, nil)
+}
+
+func (s *connection) SendDatagramWithOptions(p []byte, opts *DatagramOptions) error {
 	if !s.supportsDatagrams() {
 		return errors.New("datagram support disabled")
 	}
+	if opts != nil && opts.Priority > DatagramPriorityLow {
+		return fmt.Errorf("invalid datagram priority: %d", opts.Priority)
+	}
 
 	f := &wire.DatagramFrame{DataLenPresent: true}
 	// The payload size estimate is conservative.
@@ -2402,7 +2949,7 @@ func (s *connection) SendDatagram(p []byte) error {
 	}
 	f.Data = make([]byte, len(p))
 	copy(f.Data, p)
-	return s.datagramQueue.Add(f)
+	return s.datagramQueue.Add(f, opts)
 }
 
 func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
@@ -2412,14 +2959,55 @@ func (s *connection) ReceiveDatagram(ctx context.Context) ([]byte, error) {
 	return s.datagramQueue.Receive(ctx)
 }
 
+func (s *connection) ReceiveDatagrams(ctx context.Context, datagrams []ReceivedDatagram) (int, error) {
+	if !s.config.EnableDatagrams {
+		return 0, errors.New("datagram support disabled")
+	}
+	return s.datagramQueue.ReceiveBatch(ctx, datagrams)
+}
+
 func (s *connection) LocalAddr() net.Addr {
+	s.connStateMutex.Lock()
+	defer s.connStateMutex.Unlock()
 	return s.conn.LocalAddr()
 }
 
 func (s *connection) RemoteAddr() net.Addr {
+	s.connStateMutex.Lock()
+	defer s.connStateMutex.Unlock()
 	return s.conn.RemoteAddr()
 }
 
+// AddPath creates a new path using the Transport.
+// The path needs to be probed (using Path.Probe) before the connection can switch to it.
+func (s *connection) AddPath(t *Transport) (*Path, error) {
+	if s.perspective == protocol.PerspectiveServer {
+		return nil, errors.New("server cannot initiate connection migration")
+	}
+	select {
+	case <-s.HandshakeComplete():
+	default:
+		return nil, errors.New("cannot migrate connection before handshake completion")
+	}
+	if s.peerParams.DisableActiveMigration {
+		return nil, errors.New("server disabled connection migration")
+	}
+	if err := t.init(false); err != nil {
+		return nil, err
+	}
+	if t.connIDLen != s.srcConnIDLen {
+		return nil, fmt.Errorf("connection ID length mismatch: Transport uses %d bytes, connection uses %d bytes", t.connIDLen, s.srcConnIDLen)
+	}
+	return s.pathManagerOutgoing.NewPath(t, nil, func() {
+		s.connIDGenerator.AddConnRunner(connRunnerCallbacks{
+			AddConnectionID:    func(connID protocol.ConnectionID) { t.handlerMap.Add(connID, s) },
+			RemoveConnectionID: t.handlerMap.Remove,
+			RetireConnectionID: t.handlerMap.Retire,
+			ReplaceWithClosed:  t.handlerMap.ReplaceWithClosed,
+		})
+	}), nil
+}
+
 func (s *connection) GetVersion() protocol.Version {
 	return s.version
 }
@@ -8,12 +8,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/netip"
 	"runtime/pprof"
 	"strings"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/ackhandler"
 	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/mocks"
@@ -131,7 +133,9 @@ var _ = Describe("Connection", func() {
 			populateConfig(&Config{DisablePathMTUDiscovery: true}),
 			&tls.Config{},
 			tokenGenerator,
+			nil,
 			false,
+			nil,
 			tr,
 			utils.DefaultLogger,
 			protocol.Version1,
@@ -194,6 +198,46 @@ var _ = Describe("Connection", func() {
 				err := conn.handleAckFrame(f, protocol.EncryptionHandshake)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("queues an ACK_FREQUENCY frame, if the ACK frequency needs to be updated", func() {
+				f := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				conn.sentPacketHandler = sph
+				conn.handshakeConfirmed = true
+				sph.EXPECT().ReceivedAck(f, protocol.Encryption1RTT, gomock.Any()).Return(true, nil)
+				ackFrequencyFrame := &wire.AckFrequencyFrame{AckElicitingThreshold: 7, RequestMaxAckDelay: 5 * time.Millisecond}
+				sph.EXPECT().GetAckFrequencyFrame(gomock.Any()).Return(ackFrequencyFrame)
+				cryptoSetup.EXPECT().SetLargest1RTTAcked(protocol.PacketNumber(3))
+				Expect(conn.handleAckFrame(f, protocol.Encryption1RTT)).To(Succeed())
+				frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(ackFrequencyFrame))
+			})
+		})
+
+		Context("handling ACK_FREQUENCY and IMMEDIATE_ACK frames", func() {
+			It("passes ACK_FREQUENCY frames to the ReceivedPacketHandler", func() {
+				rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				conn.receivedPacketHandler = rph
+				f := &wire.AckFrequencyFrame{AckElicitingThreshold: 9, RequestMaxAckDelay: 10 * time.Millisecond}
+				rph.EXPECT().ReceivedAckFrequencyFrame(f)
+				Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+
+			It("rejects ACK_FREQUENCY frames that request a max_ack_delay smaller than the min_ack_delay", func() {
+				f := &wire.AckFrequencyFrame{AckElicitingThreshold: 9, RequestMaxAckDelay: 500 * time.Microsecond}
+				Expect(conn.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(MatchError(&qerr.TransportError{
+					ErrorCode:    qerr.ProtocolViolation,
+					ErrorMessage: "requested max_ack_delay (500µs) smaller than min_ack_delay (1ms)",
+				}))
+			})
+
+			It("passes IMMEDIATE_ACK frames to the ReceivedPacketHandler", func() {
+				rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				conn.receivedPacketHandler = rph
+				rph.EXPECT().ReceivedImmediateAckFrame()
+				Expect(conn.handleFrame(&wire.ImmediateAckFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
 		})
 
 		Context("handling RESET_STREAM frames", func() {
@@ -313,9 +357,9 @@ var _ = Describe("Connection", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("rejects PATH_RESPONSE frames", func() {
+		It("ignores PATH_RESPONSE frames that don't match a PATH_CHALLENGE", func() {
 			err := conn.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{})
-			Expect(err).To(MatchError("unexpected PATH_RESPONSE frame"))
+			Expect(err).ToNot(HaveOccurred())
 		})
 
 		It("handles PATH_CHALLENGE frames", func() {
@@ -479,6 +523,22 @@ var _ = Describe("Connection", func() {
 			Expect(conn.Context().Done()).To(BeClosed())
 		})
 
+		It("releases the flow control memory when closed", func() {
+			fc := mocks.NewMockConnectionFlowController(mockCtrl)
+			conn.connFlowController = fc
+			runConn()
+			streamManager.EXPECT().CloseWithError(gomock.Any())
+			expectReplaceWithClosed()
+			cryptoSetup.EXPECT().Close()
+			packer.EXPECT().PackApplicationClose(gomock.Any(), gomock.Any(), conn.version).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
+			mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
+			tracer.EXPECT().ClosedConnection(gomock.Any())
+			tracer.EXPECT().Close()
+			fc.EXPECT().Close()
+			conn.CloseWithError(0, "")
+			Eventually(areConnsRunning).Should(BeFalse())
+		})
+
 		It("only closes once", func() {
 			runConn()
 			streamManager.EXPECT().CloseWithError(gomock.Any())
@@ -603,7 +663,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().ECNMode(true).Return(protocol.ECT1).AnyTimes()
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 			// only expect a single SentPacket() call
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ClosedConnection(gomock.Any())
 			tracer.EXPECT().Close()
@@ -649,9 +709,10 @@ var _ = Describe("Connection", func() {
 			b, err := wire.AppendShortHeader(nil, connID, pn, protocol.PacketNumberLen2, protocol.KeyPhaseOne)
 			Expect(err).ToNot(HaveOccurred())
 			return receivedPacket{
-				data:    append(b, data...),
-				buffer:  getPacketBuffer(),
-				rcvTime: time.Now(),
+				remoteAddr: remoteAddr,
+				data:       append(b, data...),
+				buffer:     getPacketBuffer(),
+				rcvTime:    time.Now(),
 			}
 		}
 
@@ -802,6 +863,32 @@ var _ = Describe("Connection", func() {
 			Expect(conn.handlePacketImpl(packet)).To(BeFalse())
 		})
 
+		It("counts received packets and bytes", func() {
+			b, err := (&wire.PingFrame{}).Append(nil, conn.version)
+			Expect(err).ToNot(HaveOccurred())
+			packet1 := getShortHeaderPacket(srcConnID, 0x37, nil)
+			packet2 := getShortHeaderPacket(srcConnID, 0x38, nil)
+			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+			conn.receivedPacketHandler = rph
+			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(0x37), protocol.PacketNumberLen2, protocol.KeyPhaseZero, b, nil)
+			rph.EXPECT().IsPotentiallyDuplicate(protocol.PacketNumber(0x37), protocol.Encryption1RTT)
+			rph.EXPECT().ReceivedPacket(protocol.PacketNumber(0x37), gomock.Any(), protocol.Encryption1RTT, gomock.Any(), true)
+			tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(conn.handlePacketImpl(packet1)).To(BeTrue())
+			// duplicate packets are not counted as received packets
+			unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(0x37), protocol.PacketNumberLen2, protocol.KeyPhaseZero, b, nil)
+			rph.EXPECT().IsPotentiallyDuplicate(protocol.PacketNumber(0x37), protocol.Encryption1RTT).Return(true)
+			tracer.EXPECT().DroppedPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			Expect(conn.handlePacketImpl(packet2)).To(BeFalse())
+
+			conn.rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+			stats := conn.Stats()
+			Expect(stats.PacketsReceived).To(BeEquivalentTo(1))
+			Expect(stats.BytesReceived).To(BeEquivalentTo(len(packet1.data) + len(packet2.data)))
+			Expect(stats.SmoothedRTT).To(Equal(100 * time.Millisecond))
+			Expect(stats.MinRTT).To(Equal(100 * time.Millisecond))
+		})
+
 		It("drops a packet when unpacking fails", func() {
 			unpacker.EXPECT().UnpackLongHeader(gomock.Any(), gomock.Any(), gomock.Any(), conn.version).Return(nil, handshake.ErrDecryptionFailed)
 			streamManager.EXPECT().CloseWithError(gomock.Any())
@@ -1040,12 +1127,248 @@ var _ = Describe("Connection", func() {
 		})
 
 		Context("updating the remote address", func() {
-			It("doesn't support connection migration", func() {
-				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(10), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0} /* one PADDING frame */, nil)
-				packet := getShortHeaderPacket(srcConnID, 0x42, nil)
-				packet.remoteAddr = &net.IPAddr{IP: net.IPv4(192, 168, 0, 100)}
+			newRemoteAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 4242}
+			newConnID := protocol.ParseConnectionID([]byte{4, 2, 4, 2})
+
+			getSmallPacketFromNewPath := func(pn protocol.PacketNumber, size int, frames ...wire.Frame) receivedPacket {
+				var data []byte
+				for _, f := range frames {
+					var err error
+					data, err = f.Append(data, protocol.Version1)
+					Expect(err).ToNot(HaveOccurred())
+				}
+				unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(pn, protocol.PacketNumberLen2, protocol.KeyPhaseZero, data, nil)
+				packet := getShortHeaderPacket(srcConnID, pn, make([]byte, size))
+				packet.remoteAddr = newRemoteAddr
 				tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any(), gomock.Any())
+				return packet
+			}
+
+			getPacketFromNewPath := func(pn protocol.PacketNumber, frames ...wire.Frame) receivedPacket {
+				// make sure the packet is large enough to not be blocked by the anti-amplification limit
+				return getSmallPacketFromNewPath(pn, 500, frames...)
+			}
+
+			It("ignores address changes before the handshake is confirmed", func() {
+				packet := getPacketFromNewPath(10, &wire.PingFrame{})
 				Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+				Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+			})
+
+			Context("after handshake confirmation", func() {
+				var sph *mockackhandler.MockSentPacketHandler
+
+				BeforeEach(func() {
+					conn.handshakeConfirmed = true
+					conn.peerParams = &wire.TransportParameters{}
+					sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+					sph.EXPECT().ReceivedBytes(gomock.Any()).AnyTimes()
+					conn.sentPacketHandler = sph
+					Expect(conn.connIDManager.Add(&wire.NewConnectionIDFrame{
+						SequenceNumber:      1,
+						ConnectionID:        newConnID,
+						StatelessResetToken: protocol.StatelessResetToken{0xde, 0xad, 0xbe, 0xef},
+					})).To(Succeed())
+				})
+
+				expectPathProbe := func(size protocol.ByteCount) *[8]byte {
+					var challenge [8]byte
+					packer.EXPECT().PackPathProbePacket(newConnID, gomock.Any(), size, protocol.Version1).DoAndReturn(
+						func(_ protocol.ConnectionID, frames []ackhandler.Frame, _ protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+							Expect(frames).To(HaveLen(1))
+							Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+							challenge = frames[0].Frame.(*wire.PathChallengeFrame).Data
+							buf := getPacketBuffer()
+							buf.Data = append(buf.Data, []byte("probe")...)
+							return shortHeaderPacket{PacketNumber: 1, DestConnID: newConnID, Frames: frames, IsPathProbePacket: true}, buf, nil
+						},
+					)
+					tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(1), gomock.Any(), gomock.Any(), gomock.Any(), protocol.Encryption1RTT, gomock.Any(), gomock.Any(), false, true)
+					mconn.EXPECT().WriteTo([]byte("probe"), newRemoteAddr)
+					return &challenge
+				}
+
+				expectMigration := func() {
+					sender := NewMockSender(mockCtrl)
+					conn.sendQueue = sender
+					sender.EXPECT().Close()
+					mconn.EXPECT().ChangeRemoteAddr(newRemoteAddr, gomock.Any()).Do(func(net.Addr, packetInfo) {
+						// from now on, the connection uses the new remote address
+						newConn := NewMockSendConn(mockCtrl)
+						newConn.EXPECT().capabilities().AnyTimes()
+						newConn.EXPECT().RemoteAddr().Return(newRemoteAddr).AnyTimes()
+						newConn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
+						conn.conn = newConn
+					})
+					sph.EXPECT().MigratedPath(gomock.Any())
+					tracer.EXPECT().MigratedPath(remoteAddr, newRemoteAddr)
+				}
+
+				It("validates the new path, but doesn't migrate on probing packets", func() {
+					connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{0xde, 0xad, 0xbe, 0xef}, conn)
+					expectPathProbe(protocol.ByteCount(protocol.MinInitialPacketSize))
+					Expect(conn.handlePacketImpl(getPacketFromNewPath(10, &wire.NewConnectionIDFrame{
+						SequenceNumber:      2,
+						ConnectionID:        protocol.ParseConnectionID([]byte{1, 3, 3, 7}),
+						StatelessResetToken: protocol.StatelessResetToken{1, 3, 3, 7},
+					}))).To(BeTrue())
+					Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+				})
+
+				It("migrates to the new path after it was validated", func() {
+					connRunner.EXPECT().AddResetToken(gomock.Any(), conn)
+					challenge := expectPathProbe(protocol.ByteCount(protocol.MinInitialPacketSize))
+					// packets containing only probing frames don't cause a migration
+					Expect(conn.handlePacketImpl(getPacketFromNewPath(10, &wire.NewConnectionIDFrame{
+						SequenceNumber:      2,
+						ConnectionID:        protocol.ParseConnectionID([]byte{1, 3, 3, 7}),
+						StatelessResetToken: protocol.StatelessResetToken{1, 3, 3, 7},
+					}))).To(BeTrue())
+					Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+					// the next packet doesn't trigger another PATH_CHALLENGE, since the path is already validated
+					Expect(conn.handlePacketImpl(getPacketFromNewPath(11, &wire.NewConnectionIDFrame{
+						SequenceNumber:      3,
+						ConnectionID:        protocol.ParseConnectionID([]byte{1, 3, 3, 8}),
+						StatelessResetToken: protocol.StatelessResetToken{1, 3, 3, 8},
+					}))).To(BeTrue())
+
+					expectMigration()
+					Expect(conn.handlePacketImpl(getPacketFromNewPath(12, &wire.PingFrame{}))).To(BeTrue())
+					Expect(conn.connIDManager.Get()).To(Equal(newConnID))
+					// stop the newly created send queue
+					conn.sendQueue.Close()
+				})
+
+				It("migrates to the new path before it was validated", func() {
+					connRunner.EXPECT().AddResetToken(gomock.Any(), conn)
+					challenge := expectPathProbe(protocol.ByteCount(protocol.MinInitialPacketSize))
+					expectMigration()
+					packet := getPacketFromNewPath(10, &wire.PingFrame{})
+					sph.EXPECT().MigratedToUnvalidatedPath(protocol.ByteCount(protocol.MinInitialPacketSize), packet.Size())
+					Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+					Expect(conn.connIDManager.Get()).To(Equal(newConnID))
+
+					// the anti-amplification limit is lifted once the path is validated
+					sph.EXPECT().PathValidated()
+					Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+					// stop the newly created send queue
+					conn.sendQueue.Close()
+				})
+
+				It("validates the path of a rebound client that only sends small packets", func() {
+					connRunner.EXPECT().AddResetToken(gomock.Any(), conn)
+					// The first packet is too small to send a PATH_CHALLENGE within the anti-amplification limit,
+					// but the connection still migrates to the new path.
+					expectMigration()
+					packet := getSmallPacketFromNewPath(10, 1, &wire.PingFrame{})
+					sph.EXPECT().MigratedToUnvalidatedPath(protocol.ByteCount(0), packet.Size())
+					Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+					Expect(conn.RemoteAddr()).To(Equal(newRemoteAddr))
+					Expect(conn.connIDManager.Get()).To(Equal(newConnID))
+					// The PATH_CHALLENGE is now sent on the current path (together with the RETIRE_CONNECTION_ID
+					// for the old connection ID), subject to the anti-amplification limit enforced by the sent packet handler.
+					frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+					Expect(frames).To(HaveLen(2))
+					Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+					Expect(frames[1].Frame).To(Equal(&wire.RetireConnectionIDFrame{SequenceNumber: 0}))
+					challenge := frames[0].Frame.(*wire.PathChallengeFrame).Data
+
+					// the next small packet is received on the current path, and doesn't trigger another PATH_CHALLENGE
+					unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(11), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0x1} /* PING */, nil)
+					tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					packet = getShortHeaderPacket(srcConnID, 11, nil)
+					packet.remoteAddr = newRemoteAddr
+					Expect(conn.handlePacketImpl(packet)).To(BeTrue())
+					frames, _ = conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+					Expect(frames).To(BeEmpty())
+
+					sph.EXPECT().PathValidated()
+					Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+					// stop the newly created send queue
+					conn.sendQueue.Close()
+				})
+
+				It("doesn't validate or migrate to new paths if active migration is disabled", func() {
+					conn.config.DisableActiveMigration = true
+					Expect(conn.handlePacketImpl(getPacketFromNewPath(10, &wire.PingFrame{}))).To(BeTrue())
+					Expect(conn.handlePacketImpl(getPacketFromNewPath(11, &wire.PingFrame{}))).To(BeTrue())
+					Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+					Expect(conn.connIDManager.Get()).ToNot(Equal(newConnID))
+				})
+
+				It("doesn't migrate when receiving a reordered packet", func() {
+					connRunner.EXPECT().AddResetToken(gomock.Any(), conn)
+					challenge := expectPathProbe(protocol.ByteCount(protocol.MinInitialPacketSize))
+					Expect(conn.handlePacketImpl(getPacketFromNewPath(10, &wire.NewConnectionIDFrame{
+						SequenceNumber:      2,
+						ConnectionID:        protocol.ParseConnectionID([]byte{1, 3, 3, 7}),
+						StatelessResetToken: protocol.StatelessResetToken{1, 3, 3, 7},
+					}))).To(BeTrue())
+					Expect(conn.handleFrame(&wire.PathResponseFrame{Data: *challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+					unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(20), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0x1} /* PING */, nil)
+					tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					Expect(conn.handlePacketImpl(getShortHeaderPacket(srcConnID, 20, nil))).To(BeTrue())
+					Expect(conn.handlePacketImpl(getPacketFromNewPath(15, &wire.PingFrame{}))).To(BeTrue())
+					Expect(conn.connIDManager.Get()).ToNot(Equal(newConnID))
+				})
+
+				It("migrates to the preferred address", func() {
+					preferredLocalAddr := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 443}
+					rawConn := NewMockRawConn(mockCtrl)
+					rawConn.EXPECT().LocalAddr().Return(preferredLocalAddr).AnyTimes()
+					rawConn.EXPECT().capabilities().AnyTimes()
+					conn.preferredAddressConn = rawConn
+
+					getPacketOnPreferredAddress := func(pn protocol.PacketNumber, frames ...wire.Frame) receivedPacket {
+						p := getPacketFromNewPath(pn, frames...)
+						p.remoteAddr = remoteAddr
+						p.onPreferredAddress = true
+						return p
+					}
+
+					// PATH_CHALLENGE frames are answered from the preferred address,
+					// and the client's address is validated on the new path
+					connRunner.EXPECT().AddResetToken(gomock.Any(), conn)
+					var challenge [8]byte
+					packer.EXPECT().PackPathProbePacket(newConnID, gomock.Any(), protocol.ByteCount(protocol.MinInitialPacketSize), protocol.Version1).DoAndReturn(
+						func(_ protocol.ConnectionID, frames []ackhandler.Frame, _ protocol.ByteCount, _ protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+							Expect(frames).To(HaveLen(2))
+							Expect(frames[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}))
+							Expect(frames[1].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+							challenge = frames[1].Frame.(*wire.PathChallengeFrame).Data
+							buf := getPacketBuffer()
+							buf.Data = append(buf.Data, []byte("response")...)
+							return shortHeaderPacket{PacketNumber: 1, Frames: frames, IsPathProbePacket: true}, buf, nil
+						},
+					)
+					tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(1), gomock.Any(), gomock.Any(), gomock.Any(), protocol.Encryption1RTT, gomock.Any(), gomock.Any(), false, true)
+					rawConn.EXPECT().WritePacket([]byte("response"), remoteAddr, gomock.Any(), uint16(0), protocol.ECNUnsupported)
+					Expect(conn.handlePacketImpl(getPacketOnPreferredAddress(10, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}))).To(BeTrue())
+					Expect(conn.LocalAddr()).To(Equal(localAddr))
+
+					Expect(conn.handleFrame(&wire.PathResponseFrame{Data: challenge}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+
+					// the first non-probing packet on the validated path causes the migration
+					sender := NewMockSender(mockCtrl)
+					conn.sendQueue = sender
+					sender.EXPECT().Close()
+					sph.EXPECT().MigratedPath(gomock.Any())
+					tracer.EXPECT().MigratedPath(remoteAddr, remoteAddr)
+					Expect(conn.handlePacketImpl(getPacketOnPreferredAddress(12, &wire.PingFrame{}))).To(BeTrue())
+					Expect(conn.LocalAddr()).To(Equal(preferredLocalAddr))
+					Expect(conn.RemoteAddr()).To(Equal(remoteAddr))
+					Expect(conn.connIDManager.Get()).To(Equal(newConnID))
+
+					// delayed packets sent to the original address don't cause another migration
+					unpacker.EXPECT().UnpackShortHeader(gomock.Any(), gomock.Any()).Return(protocol.PacketNumber(9), protocol.PacketNumberLen2, protocol.KeyPhaseZero, []byte{0x1} /* PING */, nil)
+					tracer.EXPECT().ReceivedShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					Expect(conn.handlePacketImpl(getShortHeaderPacket(srcConnID, 9, nil))).To(BeTrue())
+					Expect(conn.LocalAddr()).To(Equal(preferredLocalAddr))
+					// stop the newly created send queue
+					conn.sendQueue.Close()
+				})
 			})
 		})
 
@@ -1226,7 +1549,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().ECNMode(true).Return(protocol.ECNNon).AnyTimes()
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			runConn()
 			p := shortHeaderPacket{
 				DestConnID:      protocol.ParseConnectionID([]byte{1, 2, 3}),
@@ -1281,9 +1604,10 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			fc := mocks.NewMockConnectionFlowController(mockCtrl)
 			fc.EXPECT().IsNewlyBlocked().Return(true, protocol.ByteCount(1337))
+			fc.EXPECT().Close()
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 13}, []byte("foobar"))
 			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack).AnyTimes()
 			conn.connFlowController = fc
@@ -1336,7 +1660,7 @@ var _ = Describe("Connection", func() {
 					sph.EXPECT().ECNMode(gomock.Any())
 					p := getCoalescedPacket(123, encLevel)
 					packer.EXPECT().MaybePackProbePacket(encLevel, gomock.Any(), conn.version).Return(p, nil)
-					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 					conn.sentPacketHandler = sph
 					runConn()
 					sent := make(chan struct{})
@@ -1359,7 +1683,7 @@ var _ = Describe("Connection", func() {
 					sph.EXPECT().QueueProbePacket(encLevel).Return(false)
 					p := getCoalescedPacket(123, encLevel)
 					packer.EXPECT().MaybePackProbePacket(encLevel, gomock.Any(), conn.version).Return(p, nil)
-					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+					sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 					runConn()
 					sent := make(chan struct{})
 					sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
@@ -1376,6 +1700,32 @@ var _ = Describe("Connection", func() {
 				})
 			})
 		}
+
+		It("sends an IMMEDIATE_ACK frame with 1-RTT probe packets, if the peer supports the ACK frequency extension", func() {
+			conn.config.EnableAckFrequency = true
+			minAckDelay := time.Millisecond
+			conn.peerParams = &wire.TransportParameters{MinAckDelay: &minAckDelay}
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPTOAppData)
+			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendNone)
+			sph.EXPECT().QueueProbePacket(protocol.Encryption1RTT)
+			sph.EXPECT().ECNMode(gomock.Any())
+			p := getCoalescedPacket(123, protocol.Encryption1RTT)
+			packer.EXPECT().MaybePackProbePacket(protocol.Encryption1RTT, gomock.Any(), conn.version).Return(p, nil)
+			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(123), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			runConn()
+			sent := make(chan struct{})
+			sender.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any()).Do(func(*packetBuffer, uint16, protocol.ECN) { close(sent) })
+			tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), p.shortHdrPacket.Length, gomock.Any(), gomock.Any(), gomock.Any())
+			conn.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+			// We're using a mock packet packer in this test.
+			// We therefore need to test separately that the IMMEDIATE_ACK was actually queued.
+			frames, _ := conn.framer.AppendControlFrames(nil, 1000, protocol.Version1)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(&wire.ImmediateAckFrame{}))
+		})
 	})
 
 	Context("packet pacing", func() {
@@ -1412,7 +1762,7 @@ var _ = Describe("Connection", func() {
 		})
 
 		It("sends multiple packets one by one immediately", func() {
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(2)
 			sph.EXPECT().ECNMode(gomock.Any()).Times(2)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited)
@@ -1438,7 +1788,7 @@ var _ = Describe("Connection", func() {
 
 		It("sends multiple packets one by one immediately, with GSO", func() {
 			enableGSO()
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 			sph.EXPECT().ECNMode(true).Return(protocol.ECT1).Times(4)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(3)
 			payload1 := make([]byte, conn.maxPacketSize())
@@ -1464,7 +1814,7 @@ var _ = Describe("Connection", func() {
 
 		It("stops appending packets when a smaller packet is packed, with GSO", func() {
 			enableGSO()
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(3)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendNone)
 			sph.EXPECT().ECNMode(true).Times(4)
@@ -1496,7 +1846,7 @@ var _ = Describe("Connection", func() {
 
 		It("stops appending packets when the ECN marking changes, with GSO", func() {
 			enableGSO()
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(3)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendNone)
 			sph.EXPECT().ECNMode(true).Return(protocol.ECT1).Times(2)
@@ -1528,7 +1878,7 @@ var _ = Describe("Connection", func() {
 		})
 
 		It("sends multiple packets, when the pacer allows immediate sending", func() {
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(2)
 			sph.EXPECT().ECNMode(gomock.Any()).Times(2)
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 10}, []byte("packet10"))
@@ -1546,7 +1896,7 @@ var _ = Describe("Connection", func() {
 		})
 
 		It("allows an ACK to be sent when pacing limited", func() {
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			sph.EXPECT().TimeUntilSend().Return(time.Now().Add(time.Hour))
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited)
 			sph.EXPECT().ECNMode(gomock.Any())
@@ -1567,7 +1917,7 @@ var _ = Describe("Connection", func() {
 		// when becoming congestion limited, at some point the SendMode will change from SendAny to SendAck
 		// we shouldn't send the ACK in the same run
 		It("doesn't send an ACK right after becoming congestion limited", func() {
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAck)
 			sph.EXPECT().ECNMode(gomock.Any()).Times(2)
@@ -1590,13 +1940,13 @@ var _ = Describe("Connection", func() {
 				sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny),
 				sph.EXPECT().ECNMode(gomock.Any()),
 				expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 100}, []byte("packet100")),
-				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
 				sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited),
 				sph.EXPECT().TimeUntilSend().Return(time.Now().Add(pacingDelay)),
 				sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny),
 				sph.EXPECT().ECNMode(gomock.Any()),
 				expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 101}, []byte("packet101")),
-				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
+				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()),
 				sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited),
 				sph.EXPECT().TimeUntilSend().Return(time.Now().Add(time.Hour)),
 			)
@@ -1616,7 +1966,7 @@ var _ = Describe("Connection", func() {
 		})
 
 		It("sends multiple packets at once", func() {
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).Times(3)
 			sph.EXPECT().ECNMode(gomock.Any()).Times(3)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendPacingLimited)
@@ -1657,7 +2007,7 @@ var _ = Describe("Connection", func() {
 
 				written := make(chan struct{})
 				sender.EXPECT().WouldBlock().AnyTimes()
-				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 				sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 				sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
 				expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 1000}, []byte("packet1000"))
@@ -1679,9 +2029,9 @@ var _ = Describe("Connection", func() {
 
 			written := make(chan struct{})
 			sender.EXPECT().WouldBlock().AnyTimes()
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(time.Time, protocol.PacketNumber, protocol.PacketNumber, []ackhandler.StreamFrame, []ackhandler.Frame, protocol.EncryptionLevel, protocol.ECN, protocol.ByteCount, bool) {
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(time.Time, protocol.PacketNumber, protocol.PacketNumber, []ackhandler.StreamFrame, []ackhandler.Frame, protocol.EncryptionLevel, protocol.ECN, protocol.ByteCount, bool, bool) {
 				sph.EXPECT().ReceivedBytes(gomock.Any())
-				conn.handlePacket(receivedPacket{buffer: getPacketBuffer()})
+				conn.handlePacket(receivedPacket{remoteAddr: remoteAddr, buffer: getPacketBuffer()})
 			})
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
@@ -1696,7 +2046,7 @@ var _ = Describe("Connection", func() {
 		})
 
 		It("stops sending when the send queue is full", func() {
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny)
 			sph.EXPECT().ECNMode(gomock.Any())
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 1000}, []byte("packet1000"))
@@ -1717,7 +2067,7 @@ var _ = Describe("Connection", func() {
 			time.Sleep(scaleDuration(50 * time.Millisecond))
 
 			// now make room in the send queue
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
 			sender.EXPECT().WouldBlock().AnyTimes()
@@ -1752,7 +2102,7 @@ var _ = Describe("Connection", func() {
 			mtuDiscoverer := NewMockMTUDiscoverer(mockCtrl)
 			conn.mtuDiscoverer = mtuDiscoverer
 			conn.config.DisablePathMTUDiscovery = false
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny)
 			sph.EXPECT().ECNMode(true)
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendNone)
@@ -1807,7 +2157,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
 
-			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			conn.sentPacketHandler = sph
 			expectAppendPacket(packer, shortHeaderPacket{PacketNumber: 1}, []byte("packet1"))
 			packer.EXPECT().AppendPacket(gomock.Any(), gomock.Any(), conn.version).Return(shortHeaderPacket{}, errNothingToPack)
@@ -1835,7 +2185,7 @@ var _ = Describe("Connection", func() {
 			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 			sph.EXPECT().SendMode(gomock.Any()).Return(ackhandler.SendAny).AnyTimes()
 			sph.EXPECT().ECNMode(gomock.Any()).AnyTimes()
-			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(1234), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(1234), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			conn.sentPacketHandler = sph
 			rph := mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
 			rph.EXPECT().GetAlarmTimeout().Return(time.Now().Add(10 * time.Millisecond))
@@ -1889,8 +2239,8 @@ var _ = Describe("Connection", func() {
 		sph.EXPECT().ECNMode(false).Return(protocol.ECT1).AnyTimes()
 		sph.EXPECT().TimeUntilSend().Return(time.Now()).AnyTimes()
 		gomock.InOrder(
-			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(13), gomock.Any(), gomock.Any(), gomock.Any(), protocol.EncryptionInitial, protocol.ECT1, protocol.ByteCount(123), gomock.Any()),
-			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(37), gomock.Any(), gomock.Any(), gomock.Any(), protocol.EncryptionHandshake, protocol.ECT1, protocol.ByteCount(1234), gomock.Any()),
+			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(13), gomock.Any(), gomock.Any(), gomock.Any(), protocol.EncryptionInitial, protocol.ECT1, protocol.ByteCount(123), gomock.Any(), gomock.Any()),
+			sph.EXPECT().SentPacket(gomock.Any(), protocol.PacketNumber(37), gomock.Any(), gomock.Any(), gomock.Any(), protocol.EncryptionHandshake, protocol.ECT1, protocol.ByteCount(1234), gomock.Any(), gomock.Any()),
 		)
 		gomock.InOrder(
 			tracer.EXPECT().SentLongHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(func(hdr *wire.ExtendedHeader, _ protocol.ByteCount, _ logging.ECN, _ *wire.AckFrame, _ []logging.Frame) {
@@ -1978,6 +2328,84 @@ var _ = Describe("Connection", func() {
 		Expect(size).To(BeEquivalentTo(s))
 	})
 
+	It("saves the congestion state keyed by the token sent in the NEW_TOKEN frame", func() {
+		tokenStore := NewLRUTokenStore(10, 4)
+		conn.config.TokenStore = tokenStore
+		conn.config.EnableCarefulResume = true
+		packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).AnyTimes()
+		connRunner.EXPECT().Retire(clientDestConnID)
+		conn.sentPacketHandler.DropPackets(protocol.EncryptionInitial)
+		tracer.EXPECT().DroppedEncryptionLevel(protocol.EncryptionHandshake)
+		tracer.EXPECT().ChoseALPN(gomock.Any())
+		cryptoSetup.EXPECT().SetHandshakeConfirmed()
+		cryptoSetup.EXPECT().GetSessionTicket()
+		cryptoSetup.EXPECT().ConnectionState()
+		Expect(conn.handleHandshakeComplete()).To(Succeed())
+		frames, _ := conn.framer.AppendControlFrames(nil, protocol.MaxByteCount, protocol.Version1)
+		var token []byte
+		for _, f := range frames {
+			if tf, ok := f.Frame.(*wire.NewTokenFrame); ok {
+				token = tf.Token
+			}
+		}
+		Expect(token).ToNot(BeEmpty())
+
+		conn.rttStats.UpdateRTT(25*time.Millisecond, 0, time.Now())
+		conn.maybeSaveCongestionState()
+		state := tokenStore.(CongestionStateStore).PopCongestionState(string(token))
+		Expect(state).ToNot(BeNil())
+		Expect(state.RTT).To(Equal(25 * time.Millisecond))
+		Expect(state.CongestionWindow).To(Equal(conn.sentPacketHandler.CongestionWindow()))
+	})
+
+	It("uses the congestion state saved for the token presented by the client", func() {
+		tokenStore := NewLRUTokenStore(10, 4)
+		tokenStore.(CongestionStateStore).PutCongestionState("token", &CongestionState{RTT: 30 * time.Millisecond, CongestionWindow: 1e6, SavedAt: time.Now()})
+		var resumedRTT time.Duration
+		var resumedCwnd congestion.ByteCount
+		conf := populateConfig(&Config{
+			DisablePathMTUDiscovery: true,
+			EnableCarefulResume:     true,
+			TokenStore:              tokenStore,
+			CongestionControl: func(info *congestion.ConnectionInfo) congestion.SendAlgorithm {
+				return &resumableSendAlgorithm{
+					SendAlgorithm: congestion.NewReno(info),
+					onResume: func(rtt time.Duration, cwnd congestion.ByteCount) {
+						resumedRTT = rtt
+						resumedCwnd = cwnd
+					},
+				}
+			},
+		})
+		ctx, cancel := context.WithCancelCause(context.Background())
+		defer cancel(nil)
+		newConnection(
+			ctx,
+			cancel,
+			mconn,
+			connRunner,
+			protocol.ConnectionID{},
+			nil,
+			clientDestConnID,
+			destConnID,
+			srcConnID,
+			&protocol.DefaultConnectionIDGenerator{},
+			protocol.StatelessResetToken{},
+			conf,
+			&tls.Config{},
+			handshake.NewTokenGenerator([32]byte{}),
+			nil,
+			true,
+			[]byte("token"),
+			nil,
+			utils.DefaultLogger,
+			protocol.Version1,
+		)
+		Expect(resumedRTT).To(Equal(30 * time.Millisecond))
+		Expect(resumedCwnd).To(BeEquivalentTo(1e6))
+		Expect(tokenStore.(CongestionStateStore).PopCongestionState("token")).To(BeNil())
+	})
+
 	It("doesn't cancel the HandshakeComplete context when the handshake fails", func() {
 		packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).AnyTimes()
 		streamManager.EXPECT().CloseWithError(gomock.Any())
@@ -2007,7 +2435,7 @@ var _ = Describe("Connection", func() {
 		sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
 		sph.EXPECT().TimeUntilSend().AnyTimes()
 		sph.EXPECT().SetHandshakeConfirmed()
-		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+		sph.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		mconn.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().SentShortHeaderPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 		tracer.EXPECT().ChoseALPN(gomock.Any())
@@ -2483,6 +2911,24 @@ var _ = Describe("Connection", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(str).To(Equal(mstr))
 		})
+		It("changes the stream limits", func() {
+			streamManager.EXPECT().SetMaxIncomingStreams(protocol.StreamTypeBidi, uint64(42))
+			conn.SetMaxIncomingStreams(42)
+			streamManager.EXPECT().SetMaxIncomingStreams(protocol.StreamTypeUni, uint64(0))
+			conn.SetMaxIncomingUniStreams(-1)
+			streamManager.EXPECT().SetMaxIncomingStreams(protocol.StreamTypeUni, uint64(protocol.MaxStreamCount))
+			conn.SetMaxIncomingUniStreams(math.MaxInt64)
+		})
+
+		It("changes the receive window of a stream", func() {
+			mstr := NewMockReceiveStreamI(mockCtrl)
+			streamManager.EXPECT().GetReceiveStream(protocol.StreamID(5)).Return(mstr, true)
+			mstr.EXPECT().setReceiveWindowSize(protocol.ByteCount(1 << 20))
+			Expect(conn.SetStreamReceiveWindow(5, 1<<20)).To(Succeed())
+			streamManager.EXPECT().GetReceiveStream(protocol.StreamID(9)).Return(nil, false)
+			Expect(conn.SetStreamReceiveWindow(9, 1<<20)).To(MatchError("no receive stream with ID 9"))
+			Expect(conn.SetStreamReceiveWindow(5, 0)).To(MatchError("invalid receive window: 0"))
+		})
 	})
 
 	Context("datagrams", func() {
@@ -2494,11 +2940,21 @@ var _ = Describe("Connection", func() {
 		It("sends a datagram", func() {
 			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 1000}
 			Expect(conn.SendDatagram([]byte("foobar"))).To(Succeed())
-			f := conn.datagramQueue.Peek()
+			f := conn.datagramQueue.Peek(DatagramPriorityHigh)
 			Expect(f).ToNot(BeNil())
 			Expect(f.Data).To(Equal([]byte("foobar")))
 		})
 
+		It("sends a datagram with options", func() {
+			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 1000}
+			Expect(conn.SendDatagramWithOptions([]byte("foobar"), &DatagramOptions{Priority: DatagramPriorityLow})).To(Succeed())
+			Expect(conn.datagramQueue.Peek(DatagramPriorityHigh)).To(BeNil())
+			f := conn.datagramQueue.Peek(DatagramPriorityLow)
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte("foobar")))
+			Expect(conn.SendDatagramWithOptions([]byte("foobar"), &DatagramOptions{Priority: 42})).To(MatchError("invalid datagram priority: 42"))
+		})
+
 		It("says when a datagram is too big", func() {
 			conn.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 1000}
 			err := conn.SendDatagram(make([]byte, 2000))
@@ -2511,11 +2967,36 @@ var _ = Describe("Connection", func() {
 
 		It("receives datagrams", func() {
 			conn.config.EnableDatagrams = true
-			conn.datagramQueue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			conn.datagramQueue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now())
 			data, err := conn.ReceiveDatagram(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(data).To(Equal([]byte("foobar")))
 		})
+
+		It("receives multiple datagrams at once", func() {
+			conn.config.EnableDatagrams = true
+			rcvTime := time.Now()
+			conn.lastPacketReceivedTime = rcvTime
+			Expect(conn.handleFrame(&wire.DatagramFrame{Data: []byte("foo")}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			Expect(conn.handleFrame(&wire.DatagramFrame{Data: []byte("bar")}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			dgs := make([]ReceivedDatagram, 5)
+			n, err := conn.ReceiveDatagrams(context.Background(), dgs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(dgs[:n]).To(Equal([]ReceivedDatagram{
+				{Data: []byte("foo"), ReceivedAt: rcvTime},
+				{Data: []byte("bar"), ReceivedAt: rcvTime},
+			}))
+		})
+
+		It("counts and traces dropped datagrams", func() {
+			conn.config.EnableDatagrams = true
+			conn.datagramQueue = newDatagramQueue(func() {}, 1, utils.DefaultLogger)
+			Expect(conn.handleFrame(&wire.DatagramFrame{Data: []byte("foo")}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			tracer.EXPECT().DroppedDatagram(protocol.ByteCount(6))
+			Expect(conn.handleFrame(&wire.DatagramFrame{Data: []byte("foobar")}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			cryptoSetup.EXPECT().ConnectionState()
+			Expect(conn.ConnectionState().DatagramsDropped).To(BeEquivalentTo(1))
+		})
 	})
 
 	It("returns the local address", func() {
@@ -2583,6 +3064,7 @@ var _ = Describe("Client Connection", func() {
 			&protocol.DefaultConnectionIDGenerator{},
 			quicConf,
 			tlsConf,
+			nil,
 			42, // initial packet number
 			false,
 			false,
@@ -2691,6 +3173,7 @@ var _ = Describe("Client Connection", func() {
 		sph.EXPECT().ReceivedAck(ack, protocol.Encryption1RTT, gomock.Any()).Return(true, nil)
 		sph.EXPECT().DropPackets(protocol.EncryptionHandshake)
 		sph.EXPECT().SetHandshakeConfirmed()
+		sph.EXPECT().GetAckFrequencyFrame(gomock.Any())
 		cryptoSetup.EXPECT().SetLargest1RTTAcked(protocol.PacketNumber(3))
 		cryptoSetup.EXPECT().SetHandshakeConfirmed()
 		Expect(conn.handleAckFrame(ack, protocol.Encryption1RTT)).To(Succeed())
@@ -2717,6 +3200,22 @@ var _ = Describe("Client Connection", func() {
 		Eventually(areConnsRunning).Should(BeFalse())
 	})
 
+	It("ignores PATH_RESPONSE frames that don't match a PATH_CHALLENGE", func() {
+		Expect(conn.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+	})
+
+	It("doesn't allow adding paths before completion of the handshake", func() {
+		_, err := conn.AddPath(&Transport{})
+		Expect(err).To(MatchError("cannot migrate connection before handshake completion"))
+	})
+
+	It("doesn't allow adding paths if the server disabled active migration", func() {
+		close(conn.handshakeCompleteChan)
+		conn.peerParams = &wire.TransportParameters{DisableActiveMigration: true}
+		_, err := conn.AddPath(&Transport{})
+		Expect(err).To(MatchError("server disabled connection migration"))
+	})
+
 	Context("handling tokens", func() {
 		var mockTokenStore *MockTokenStore
 
@@ -2734,6 +3233,136 @@ var _ = Describe("Client Connection", func() {
 		})
 	})
 
+	Context("careful resume", func() {
+		var (
+			tokenStore   TokenStore
+			resumedRTT   time.Duration
+			resumedCwnd  congestion.ByteCount
+			resumeCalled bool
+		)
+
+		BeforeEach(func() {
+			tokenStore = NewLRUTokenStore(1, 4)
+			tlsConf = &tls.Config{ServerName: "server"}
+			quicConf.TokenStore = tokenStore
+			quicConf.EnableCarefulResume = true
+			resumeCalled = false
+			quicConf.CongestionControl = func(info *congestion.ConnectionInfo) congestion.SendAlgorithm {
+				return &resumableSendAlgorithm{
+					SendAlgorithm: congestion.NewReno(info),
+					onResume: func(rtt time.Duration, cwnd congestion.ByteCount) {
+						resumeCalled = true
+						resumedRTT = rtt
+						resumedCwnd = cwnd
+					},
+				}
+			}
+		})
+
+		Context("using saved state", func() {
+			BeforeEach(func() {
+				tokenStore.Put("server", &ClientToken{data: []byte("token")})
+				tokenStore.(CongestionStateStore).PutCongestionState("server", &CongestionState{RTT: 30 * time.Millisecond, CongestionWindow: 1e6, SavedAt: time.Now()})
+			})
+
+			It("uses the congestion state saved in the token store", func() {
+				Expect(resumeCalled).To(BeTrue())
+				Expect(resumedRTT).To(Equal(30 * time.Millisecond))
+				Expect(resumedCwnd).To(BeEquivalentTo(1e6))
+				// both the token and the congestion state were consumed
+				Expect(tokenStore.Pop("server")).To(BeNil())
+				Expect(tokenStore.(CongestionStateStore).PopCongestionState("server")).To(BeNil())
+			})
+		})
+
+		Context("using expired state", func() {
+			BeforeEach(func() {
+				tokenStore.(CongestionStateStore).PutCongestionState("server", &CongestionState{RTT: 30 * time.Millisecond, CongestionWindow: 1e6, SavedAt: time.Now().Add(-protocol.CarefulResumeMaxAge - time.Second)})
+			})
+
+			It("doesn't use expired congestion state", func() {
+				Expect(resumeCalled).To(BeFalse())
+			})
+		})
+
+		It("saves the congestion state when the connection is closed", func() {
+			tokenStore.Put("server", &ClientToken{data: []byte("token")})
+			conn.handshakeComplete = true
+			conn.rttStats.UpdateRTT(25*time.Millisecond, 0, time.Now())
+			conn.maybeSaveCongestionState()
+			state := tokenStore.(CongestionStateStore).PopCongestionState("server")
+			Expect(state).ToNot(BeNil())
+			Expect(state.RTT).To(Equal(25 * time.Millisecond))
+			Expect(state.CongestionWindow).To(Equal(conn.sentPacketHandler.CongestionWindow()))
+			Expect(state.SavedAt).To(BeTemporally("~", time.Now(), time.Second))
+			// saving the congestion state doesn't affect the tokens
+			Expect(tokenStore.Pop("server")).To(Equal(&ClientToken{data: []byte("token")}))
+		})
+
+		It("doesn't save the congestion state if the handshake didn't complete", func() {
+			conn.rttStats.UpdateRTT(25*time.Millisecond, 0, time.Now())
+			conn.maybeSaveCongestionState()
+			Expect(tokenStore.(CongestionStateStore).PopCongestionState("server")).To(BeNil())
+		})
+
+		It("doesn't save the congestion state if careful resume is disabled", func() {
+			conn.config.EnableCarefulResume = false
+			conn.handshakeComplete = true
+			conn.rttStats.UpdateRTT(25*time.Millisecond, 0, time.Now())
+			conn.maybeSaveCongestionState()
+			Expect(tokenStore.(CongestionStateStore).PopCongestionState("server")).To(BeNil())
+		})
+
+		Context("using a custom store", func() {
+			var store *mapCongestionStateStore
+
+			BeforeEach(func() {
+				store = &mapCongestionStateStore{
+					TokenStore: tokenStore,
+					states: map[string]*CongestionState{
+						"server": {RTT: 40 * time.Millisecond, CongestionWindow: 2e6, SavedAt: time.Now()},
+					},
+				}
+				quicConf.TokenStore = store
+			})
+
+			It("uses the congestion state", func() {
+				Expect(resumeCalled).To(BeTrue())
+				Expect(resumedRTT).To(Equal(40 * time.Millisecond))
+				Expect(resumedCwnd).To(BeEquivalentTo(2e6))
+				Expect(store.states).To(BeEmpty())
+			})
+
+			It("saves the congestion state", func() {
+				conn.handshakeComplete = true
+				conn.rttStats.UpdateRTT(25*time.Millisecond, 0, time.Now())
+				conn.maybeSaveCongestionState()
+				Expect(store.states).To(HaveKey("server"))
+				Expect(store.states["server"].RTT).To(Equal(25 * time.Millisecond))
+				Expect(store.states["server"].CongestionWindow).To(Equal(conn.sentPacketHandler.CongestionWindow()))
+			})
+		})
+
+		Context("using a store that doesn't store congestion state", func() {
+			BeforeEach(func() {
+				tokenStore.(CongestionStateStore).PutCongestionState("server", &CongestionState{RTT: 30 * time.Millisecond, CongestionWindow: 1e6, SavedAt: time.Now()})
+				// hide the CongestionStateStore methods of the LRU token store
+				quicConf.TokenStore = struct{ TokenStore }{tokenStore}
+			})
+
+			It("doesn't use careful resume", func() {
+				Expect(resumeCalled).To(BeFalse())
+				conn.handshakeComplete = true
+				conn.rttStats.UpdateRTT(25*time.Millisecond, 0, time.Now())
+				conn.maybeSaveCongestionState()
+				// the state saved before the connection was neither used nor overwritten
+				state := tokenStore.(CongestionStateStore).PopCongestionState("server")
+				Expect(state).ToNot(BeNil())
+				Expect(state.RTT).To(Equal(30 * time.Millisecond))
+			})
+		})
+	})
+
 	Context("handling Version Negotiation", func() {
 		getVNP := func(versions ...protocol.Version) receivedPacket {
 			b := wire.ComposeVersionNegotiation(
@@ -2951,7 +3580,7 @@ var _ = Describe("Client Connection", func() {
 			Eventually(errChan).Should(BeClosed())
 		})
 
-		It("uses the preferred_address connection ID", func() {
+		It("reserves the preferred_address connection ID for migrating to the preferred address", func() {
 			params := &wire.TransportParameters{
 				OriginalDestinationConnectionID: destConnID,
 				InitialSourceConnectionID:       destConnID,
@@ -2962,6 +3591,37 @@ var _ = Describe("Client Connection", func() {
 					StatelessResetToken: protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
 				},
 			}
+			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			reserved := make(chan struct{})
+			connRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, conn).Do(
+				func(protocol.StatelessResetToken, packetHandler) { close(reserved) },
+			)
+			paramsChan <- params
+			Eventually(reserved).Should(BeClosed())
+			// the client uses the IPv6 address, since it's not connected via IPv4
+			pm := conn.pathManagerOutgoing
+			pm.mutex.Lock()
+			Expect(pm.paths).To(HaveLen(1))
+			Expect(pm.paths[1].tr).To(BeNil())
+			Expect(pm.paths[1].remoteAddr).To(Equal(&net.UDPAddr{IP: net.IP{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, Port: 13}))
+			pm.mutex.Unlock()
+			// shut down
+			connRunner.EXPECT().RemoveResetToken(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1})
+			expectClose(true, false)
+		})
+
+		It("uses the preferred_address connection ID, if there's no address of the same address family", func() {
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				PreferredAddress: &wire.PreferredAddress{
+					IPv4:                netip.AddrPortFrom(netip.AddrFrom4([4]byte{127, 0, 0, 1}), 42),
+					IPv6:                netip.AddrPortFrom(netip.IPv6Unspecified(), 0),
+					ConnectionID:        protocol.ParseConnectionID([]byte{1, 2, 3, 4}),
+					StatelessResetToken: protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+				},
+			}
 			packer.EXPECT().PackCoalescedPacket(false, gomock.Any(), conn.version).MaxTimes(1)
 			processed := make(chan struct{})
 			tracer.EXPECT().ReceivedTransportParameters(params).Do(func(*wire.TransportParameters) { close(processed) })
@@ -3244,3 +3904,31 @@ var _ = Describe("Client Connection", func() {
 		})
 	})
 })
+
+type resumableSendAlgorithm struct {
+	congestion.SendAlgorithm
+	onResume func(time.Duration, congestion.ByteCount)
+}
+
+var _ congestion.CarefulResumeSendAlgorithm = &resumableSendAlgorithm{}
+
+func (a *resumableSendAlgorithm) SetResumeState(rtt time.Duration, cwnd congestion.ByteCount) {
+	a.onResume(rtt, cwnd)
+}
+
+type mapCongestionStateStore struct {
+	TokenStore
+	states map[string]*CongestionState
+}
+
+var _ CongestionStateStore = &mapCongestionStateStore{}
+
+func (s *mapCongestionStateStore) PopCongestionState(key string) *CongestionState {
+	state := s.states[key]
+	delete(s.states, key)
+	return state
+}
+
+func (s *mapCongestionStateStore) PutCongestionState(key string, state *CongestionState) {
+	s.states[key] = state
+}
@@ -3,25 +3,51 @@ package quic
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/quic-go/quic-go/internal/ackhandler"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/utils/ringbuffer"
 	"github.com/quic-go/quic-go/internal/wire"
 )
 
-const (
-	maxDatagramSendQueueLen = 32
-	maxDatagramRcvQueueLen  = 128
-)
+const maxDatagramSendQueueLen = 32
+
+// A queuedDatagram is a DATAGRAM frame queued for sending.
+// If the application requested to be notified about the fate of the datagram,
+// it is used as the handler of the DATAGRAM frame in the ackhandler.
+type queuedDatagram struct {
+	frame    *wire.DatagramFrame
+	deadline time.Time
+	onAcked  func()
+	onLost   func()
+}
+
+var _ ackhandler.FrameHandler = &queuedDatagram{}
+
+func (d *queuedDatagram) OnAcked(wire.Frame) {
+	if d.onAcked != nil {
+		d.onAcked()
+	}
+}
+
+func (d *queuedDatagram) OnLost(wire.Frame) {
+	if d.onLost != nil {
+		d.onLost()
+	}
+}
 
 type datagramQueue struct {
-	sendMx    sync.Mutex
-	sendQueue ringbuffer.RingBuffer[*wire.DatagramFrame]
-	sent      chan struct{} // used to notify Add that a datagram was dequeued
+	sendMx sync.Mutex
+	// one queue per DatagramPriority
+	sendQueues [2]ringbuffer.RingBuffer[*queuedDatagram]
+	sent       chan struct{} // used to notify Add that a datagram was dequeued
 
-	rcvMx    sync.Mutex
-	rcvQueue [][]byte
-	rcvd     chan struct{} // used to notify Receive that a new datagram was received
+	rcvMx          sync.Mutex
+	rcvQueue       ringbuffer.RingBuffer[ReceivedDatagram]
+	maxRcvQueueLen int
+	rcvDropped     uint64        // number of received datagrams dropped because the queue was full
+	rcvd           chan struct{} // used to notify Receive that a new datagram was received
 
 	closeErr error
 	closed   chan struct{}
@@ -31,25 +57,36 @@ type datagramQueue struct {
 	logger utils.Logger
 }
 
-func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
+func newDatagramQueue(hasData func(), maxRcvQueueLen int, logger utils.Logger) *datagramQueue {
 	return &datagramQueue{
-		hasData: hasData,
-		rcvd:    make(chan struct{}, 1),
-		sent:    make(chan struct{}, 1),
-		closed:  make(chan struct{}),
-		logger:  logger,
+		hasData:        hasData,
+		maxRcvQueueLen: maxRcvQueueLen,
+		rcvd:           make(chan struct{}, 1),
+		sent:           make(chan struct{}, 1),
+		closed:         make(chan struct{}),
+		logger:         logger,
 	}
 }
 
 // Add queues a new DATAGRAM frame for sending.
 // Up to 32 DATAGRAM frames will be queued.
 // Once that limit is reached, Add blocks until the queue size has reduced.
-func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
+// opts may be nil.
+func (h *datagramQueue) Add(f *wire.DatagramFrame, opts *DatagramOptions) error {
+	d := &queuedDatagram{frame: f}
+	var prio DatagramPriority
+	if opts != nil {
+		prio = opts.Priority
+		d.deadline = opts.Deadline
+		d.onAcked = opts.OnAcked
+		d.onLost = opts.OnLost
+	}
+
 	h.sendMx.Lock()
 
 	for {
-		if h.sendQueue.Len() < maxDatagramSendQueueLen {
-			h.sendQueue.PushBack(f)
+		if h.sendQueues[0].Len()+h.sendQueues[1].Len() < maxDatagramSendQueueLen {
+			h.sendQueues[prio].PushBack(d)
 			h.sendMx.Unlock()
 			h.hasData()
 			return nil
@@ -68,21 +105,64 @@ func (h *datagramQueue) Add(f *wire.DatagramFrame) error {
 	}
 }
 
-// Peek gets the next DATAGRAM frame for sending.
+// Peek gets the next DATAGRAM frame of the given priority for sending.
+// Datagrams whose deadline has passed are dropped.
 // If actually sent out, Pop needs to be called before the next call to Peek.
-func (h *datagramQueue) Peek() *wire.DatagramFrame {
+func (h *datagramQueue) Peek(prio DatagramPriority) *wire.DatagramFrame {
+	var expired []*queuedDatagram
+	defer func() {
+		for _, d := range expired {
+			d.OnLost(d.frame)
+		}
+	}()
+
 	h.sendMx.Lock()
 	defer h.sendMx.Unlock()
-	if h.sendQueue.Empty() {
-		return nil
+	q := &h.sendQueues[prio]
+	var now time.Time
+	for !q.Empty() {
+		d := q.PeekFront()
+		if d.deadline.IsZero() {
+			return d.frame
+		}
+		if now.IsZero() {
+			now = time.Now()
+		}
+		if now.Before(d.deadline) {
+			return d.frame
+		}
+		if h.logger.Debug() {
+			h.logger.Debugf("Discarding DATAGRAM frame (%d bytes payload), since its deadline expired", len(d.frame.Data))
+		}
+		expired = append(expired, q.PopFront())
+		h.notifySent()
 	}
-	return h.sendQueue.PeekFront()
+	return nil
 }
 
-func (h *datagramQueue) Pop() {
+// Pop removes the DATAGRAM frame returned by Peek from the queue.
+// It returns the handler that needs to be used for the frame, if any.
+func (h *datagramQueue) Pop(prio DatagramPriority) ackhandler.FrameHandler {
 	h.sendMx.Lock()
 	defer h.sendMx.Unlock()
-	_ = h.sendQueue.PopFront()
+	d := h.sendQueues[prio].PopFront()
+	h.notifySent()
+	if d.onAcked == nil && d.onLost == nil {
+		return nil
+	}
+	return d
+}
+
+// Drop discards the DATAGRAM frame returned by Peek, without sending it.
+func (h *datagramQueue) Drop(prio DatagramPriority) {
+	h.sendMx.Lock()
+	d := h.sendQueues[prio].PopFront()
+	h.notifySent()
+	h.sendMx.Unlock()
+	d.OnLost(d.frame)
+}
+
+func (h *datagramQueue) notifySent() {
 	select {
 	case h.sent <- struct{}{}:
 	default:
@@ -90,43 +170,71 @@ func (h *datagramQueue) Pop() {
 }
 
 // HandleDatagramFrame handles a received DATAGRAM frame.
-func (h *datagramQueue) HandleDatagramFrame(f *wire.DatagramFrame) {
-	data := make([]byte, len(f.Data))
-	copy(data, f.Data)
-	var queued bool
+// It returns false if the frame was dropped because the receive queue is full.
+func (h *datagramQueue) HandleDatagramFrame(f *wire.DatagramFrame, rcvTime time.Time) bool {
 	h.rcvMx.Lock()
-	if len(h.rcvQueue) < maxDatagramRcvQueueLen {
-		h.rcvQueue = append(h.rcvQueue, data)
-		queued = true
-		select {
-		case h.rcvd <- struct{}{}:
-		default:
+	if h.rcvQueue.Len() >= h.maxRcvQueueLen {
+		h.rcvDropped++
+		h.rcvMx.Unlock()
+		if h.logger.Debug() {
+			h.logger.Debugf("Discarding received DATAGRAM frame (%d bytes payload)", len(f.Data))
 		}
+		return false
 	}
+	data := make([]byte, len(f.Data))
+	copy(data, f.Data)
+	h.rcvQueue.PushBack(ReceivedDatagram{Data: data, ReceivedAt: rcvTime})
 	h.rcvMx.Unlock()
-	if !queued && h.logger.Debug() {
-		h.logger.Debugf("Discarding received DATAGRAM frame (%d bytes payload)", len(f.Data))
+	select {
+	case h.rcvd <- struct{}{}:
+	default:
 	}
+	return true
+}
+
+// NumDropped returns the number of received DATAGRAM frames that were dropped
+// because the receive queue was full.
+func (h *datagramQueue) NumDropped() uint64 {
+	h.rcvMx.Lock()
+	defer h.rcvMx.Unlock()
+	return h.rcvDropped
 }
 
 // Receive gets a received DATAGRAM frame.
 func (h *datagramQueue) Receive(ctx context.Context) ([]byte, error) {
+	var dgs [1]ReceivedDatagram
+	if _, err := h.ReceiveBatch(ctx, dgs[:]); err != nil {
+		return nil, err
+	}
+	return dgs[0].Data, nil
+}
+
+// ReceiveBatch dequeues up to len(dgs) received DATAGRAM frames.
+// It blocks until at least one DATAGRAM frame is available.
+// If the context is already done, it returns the frames that are queued, without blocking.
+func (h *datagramQueue) ReceiveBatch(ctx context.Context, dgs []ReceivedDatagram) (int, error) {
+	if len(dgs) == 0 {
+		return 0, nil
+	}
 	for {
 		h.rcvMx.Lock()
-		if len(h.rcvQueue) > 0 {
-			data := h.rcvQueue[0]
-			h.rcvQueue = h.rcvQueue[1:]
+		if !h.rcvQueue.Empty() {
+			var n int
+			for n < len(dgs) && !h.rcvQueue.Empty() {
+				dgs[n] = h.rcvQueue.PopFront()
+				n++
+			}
 			h.rcvMx.Unlock()
-			return data, nil
+			return n, nil
 		}
 		h.rcvMx.Unlock()
 		select {
 		case <-h.rcvd:
 			continue
 		case <-h.closed:
-			return nil, h.closeErr
+			return 0, h.closeErr
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return 0, ctx.Err()
 		}
 	}
 }
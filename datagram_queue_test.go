@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
 
@@ -18,69 +19,141 @@ var _ = Describe("Datagram Queue", func() {
 
 	BeforeEach(func() {
 		queued = make(chan struct{}, 100)
-		queue = newDatagramQueue(func() { queued <- struct{}{} }, utils.DefaultLogger)
+		queue = newDatagramQueue(func() { queued <- struct{}{} }, protocol.DefaultMaxDatagramReceiveQueueLen, utils.DefaultLogger)
 	})
 
 	Context("sending", func() {
 		It("returns nil when there's no datagram to send", func() {
-			Expect(queue.Peek()).To(BeNil())
+			Expect(queue.Peek(DatagramPriorityHigh)).To(BeNil())
 		})
 
 		It("queues a datagram", func() {
 			frame := &wire.DatagramFrame{Data: []byte("foobar")}
-			Expect(queue.Add(frame)).To(Succeed())
+			Expect(queue.Add(frame, nil)).To(Succeed())
 			Expect(queued).To(HaveLen(1))
-			f := queue.Peek()
+			f := queue.Peek(DatagramPriorityHigh)
 			Expect(f.Data).To(Equal([]byte("foobar")))
-			queue.Pop()
-			Expect(queue.Peek()).To(BeNil())
+			queue.Pop(DatagramPriorityHigh)
+			Expect(queue.Peek(DatagramPriorityHigh)).To(BeNil())
 		})
 
 		It("blocks when the maximum number of datagrams have been queued", func() {
 			for i := 0; i < maxDatagramSendQueueLen; i++ {
-				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}})).To(Succeed())
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}}, nil)).To(Succeed())
 			}
 			errChan := make(chan error, 1)
 			go func() {
 				defer GinkgoRecover()
-				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foobar")})
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, nil)
 			}()
 			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
-			Expect(queue.Peek()).ToNot(BeNil())
+			Expect(queue.Peek(DatagramPriorityHigh)).ToNot(BeNil())
 			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
-			queue.Pop()
+			queue.Pop(DatagramPriorityHigh)
 			Eventually(errChan).Should(Receive(BeNil()))
 			for i := 1; i < maxDatagramSendQueueLen; i++ {
-				queue.Pop()
+				queue.Pop(DatagramPriorityHigh)
 			}
-			f := queue.Peek()
+			f := queue.Peek(DatagramPriorityHigh)
 			Expect(f).ToNot(BeNil())
 			Expect(f.Data).To(Equal([]byte("foobar")))
 		})
 
 		It("returns the same datagram multiple times, when Pop isn't called", func() {
-			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
-			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, nil)).To(Succeed())
 
 			Eventually(queued).Should(HaveLen(2))
-			f := queue.Peek()
+			f := queue.Peek(DatagramPriorityHigh)
 			Expect(f.Data).To(Equal([]byte("foo")))
-			Expect(queue.Peek()).To(Equal(f))
-			Expect(queue.Peek()).To(Equal(f))
-			queue.Pop()
-			f = queue.Peek()
+			Expect(queue.Peek(DatagramPriorityHigh)).To(Equal(f))
+			Expect(queue.Peek(DatagramPriorityHigh)).To(Equal(f))
+			queue.Pop(DatagramPriorityHigh)
+			f = queue.Peek(DatagramPriorityHigh)
 			Expect(f).ToNot(BeNil())
 			Expect(f.Data).To(Equal([]byte("bar")))
 		})
 
+		It("queues datagrams by priority", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("low")}, &DatagramOptions{Priority: DatagramPriorityLow})).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("high")}, &DatagramOptions{Priority: DatagramPriorityHigh})).To(Succeed())
+			f := queue.Peek(DatagramPriorityHigh)
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte("high")))
+			queue.Pop(DatagramPriorityHigh)
+			Expect(queue.Peek(DatagramPriorityHigh)).To(BeNil())
+			f = queue.Peek(DatagramPriorityLow)
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte("low")))
+		})
+
+		It("shares the queue size limit between priorities", func() {
+			for i := 0; i < maxDatagramSendQueueLen; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}}, &DatagramOptions{Priority: DatagramPriorityLow})).To(Succeed())
+			}
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, nil)
+			}()
+			Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+			queue.Pop(DatagramPriorityLow)
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+
+		It("drops datagrams when their deadline expires", func() {
+			var lost []string
+			add := func(data string, deadline time.Time) {
+				ExpectWithOffset(1, queue.Add(
+					&wire.DatagramFrame{Data: []byte(data)},
+					&DatagramOptions{Deadline: deadline, OnLost: func() { lost = append(lost, data) }},
+				)).To(Succeed())
+			}
+			add("foo", time.Now().Add(-time.Second))
+			add("bar", time.Now().Add(-time.Second))
+			add("baz", time.Now().Add(time.Hour))
+			f := queue.Peek(DatagramPriorityHigh)
+			Expect(f).ToNot(BeNil())
+			Expect(f.Data).To(Equal([]byte("baz")))
+			Expect(lost).To(Equal([]string{"foo", "bar"}))
+		})
+
+		It("returns a handler for datagrams that request notifications", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
+			var acked, lost int
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, &DatagramOptions{
+				OnAcked: func() { acked++ },
+				OnLost:  func() { lost++ },
+			})).To(Succeed())
+			Expect(queue.Peek(DatagramPriorityHigh)).ToNot(BeNil())
+			Expect(queue.Pop(DatagramPriorityHigh)).To(BeNil())
+			f := queue.Peek(DatagramPriorityHigh)
+			Expect(f).ToNot(BeNil())
+			h := queue.Pop(DatagramPriorityHigh)
+			Expect(h).ToNot(BeNil())
+			h.OnAcked(f)
+			Expect(acked).To(Equal(1))
+			h.OnLost(f)
+			Expect(lost).To(Equal(1))
+		})
+
+		It("reports dropped datagrams as lost", func() {
+			var lost bool
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, &DatagramOptions{OnLost: func() { lost = true }})).To(Succeed())
+			Expect(queue.Peek(DatagramPriorityHigh)).ToNot(BeNil())
+			queue.Drop(DatagramPriorityHigh)
+			Expect(lost).To(BeTrue())
+			Expect(queue.Peek(DatagramPriorityHigh)).To(BeNil())
+		})
+
 		It("closes", func() {
 			for i := 0; i < maxDatagramSendQueueLen; i++ {
-				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")})).To(Succeed())
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)).To(Succeed())
 			}
 			errChan := make(chan error, 1)
 			go func() {
 				defer GinkgoRecover()
-				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foo")})
+				errChan <- queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, nil)
 			}()
 			Consistently(errChan, 25*time.Millisecond).ShouldNot(Receive())
 			testErr := errors.New("test error")
@@ -91,8 +164,8 @@ var _ = Describe("Datagram Queue", func() {
 
 	Context("receiving", func() {
 		It("receives DATAGRAM frames", func() {
-			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")})
-			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, time.Now())
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")}, time.Now())
 			data, err := queue.Receive(context.Background())
 			Expect(err).ToNot(HaveOccurred())
 			Expect(data).To(Equal([]byte("foo")))
@@ -101,6 +174,54 @@ var _ = Describe("Datagram Queue", func() {
 			Expect(data).To(Equal([]byte("bar")))
 		})
 
+		It("receives multiple DATAGRAM frames at once", func() {
+			t1 := time.Now()
+			t2 := t1.Add(time.Millisecond)
+			t3 := t2.Add(time.Millisecond)
+			Expect(queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, t1)).To(BeTrue())
+			Expect(queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")}, t2)).To(BeTrue())
+			Expect(queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("baz")}, t3)).To(BeTrue())
+			dgs := make([]ReceivedDatagram, 2)
+			n, err := queue.ReceiveBatch(context.Background(), dgs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(2))
+			Expect(dgs).To(Equal([]ReceivedDatagram{
+				{Data: []byte("foo"), ReceivedAt: t1},
+				{Data: []byte("bar"), ReceivedAt: t2},
+			}))
+			n, err = queue.ReceiveBatch(context.Background(), dgs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(1))
+			Expect(dgs[0]).To(Equal(ReceivedDatagram{Data: []byte("baz"), ReceivedAt: t3}))
+		})
+
+		It("doesn't block if the context is already done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			dgs := make([]ReceivedDatagram, 10)
+			_, err := queue.ReceiveBatch(ctx, dgs)
+			Expect(err).To(MatchError(context.Canceled))
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, time.Now())
+			n, err := queue.ReceiveBatch(ctx, dgs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(1))
+			Expect(dgs[0].Data).To(Equal([]byte("foo")))
+		})
+
+		It("drops DATAGRAM frames when the queue is full", func() {
+			queue = newDatagramQueue(func() {}, 2, utils.DefaultLogger)
+			Expect(queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foo")}, time.Now())).To(BeTrue())
+			Expect(queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("bar")}, time.Now())).To(BeTrue())
+			Expect(queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("baz")}, time.Now())).To(BeFalse())
+			Expect(queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("qux")}, time.Now())).To(BeFalse())
+			Expect(queue.NumDropped()).To(BeEquivalentTo(2))
+			data, err := queue.Receive(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal([]byte("foo")))
+			Expect(queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("baz")}, time.Now())).To(BeTrue())
+			Expect(queue.NumDropped()).To(BeEquivalentTo(2))
+		})
+
 		It("blocks until a frame is received", func() {
 			c := make(chan []byte, 1)
 			go func() {
@@ -111,7 +232,7 @@ var _ = Describe("Datagram Queue", func() {
 			}()
 
 			Consistently(c).ShouldNot(Receive())
-			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")})
+			queue.HandleDatagramFrame(&wire.DatagramFrame{Data: []byte("foobar")}, time.Now())
 			Eventually(c).Should(Receive(Equal([]byte("foobar"))))
 		})
 
@@ -2,6 +2,7 @@ package quic
 
 import (
 	"errors"
+	"slices"
 	"sync"
 
 	"github.com/quic-go/quic-go/internal/ackhandler"
@@ -18,6 +19,8 @@ type framer interface {
 	AppendControlFrames([]ackhandler.Frame, protocol.ByteCount, protocol.Version) ([]ackhandler.Frame, protocol.ByteCount)
 
 	AddActiveStream(protocol.StreamID)
+	SetStreamPriority(protocol.StreamID, StreamPriority)
+	RemoveStream(protocol.StreamID)
 	AppendStreamFrames([]ackhandler.StreamFrame, protocol.ByteCount, protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount)
 
 	Handle0RTTRejection() error
@@ -35,13 +38,69 @@ const (
 	maxControlFrames = 16 << 10
 )
 
+const maxStreamUrgency = 7
+
+var defaultStreamPriority = StreamPriority{Urgency: 3, Incremental: true}
+
+// A streamQueue holds the active streams of a single urgency.
+type streamQueue struct {
+	// non-incremental streams, sorted by stream ID
+	sequential []protocol.StreamID
+	// incremental streams, served in round-robin order
+	incremental ringbuffer.RingBuffer[protocol.StreamID]
+}
+
+func (q *streamQueue) Len() int {
+	return len(q.sequential) + q.incremental.Len()
+}
+
+func (q *streamQueue) Push(id protocol.StreamID, incremental bool) {
+	if incremental {
+		q.incremental.PushBack(id)
+		return
+	}
+	i, _ := slices.BinarySearch(q.sequential, id)
+	q.sequential = slices.Insert(q.sequential, i, id)
+}
+
+func (q *streamQueue) Pop() protocol.StreamID {
+	if len(q.sequential) > 0 {
+		id := q.sequential[0]
+		q.sequential = slices.Delete(q.sequential, 0, 1)
+		return id
+	}
+	return q.incremental.PopFront()
+}
+
+func (q *streamQueue) Remove(id protocol.StreamID, incremental bool) {
+	if !incremental {
+		if i, ok := slices.BinarySearch(q.sequential, id); ok {
+			q.sequential = slices.Delete(q.sequential, i, i+1)
+		}
+		return
+	}
+	for i, n := 0, q.incremental.Len(); i < n; i++ {
+		if sid := q.incremental.PopFront(); sid != id {
+			q.incremental.PushBack(sid)
+		}
+	}
+}
+
+func (q *streamQueue) Clear() {
+	q.sequential = q.sequential[:0]
+	q.incremental.Clear()
+}
+
 type framerI struct {
 	mutex sync.Mutex
 
 	streamGetter streamGetter
 
-	activeStreams map[protocol.StreamID]struct{}
-	streamQueue   ringbuffer.RingBuffer[protocol.StreamID]
+	// the priority of the active streams, as used for queueing them
+	activeStreams map[protocol.StreamID]StreamPriority
+	streamQueues  [maxStreamUrgency + 1]streamQueue
+	// only contains streams that don't use the default priority
+	priorities map[protocol.StreamID]StreamPriority
 
 	controlFrameMutex          sync.Mutex
 	controlFrames              []wire.Frame
@@ -54,13 +113,14 @@ var _ framer = &framerI{}
 func newFramer(streamGetter streamGetter) framer {
 	return &framerI{
 		streamGetter:  streamGetter,
-		activeStreams: make(map[protocol.StreamID]struct{}),
+		activeStreams: make(map[protocol.StreamID]StreamPriority),
+		priorities:    make(map[protocol.StreamID]StreamPriority),
 	}
 }
 
 func (f *framerI) HasData() bool {
 	f.mutex.Lock()
-	hasData := !f.streamQueue.Empty()
+	hasData := len(f.activeStreams) > 0
 	f.mutex.Unlock()
 	if hasData {
 		return true
@@ -128,8 +188,42 @@ func (f *framerI) QueuedTooManyControlFrames() bool {
 func (f *framerI) AddActiveStream(id protocol.StreamID) {
 	f.mutex.Lock()
 	if _, ok := f.activeStreams[id]; !ok {
-		f.streamQueue.PushBack(id)
-		f.activeStreams[id] = struct{}{}
+		prio, ok := f.priorities[id]
+		if !ok {
+			prio = defaultStreamPriority
+		}
+		f.activeStreams[id] = prio
+		f.streamQueues[prio.Urgency].Push(id, prio.Incremental)
+	}
+	f.mutex.Unlock()
+}
+
+func (f *framerI) SetStreamPriority(id protocol.StreamID, prio StreamPriority) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if prio == defaultStreamPriority {
+		delete(f.priorities, id)
+	} else {
+		f.priorities[id] = prio
+	}
+	// If the stream is currently active, move it to the queue for its new priority.
+	oldPrio, ok := f.activeStreams[id]
+	if !ok || oldPrio == prio {
+		return
+	}
+	f.streamQueues[oldPrio.Urgency].Remove(id, oldPrio.Incremental)
+	f.activeStreams[id] = prio
+	f.streamQueues[prio.Urgency].Push(id, prio.Incremental)
+}
+
+func (f *framerI) RemoveStream(id protocol.StreamID) {
+	f.mutex.Lock()
+	delete(f.priorities, id)
+	// A completed stream doesn't have any data left to send, so it doesn't need to stay in its queue.
+	if prio, ok := f.activeStreams[id]; ok {
+		delete(f.activeStreams, id)
+		f.streamQueues[prio.Urgency].Remove(id, prio.Incremental)
 	}
 	f.mutex.Unlock()
 }
@@ -138,40 +232,46 @@ func (f *framerI) AppendStreamFrames(frames []ackhandler.StreamFrame, maxLen pro
 	startLen := len(frames)
 	var length protocol.ByteCount
 	f.mutex.Lock()
-	// pop STREAM frames, until less than MinStreamFrameSize bytes are left in the packet
-	numActiveStreams := f.streamQueue.Len()
-	for i := 0; i < numActiveStreams; i++ {
-		if protocol.MinStreamFrameSize+length > maxLen {
-			break
-		}
-		id := f.streamQueue.PopFront()
-		// This should never return an error. Better check it anyway.
-		// The stream will only be in the streamQueue, if it enqueued itself there.
-		str, err := f.streamGetter.GetOrOpenSendStream(id)
-		// The stream can be nil if it completed after it said it had data.
-		if str == nil || err != nil {
-			delete(f.activeStreams, id)
-			continue
+	// Serve the queues in the order of their urgency.
+	// Streams from a less urgent queue are only dequeued if all streams from more urgent queues are blocked.
+	for urgency := range f.streamQueues {
+		q := &f.streamQueues[urgency]
+		// pop STREAM frames, until less than MinStreamFrameSize bytes are left in the packet
+		numActiveStreams := q.Len()
+		for i := 0; i < numActiveStreams; i++ {
+			if protocol.MinStreamFrameSize+length > maxLen {
+				break
+			}
+			id := q.Pop()
+			// This should never return an error. Better check it anyway.
+			// The stream will only be in the streamQueue, if it enqueued itself there.
+			str, err := f.streamGetter.GetOrOpenSendStream(id)
+			// The stream can be nil if it completed after it said it had data.
+			if str == nil || err != nil {
+				delete(f.activeStreams, id)
+				continue
+			}
+			remainingLen := maxLen - length
+			// For the last STREAM frame, we'll remove the DataLen field later.
+			// Therefore, we can pretend to have more bytes available when popping
+			// the STREAM frame (which will always have the DataLen set).
+			remainingLen += protocol.ByteCount(quicvarint.Len(uint64(remainingLen)))
+			frame, ok, hasMoreData := str.popStreamFrame(remainingLen, v)
+			if hasMoreData { // put the stream back in the queue (at the end, for incremental streams)
+				prio := f.activeStreams[id]
+				f.streamQueues[prio.Urgency].Push(id, prio.Incremental)
+			} else { // no more data to send. Stream is not active
+				delete(f.activeStreams, id)
+			}
+			// The frame can be "nil"
+			// * if the receiveStream was canceled after it said it had data
+			// * the remaining size doesn't allow us to add another STREAM frame
+			if !ok {
+				continue
+			}
+			frames = append(frames, frame)
+			length += frame.Frame.Length(v)
 		}
-		remainingLen := maxLen - length
-		// For the last STREAM frame, we'll remove the DataLen field later.
-		// Therefore, we can pretend to have more bytes available when popping
-		// the STREAM frame (which will always have the DataLen set).
-		remainingLen += protocol.ByteCount(quicvarint.Len(uint64(remainingLen)))
-		frame, ok, hasMoreData := str.popStreamFrame(remainingLen, v)
-		if hasMoreData { // put the stream back in the queue (at the end)
-			f.streamQueue.PushBack(id)
-		} else { // no more data to send. Stream is not active
-			delete(f.activeStreams, id)
-		}
-		// The frame can be "nil"
-		// * if the receiveStream was canceled after it said it had data
-		// * the remaining size doesn't allow us to add another STREAM frame
-		if !ok {
-			continue
-		}
-		frames = append(frames, frame)
-		length += frame.Frame.Length(v)
 	}
 	f.mutex.Unlock()
 	if len(frames) > startLen {
@@ -188,7 +288,9 @@ func (f *framerI) Handle0RTTRejection() error {
 	defer f.mutex.Unlock()
 
 	f.controlFrameMutex.Lock()
-	f.streamQueue.Clear()
+	for i := range f.streamQueues {
+		f.streamQueues[i].Clear()
+	}
 	for id := range f.activeStreams {
 		delete(f.activeStreams, id)
 	}
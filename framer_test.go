@@ -443,6 +443,125 @@ var _ = Describe("Framer", func() {
 			Expect(length).To(Equal(f.Length(version)))
 		})
 
+		Context("priorities", func() {
+			const id3 = protocol.StreamID(12)
+			var stream3 *MockSendStreamI
+
+			BeforeEach(func() {
+				stream3 = NewMockSendStreamI(mockCtrl)
+			})
+
+			expectFrame := func(str *MockSendStreamI, id protocol.StreamID, hasMore bool) *wire.StreamFrame {
+				f := &wire.StreamFrame{StreamID: id, Data: []byte("foobar")}
+				streamGetter.EXPECT().GetOrOpenSendStream(id).Return(str, nil)
+				str.EXPECT().popStreamFrame(gomock.Any(), protocol.Version1).Return(ackhandler.StreamFrame{Frame: f}, true, hasMore)
+				return f
+			}
+
+			It("sends data from more urgent streams first", func() {
+				framer.SetStreamPriority(id2, StreamPriority{Urgency: 1, Incremental: true})
+				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id2)
+				f2 := expectFrame(stream2, id2, true)
+				frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f2))
+				// stream 2 still has data, so it is served again
+				f2 = expectFrame(stream2, id2, false)
+				frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f2))
+				// now stream 1 is served
+				f1 := expectFrame(stream1, id1, false)
+				frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f1))
+				Expect(framer.HasData()).To(BeFalse())
+			})
+
+			It("fills the packet with data from less urgent streams", func() {
+				framer.SetStreamPriority(id1, StreamPriority{Urgency: 7, Incremental: true})
+				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id2)
+				f2 := expectFrame(stream2, id2, false)
+				f1 := expectFrame(stream1, id1, false)
+				frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(HaveLen(2))
+				Expect(frames[0].Frame).To(Equal(f2))
+				Expect(frames[1].Frame).To(Equal(f1))
+			})
+
+			It("sends non-incremental streams sequentially, in the order of their stream IDs", func() {
+				framer.SetStreamPriority(id1, StreamPriority{Urgency: 3})
+				framer.SetStreamPriority(id2, StreamPriority{Urgency: 3})
+				framer.AddActiveStream(id3)
+				framer.AddActiveStream(id2)
+				framer.AddActiveStream(id1)
+				// stream 1 is served until it doesn't have any more data
+				f := expectFrame(stream1, id1, true)
+				frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f))
+				f = expectFrame(stream1, id1, false)
+				frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f))
+				// then stream 2
+				f = expectFrame(stream2, id2, false)
+				frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f))
+				// then the incremental stream 3
+				f = expectFrame(stream3, id3, false)
+				frames, _ = framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f))
+			})
+
+			It("reschedules active streams when their priority changes", func() {
+				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id2)
+				framer.SetStreamPriority(id2, StreamPriority{Urgency: 0})
+				f2 := expectFrame(stream2, id2, false)
+				frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f2))
+				// stream 1 is only queued once
+				f1 := expectFrame(stream1, id1, false)
+				frames, _ = framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f1))
+			})
+
+			It("forgets the priority when a stream is removed", func() {
+				framer.SetStreamPriority(id2, StreamPriority{Urgency: 7, Incremental: true})
+				framer.RemoveStream(id2)
+				framer.AddActiveStream(id2)
+				framer.AddActiveStream(id1)
+				f2 := expectFrame(stream2, id2, false)
+				f1 := expectFrame(stream1, id1, false)
+				frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(HaveLen(2))
+				Expect(frames[0].Frame).To(Equal(f2))
+				Expect(frames[1].Frame).To(Equal(f1))
+			})
+
+			It("removes a removed stream from its queue", func() {
+				framer.SetStreamPriority(id2, StreamPriority{Urgency: 7, Incremental: true})
+				framer.AddActiveStream(id1)
+				framer.AddActiveStream(id2)
+				framer.RemoveStream(id1)
+				framer.RemoveStream(id2)
+				for _, q := range framer.(*framerI).streamQueues {
+					Expect(q.Len()).To(BeZero())
+				}
+				Expect(framer.HasData()).To(BeFalse())
+				// the removed streams are not dequeued
+				frames, _ := framer.AppendStreamFrames(nil, 1000, protocol.Version1)
+				Expect(frames).To(BeEmpty())
+			})
+		})
+
 		It("drops all STREAM frames when 0-RTT is rejected", func() {
 			framer.AddActiveStream(id1)
 			Expect(framer.Handle0RTTRejection()).To(Succeed())
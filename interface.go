@@ -6,8 +6,10 @@ import (
 	"errors"
 	"io"
 	"net"
+	"net/netip"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/logging"
@@ -47,6 +49,69 @@ type TokenStore interface {
 	Put(key string, token *ClientToken)
 }
 
+// A CongestionState is the congestion state of a path, saved at the end of a connection.
+// See Config.EnableCarefulResume.
+type CongestionState struct {
+	// RTT is the minimum RTT of the path.
+	RTT time.Duration
+	// CongestionWindow is the congestion window at the end of the connection.
+	CongestionWindow congestion.ByteCount
+	// SavedAt is the time when the state was saved.
+	SavedAt time.Time
+}
+
+// A CongestionStateStore stores the congestion state of the last connection to a server,
+// or, on the server side, of the connection that issued an address validation token.
+// It is an optional interface that can be implemented by a TokenStore, see Config.EnableCarefulResume.
+// The TokenStore returned by NewLRUTokenStore implements it.
+type CongestionStateStore interface {
+	// PopCongestionState returns the congestion state saved for the given key, and removes it from the store.
+	// It returns nil when no state is found.
+	PopCongestionState(key string) *CongestionState
+
+	// PutCongestionState saves the congestion state for the given key, replacing any state saved before.
+	PutCongestionState(key string, state *CongestionState)
+}
+
+// DatagramPriority is the priority of a datagram relative to stream data.
+type DatagramPriority uint8
+
+const (
+	// DatagramPriorityHigh means that datagrams are sent before stream data (including retransmissions).
+	// This is the priority used by SendDatagram.
+	DatagramPriorityHigh DatagramPriority = iota
+	// DatagramPriorityLow means that datagrams are only sent if there's space left in a packet after
+	// all pending stream data was added.
+	DatagramPriorityLow
+)
+
+// DatagramOptions are the options for sending a datagram using Connection.SendDatagramWithOptions.
+type DatagramOptions struct {
+	// Priority is the priority of the datagram relative to stream data.
+	// Datagrams of the same priority are sent in the order they were queued.
+	Priority DatagramPriority
+	// Deadline is the time after which the datagram is dropped instead of being sent, if it is still queued.
+	// If zero, the datagram doesn't expire.
+	Deadline time.Time
+	// OnAcked is called when the packet carrying the datagram is acknowledged.
+	// At most one of OnAcked and OnLost is called. Neither is called if the connection is closed
+	// before the fate of the datagram is known.
+	// Both callbacks are called from the connection's run loop, and must not block.
+	OnAcked func()
+	// OnLost is called when the packet carrying the datagram is declared lost,
+	// or when the datagram is dropped before it was sent (e.g. because its deadline passed).
+	// Datagrams are never retransmitted.
+	OnLost func()
+}
+
+// A ReceivedDatagram is a datagram received from the peer.
+type ReceivedDatagram struct {
+	// Data is the payload of the datagram.
+	Data []byte
+	// ReceivedAt is the time when the packet carrying the datagram was received.
+	ReceivedAt time.Time
+}
+
 // Err0RTTRejected is the returned from:
 // * Open{Uni}Stream{Sync}
 // * Accept{Uni}Stream
@@ -94,6 +159,15 @@ type ReceiveStream interface {
 	// If the connection was closed due to a timeout, the error satisfies
 	// the net.Error interface, and Timeout() will be true.
 	io.Reader
+	// ReadChunk reads the next chunk of data from the stream, without copying it.
+	// The returned slice references the receive buffer of the stream, and is valid until release is called.
+	// Calling release allows the buffer to be reused. It is safe to never call release,
+	// in which case the buffer is garbage collected once it's not referenced any more.
+	// If the data is passed to SendStream.WriteOwned, release must not be called until
+	// every STREAM frame referencing the slice has been acknowledged.
+	// Like Read, ReadChunk can return data together with an error (e.g. io.EOF).
+	// It must not be called concurrently with Read.
+	ReadChunk() (data []byte, release func(), err error)
 	// CancelRead aborts receiving on this stream.
 	// It will ask the peer to stop transmitting stream data.
 	// Read will unblock immediately, and future Read calls will fail.
@@ -118,6 +192,12 @@ type SendStream interface {
 	// If the connection was closed due to a timeout, the error satisfies
 	// the net.Error interface, and Timeout() will be true.
 	io.Writer
+	// WriteOwned writes data to the stream, without copying it.
+	// The stream takes ownership of p: STREAM frames (including retransmissions) reference p directly,
+	// so p must not be modified after calling WriteOwned.
+	// It blocks until all data has been handed to STREAM frames, or an error occurs.
+	// It must not be called concurrently with Write.
+	WriteOwned(p []byte) (int, error)
 	// Close closes the write-direction of the stream.
 	// Future calls to Write are not permitted after calling Close.
 	// It must not be called concurrently with Write.
@@ -130,6 +210,15 @@ type SendStream interface {
 	// When called after Close, it aborts delivery. Note that there is no guarantee if
 	// the peer will receive the FIN or the reset first.
 	CancelWrite(StreamErrorCode)
+	// CancelWriteAt aborts sending on this stream, like CancelWrite,
+	// but guarantees reliable delivery of the first reliableSize bytes of the stream.
+	// reliableSize must not be larger than the number of bytes written to the stream.
+	// If sending the first reliableSize bytes would exceed the flow control limit granted by the peer,
+	// the reliable size is reduced to that limit.
+	// This requires support for the reliable stream reset extension to be negotiated
+	// (see Config.EnableStreamResetPartialDelivery).
+	// When called after the stream was already canceled, it is a no-op.
+	CancelWriteAt(errorCode StreamErrorCode, reliableSize int64) error
 	// The Context is canceled as soon as the write-side of the stream is closed.
 	// This happens when Close() or CancelWrite() is called, or when the peer
 	// cancels the read-side of their stream.
@@ -142,6 +231,46 @@ type SendStream interface {
 	// some data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// SetPriority sets the priority of the stream.
+	// The priority determines the order in which data from different streams is sent.
+	// It can be changed at any time, and applies to all data that hasn't been sent yet.
+	SetPriority(StreamPriority)
+	// Priority returns the priority of the stream.
+	Priority() StreamPriority
+	// Stats returns statistics about the data sent on this stream.
+	Stats() SendStreamStats
+	// NotifyAcked returns a channel that is closed once all data up to offset
+	// (i.e. the first offset bytes of the stream) has been acknowledged by the peer.
+	// If the stream is canceled or the connection is closed before that,
+	// the channel is never closed. Use Context to detect this case.
+	NotifyAcked(offset int64) <-chan struct{}
+}
+
+// SendStreamStats contains statistics about the send direction of a stream.
+type SendStreamStats struct {
+	// BytesWritten is the number of bytes accepted from Write calls.
+	BytesWritten uint64
+	// BytesSent is the number of bytes sent on the wire at least once.
+	BytesSent uint64
+	// BytesAcked is the number of bytes at the beginning of the stream that were acknowledged by the peer.
+	// Data acknowledged out of order only counts once all preceding data has been acknowledged.
+	BytesAcked uint64
+	// BytesRetransmitted is the number of bytes that were retransmitted after the packet carrying them was declared lost.
+	BytesRetransmitted uint64
+}
+
+// StreamPriority is the priority of a stream, following the model of RFC 9218.
+// Streams with a lower urgency value are always served first.
+// By default, streams have an urgency of 3 and are incremental, which means that the data of all streams
+// is sent in a round-robin fashion.
+type StreamPriority struct {
+	// Urgency ranges from 0 (most urgent) to 7 (least urgent).
+	// Values larger than 7 are treated as 7.
+	Urgency uint8
+	// Incremental says if data from this stream can be interleaved with data from other streams with the same urgency.
+	// Non-incremental streams are sent one after the other, in the order of their stream IDs,
+	// before any incremental stream with the same urgency.
+	Incremental bool
 }
 
 // A Connection is a QUIC connection between two peers.
@@ -187,6 +316,25 @@ type Connection interface {
 	// The peer can only accept the stream after data has been sent on the stream,
 	// or the stream has been reset or closed.
 	OpenUniStreamSync(context.Context) (SendStream, error)
+	// SetMaxIncomingStreams changes the maximum number of concurrent bidirectional streams that the peer is allowed to open,
+	// overriding Config.MaxIncomingStreams. A negative value doesn't allow any new streams.
+	// When the limit is raised, the peer is immediately granted additional streams.
+	// QUIC doesn't allow revoking stream credit that was already granted to the peer, so when the limit is lowered,
+	// the peer may still open streams up to the old limit, and is not granted any new streams
+	// until the number of concurrent streams has dropped below the new limit.
+	SetMaxIncomingStreams(int64)
+	// SetMaxIncomingUniStreams changes the maximum number of concurrent unidirectional streams that the peer is allowed to open,
+	// overriding Config.MaxIncomingUniStreams. It behaves like SetMaxIncomingStreams.
+	SetMaxIncomingUniStreams(int64)
+	// SetStreamReceiveWindow changes the size of the flow control window for receiving data on a stream.
+	// This also disables auto-tuning of the window beyond this size (see Config.MaxStreamReceiveWindow).
+	// When the window is enlarged, the peer is immediately granted additional flow control credit.
+	// Flow control credit that was already granted can't be revoked, so a smaller window only takes effect
+	// once the peer has used up the credit it was granted before.
+	// The stream window is not charged against the Transport.FlowControlMemoryBudget,
+	// but the peer can't send more data than the connection-level window allows.
+	// It returns an error if the stream doesn't exist (anymore), or if it is a send-only stream.
+	SetStreamReceiveWindow(id StreamID, size uint64) error
 	// LocalAddr returns the local address.
 	LocalAddr() net.Addr
 	// RemoteAddr returns the address of the peer.
@@ -201,6 +349,9 @@ type Connection interface {
 	// ConnectionState returns basic details about the QUIC connection.
 	// Warning: This API should not be considered stable and might change soon.
 	ConnectionState() ConnectionState
+	// Stats returns a snapshot of the connection's statistics.
+	// It is cheap enough to be polled frequently.
+	Stats() ConnectionStats
 
 	// SendDatagram sends a message using a QUIC datagram, as specified in RFC 9221.
 	// There is no delivery guarantee for DATAGRAM frames, they are not retransmitted if lost.
@@ -208,8 +359,24 @@ type Connection interface {
 	// In addition, a datagram may be dropped before being sent out if the available packet size suddenly decreases.
 	// If the payload is too large to be sent at the current time, a DatagramTooLargeError is returned.
 	SendDatagram(payload []byte) error
+	// SendDatagramWithOptions sends a message using a QUIC datagram, like SendDatagram.
+	// The options control the priority of the datagram relative to stream data,
+	// allow dropping datagrams that couldn't be sent in time, and notify the application
+	// when the datagram is acknowledged or lost.
+	// opts may be nil, in which case it behaves like SendDatagram.
+	SendDatagramWithOptions(payload []byte, opts *DatagramOptions) error
 	// ReceiveDatagram gets a message received in a datagram, as specified in RFC 9221.
 	ReceiveDatagram(context.Context) ([]byte, error)
+	// ReceiveDatagrams dequeues up to len(datagrams) received datagrams, and returns the number of datagrams dequeued.
+	// It blocks until at least one datagram is available.
+	// If the context is already done, it doesn't block: it returns the datagrams that are queued,
+	// or the context's error if there are none. This can be used to poll for datagrams.
+	ReceiveDatagrams(ctx context.Context, datagrams []ReceivedDatagram) (int, error)
+
+	// AddPath creates a new path for connection migration, using the Transport.
+	// The path needs to be validated using Path.Probe before the connection can switch to it using Path.Switch.
+	// Only clients can initiate connection migration, and only after completion of the handshake.
+	AddPath(*Transport) (*Path, error)
 }
 
 // An EarlyConnection is a connection that is handshaking.
@@ -286,7 +453,23 @@ type Config struct {
 	// Tokens are used to skip address validation on future connection attempts.
 	// The key used to store tokens is the ServerName from the tls.Config, if set
 	// otherwise the token is associated with the server's IP address.
+	// On the server, it is only used to store the congestion state, see EnableCarefulResume.
 	TokenStore TokenStore
+	// EnableCarefulResume enables careful resume of the congestion state (draft-ietf-tsvwg-careful-resume).
+	// When the connection is closed, the client saves the RTT and the congestion window of the path in the TokenStore.
+	// The state is saved alongside the tokens, and doesn't take up any of the token slots.
+	// When dialing the same server again, it uses the saved state to ramp up faster than slow start,
+	// after validating that the RTT of the path didn't change significantly.
+	// If packets sent using the saved congestion window are lost, the sender quickly retreats to a safe window.
+	// Saved state is used for at most one hour.
+	// The server saves the state in its TokenStore, keyed by the address validation token it sent in the NEW_TOKEN frame.
+	// It uses the state when the client presents that token on its next connection.
+	// The state is only saved if the TokenStore implements CongestionStateStore, as the one returned by NewLRUTokenStore does.
+	// If it doesn't, careful resume is disabled, and every connection starts with slow start.
+	// Careful resume is only used if the congestion controller supports it (see congestion.CarefulResumeSendAlgorithm).
+	// Of the congestion controllers in the congestion package, only NewReno and CUBIC do.
+	// It has no effect when using BBR, Prague or the congestion controller for interactive media.
+	EnableCarefulResume bool
 	// InitialStreamReceiveWindow is the initial size of the stream-level flow control window for receiving data.
 	// If the application is consuming data quickly enough, the flow control auto-tuning algorithm
 	// will increase the window up to MaxStreamReceiveWindow.
@@ -338,12 +521,52 @@ type Config struct {
 	// This allows the sending of QUIC packets that fully utilize the available MTU of the path.
 	// Path MTU discovery is only available on systems that allow setting of the Don't Fragment (DF) bit.
 	DisablePathMTUDiscovery bool
+	// DisableActiveMigration disables connection migration by the client (see section 9 of RFC 9000).
+	// If set, the server sends the disable_active_migration transport parameter, and doesn't migrate
+	// the connection when it receives packets from a new client address.
+	// Migration to the server's preferred address (see PreferredAddress) is still possible.
+	// Only valid for the server.
+	DisableActiveMigration bool
 	// Allow0RTT allows the application to decide if a 0-RTT connection attempt should be accepted.
 	// Only valid for the server.
 	Allow0RTT bool
 	// Enable QUIC datagram support (RFC 9221).
 	EnableDatagrams bool
-	Tracer          func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
+	// DatagramReceiveQueueLen is the maximum number of received datagrams that are queued
+	// until they are read by the application (using ReceiveDatagram or ReceiveDatagrams).
+	// When the queue is full, newly received datagrams are dropped, see ConnectionState.DatagramsDropped.
+	// If not set, it will default to 128.
+	DatagramReceiveQueueLen int
+	// EnableStreamResetPartialDelivery enables support for the reliable stream reset extension
+	// (draft-ietf-quic-reliable-stream-reset).
+	// It allows SendStream.CancelWriteAt to reset a stream while still guaranteeing delivery of
+	// the beginning of the stream. This requires both nodes to enable the extension.
+	EnableStreamResetPartialDelivery bool
+	// EnableAckFrequency enables support for the ACK frequency extension (draft-ietf-quic-ack-frequency).
+	// It allows the peer to request how often ACKs are sent, and allows the local endpoint to reduce the
+	// number of ACKs sent by the peer if the peer supports the extension as well.
+	EnableAckFrequency bool
+	// PreferredAddress is the preferred address advertised to the client (see section 9.6 of RFC 9000).
+	// After completion of the handshake, the client validates this address and migrates the connection to it.
+	// Only valid for the server.
+	PreferredAddress *PreferredAddress
+	// CongestionControl creates the congestion controller used for a connection.
+	// It is called when the connection is created, and again every time the connection migrates to a new path.
+	// The congestion package provides implementations of NewReno, CUBIC (optionally using HyStart++),
+	// BBR, Prague (for L4S), and a delay-based congestion controller for interactive media.
+	// If nil, NewReno is used.
+	CongestionControl func(*congestion.ConnectionInfo) congestion.SendAlgorithm
+	Tracer            func(context.Context, logging.Perspective, ConnectionID) *logging.ConnectionTracer
+}
+
+// PreferredAddress is the address that a server advertises in the preferred_address transport parameter.
+type PreferredAddress struct {
+	// IPv4 and IPv6 are the addresses advertised to the client.
+	// At least one of them needs to be set.
+	IPv4, IPv6 netip.AddrPort
+	// Transport is the Transport that receives packets sent to the preferred address.
+	// It is not necessary (and not possible) to call Listen on this Transport.
+	Transport *Transport
 }
 
 // ClientHelloInfo contains information about an incoming connection attempt.
@@ -357,6 +580,39 @@ type ClientHelloInfo struct {
 	AddrVerified bool
 }
 
+// ConnectionStats contains statistics about a QUIC connection.
+type ConnectionStats struct {
+	// MinRTT is the minimum RTT observed on the current path.
+	MinRTT time.Duration
+	// LatestRTT is the most recent RTT sample.
+	LatestRTT time.Duration
+	// SmoothedRTT is the exponentially weighted moving average of the RTT (see section 5.3 of RFC 9002).
+	SmoothedRTT time.Duration
+	// MeanDeviation is the mean deviation of the RTT samples.
+	MeanDeviation time.Duration
+
+	// CongestionWindow is the current congestion window, in bytes.
+	CongestionWindow uint64
+	// BytesInFlight is the number of bytes sent in ack-eliciting packets that haven't been acknowledged
+	// or declared lost yet.
+	BytesInFlight uint64
+
+	// PacketsSent and BytesSent count all QUIC packets sent.
+	PacketsSent uint64
+	BytesSent   uint64
+	// PacketsReceived counts all QUIC packets that were successfully processed.
+	PacketsReceived uint64
+	// BytesReceived counts the size of all UDP datagrams received, including packets that couldn't be processed.
+	BytesReceived uint64
+	// PacketsLost and BytesLost count the packets declared lost.
+	PacketsLost uint64
+	BytesLost   uint64
+	// PacketsRetransmitted is the number of packets whose frames were queued for retransmission,
+	// either because the packet was declared lost, or to send a probe packet.
+	// Note that QUIC never retransmits packets, only the frames they contained.
+	PacketsRetransmitted uint64
+}
+
 // ConnectionState records basic details about a QUIC connection
 type ConnectionState struct {
 	// TLS contains information about the TLS connection state, incl. the tls.ConnectionState.
@@ -366,6 +622,12 @@ type ConnectionState struct {
 	// If datagram support was negotiated, datagrams can be sent and received using the
 	// SendDatagram and ReceiveDatagram methods on the Connection.
 	SupportsDatagrams bool
+	// DatagramsDropped is the number of received datagrams that were dropped
+	// because the datagram receive queue was full (see Config.DatagramReceiveQueueLen).
+	DatagramsDropped uint64
+	// SupportsStreamResetPartialDelivery says if support for the reliable stream reset extension was negotiated.
+	// This requires both nodes to enable it (via Config.EnableStreamResetPartialDelivery).
+	SupportsStreamResetPartialDelivery bool
 	// Used0RTT says if 0-RTT resumption was used.
 	Used0RTT bool
 	// Version is the QUIC version of the QUIC connection.
@@ -0,0 +1,78 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+const (
+	// The ACK frequency is chosen such that the peer acknowledges about this many times per congestion window.
+	acksPerCongestionWindow = 4
+	// The maximum ack-eliciting threshold requested from the peer.
+	maxAckElicitingThreshold = 63
+)
+
+// The ackFrequencyController decides which ACK frequency to request from the peer (draft-ietf-quic-ack-frequency).
+// At high bandwidths, acknowledging every other packet is wasteful: it costs CPU on both sides,
+// without providing much additional information to the congestion controller.
+type ackFrequencyController struct {
+	peerMinAckDelay time.Duration
+	rttStats        *utils.RTTStats
+
+	nextSeqNum            uint64
+	ackElicitingThreshold uint64
+	maxAckDelay           time.Duration
+	lastUpdate            time.Time
+}
+
+func newAckFrequencyController(peerMinAckDelay time.Duration, rttStats *utils.RTTStats) *ackFrequencyController {
+	return &ackFrequencyController{
+		peerMinAckDelay:       peerMinAckDelay,
+		rttStats:              rttStats,
+		ackElicitingThreshold: defaultAckElicitingThreshold,
+		maxAckDelay:           rttStats.MaxAckDelay(),
+	}
+}
+
+// GetFrame returns an ACK_FREQUENCY frame, if the ACK frequency should be updated.
+func (c *ackFrequencyController) GetFrame(now time.Time, cwnd, maxDatagramSize protocol.ByteCount) *wire.AckFrequencyFrame {
+	// Don't update the ACK frequency more than once per RTT.
+	if !c.lastUpdate.IsZero() && now.Sub(c.lastUpdate) < c.rttStats.SmoothedRTT() {
+		return nil
+	}
+	packetsPerAck := uint64(cwnd / maxDatagramSize / acksPerCongestionWindow)
+	// Only use thresholds of the form 2^n-1.
+	// This way, a new ACK_FREQUENCY frame is only sent when the congestion window changes significantly.
+	threshold := uint64(defaultAckElicitingThreshold)
+	for 2*(threshold+1) <= packetsPerAck && threshold < maxAckElicitingThreshold {
+		threshold = 2*threshold + 1
+	}
+	// Make sure that the peer still acknowledges multiple times per RTT when it's not sending a lot of data.
+	// The requested delay is never larger than the peer's max_ack_delay, since that value is used for the PTO calculation.
+	maxAckDelay := c.rttStats.MaxAckDelay()
+	if threshold > defaultAckElicitingThreshold {
+		maxAckDelay = min(maxAckDelay, max(c.peerMinAckDelay, (c.rttStats.SmoothedRTT()/4).Truncate(time.Millisecond)))
+	}
+	if threshold == c.ackElicitingThreshold && maxAckDelay == c.maxAckDelay {
+		return nil
+	}
+	c.ackElicitingThreshold = threshold
+	c.maxAckDelay = maxAckDelay
+	c.lastUpdate = now
+	f := &wire.AckFrequencyFrame{
+		SequenceNumber:        c.nextSeqNum,
+		AckElicitingThreshold: threshold,
+		RequestMaxAckDelay:    maxAckDelay,
+		ReorderingThreshold:   defaultReorderingThreshold,
+	}
+	// If ACKs are delayed, reordering shouldn't trigger an immediate ACK.
+	// An ACK is only needed once the packet would be declared lost by packet threshold loss detection.
+	if threshold > defaultAckElicitingThreshold {
+		f.ReorderingThreshold = packetThreshold
+	}
+	c.nextSeqNum++
+	return f
+}
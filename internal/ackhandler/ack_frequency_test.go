@@ -0,0 +1,91 @@
+package ackhandler
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK Frequency Controller", func() {
+	const maxDatagramSize = 1000
+
+	var (
+		rttStats   *utils.RTTStats
+		controller *ackFrequencyController
+	)
+
+	BeforeEach(func() {
+		rttStats = &utils.RTTStats{}
+		rttStats.SetMaxAckDelay(25 * time.Millisecond)
+		rttStats.UpdateRTT(40*time.Millisecond, 0, time.Now())
+		Expect(rttStats.SmoothedRTT()).To(Equal(40 * time.Millisecond))
+		controller = newAckFrequencyController(2*time.Millisecond, rttStats)
+	})
+
+	It("doesn't request a different ACK frequency for small congestion windows", func() {
+		Expect(controller.GetFrame(time.Now(), 10*maxDatagramSize, maxDatagramSize)).To(BeNil())
+	})
+
+	It("requests fewer ACKs for large congestion windows", func() {
+		now := time.Now()
+		f := controller.GetFrame(now, 100*maxDatagramSize, maxDatagramSize)
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeZero())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(15)) // 100 / 4 = 25 packets per ACK, rounded down to 2^n-1
+		Expect(f.RequestMaxAckDelay).To(Equal(10 * time.Millisecond))
+		Expect(f.ReorderingThreshold).To(BeEquivalentTo(packetThreshold))
+		// no update if the congestion window didn't change significantly
+		now = now.Add(time.Second)
+		Expect(controller.GetFrame(now, 120*maxDatagramSize, maxDatagramSize)).To(BeNil())
+		f = controller.GetFrame(now, 200*maxDatagramSize, maxDatagramSize)
+		Expect(f).ToNot(BeNil())
+		Expect(f.SequenceNumber).To(BeEquivalentTo(1))
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(31))
+	})
+
+	It("limits the ack-eliciting threshold", func() {
+		f := controller.GetFrame(time.Now(), 10000*maxDatagramSize, maxDatagramSize)
+		Expect(f).ToNot(BeNil())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(maxAckElicitingThreshold))
+	})
+
+	It("doesn't update the ACK frequency more than once per RTT", func() {
+		now := time.Now()
+		Expect(controller.GetFrame(now, 100*maxDatagramSize, maxDatagramSize)).ToNot(BeNil())
+		Expect(controller.GetFrame(now.Add(39*time.Millisecond), 10*maxDatagramSize, maxDatagramSize)).To(BeNil())
+		f := controller.GetFrame(now.Add(40*time.Millisecond), 10*maxDatagramSize, maxDatagramSize)
+		Expect(f).ToNot(BeNil())
+		Expect(f.AckElicitingThreshold).To(BeEquivalentTo(defaultAckElicitingThreshold))
+		Expect(f.RequestMaxAckDelay).To(Equal(25 * time.Millisecond))
+		Expect(f.ReorderingThreshold).To(BeEquivalentTo(defaultReorderingThreshold))
+	})
+
+	It("doesn't request a max_ack_delay smaller than the peer's min_ack_delay", func() {
+		rttStats = &utils.RTTStats{}
+		rttStats.SetMaxAckDelay(25 * time.Millisecond)
+		rttStats.UpdateRTT(4*time.Millisecond, 0, time.Now())
+		controller = newAckFrequencyController(2*time.Millisecond, rttStats)
+		f := controller.GetFrame(time.Now(), 100*maxDatagramSize, maxDatagramSize)
+		Expect(f).ToNot(BeNil())
+		Expect(f.RequestMaxAckDelay).To(Equal(2 * time.Millisecond))
+	})
+})
+
+var _ = Describe("Sent Packet Handler, ACK frequency", func() {
+	It("only sends ACK_FREQUENCY frames if the peer supports the extension, and the handshake is confirmed", func() {
+		rttStats := &utils.RTTStats{}
+		rttStats.UpdateRTT(40*time.Millisecond, 0, time.Now())
+		sph := newSentPacketHandler(0, 1200, rttStats, false, false, protocol.PerspectiveClient, nil, nil, utils.DefaultLogger)
+		Expect(sph.GetAckFrequencyFrame(time.Now())).To(BeNil())
+		sph.EnableAckFrequency(time.Millisecond)
+		Expect(sph.GetAckFrequencyFrame(time.Now())).To(BeNil())
+		sph.DropPackets(protocol.EncryptionInitial)
+		sph.DropPackets(protocol.EncryptionHandshake)
+		sph.SetHandshakeConfirmed()
+		Expect(sph.GetAckFrequencyFrame(time.Now())).ToNot(BeNil())
+	})
+})
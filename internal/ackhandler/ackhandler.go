@@ -1,14 +1,31 @@
 package ackhandler
 
 import (
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
 )
 
+// A CongestionControllerFactory creates the congestion controller for a path.
+// It is called when the connection is created, and every time the connection migrates to a new path.
+type CongestionControllerFactory func(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithm
+
+func newRenoFactory(pers protocol.Perspective, rttStats *utils.RTTStats, tracer *logging.ConnectionTracer) CongestionControllerFactory {
+	return func(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithm {
+		return congestion.NewReno(&congestion.ConnectionInfo{
+			Perspective:            pers,
+			RTTStats:               rttStats,
+			InitialMaxDatagramSize: initialMaxDatagramSize,
+			Tracer:                 tracer,
+		})
+	}
+}
+
 // NewAckHandler creates a new SentPacketHandler and a new ReceivedPacketHandler.
 // clientAddressValidated indicates whether the address was validated beforehand by an address validation token.
 // clientAddressValidated has no effect for a client.
+// If newCongestionController is nil, NewReno is used.
 func NewAckHandler(
 	initialPacketNumber protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
@@ -16,9 +33,10 @@ func NewAckHandler(
 	clientAddressValidated bool,
 	enableECN bool,
 	pers protocol.Perspective,
+	newCongestionController CongestionControllerFactory,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
 ) (SentPacketHandler, ReceivedPacketHandler) {
-	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, enableECN, pers, tracer, logger)
+	sph := newSentPacketHandler(initialPacketNumber, initialMaxDatagramSize, rttStats, clientAddressValidated, enableECN, pers, newCongestionController, tracer, logger)
 	return sph, newReceivedPacketHandler(sph, logger)
 }
@@ -24,17 +24,23 @@ const numECNTestingPackets = 10
 type ecnHandler interface {
 	SentPacket(protocol.PacketNumber, protocol.ECN)
 	Mode() protocol.ECN
-	HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64) (congested bool)
+	// HandleNewlyAcked returns the number of newly CE-marked packets.
+	HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64) (newECNCE int64)
 	LostPacket(protocol.PacketNumber)
+	// PathChanged restarts ECN validation on the new path.
+	PathChanged()
 }
 
 // The ecnTracker performs ECN validation of a path.
-// Once failed, it doesn't do any re-validation of the path.
+// Once failed, it doesn't do any re-validation of the path, unless the connection migrates to a new path.
 // It is designed only work for 1-RTT packets, it doesn't handle multiple packet number spaces.
 // In order to avoid revealing any internal state to on-path observers,
 // callers should make sure to start using ECN (i.e. calling Mode) for the very first 1-RTT packet sent.
 // The validation logic implemented here strictly follows the algorithm described in RFC 9000 section 13.4.2 and A.4.
 type ecnTracker struct {
+	// the ECN codepoint used: ECT(0), or ECT(1) for L4S (RFC 9330)
+	codepoint protocol.ECN
+
 	state                          ecnState
 	numSentTesting, numLostTesting uint8
 
@@ -44,6 +50,8 @@ type ecnTracker struct {
 
 	numSentECT0, numSentECT1                  int64
 	numAckedECT0, numAckedECT1, numAckedECNCE int64
+	// the number of ECN-CE marks acknowledged before the last path change
+	numAckedECNCEPrevPaths int64
 
 	tracer *logging.ConnectionTracer
 	logger utils.Logger
@@ -51,8 +59,15 @@ type ecnTracker struct {
 
 var _ ecnHandler = &ecnTracker{}
 
-func newECNTracker(logger utils.Logger, tracer *logging.ConnectionTracer) *ecnTracker {
+// If useECT1 is set, packets are sent with the ECT(1) codepoint, as required for L4S.
+// Otherwise, ECT(0) is used.
+func newECNTracker(useECT1 bool, logger utils.Logger, tracer *logging.ConnectionTracer) *ecnTracker {
+	codepoint := protocol.ECT0
+	if useECT1 {
+		codepoint = protocol.ECT1
+	}
 	return &ecnTracker{
+		codepoint:          codepoint,
 		firstTestingPacket: protocol.InvalidPacketNumber,
 		lastTestingPacket:  protocol.InvalidPacketNumber,
 		firstCapablePacket: protocol.InvalidPacketNumber,
@@ -91,7 +106,7 @@ func (e *ecnTracker) SentPacket(pn protocol.PacketNumber, ecn protocol.ECN) {
 	if e.firstTestingPacket == protocol.InvalidPacketNumber {
 		e.firstTestingPacket = pn
 	}
-	if e.numSentECT0+e.numSentECT1 >= numECNTestingPackets {
+	if e.numSentTesting >= numECNTestingPackets {
 		if e.tracer != nil && e.tracer.ECNStateUpdated != nil {
 			e.tracer.ECNStateUpdated(logging.ECNStateUnknown, logging.ECNTriggerNoTrigger)
 		}
@@ -109,7 +124,7 @@ func (e *ecnTracker) Mode() protocol.ECN {
 		e.state = ecnStateTesting
 		return e.Mode()
 	case ecnStateTesting, ecnStateCapable:
-		return protocol.ECT0
+		return e.codepoint
 	case ecnStateUnknown, ecnStateFailed:
 		return protocol.ECNNon
 	default:
@@ -142,11 +157,12 @@ func (e *ecnTracker) LostPacket(pn protocol.PacketNumber) {
 }
 
 // HandleNewlyAcked handles the ECN counts on an ACK frame.
+// It returns the increase of the ECN-CE count, once ECN capability of the path has been confirmed.
 // It must only be called for ACK frames that increase the largest acknowledged packet number,
 // see section 13.4.2.1 of RFC 9000.
-func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64) (congested bool) {
+func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64) (newECNCE int64) {
 	if e.state == ecnStateFailed {
-		return false
+		return 0
 	}
 
 	// ECN validation can fail if the received total count for either ECT(0) or ECT(1) exceeds
@@ -157,7 +173,7 @@ func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64
 			e.tracer.ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedMoreECNCountsThanSent)
 		}
 		e.state = ecnStateFailed
-		return false
+		return 0
 	}
 
 	// Count ECT0 and ECT1 marks that we used when sending the packets that are now being acknowledged.
@@ -183,13 +199,13 @@ func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64
 			e.tracer.ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedNoECNCounts)
 		}
 		e.state = ecnStateFailed
-		return false
+		return 0
 	}
 
 	// Determine the increase in ECT0, ECT1 and ECNCE marks
 	newECT0 := ect0 - e.numAckedECT0
 	newECT1 := ect1 - e.numAckedECT1
-	newECNCE := ecnce - e.numAckedECNCE
+	newECNCE = ecnce - e.numAckedECNCE
 
 	// We're only processing ACKs that increase the Largest Acked.
 	// Therefore, the ECN counters should only ever increase.
@@ -200,7 +216,7 @@ func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64
 			e.tracer.ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedDecreasedECNCounts)
 		}
 		e.state = ecnStateFailed
-		return false
+		return 0
 	}
 
 	// ECN validation also fails if the sum of the increase in ECT(0) and ECN-CE counts is less than the number
@@ -212,7 +228,7 @@ func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64
 			e.tracer.ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedTooFewECNCounts)
 		}
 		e.state = ecnStateFailed
-		return false
+		return 0
 	}
 	// Similarly, ECN validation fails if the sum of the increases to ECT(1) and ECN-CE counts is less than
 	// the number of newly acknowledged packets sent with an ECT(1) marking.
@@ -222,7 +238,7 @@ func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64
 			e.tracer.ECNStateUpdated(logging.ECNStateFailed, logging.ECNFailedTooFewECNCounts)
 		}
 		e.state = ecnStateFailed
-		return false
+		return 0
 	}
 
 	// update our counters
@@ -235,7 +251,7 @@ func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64
 	if e.state == ecnStateUnknown {
 		e.failIfMangled()
 		if e.state == ecnStateFailed {
-			return false
+			return 0
 		}
 	}
 	if e.state == ecnStateTesting || e.state == ecnStateUnknown {
@@ -258,13 +274,16 @@ func (e *ecnTracker) HandleNewlyAcked(packets []*packet, ect0, ect1, ecnce int64
 
 	// Don't trust CE marks before having confirmed ECN capability of the path.
 	// Otherwise, mangling would be misinterpreted as actual congestion.
-	return e.state == ecnStateCapable && newECNCE > 0
+	if e.state != ecnStateCapable {
+		return 0
+	}
+	return newECNCE
 }
 
 // failIfMangled fails ECN validation if all testing packets are lost or CE-marked.
 func (e *ecnTracker) failIfMangled() {
-	numAckedECNCE := e.numAckedECNCE + int64(e.numLostTesting)
-	if e.numSentECT0+e.numSentECT1 > numAckedECNCE {
+	numAckedECNCE := e.numAckedECNCE - e.numAckedECNCEPrevPaths + int64(e.numLostTesting)
+	if int64(e.numSentTesting) > numAckedECNCE {
 		return
 	}
 	if e.tracer != nil && e.tracer.ECNStateUpdated != nil {
@@ -273,19 +292,32 @@ func (e *ecnTracker) failIfMangled() {
 	e.state = ecnStateFailed
 }
 
+// PathChanged resets the validation state, since ECN capability is a property of the path.
+// The ECN counts reported in ACK frames are cumulative for the packet number space,
+// so the counters of sent and acknowledged packets are kept.
+func (e *ecnTracker) PathChanged() {
+	e.state = ecnStateInitial
+	e.numSentTesting = 0
+	e.numLostTesting = 0
+	e.firstTestingPacket = protocol.InvalidPacketNumber
+	e.lastTestingPacket = protocol.InvalidPacketNumber
+	e.firstCapablePacket = protocol.InvalidPacketNumber
+	e.numAckedECNCEPrevPaths = e.numAckedECNCE
+}
+
 func (e *ecnTracker) ecnMarking(pn protocol.PacketNumber) protocol.ECN {
 	if pn < e.firstTestingPacket || e.firstTestingPacket == protocol.InvalidPacketNumber {
 		return protocol.ECNNon
 	}
 	if pn < e.lastTestingPacket || e.lastTestingPacket == protocol.InvalidPacketNumber {
-		return protocol.ECT0
+		return e.codepoint
 	}
 	if pn < e.firstCapablePacket || e.firstCapablePacket == protocol.InvalidPacketNumber {
 		return protocol.ECNNon
 	}
 	// We don't need to deal with the case when ECN validation fails,
 	// since we're ignoring any ECN counts reported in ACK frames in that case.
-	return protocol.ECT0
+	return e.codepoint
 }
 
 func (e *ecnTracker) isTestingPacket(pn protocol.PacketNumber) bool {
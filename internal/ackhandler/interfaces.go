@@ -10,7 +10,7 @@ import (
 // SentPacketHandler handles ACKs received for outgoing packets
 type SentPacketHandler interface {
 	// SentPacket may modify the packet
-	SentPacket(t time.Time, pn, largestAcked protocol.PacketNumber, streamFrames []StreamFrame, frames []Frame, encLevel protocol.EncryptionLevel, ecn protocol.ECN, size protocol.ByteCount, isPathMTUProbePacket bool)
+	SentPacket(t time.Time, pn, largestAcked protocol.PacketNumber, streamFrames []StreamFrame, frames []Frame, encLevel protocol.EncryptionLevel, ecn protocol.ECN, size protocol.ByteCount, isPathMTUProbePacket, isPathProbePacket bool)
 	// ReceivedAck processes an ACK frame.
 	// It does not store a copy of the frame.
 	ReceivedAck(f *wire.AckFrame, encLevel protocol.EncryptionLevel, rcvTime time.Time) (bool /* 1-RTT packet acked */, error)
@@ -18,6 +18,21 @@ type SentPacketHandler interface {
 	DropPackets(protocol.EncryptionLevel)
 	ResetForRetry(rcvTime time.Time) error
 	SetHandshakeConfirmed()
+	// EnableAckFrequency is called when the peer supports the ACK frequency extension (draft-ietf-quic-ack-frequency).
+	// minAckDelay is the min_ack_delay advertised by the peer.
+	EnableAckFrequency(minAckDelay time.Duration)
+	// GetAckFrequencyFrame returns an ACK_FREQUENCY frame, if the ACK frequency requested from the peer should be updated.
+	// The requested frequency depends on the congestion window and the RTT.
+	GetAckFrequencyFrame(now time.Time) *wire.AckFrequencyFrame
+	// MigratedPath is called when the connection switches to a new path.
+	// It resets the congestion controller, the RTT estimate and ECN validation.
+	MigratedPath(initialMaxDatagramSize protocol.ByteCount)
+	// MigratedToUnvalidatedPath is called when the server switches to a path before it was validated.
+	// Until PathValidated is called, the anti-amplification limit applies,
+	// taking into account the bytes that were already sent and received on this path.
+	MigratedToUnvalidatedPath(bytesSent, bytesReceived protocol.ByteCount)
+	// PathValidated is called when the current path was validated.
+	PathValidated()
 
 	// The SendMode determines if and what kind of packets can be sent.
 	SendMode(now time.Time) SendMode
@@ -25,6 +40,13 @@ type SentPacketHandler interface {
 	// It is used for pacing packets.
 	TimeUntilSend() time.Time
 	SetMaxDatagramSize(count protocol.ByteCount)
+	CongestionWindow() protocol.ByteCount
+	// SetResumeState passes the congestion state of a previous connection to the congestion controller,
+	// if it supports careful resume (draft-ietf-tsvwg-careful-resume).
+	SetResumeState(savedRTT time.Duration, savedCongestionWindow protocol.ByteCount)
+	// Stats returns statistics about the packets sent.
+	// It is safe to call it concurrently with the other methods.
+	Stats() Stats
 
 	// only to be called once the handshake is complete
 	QueueProbePacket(protocol.EncryptionLevel) bool /* was a packet queued */
@@ -37,6 +59,20 @@ type SentPacketHandler interface {
 	OnLossDetectionTimeout() error
 }
 
+// Stats are statistics about the packets sent on a connection.
+type Stats struct {
+	PacketsSent uint64
+	BytesSent   protocol.ByteCount
+	PacketsLost uint64
+	BytesLost   protocol.ByteCount
+	// PacketsRetransmitted is the number of packets whose frames were queued for retransmission,
+	// either because the packet was declared lost, or to send a probe packet.
+	PacketsRetransmitted uint64
+
+	CongestionWindow protocol.ByteCount
+	BytesInFlight    protocol.ByteCount
+}
+
 type sentPacketTracker interface {
 	GetLowestPacketNotConfirmedAcked() protocol.PacketNumber
 	ReceivedPacket(protocol.EncryptionLevel)
@@ -47,6 +83,10 @@ type ReceivedPacketHandler interface {
 	IsPotentiallyDuplicate(protocol.PacketNumber, protocol.EncryptionLevel) bool
 	ReceivedPacket(pn protocol.PacketNumber, ecn protocol.ECN, encLevel protocol.EncryptionLevel, rcvTime time.Time, ackEliciting bool) error
 	DropPackets(protocol.EncryptionLevel)
+	// ReceivedAckFrequencyFrame applies the ACK frequency requested by the peer (draft-ietf-quic-ack-frequency).
+	ReceivedAckFrequencyFrame(*wire.AckFrequencyFrame)
+	// ReceivedImmediateAckFrame makes sure that an ACK is sent as soon as possible.
+	ReceivedImmediateAckFrame()
 
 	GetAlarmTimeout() time.Time
 	GetAckFrame(encLevel protocol.EncryptionLevel, onlyIfQueued bool) *wire.AckFrame
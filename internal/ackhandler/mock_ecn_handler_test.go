@@ -40,10 +40,10 @@ func (m *MockECNHandler) EXPECT() *MockECNHandlerMockRecorder {
 }
 
 // HandleNewlyAcked mocks base method.
-func (m *MockECNHandler) HandleNewlyAcked(arg0 []*packet, arg1, arg2, arg3 int64) bool {
+func (m *MockECNHandler) HandleNewlyAcked(arg0 []*packet, arg1, arg2, arg3 int64) int64 {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "HandleNewlyAcked", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].(bool)
+	ret0, _ := ret[0].(int64)
 	return ret0
 }
 
@@ -79,6 +79,18 @@ func (mr *MockECNHandlerMockRecorder) Mode() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Mode", reflect.TypeOf((*MockECNHandler)(nil).Mode))
 }
 
+// PathChanged mocks base method.
+func (m *MockECNHandler) PathChanged() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PathChanged")
+}
+
+// PathChanged indicates an expected call of PathChanged.
+func (mr *MockECNHandlerMockRecorder) PathChanged() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PathChanged", reflect.TypeOf((*MockECNHandler)(nil).PathChanged))
+}
+
 // SentPacket mocks base method.
 func (m *MockECNHandler) SentPacket(arg0 protocol.PacketNumber, arg1 protocol.ECN) {
 	m.ctrl.T.Helper()
@@ -18,6 +18,8 @@ type packet struct {
 	EncryptionLevel protocol.EncryptionLevel
 
 	IsPathMTUProbePacket bool // We don't report the loss of Path MTU probe packets to the congestion controller.
+	isPathProbePacket    bool // Path probe packets are sent on a different path, and are not accounted for in congestion control.
+	sentOnPreviousPath   bool // Packets sent before a migration are not accounted for in congestion control of the new path.
 
 	includedInBytesInFlight bool
 	declaredLost            bool
@@ -25,7 +27,7 @@ type packet struct {
 }
 
 func (p *packet) outstanding() bool {
-	return !p.declaredLost && !p.skippedPacket && !p.IsPathMTUProbePacket
+	return !p.declaredLost && !p.skippedPacket && !p.IsPathMTUProbePacket && !p.isPathProbePacket
 }
 
 var packetPool = sync.Pool{New: func() any { return &packet{} }}
@@ -40,6 +42,8 @@ func getPacket() *packet {
 	p.EncryptionLevel = protocol.EncryptionLevel(0)
 	p.SendTime = time.Time{}
 	p.IsPathMTUProbePacket = false
+	p.isPathProbePacket = false
+	p.sentOnPreviousPath = false
 	p.includedInBytesInFlight = false
 	p.declaredLost = false
 	p.skippedPacket = false
@@ -83,6 +83,14 @@ func (h *receivedPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	}
 }
 
+func (h *receivedPacketHandler) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	h.appDataPackets.ReceivedAckFrequencyFrame(f)
+}
+
+func (h *receivedPacketHandler) ReceivedImmediateAckFrame() {
+	h.appDataPackets.ReceivedImmediateAckFrame()
+}
+
 func (h *receivedPacketHandler) GetAlarmTimeout() time.Time {
 	return h.appDataPackets.GetAlarmTimeout()
 }
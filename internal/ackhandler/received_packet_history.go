@@ -135,6 +135,19 @@ func (h *receivedPacketHistory) GetHighestAckRange() wire.AckRange {
 	return ackRange
 }
 
+// LargestMissing returns the largest packet number smaller than or equal to p that hasn't been received.
+func (h *receivedPacketHistory) LargestMissing(p protocol.PacketNumber) protocol.PacketNumber {
+	for el := h.ranges.Back(); el != nil; el = el.Prev() {
+		if p > el.Value.End {
+			return p
+		}
+		if p >= el.Value.Start {
+			return el.Value.Start - 1
+		}
+	}
+	return p
+}
+
 func (h *receivedPacketHistory) IsPotentiallyDuplicate(p protocol.PacketNumber) bool {
 	if p < h.deletedBelow {
 		return true
@@ -259,6 +259,25 @@ var _ = Describe("receivedPacketHistory", func() {
 		})
 	})
 
+	Context("finding missing packets", func() {
+		It("returns the packet number itself if it wasn't received", func() {
+			Expect(hist.ReceivedPacket(3)).To(BeTrue())
+			Expect(hist.ReceivedPacket(7)).To(BeTrue())
+			Expect(hist.LargestMissing(5)).To(Equal(protocol.PacketNumber(5)))
+			Expect(hist.LargestMissing(10)).To(Equal(protocol.PacketNumber(10)))
+			Expect(hist.LargestMissing(1)).To(Equal(protocol.PacketNumber(1)))
+		})
+
+		It("returns the packet number below the range that contains the packet number", func() {
+			Expect(hist.ReceivedPacket(3)).To(BeTrue())
+			for i := protocol.PacketNumber(6); i <= 9; i++ {
+				Expect(hist.ReceivedPacket(i)).To(BeTrue())
+			}
+			Expect(hist.LargestMissing(8)).To(Equal(protocol.PacketNumber(5)))
+			Expect(hist.LargestMissing(3)).To(Equal(protocol.PacketNumber(2)))
+		})
+	})
+
 	Context("duplicate detection", func() {
 		It("doesn't declare the first packet a duplicate", func() {
 			Expect(hist.IsPotentiallyDuplicate(5)).To(BeFalse())
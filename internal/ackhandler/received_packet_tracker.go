@@ -70,11 +70,18 @@ func (h *receivedPacketTracker) IsPotentiallyDuplicate(pn protocol.PacketNumber)
 	return h.packetHistory.IsPotentiallyDuplicate(pn)
 }
 
-// number of ack-eliciting packets received before sending an ACK
-const packetsBeforeAck = 2
+const (
+	// number of ack-eliciting packets received without sending an ACK,
+	// unless the peer requested a different value using an ACK_FREQUENCY frame
+	defaultAckElicitingThreshold = 1
+	// number of out-of-order packets that trigger an immediate ACK,
+	// unless the peer requested a different value using an ACK_FREQUENCY frame
+	defaultReorderingThreshold = 1
+)
 
 // The appDataReceivedPacketTracker tracks packets received in the Application Data packet number space.
-// It waits until at least 2 packets were received before queueing an ACK, or until the max_ack_delay was reached.
+// By default, it waits until at least 2 packets were received before queueing an ACK, or until the max_ack_delay was reached.
+// The peer can change this behavior using the ACK frequency extension.
 type appDataReceivedPacketTracker struct {
 	receivedPacketTracker
 
@@ -86,7 +93,11 @@ type appDataReceivedPacketTracker struct {
 	maxAckDelay time.Duration
 	ackQueued   bool // true if we need send a new ACK
 
-	ackElicitingPacketsReceivedSinceLastAck int
+	ackElicitingThreshold  uint64
+	reorderingThreshold    protocol.PacketNumber
+	nextAckFrequencySeqNum uint64 // the lowest sequence number of an ACK_FREQUENCY frame that is still accepted
+
+	ackElicitingPacketsReceivedSinceLastAck uint64
 	ackAlarm                                time.Time
 
 	logger utils.Logger
@@ -96,6 +107,8 @@ func newAppDataReceivedPacketTracker(logger utils.Logger) *appDataReceivedPacket
 	h := &appDataReceivedPacketTracker{
 		receivedPacketTracker: *newReceivedPacketTracker(),
 		maxAckDelay:           protocol.MaxAckDelay,
+		ackElicitingThreshold: defaultAckElicitingThreshold,
+		reorderingThreshold:   defaultReorderingThreshold,
 		logger:                logger,
 	}
 	return h
@@ -128,6 +141,27 @@ func (h *appDataReceivedPacketTracker) ReceivedPacket(pn protocol.PacketNumber,
 	return nil
 }
 
+// ReceivedAckFrequencyFrame applies the ACK frequency requested by the peer.
+// Reordered ACK_FREQUENCY frames are ignored.
+func (h *appDataReceivedPacketTracker) ReceivedAckFrequencyFrame(f *wire.AckFrequencyFrame) {
+	if f.SequenceNumber < h.nextAckFrequencySeqNum {
+		return
+	}
+	h.nextAckFrequencySeqNum = f.SequenceNumber + 1
+	h.ackElicitingThreshold = f.AckElicitingThreshold
+	h.maxAckDelay = f.RequestMaxAckDelay
+	h.reorderingThreshold = f.ReorderingThreshold
+	if h.logger.Debug() {
+		h.logger.Debugf("\tUpdating ACK frequency: ack-eliciting threshold %d, max ack delay %s, reordering threshold %d", h.ackElicitingThreshold, h.maxAckDelay, h.reorderingThreshold)
+	}
+}
+
+// ReceivedImmediateAckFrame queues an ACK, in response to an IMMEDIATE_ACK frame.
+func (h *appDataReceivedPacketTracker) ReceivedImmediateAckFrame() {
+	h.ackQueued = true
+	h.ackAlarm = time.Time{}
+}
+
 // IgnoreBelow sets a lower limit for acknowledging packets.
 // Packets with packet numbers smaller than p will not be acked.
 func (h *appDataReceivedPacketTracker) IgnoreBelow(pn protocol.PacketNumber) {
@@ -149,12 +183,15 @@ func (h *appDataReceivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 	return p < h.lastAck.LargestAcked() && !h.lastAck.AcksPacket(p)
 }
 
+// hasNewMissingPackets says if there are missing packets that need to be reported immediately.
+// A missing packet needs to be reported once the largest received packet number exceeds it by at least the reordering threshold,
+// unless this was already the case when the last ACK was sent.
 func (h *appDataReceivedPacketTracker) hasNewMissingPackets() bool {
 	if h.lastAck == nil {
 		return false
 	}
-	highestRange := h.packetHistory.GetHighestAckRange()
-	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
+	largestMissing := h.packetHistory.LargestMissing(h.largestObserved - h.reorderingThreshold)
+	return largestMissing >= h.ignoreBelow && largestMissing > h.lastAck.LargestAcked()-h.reorderingThreshold
 }
 
 func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber, ecn protocol.ECN, wasMissing bool) bool {
@@ -167,23 +204,24 @@ func (h *appDataReceivedPacketTracker) shouldQueueACK(pn protocol.PacketNumber,
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ACK, send an ACK immediately.
-	if wasMissing {
+	// The peer can disable this by setting the reordering threshold to 0.
+	if wasMissing && h.reorderingThreshold > 0 {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		return true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK once the ack-eliciting threshold is exceeded (by default, every 2 ack-eliciting packets)
+	if h.ackElicitingPacketsReceivedSinceLastAck > h.ackElicitingThreshold {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.ackElicitingThreshold)
 		}
 		return true
 	}
 
 	// queue an ACK if there are new missing packets to report
-	if h.hasNewMissingPackets() {
+	if h.reorderingThreshold > 0 && h.hasNewMissingPackets() {
 		h.logger.Debugf("\tQueuing ACK because there's a new missing packet to report.")
 		return true
 	}
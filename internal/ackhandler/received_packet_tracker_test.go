@@ -238,6 +238,147 @@ var _ = Describe("Application Data Received Packet Tracker", func() {
 			})
 		})
 
+		Context("ACK frequency", func() {
+			BeforeEach(func() {
+				for i := 1; i <= 10; i++ {
+					Expect(tracker.ReceivedPacket(protocol.PacketNumber(i), protocol.ECNNon, time.Time{}, true)).To(Succeed())
+				}
+				Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+			})
+
+			It("uses the ack-eliciting threshold and max ack delay requested by the peer", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 4,
+					RequestMaxAckDelay:    5 * time.Millisecond,
+					ReorderingThreshold:   1,
+				})
+				rcvTime := time.Now()
+				for i := 11; i <= 14; i++ {
+					Expect(tracker.ReceivedPacket(protocol.PacketNumber(i), protocol.ECNNon, rcvTime, true)).To(Succeed())
+					Expect(tracker.ackQueued).To(BeFalse())
+					Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(5 * time.Millisecond)))
+				}
+				Expect(tracker.ReceivedPacket(15, protocol.ECNNon, rcvTime, true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
+			It("ignores reordered ACK_FREQUENCY frames", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					SequenceNumber:        1,
+					AckElicitingThreshold: 4,
+					RequestMaxAckDelay:    5 * time.Millisecond,
+				})
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					SequenceNumber:        0,
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    10 * time.Millisecond,
+				})
+				Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(4))
+				Expect(tracker.maxAckDelay).To(Equal(5 * time.Millisecond))
+			})
+
+			It("doesn't queue ACKs for out-of-order packets if the reordering threshold is 0", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    5 * time.Millisecond,
+					ReorderingThreshold:   0,
+				})
+				Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.GetAckFrame(false)).ToNot(BeNil()) // ACK: 1-10 and 12, missing: 11
+				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+			})
+
+			It("queues an ACK once the reordering threshold is reached", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    5 * time.Millisecond,
+					ReorderingThreshold:   3,
+				})
+				// 11 is missing
+				Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
+			It("queues an ACK once the reordering threshold is reached for the lower of two gaps", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    5 * time.Millisecond,
+					ReorderingThreshold:   3,
+				})
+				// 11 is missing
+				Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				// 14 is missing as well
+				Expect(tracker.ReceivedPacket(15, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+				ack := tracker.GetAckFrame(true)
+				Expect(ack).ToNot(BeNil())
+				Expect(ack.AckRanges).To(Equal([]wire.AckRange{
+					{Smallest: 15, Largest: 15},
+					{Smallest: 12, Largest: 13},
+					{Smallest: 1, Largest: 10},
+				}))
+			})
+
+			It("queues an ACK once the reordering threshold is reached for the higher of two gaps", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    5 * time.Millisecond,
+					ReorderingThreshold:   3,
+				})
+				// 11 and 13 are missing
+				Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+				Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+				// 11 was already reported, 13 hasn't reached the threshold yet
+				Expect(tracker.ReceivedPacket(15, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.ReceivedPacket(16, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
+			It("queues an ACK once a gap that was reported in an ACK reaches the reordering threshold", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    5 * time.Millisecond,
+					ReorderingThreshold:   3,
+				})
+				// 11 is missing
+				Expect(tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.GetAckFrame(false)).ToNot(BeNil())
+				Expect(tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeTrue())
+				Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+				// 11 was already reported as missing beyond the reordering threshold
+				Expect(tracker.ReceivedPacket(15, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.ackQueued).To(BeFalse())
+			})
+
+			It("queues an ACK when receiving an IMMEDIATE_ACK frame", func() {
+				tracker.ReceivedAckFrequencyFrame(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    5 * time.Millisecond,
+					ReorderingThreshold:   1,
+				})
+				tracker.ReceivedImmediateAckFrame()
+				Expect(tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)).To(Succeed())
+				Expect(tracker.GetAlarmTimeout()).To(BeZero())
+				ack := tracker.GetAckFrame(true)
+				Expect(ack).ToNot(BeNil())
+				Expect(ack.LargestAcked()).To(Equal(protocol.PacketNumber(11)))
+			})
+		})
+
 		Context("ACK generation", func() {
 			It("generates an ACK for an ack-eliciting packet, if no ACK is queued yet", func() {
 				Expect(tracker.ReceivedPacket(1, protocol.ECNNon, time.Now(), true)).To(Succeed())
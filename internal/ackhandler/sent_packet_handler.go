@@ -3,6 +3,7 @@ package ackhandler
 import (
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/congestion"
@@ -79,8 +80,14 @@ type sentPacketHandler struct {
 
 	bytesInFlight protocol.ByteCount
 
-	congestion congestion.SendAlgorithmWithDebugInfos
-	rttStats   *utils.RTTStats
+	congestion      congestion.SendAlgorithmWithDebugInfos
+	rttStats        *utils.RTTStats
+	maxDatagramSize protocol.ByteCount
+	// used to create a new congestion controller when the connection migrates to a new path
+	newCongestionController CongestionControllerFactory
+
+	// only set if the peer supports the ACK frequency extension
+	ackFrequency *ackFrequencyController
 
 	// The number of times a PTO has been sent without receiving an ack.
 	ptoCount uint32
@@ -97,10 +104,20 @@ type sentPacketHandler struct {
 
 	perspective protocol.Perspective
 
+	stats sentPacketStats
+
 	tracer *logging.ConnectionTracer
 	logger utils.Logger
 }
 
+// sentPacketStats are updated on the connection's run loop, but can be read concurrently.
+type sentPacketStats struct {
+	packetsSent, bytesSent          atomic.Uint64
+	packetsLost, bytesLost          atomic.Uint64
+	packetsRetransmitted            atomic.Uint64
+	congestionWindow, bytesInFlight atomic.Uint64
+}
+
 var (
 	_ SentPacketHandler = &sentPacketHandler{}
 	_ sentPacketTracker = &sentPacketHandler{}
@@ -115,16 +132,13 @@ func newSentPacketHandler(
 	clientAddressValidated bool,
 	enableECN bool,
 	pers protocol.Perspective,
+	newCongestionController CongestionControllerFactory,
 	tracer *logging.ConnectionTracer,
 	logger utils.Logger,
 ) *sentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		tracer,
-	)
+	if newCongestionController == nil {
+		newCongestionController = newRenoFactory(pers, rttStats, tracer)
+	}
 
 	h := &sentPacketHandler{
 		peerCompletedAddressValidation: pers == protocol.PerspectiveServer,
@@ -133,15 +147,19 @@ func newSentPacketHandler(
 		handshakePackets:               newPacketNumberSpace(0, false),
 		appDataPackets:                 newPacketNumberSpace(0, true),
 		rttStats:                       rttStats,
-		congestion:                     congestion,
+		congestion:                     newCongestionController(initialMaxDatagramSize),
+		newCongestionController:        newCongestionController,
+		maxDatagramSize:                initialMaxDatagramSize,
 		perspective:                    pers,
 		tracer:                         tracer,
 		logger:                         logger,
 	}
 	if enableECN {
 		h.enableECN = true
-		h.ecnTracker = newECNTracker(logger, tracer)
+		_, isL4S := h.congestion.(congestion.L4SSendAlgorithm)
+		h.ecnTracker = newECNTracker(isL4S, logger, tracer)
 	}
+	h.stats.congestionWindow.Store(uint64(h.congestion.GetCongestionWindow()))
 	return h
 }
 
@@ -196,6 +214,7 @@ func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	default:
 		panic(fmt.Sprintf("Cannot drop keys for encryption level %s", encLevel))
 	}
+	h.congestion.OnPacketsDropped(encLevel)
 	if h.tracer != nil && h.tracer.UpdatedPTOCount != nil && h.ptoCount != 0 {
 		h.tracer.UpdatedPTOCount(0)
 	}
@@ -240,8 +259,11 @@ func (h *sentPacketHandler) SentPacket(
 	ecn protocol.ECN,
 	size protocol.ByteCount,
 	isPathMTUProbePacket bool,
+	isPathProbePacket bool,
 ) {
 	h.bytesSent += size
+	h.stats.packetsSent.Add(1)
+	h.stats.bytesSent.Add(uint64(size))
 
 	pnSpace := h.getPacketNumberSpace(encLevel)
 	if h.logger.Debug() && pnSpace.history.HasOutstandingPackets() {
@@ -253,6 +275,21 @@ func (h *sentPacketHandler) SentPacket(
 	pnSpace.largestSent = pn
 	isAckEliciting := len(streamFrames) > 0 || len(frames) > 0
 
+	// Path probe packets are sent on a path that's not (yet) used for sending data.
+	// They are neither counted towards bytes in flight, nor reported to the congestion controller.
+	if isPathProbePacket {
+		p := getPacket()
+		p.SendTime = t
+		p.PacketNumber = pn
+		p.EncryptionLevel = encLevel
+		p.Length = size
+		p.LargestAcked = largestAcked
+		p.Frames = frames
+		p.isPathProbePacket = true
+		pnSpace.history.SentAckElicitingPacket(p)
+		return
+	}
+
 	if isAckEliciting {
 		pnSpace.lastAckElicitingPacketTime = t
 		h.bytesInFlight += size
@@ -260,7 +297,7 @@ func (h *sentPacketHandler) SentPacket(
 			h.numProbesToSend--
 		}
 	}
-	h.congestion.OnPacketSent(t, h.bytesInFlight, pn, size, isAckEliciting)
+	h.congestion.OnPacketSent(t, h.bytesInFlight, encLevel, pn, size, isAckEliciting)
 
 	if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil {
 		h.ecnTracker.SentPacket(pn, ecn)
@@ -286,9 +323,7 @@ func (h *sentPacketHandler) SentPacket(
 	p.includedInBytesInFlight = true
 
 	pnSpace.history.SentAckElicitingPacket(p)
-	if h.tracer != nil && h.tracer.UpdatedMetrics != nil {
-		h.tracer.UpdatedMetrics(h.rttStats, h.congestion.GetCongestionWindow(), h.bytesInFlight, h.packetsInFlight())
-	}
+	h.updatedMetrics()
 	h.setLossDetectionTimer()
 }
 
@@ -331,8 +366,10 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 		return false, err
 	}
 	// update the RTT, if the largest acked is newly acknowledged
+	// Path probe packets and packets sent before a migration are sent on a different path,
+	// and therefore can't be used to update the RTT.
 	if len(ackedPackets) > 0 {
-		if p := ackedPackets[len(ackedPackets)-1]; p.PacketNumber == ack.LargestAcked() {
+		if p := ackedPackets[len(ackedPackets)-1]; p.PacketNumber == ack.LargestAcked() && !p.isPathProbePacket && !p.sentOnPreviousPath {
 			// don't use the ack delay for Initial and Handshake packets
 			var ackDelay time.Duration
 			if encLevel == protocol.Encryption1RTT {
@@ -348,9 +385,12 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 
 	// Only inform the ECN tracker about new 1-RTT ACKs if the ACK increases the largest acked.
 	if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil && largestAcked > pnSpace.largestAcked {
-		congested := h.ecnTracker.HandleNewlyAcked(ackedPackets, int64(ack.ECT0), int64(ack.ECT1), int64(ack.ECNCE))
-		if congested {
-			h.congestion.OnCongestionEvent(largestAcked, 0, priorInFlight)
+		newECNCE := h.ecnTracker.HandleNewlyAcked(ackedPackets, int64(ack.ECT0), int64(ack.ECT1), int64(ack.ECNCE))
+		// L4S congestion controllers respond to the extent of congestion, not only to its presence.
+		if l4s, ok := h.congestion.(congestion.L4SSendAlgorithm); ok {
+			l4s.OnECNFeedback(int64(len(ackedPackets)), newECNCE)
+		} else if newECNCE > 0 {
+			h.congestion.OnCongestionEvent(encLevel, largestAcked, 0, priorInFlight)
 		}
 	}
 
@@ -362,7 +402,7 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 	var acked1RTTPacket bool
 	for _, p := range ackedPackets {
 		if p.includedInBytesInFlight && !p.declaredLost {
-			h.congestion.OnPacketAcked(p.PacketNumber, p.Length, priorInFlight, rcvTime)
+			h.congestion.OnPacketAcked(p.EncryptionLevel, p.PacketNumber, p.Length, priorInFlight, rcvTime)
 		}
 		if p.EncryptionLevel == protocol.Encryption1RTT {
 			acked1RTTPacket = true
@@ -383,9 +423,7 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 	}
 	h.numProbesToSend = 0
 
-	if h.tracer != nil && h.tracer.UpdatedMetrics != nil {
-		h.tracer.UpdatedMetrics(h.rttStats, h.congestion.GetCongestionWindow(), h.bytesInFlight, h.packetsInFlight())
-	}
+	h.updatedMetrics()
 
 	h.setLossDetectionTimer()
 	return acked1RTTPacket, nil
@@ -653,12 +691,26 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 		}
 		if packetLost {
 			pnSpace.history.DeclareLost(p.PacketNumber)
+			if !p.skippedPacket {
+				h.stats.packetsLost.Add(1)
+				h.stats.bytesLost.Add(uint64(p.Length))
+			}
+			if p.isPathProbePacket {
+				// Path probe packets are never retransmitted, and their loss says nothing about the current path.
+				return true, nil
+			}
 			if !p.skippedPacket {
 				// the bytes in flight need to be reduced no matter if the frames in this packet will be retransmitted
 				h.removeFromBytesInFlight(p)
 				h.queueFramesForRetransmission(p)
-				if !p.IsPathMTUProbePacket {
-					h.congestion.OnCongestionEvent(p.PacketNumber, p.Length, priorInFlight)
+				// The loss of a packet sent on a previous path says nothing about the current path.
+				if p.sentOnPreviousPath {
+					return true, nil
+				}
+				if p.IsPathMTUProbePacket {
+					h.congestion.OnPacketDiscarded(p.EncryptionLevel, p.PacketNumber)
+				} else {
+					h.congestion.OnCongestionEvent(p.EncryptionLevel, p.PacketNumber, p.Length, priorInFlight)
 				}
 				if encLevel == protocol.Encryption1RTT && h.ecnTracker != nil {
 					h.ecnTracker.LostPacket(p.PacketNumber)
@@ -822,9 +874,54 @@ func (h *sentPacketHandler) TimeUntilSend() time.Time {
 }
 
 func (h *sentPacketHandler) SetMaxDatagramSize(s protocol.ByteCount) {
+	h.maxDatagramSize = s
 	h.congestion.SetMaxDatagramSize(s)
 }
 
+func (h *sentPacketHandler) CongestionWindow() protocol.ByteCount {
+	return h.congestion.GetCongestionWindow()
+}
+
+func (h *sentPacketHandler) Stats() Stats {
+	return Stats{
+		PacketsSent:          h.stats.packetsSent.Load(),
+		BytesSent:            protocol.ByteCount(h.stats.bytesSent.Load()),
+		PacketsLost:          h.stats.packetsLost.Load(),
+		BytesLost:            protocol.ByteCount(h.stats.bytesLost.Load()),
+		PacketsRetransmitted: h.stats.packetsRetransmitted.Load(),
+		CongestionWindow:     protocol.ByteCount(h.stats.congestionWindow.Load()),
+		BytesInFlight:        protocol.ByteCount(h.stats.bytesInFlight.Load()),
+	}
+}
+
+// updatedMetrics is called every time the RTT, the congestion window or the bytes in flight might have changed.
+func (h *sentPacketHandler) updatedMetrics() {
+	h.stats.congestionWindow.Store(uint64(h.congestion.GetCongestionWindow()))
+	h.stats.bytesInFlight.Store(uint64(h.bytesInFlight))
+	if h.tracer != nil && h.tracer.UpdatedMetrics != nil {
+		h.tracer.UpdatedMetrics(h.rttStats, h.congestion.GetCongestionWindow(), h.bytesInFlight, h.packetsInFlight())
+	}
+}
+
+func (h *sentPacketHandler) SetResumeState(savedRTT time.Duration, savedCongestionWindow protocol.ByteCount) {
+	if c, ok := h.congestion.(congestion.CarefulResumeSendAlgorithm); ok {
+		c.SetResumeState(savedRTT, savedCongestionWindow)
+	}
+}
+
+func (h *sentPacketHandler) EnableAckFrequency(minAckDelay time.Duration) {
+	h.ackFrequency = newAckFrequencyController(minAckDelay, h.rttStats)
+}
+
+func (h *sentPacketHandler) GetAckFrequencyFrame(now time.Time) *wire.AckFrequencyFrame {
+	// ACK_FREQUENCY frames are only sent once the handshake is confirmed,
+	// since this requires an RTT estimate.
+	if h.ackFrequency == nil || !h.handshakeConfirmed {
+		return nil
+	}
+	return h.ackFrequency.GetFrame(now, h.congestion.GetCongestionWindow(), h.maxDatagramSize)
+}
+
 func (h *sentPacketHandler) isAmplificationLimited() bool {
 	if h.peerAddressValidated {
 		return false
@@ -850,6 +947,7 @@ func (h *sentPacketHandler) queueFramesForRetransmission(p *packet) {
 	if len(p.Frames) == 0 && len(p.StreamFrames) == 0 {
 		panic("no frames")
 	}
+	h.stats.packetsRetransmitted.Add(1)
 	for _, f := range p.Frames {
 		if f.Handler != nil {
 			f.Handler.OnLost(f.Frame)
@@ -894,12 +992,12 @@ func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tupdated RTT: %s (σ: %s)", h.rttStats.SmoothedRTT(), h.rttStats.MeanDeviation())
 		}
-		if h.tracer != nil && h.tracer.UpdatedMetrics != nil {
-			h.tracer.UpdatedMetrics(h.rttStats, h.congestion.GetCongestionWindow(), h.bytesInFlight, h.packetsInFlight())
-		}
+		h.updatedMetrics()
 	}
 	h.initialPackets = newPacketNumberSpace(h.initialPackets.pns.Peek(), false)
 	h.appDataPackets = newPacketNumberSpace(h.appDataPackets.pns.Peek(), true)
+	h.congestion.OnPacketsDropped(protocol.EncryptionInitial)
+	h.congestion.OnPacketsDropped(protocol.Encryption0RTT)
 	oldAlarm := h.alarm
 	h.alarm = time.Time{}
 	if h.tracer != nil {
@@ -914,6 +1012,49 @@ func (h *sentPacketHandler) ResetForRetry(now time.Time) error {
 	return nil
 }
 
+func (h *sentPacketHandler) MigratedPath(initialMaxDatagramSize protocol.ByteCount) {
+	h.rttStats.OnConnectionMigration()
+	// Packets sent on the old path stay outstanding, and are acknowledged or declared lost as usual.
+	// They don't count towards bytes in flight on the new path, and are not reported to its congestion controller,
+	// see section 9.4 of RFC 9002.
+	h.appDataPackets.history.Iterate(func(p *packet) (bool, error) {
+		if p.declaredLost || p.skippedPacket || p.isPathProbePacket {
+			return true, nil
+		}
+		h.removeFromBytesInFlight(p)
+		p.sentOnPreviousPath = true
+		return true, nil
+	})
+	h.congestion = h.newCongestionController(initialMaxDatagramSize)
+	h.maxDatagramSize = initialMaxDatagramSize
+	if h.ecnTracker != nil {
+		h.ecnTracker.PathChanged()
+	}
+	if h.tracer != nil && h.tracer.UpdatedPTOCount != nil && h.ptoCount != 0 {
+		h.tracer.UpdatedPTOCount(0)
+	}
+	h.ptoCount = 0
+	h.numProbesToSend = 0
+	h.ptoMode = SendNone
+	h.updatedMetrics()
+	h.setLossDetectionTimer()
+}
+
+func (h *sentPacketHandler) MigratedToUnvalidatedPath(bytesSent, bytesReceived protocol.ByteCount) {
+	h.peerAddressValidated = false
+	h.bytesSent = bytesSent
+	h.bytesReceived = bytesReceived
+	h.setLossDetectionTimer()
+}
+
+func (h *sentPacketHandler) PathValidated() {
+	if h.peerAddressValidated {
+		return
+	}
+	h.peerAddressValidated = true
+	h.setLossDetectionTimer()
+}
+
 func (h *sentPacketHandler) SetHandshakeConfirmed() {
 	if h.initialPackets != nil {
 		panic("didn't drop initial correctly")
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quic-go/quic-go/congestion"
 	"github.com/quic-go/quic-go/internal/mocks"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
@@ -44,7 +45,7 @@ var _ = Describe("SentPacketHandler", func() {
 	JustBeforeEach(func() {
 		lostPackets = nil
 		rttStats := utils.NewRTTStats()
-		handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, false, false, perspective, nil, utils.DefaultLogger)
+		handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, false, false, perspective, nil, nil, utils.DefaultLogger)
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
 			Data:     []byte{0x13, 0x37},
@@ -106,7 +107,7 @@ var _ = Describe("SentPacketHandler", func() {
 	}
 
 	sentPacket := func(p *packet) {
-		handler.SentPacket(p.SendTime, p.PacketNumber, p.LargestAcked, p.StreamFrames, p.Frames, p.EncryptionLevel, protocol.ECNNon, p.Length, p.IsPathMTUProbePacket)
+		handler.SentPacket(p.SendTime, p.PacketNumber, p.LargestAcked, p.StreamFrames, p.Frames, p.EncryptionLevel, protocol.ECNNon, p.Length, p.IsPathMTUProbePacket, p.isPathProbePacket)
 	}
 
 	expectInPacketHistory := func(expected []protocol.PacketNumber, encLevel protocol.EncryptionLevel) {
@@ -521,6 +522,7 @@ var _ = Describe("SentPacketHandler", func() {
 
 		JustBeforeEach(func() {
 			cong = mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			cong.EXPECT().GetCongestionWindow().AnyTimes()
 			handler.congestion = cong
 		})
 
@@ -528,6 +530,7 @@ var _ = Describe("SentPacketHandler", func() {
 			cong.EXPECT().OnPacketSent(
 				gomock.Any(),
 				protocol.ByteCount(42),
+				protocol.Encryption1RTT,
 				protocol.PacketNumber(1),
 				protocol.ByteCount(42),
 				true,
@@ -542,11 +545,11 @@ var _ = Describe("SentPacketHandler", func() {
 
 		It("should call MaybeExitSlowStart and OnPacketAcked", func() {
 			rcvTime := time.Now().Add(-5 * time.Second)
-			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 			gomock.InOrder(
 				cong.EXPECT().MaybeExitSlowStart(), // must be called before packets are acked
-				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(3), rcvTime),
-				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(3), rcvTime),
+				cong.EXPECT().OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(3), rcvTime),
+				cong.EXPECT().OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(3), rcvTime),
 			)
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1}))
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2}))
@@ -557,14 +560,14 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 
 		It("doesn't call OnPacketAcked when a retransmitted packet is acked", func() {
-			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour)}))
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2}))
 			// lose packet 1
 			gomock.InOrder(
 				cong.EXPECT().MaybeExitSlowStart(),
-				cong.EXPECT().OnCongestionEvent(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(2)),
-				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any()),
+				cong.EXPECT().OnCongestionEvent(protocol.Encryption1RTT, protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(2)),
+				cong.EXPECT().OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any()),
 			)
 			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
 			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
@@ -576,7 +579,7 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 
 		It("doesn't call OnCongestionEvent when a Path MTU probe packet is lost", func() {
-			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
 			var mtuPacketDeclaredLost bool
 			sentPacket(ackElicitingPacket(&packet{
 				PacketNumber:         1,
@@ -593,7 +596,8 @@ var _ = Describe("SentPacketHandler", func() {
 			// lose packet 1, but don't EXPECT any calls to OnCongestionEvent()
 			gomock.InOrder(
 				cong.EXPECT().MaybeExitSlowStart(),
-				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any()),
+				cong.EXPECT().OnPacketDiscarded(protocol.Encryption1RTT, protocol.PacketNumber(1)),
+				cong.EXPECT().OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any()),
 			)
 			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
 			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
@@ -602,8 +606,30 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(handler.bytesInFlight).To(BeZero())
 		})
 
+		It("doesn't inform the congestion controller about path probe packets", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			sentPacket(ackElicitingPacket(&packet{
+				PacketNumber:      1,
+				SendTime:          time.Now().Add(-time.Hour),
+				isPathProbePacket: true,
+				Frames:            []Frame{{Frame: &wire.PathChallengeFrame{}}},
+			}))
+			Expect(handler.bytesInFlight).To(BeZero())
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2}))
+			// lose packet 1, but don't EXPECT any calls to OnCongestionEvent()
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(1), gomock.Any()),
+			)
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.bytesInFlight).To(BeZero())
+			Expect(handler.appDataPackets.history.Len()).To(BeZero())
+		})
+
 		It("calls OnPacketAcked and OnCongestionEvent with the right bytes_in_flight value", func() {
-			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour)}))
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, SendTime: time.Now().Add(-30 * time.Minute)}))
 			sentPacket(ackElicitingPacket(&packet{PacketNumber: 3, SendTime: time.Now().Add(-30 * time.Minute)}))
@@ -611,8 +637,8 @@ var _ = Describe("SentPacketHandler", func() {
 			// receive the first ACK
 			gomock.InOrder(
 				cong.EXPECT().MaybeExitSlowStart(),
-				cong.EXPECT().OnCongestionEvent(protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(4)),
-				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(4), gomock.Any()),
+				cong.EXPECT().OnCongestionEvent(protocol.Encryption1RTT, protocol.PacketNumber(1), protocol.ByteCount(1), protocol.ByteCount(4)),
+				cong.EXPECT().OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(2), protocol.ByteCount(1), protocol.ByteCount(4), gomock.Any()),
 			)
 			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
 			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now().Add(-30*time.Minute))
@@ -620,8 +646,8 @@ var _ = Describe("SentPacketHandler", func() {
 			// receive the second ACK
 			gomock.InOrder(
 				cong.EXPECT().MaybeExitSlowStart(),
-				cong.EXPECT().OnCongestionEvent(protocol.PacketNumber(3), protocol.ByteCount(1), protocol.ByteCount(2)),
-				cong.EXPECT().OnPacketAcked(protocol.PacketNumber(4), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any()),
+				cong.EXPECT().OnCongestionEvent(protocol.Encryption1RTT, protocol.PacketNumber(3), protocol.ByteCount(1), protocol.ByteCount(2)),
+				cong.EXPECT().OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(4), protocol.ByteCount(1), protocol.ByteCount(2), gomock.Any()),
 			)
 			ack = &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 4, Largest: 4}}}
 			_, err = handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
@@ -630,7 +656,7 @@ var _ = Describe("SentPacketHandler", func() {
 
 		It("passes the bytes in flight to the congestion controller", func() {
 			handler.ReceivedPacket(protocol.EncryptionHandshake)
-			cong.EXPECT().OnPacketSent(gomock.Any(), protocol.ByteCount(42), gomock.Any(), protocol.ByteCount(42), true)
+			cong.EXPECT().OnPacketSent(gomock.Any(), protocol.ByteCount(42), gomock.Any(), gomock.Any(), protocol.ByteCount(42), true)
 			sentPacket(&packet{
 				Length:          42,
 				EncryptionLevel: protocol.EncryptionInitial,
@@ -655,7 +681,7 @@ var _ = Describe("SentPacketHandler", func() {
 			handler.ReceivedPacket(protocol.EncryptionHandshake)
 			cong.EXPECT().CanSend(gomock.Any()).Return(true).AnyTimes()
 			cong.EXPECT().HasPacingBudget(gomock.Any()).Return(true).AnyTimes()
-			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			for i := protocol.PacketNumber(0); i < protocol.MaxOutstandingSentPackets; i++ {
 				Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
 				sentPacket(ackElicitingPacket(&packet{PacketNumber: i}))
@@ -677,6 +703,31 @@ var _ = Describe("SentPacketHandler", func() {
 			cong.EXPECT().TimeUntilSend(gomock.Any()).Return(t)
 			Expect(handler.TimeUntilSend()).To(Equal(t))
 		})
+
+		It("passes the encryption level along with the packet number", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), protocol.EncryptionInitial, protocol.PacketNumber(1), gomock.Any(), true)
+			sentPacket(initialPacket(&packet{PacketNumber: 1}))
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), protocol.Encryption1RTT, protocol.PacketNumber(1), gomock.Any(), true)
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), protocol.Encryption1RTT, protocol.PacketNumber(2), gomock.Any(), true)
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour)}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2}))
+			gomock.InOrder(
+				cong.EXPECT().MaybeExitSlowStart(),
+				cong.EXPECT().OnCongestionEvent(protocol.Encryption1RTT, protocol.PacketNumber(1), protocol.ByteCount(1), gomock.Any()),
+				cong.EXPECT().OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(2), protocol.ByteCount(1), gomock.Any(), gomock.Any()),
+			)
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("informs the congestion controller when packets are dropped", func() {
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), protocol.EncryptionInitial, protocol.PacketNumber(1), gomock.Any(), true)
+			sentPacket(initialPacket(&packet{PacketNumber: 1}))
+			cong.EXPECT().OnPacketsDropped(protocol.EncryptionInitial)
+			handler.DropPackets(protocol.EncryptionInitial)
+		})
 	})
 
 	It("doesn't set an alarm if there are no outstanding packets", func() {
@@ -915,6 +966,97 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("path migration", func() {
+		It("doesn't use path probe packets for RTT estimation", func() {
+			sentPacket(ackElicitingPacket(&packet{
+				PacketNumber:      1,
+				SendTime:          time.Now().Add(-time.Hour),
+				isPathProbePacket: true,
+				Frames:            []Frame{{Frame: &wire.PathChallengeFrame{}}},
+			}))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+		})
+
+		It("doesn't set the PTO timer for path probe packets", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			setHandshakeConfirmed()
+			updateRTT(time.Second)
+			sentPacket(ackElicitingPacket(&packet{
+				PacketNumber:      5,
+				isPathProbePacket: true,
+				Frames:            []Frame{{Frame: &wire.PathChallengeFrame{}}},
+			}))
+			Expect(handler.GetLossDetectionTimeout()).To(BeZero())
+		})
+
+		It("resets the congestion state when migrating, keeping outstanding packets", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			setHandshakeConfirmed()
+			updateRTT(time.Second)
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 1, Length: 500}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 2, Length: 500}))
+			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(1000)))
+			handler.MigratedPath(protocol.InitialPacketSize)
+			Expect(lostPackets).To(BeEmpty())
+			Expect(handler.bytesInFlight).To(BeZero())
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+			initialWindow := 32 * protocol.ByteCount(protocol.InitialPacketSize)
+			Expect(handler.congestion.GetCongestionWindow()).To(Equal(initialWindow))
+			// the packets sent on the old path are still outstanding
+			Expect(handler.GetLossDetectionTimeout()).ToNot(BeZero())
+			// ACKs for packets sent on the old path are accepted, but not used to update the RTT
+			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+			Expect(handler.bytesInFlight).To(BeZero())
+			// packets sent on the old path are declared lost and retransmitted,
+			// but their loss isn't reported to the congestion controller
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 3}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 4}))
+			sentPacket(ackElicitingPacket(&packet{PacketNumber: 5}))
+			_, err = handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 5, Largest: 5}}}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{2}))
+			Expect(handler.bytesInFlight).To(Equal(protocol.ByteCount(2)))
+			Expect(handler.congestion.GetCongestionWindow()).To(BeNumerically(">=", initialWindow))
+		})
+
+		It("uses the congestion controller factory, also when migrating", func() {
+			var sizes []protocol.ByteCount
+			cong1 := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			cong2 := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			cong1.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(42)).AnyTimes()
+			cong2.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(1337)).AnyTimes()
+			handler = newSentPacketHandler(
+				0,
+				1234,
+				&utils.RTTStats{},
+				false,
+				false,
+				protocol.PerspectiveClient,
+				func(initialMaxDatagramSize protocol.ByteCount) congestion.SendAlgorithm {
+					sizes = append(sizes, initialMaxDatagramSize)
+					if len(sizes) == 1 {
+						return cong1
+					}
+					return cong2
+				},
+				nil,
+				utils.DefaultLogger,
+			)
+			Expect(sizes).To(Equal([]protocol.ByteCount{1234}))
+			Expect(handler.congestion.GetCongestionWindow()).To(Equal(protocol.ByteCount(42)))
+			Expect(handler.Stats().CongestionWindow).To(Equal(protocol.ByteCount(42)))
+			handler.MigratedPath(1300)
+			Expect(sizes).To(Equal([]protocol.ByteCount{1234, 1300}))
+			Expect(handler.congestion.GetCongestionWindow()).To(Equal(protocol.ByteCount(1337)))
+			Expect(handler.Stats().CongestionWindow).To(Equal(protocol.ByteCount(1337)))
+		})
+	})
+
 	Context("amplification limit, for the server", func() {
 		It("limits the window to 3x the bytes received, to avoid amplification attacks", func() {
 			now := time.Now()
@@ -984,7 +1126,7 @@ var _ = Describe("SentPacketHandler", func() {
 	Context("amplification limit, for the server, with validated address", func() {
 		JustBeforeEach(func() {
 			rttStats := utils.NewRTTStats()
-			handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, true, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, true, false, perspective, nil, nil, utils.DefaultLogger)
 		})
 
 		It("do not limits the window", func() {
@@ -999,6 +1141,32 @@ var _ = Describe("SentPacketHandler", func() {
 			})
 			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
 		})
+
+		It("limits the window after migrating to an unvalidated path", func() {
+			handler.MigratedToUnvalidatedPath(900, 400)
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
+			sentPacket(&packet{
+				PacketNumber:    1,
+				Length:          300,
+				EncryptionLevel: protocol.Encryption1RTT,
+				Frames:          []Frame{{Frame: &wire.PingFrame{}}},
+				SendTime:        time.Now(),
+			})
+			Expect(handler.SendMode(time.Now())).To(Equal(SendNone))
+			handler.ReceivedBytes(1)
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
+			sentPacket(&packet{
+				PacketNumber:    2,
+				Length:          300,
+				EncryptionLevel: protocol.Encryption1RTT,
+				Frames:          []Frame{{Frame: &wire.PingFrame{}}},
+				SendTime:        time.Now(),
+			})
+			Expect(handler.SendMode(time.Now())).To(Equal(SendNone))
+			// the limit is lifted once the path is validated
+			handler.PathValidated()
+			Expect(handler.SendMode(time.Now())).To(Equal(SendAny))
+		})
 	})
 
 	Context("amplification limit, for the client", func() {
@@ -1120,6 +1288,39 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("statistics", func() {
+		It("counts sent, lost and retransmitted packets", func() {
+			Expect(handler.Stats().CongestionWindow).To(Equal(handler.congestion.GetCongestionWindow()))
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				sentPacket(ackElicitingPacket(&packet{PacketNumber: i, Length: 100}))
+			}
+			sentPacket(nonAckElicitingPacket(&packet{PacketNumber: 7, Length: 50}))
+			stats := handler.Stats()
+			Expect(stats.PacketsSent).To(BeEquivalentTo(7))
+			Expect(stats.BytesSent).To(Equal(protocol.ByteCount(650)))
+			Expect(stats.BytesInFlight).To(Equal(protocol.ByteCount(600)))
+			Expect(stats.PacketsLost).To(BeZero())
+
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}
+			_, err := handler.ReceivedAck(ack, protocol.Encryption1RTT, now)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(lostPackets).To(Equal([]protocol.PacketNumber{1, 2, 3}))
+			stats = handler.Stats()
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+			Expect(stats.BytesLost).To(Equal(protocol.ByteCount(300)))
+			Expect(stats.PacketsRetransmitted).To(BeEquivalentTo(3))
+			Expect(stats.BytesInFlight).To(Equal(protocol.ByteCount(200)))
+			Expect(stats.CongestionWindow).To(Equal(handler.congestion.GetCongestionWindow()))
+
+			// probe packets are retransmitted, but not declared lost
+			Expect(handler.QueueProbePacket(protocol.Encryption1RTT)).To(BeTrue())
+			stats = handler.Stats()
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+			Expect(stats.PacketsRetransmitted).To(BeEquivalentTo(4))
+		})
+	})
+
 	Context("Delay-based loss detection", func() {
 		It("immediately detects old packets as lost when receiving an ACK", func() {
 			now := time.Now()
@@ -1436,44 +1637,45 @@ var _ = Describe("SentPacketHandler", func() {
 
 		JustBeforeEach(func() {
 			cong = mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
-			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
-			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			cong.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 			cong.EXPECT().MaybeExitSlowStart().AnyTimes()
+			cong.EXPECT().GetCongestionWindow().AnyTimes()
 			ecnHandler = NewMockECNHandler(mockCtrl)
 			lostPackets = nil
 			rttStats := utils.NewRTTStats()
 			rttStats.UpdateRTT(time.Hour, 0, time.Now())
-			handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, false, false, perspective, nil, utils.DefaultLogger)
+			handler = newSentPacketHandler(42, protocol.InitialPacketSize, rttStats, false, false, perspective, nil, nil, utils.DefaultLogger)
 			handler.ecnTracker = ecnHandler
 			handler.congestion = cong
 		})
 
 		It("informs about sent packets", func() {
 			// Check that only 1-RTT packets are reported
-			handler.SentPacket(time.Now(), 100, -1, nil, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false)
-			handler.SentPacket(time.Now(), 101, -1, nil, nil, protocol.EncryptionHandshake, protocol.ECT0, 1200, false)
-			handler.SentPacket(time.Now(), 102, -1, nil, nil, protocol.Encryption0RTT, protocol.ECNCE, 1200, false)
+			handler.SentPacket(time.Now(), 100, -1, nil, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false, false)
+			handler.SentPacket(time.Now(), 101, -1, nil, nil, protocol.EncryptionHandshake, protocol.ECT0, 1200, false, false)
+			handler.SentPacket(time.Now(), 102, -1, nil, nil, protocol.Encryption0RTT, protocol.ECNCE, 1200, false, false)
 
 			ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(103), protocol.ECT1)
-			handler.SentPacket(time.Now(), 103, -1, nil, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false)
+			handler.SentPacket(time.Now(), 103, -1, nil, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false, false)
 		})
 
 		It("informs about sent packets", func() {
 			// Check that only 1-RTT packets are reported
-			handler.SentPacket(time.Now(), 100, -1, nil, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false)
-			handler.SentPacket(time.Now(), 101, -1, nil, nil, protocol.EncryptionHandshake, protocol.ECT0, 1200, false)
-			handler.SentPacket(time.Now(), 102, -1, nil, nil, protocol.Encryption0RTT, protocol.ECNCE, 1200, false)
+			handler.SentPacket(time.Now(), 100, -1, nil, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false, false)
+			handler.SentPacket(time.Now(), 101, -1, nil, nil, protocol.EncryptionHandshake, protocol.ECT0, 1200, false, false)
+			handler.SentPacket(time.Now(), 102, -1, nil, nil, protocol.Encryption0RTT, protocol.ECNCE, 1200, false, false)
 
 			ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(103), protocol.ECT1)
-			handler.SentPacket(time.Now(), 103, -1, nil, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false)
+			handler.SentPacket(time.Now(), 103, -1, nil, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false, false)
 		})
 
 		It("informs about lost packets", func() {
 			for i := 10; i < 20; i++ {
 				ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(i), protocol.ECT1)
-				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false)
+				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false, false)
 			}
-			cong.EXPECT().OnCongestionEvent(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+			cong.EXPECT().OnCongestionEvent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
 			ecnHandler.EXPECT().LostPacket(protocol.PacketNumber(10))
 			ecnHandler.EXPECT().LostPacket(protocol.PacketNumber(11))
 			ecnHandler.EXPECT().LostPacket(protocol.PacketNumber(12))
@@ -1482,24 +1684,29 @@ var _ = Describe("SentPacketHandler", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("restarts ECN validation when migrating", func() {
+			ecnHandler.EXPECT().PathChanged()
+			handler.MigratedPath(protocol.InitialPacketSize)
+		})
+
 		It("processes ACKs", func() {
 			// Check that we only care about 1-RTT packets.
-			handler.SentPacket(time.Now(), 100, -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false)
+			handler.SentPacket(time.Now(), 100, -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.EncryptionInitial, protocol.ECT1, 1200, false, false)
 			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 100, Smallest: 100}}}, protocol.EncryptionInitial, time.Now())
 			Expect(err).ToNot(HaveOccurred())
 
 			for i := 10; i < 20; i++ {
 				ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(i), protocol.ECT1)
-				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false)
+				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false, false)
 			}
-			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(1), int64(2), int64(3)).DoAndReturn(func(packets []*packet, _, _, _ int64) bool {
+			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(1), int64(2), int64(3)).DoAndReturn(func(packets []*packet, _, _, _ int64) int64 {
 				Expect(packets).To(HaveLen(5))
 				Expect(packets[0].PacketNumber).To(Equal(protocol.PacketNumber(10)))
 				Expect(packets[1].PacketNumber).To(Equal(protocol.PacketNumber(11)))
 				Expect(packets[2].PacketNumber).To(Equal(protocol.PacketNumber(12)))
 				Expect(packets[3].PacketNumber).To(Equal(protocol.PacketNumber(14)))
 				Expect(packets[4].PacketNumber).To(Equal(protocol.PacketNumber(15)))
-				return false
+				return 0
 			})
 			_, err = handler.ReceivedAck(&wire.AckFrame{
 				AckRanges: []wire.AckRange{
@@ -1516,13 +1723,13 @@ var _ = Describe("SentPacketHandler", func() {
 		It("ignores reordered ACKs", func() {
 			for i := 10; i < 20; i++ {
 				ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(i), protocol.ECT1)
-				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false)
+				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false, false)
 			}
-			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(1), int64(2), int64(3)).DoAndReturn(func(packets []*packet, _, _, _ int64) bool {
+			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(1), int64(2), int64(3)).DoAndReturn(func(packets []*packet, _, _, _ int64) int64 {
 				Expect(packets).To(HaveLen(2))
 				Expect(packets[0].PacketNumber).To(Equal(protocol.PacketNumber(11)))
 				Expect(packets[1].PacketNumber).To(Equal(protocol.PacketNumber(12)))
-				return false
+				return 0
 			})
 			_, err := handler.ReceivedAck(&wire.AckFrame{
 				AckRanges: []wire.AckRange{{Largest: 12, Smallest: 11}},
@@ -1543,12 +1750,12 @@ var _ = Describe("SentPacketHandler", func() {
 		It("ignores ACKs that don't increase the largest acked", func() {
 			for i := 10; i < 20; i++ {
 				ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(i), protocol.ECT1)
-				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false)
+				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false, false)
 			}
-			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(1), int64(2), int64(3)).DoAndReturn(func(packets []*packet, _, _, _ int64) bool {
+			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(1), int64(2), int64(3)).DoAndReturn(func(packets []*packet, _, _, _ int64) int64 {
 				Expect(packets).To(HaveLen(1))
 				Expect(packets[0].PacketNumber).To(Equal(protocol.PacketNumber(11)))
-				return false
+				return 0
 			})
 			_, err := handler.ReceivedAck(&wire.AckFrame{
 				AckRanges: []wire.AckRange{{Largest: 11, Smallest: 11}},
@@ -1568,12 +1775,53 @@ var _ = Describe("SentPacketHandler", func() {
 		It("informs the congestion controller about CE events", func() {
 			for i := 10; i < 20; i++ {
 				ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(i), protocol.ECT0)
-				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT0, 1200, false)
+				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT0, 1200, false, false)
 			}
-			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(0), int64(0), int64(0)).Return(true)
-			cong.EXPECT().OnCongestionEvent(protocol.PacketNumber(15), gomock.Any(), gomock.Any())
+			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(0), int64(0), int64(0)).Return(int64(1))
+			cong.EXPECT().OnCongestionEvent(protocol.Encryption1RTT, protocol.PacketNumber(15), gomock.Any(), gomock.Any())
 			_, err := handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Largest: 15, Smallest: 10}}}, protocol.Encryption1RTT, time.Now())
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("informs L4S congestion controllers about ECN feedback", func() {
+			l4s := mocks.NewMockL4SSendAlgorithm(mockCtrl)
+			l4s.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			l4s.EXPECT().OnPacketAcked(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			l4s.EXPECT().MaybeExitSlowStart().AnyTimes()
+			l4s.EXPECT().GetCongestionWindow().AnyTimes()
+			handler.congestion = l4s
+			for i := 10; i < 20; i++ {
+				ecnHandler.EXPECT().SentPacket(protocol.PacketNumber(i), protocol.ECT1)
+				handler.SentPacket(time.Now(), protocol.PacketNumber(i), -1, []StreamFrame{{Frame: &streamFrame}}, nil, protocol.Encryption1RTT, protocol.ECT1, 1200, false, false)
+			}
+			ecnHandler.EXPECT().HandleNewlyAcked(gomock.Any(), int64(0), int64(4), int64(2)).Return(int64(2))
+			// CE marks are not reported as congestion events
+			l4s.EXPECT().OnECNFeedback(int64(6), int64(2))
+			_, err := handler.ReceivedAck(&wire.AckFrame{
+				AckRanges: []wire.AckRange{{Largest: 15, Smallest: 10}},
+				ECT1:      4,
+				ECNCE:     2,
+			}, protocol.Encryption1RTT, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	It("uses ECT(1) if the congestion controller supports L4S", func() {
+		l4s := mocks.NewMockL4SSendAlgorithm(mockCtrl)
+		l4s.EXPECT().GetCongestionWindow().AnyTimes()
+		handler = newSentPacketHandler(
+			0,
+			protocol.InitialPacketSize,
+			utils.NewRTTStats(),
+			false,
+			true,
+			perspective,
+			func(protocol.ByteCount) congestion.SendAlgorithm { return l4s },
+			nil,
+			utils.DefaultLogger,
+		)
+		Expect(handler.ECNMode(true)).To(Equal(protocol.ECT1))
+		handler = newSentPacketHandler(0, protocol.InitialPacketSize, utils.NewRTTStats(), false, true, perspective, nil, nil, utils.DefaultLogger)
+		Expect(handler.ECNMode(true)).To(Equal(protocol.ECT0))
 	})
 })
@@ -0,0 +1,556 @@
+package congestion
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// This is an implementation of BBR (version 1), as described in draft-cardwell-iccrg-bbr-congestion-control-00.
+// BBR builds a model of the path, consisting of the bottleneck bandwidth and the round-trip propagation time,
+// and paces packets at the estimated bottleneck bandwidth, instead of using loss as a congestion signal.
+
+const (
+	// The gain used in Startup, 2/ln(2).
+	// This is the smallest gain that allows the sending rate to double every round trip.
+	bbrHighGain = 2.885
+	// The gain used in Drain, to drain the queue created in Startup in a single round trip.
+	bbrDrainGain = 1 / bbrHighGain
+	// The congestion window gain used in ProbeBW.
+	bbrCwndGain = 2
+	// The number of round trips over which the bottleneck bandwidth is estimated.
+	bbrBandwidthFilterRounds = 10
+	// The time after which the minimum RTT estimate expires, triggering the ProbeRTT mode.
+	bbrMinRTTFilterLength = 10 * time.Second
+	// The minimum time spent in ProbeRTT.
+	bbrProbeRTTDuration = 200 * time.Millisecond
+	// Startup is exited once the bandwidth estimate didn't grow by at least 25% ...
+	bbrStartupGrowthTarget = 1.25
+	// ... for 3 consecutive round trips.
+	bbrStartupFullBandwidthRounds = 3
+	// The minimum congestion window, in packets. This is the congestion window used in ProbeRTT.
+	bbrMinCongestionWindowPackets = 4
+	// The number of packets added to the target congestion window, to allow for delayed and aggregated ACKs.
+	bbrSendQuantumPackets = 3
+	// The initial RTT, used for pacing before the first RTT sample is available.
+	bbrInitialRTT = 100 * time.Millisecond
+)
+
+// The pacing gains used in ProbeBW:
+// One round trip probing for more bandwidth, one round trip draining the queue created by probing,
+// followed by 6 round trips cruising at the estimated bandwidth.
+var bbrPacingGainCycle = [...]float64{1.25, 0.75, 1, 1, 1, 1, 1, 1}
+
+type bbrMode uint8
+
+const (
+	bbrModeStartup bbrMode = iota
+	bbrModeDrain
+	bbrModeProbeBW
+	bbrModeProbeRTT
+)
+
+// bbrPacket is the delivery rate state at the time a packet was sent.
+type bbrPacket struct {
+	sentTime      time.Time
+	delivered     protocol.ByteCount
+	deliveredTime time.Time
+	firstSentTime time.Time
+	// appLimited is set if the packet was sent while the sender was application-limited
+	appLimited bool
+}
+
+type bbrSender struct {
+	rttStats RTTStats
+	pacer    *pacer
+	rand     utils.Rand
+
+	mode bbrMode
+
+	// delivery rate estimation
+	packets       sentPackets[bbrPacket]
+	delivered     protocol.ByteCount
+	deliveredTime time.Time
+	firstSentTime time.Time
+	// The value of delivered at which the sender stops being application-limited.
+	// 0 if the sender is not application-limited.
+	appLimitedUntil protocol.ByteCount
+	// whether the congestion window was full after the last packet was sent
+	cwndLimited bool
+
+	// round trip counting
+	roundCount         uint64
+	nextRoundDelivered protocol.ByteCount
+	roundStart         bool
+
+	// bottleneck bandwidth estimation: the maximum delivery rate measured in each of the last round trips
+	bandwidthSamples [bbrBandwidthFilterRounds]Bandwidth
+	bandwidthRounds  [bbrBandwidthFilterRounds]uint64
+	maxBandwidth     Bandwidth
+
+	// minimum RTT estimation
+	minRTT        time.Duration
+	minRTTStamp   time.Time
+	minRTTExpired bool
+
+	// Startup
+	filledPipe         bool
+	fullBandwidth      Bandwidth
+	fullBandwidthCount int
+
+	// ProbeBW
+	cycleIndex int
+	cycleStamp time.Time
+
+	// ProbeRTT
+	probeRTTDoneStamp time.Time
+	probeRTTRoundDone bool
+
+	// loss recovery
+	inRecovery            bool
+	packetConservation    bool
+	recoveryEndPacket     protocol.PacketNumber
+	largestSentPacket     protocol.PacketNumber
+	priorCongestionWindow protocol.ByteCount
+	hadLossInCycle        bool
+
+	pacingGain       float64
+	cwndGain         float64
+	pacingRate       Bandwidth
+	congestionWindow protocol.ByteCount
+
+	initialCongestionWindow protocol.ByteCount
+	maxCongestionWindow     protocol.ByteCount
+	maxDatagramSize         protocol.ByteCount
+
+	lastState logging.CongestionState
+	tracer    *logging.ConnectionTracer
+}
+
+var (
+	_ SendAlgorithm               = &bbrSender{}
+	_ SendAlgorithmWithDebugInfos = &bbrSender{}
+)
+
+// NewBBRSender makes a new BBR sender
+func NewBBRSender(
+	rttStats RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	tracer *logging.ConnectionTracer,
+) *bbrSender {
+	b := &bbrSender{
+		rttStats:                rttStats,
+		mode:                    bbrModeStartup,
+		packets:                 newSentPackets[bbrPacket](),
+		largestSentPacket:       protocol.InvalidPacketNumber,
+		recoveryEndPacket:       protocol.InvalidPacketNumber,
+		pacingGain:              bbrHighGain,
+		cwndGain:                bbrHighGain,
+		initialCongestionWindow: initialCongestionWindow * initialMaxDatagramSize,
+		maxCongestionWindow:     protocol.MaxCongestionWindowPackets * initialMaxDatagramSize,
+		congestionWindow:        initialCongestionWindow * initialMaxDatagramSize,
+		maxDatagramSize:         initialMaxDatagramSize,
+		tracer:                  tracer,
+	}
+	b.pacingRate = b.initialPacingRate()
+	// BBR controls the sending rate precisely using the pacing gain.
+	b.pacer = newRatePacer(func() Bandwidth { return b.pacingRate })
+	b.pacer.SetMaxDatagramSize(initialMaxDatagramSize)
+	if b.tracer != nil && b.tracer.UpdatedCongestionState != nil {
+		b.lastState = logging.CongestionStateSlowStart
+		b.tracer.UpdatedCongestionState(logging.CongestionStateSlowStart)
+	}
+	return b
+}
+
+func (b *bbrSender) initialPacingRate() Bandwidth {
+	rtt := b.rttStats.SmoothedRTT()
+	if rtt == 0 {
+		rtt = bbrInitialRTT
+	}
+	return Bandwidth(bbrHighGain * float64(BandwidthFromDelta(b.congestionWindow, rtt)))
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (b *bbrSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return b.pacer.TimeUntilSend()
+}
+
+func (b *bbrSender) HasPacingBudget(now time.Time) bool {
+	return b.pacer.Budget(now) >= b.maxDatagramSize
+}
+
+func (b *bbrSender) OnPacketSent(
+	sentTime time.Time,
+	bytesInFlight protocol.ByteCount,
+	encLevel protocol.EncryptionLevel,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	budget := b.pacer.Budget(sentTime)
+	b.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	// bytesInFlight includes this packet
+	if priorInFlight := bytesInFlight - bytes; b.wasAppLimited(priorInFlight, budget) {
+		b.appLimitedUntil = max(b.delivered+priorInFlight, 1)
+	}
+	b.cwndLimited = bytesInFlight >= b.congestionWindow
+	b.largestSentPacket = packetNumber
+	if bytesInFlight <= bytes {
+		b.firstSentTime = sentTime
+		b.deliveredTime = sentTime
+	}
+	b.packets.Add(encLevel, packetNumber, bbrPacket{
+		sentTime:      sentTime,
+		delivered:     b.delivered,
+		deliveredTime: b.deliveredTime,
+		firstSentTime: b.firstSentTime,
+		appLimited:    b.appLimitedUntil != 0,
+	})
+}
+
+// wasAppLimited says if the sender was application-limited before sending a packet,
+// i.e. if neither the congestion window nor the pacer prevented it from sending more packets.
+// The pacer only accumulates its maximum burst budget if the sender didn't send for a while.
+func (b *bbrSender) wasAppLimited(priorInFlight, pacingBudget protocol.ByteCount) bool {
+	return !b.cwndLimited && priorInFlight < b.congestionWindow && pacingBudget >= b.pacer.maxBurstSize()
+}
+
+func (b *bbrSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < b.congestionWindow
+}
+
+func (b *bbrSender) InRecovery() bool {
+	return b.inRecovery
+}
+
+func (b *bbrSender) InSlowStart() bool {
+	return b.mode == bbrModeStartup
+}
+
+func (b *bbrSender) GetCongestionWindow() protocol.ByteCount {
+	return b.congestionWindow
+}
+
+// MaybeExitSlowStart is a no-op: BBR exits Startup based on its bandwidth estimate.
+func (b *bbrSender) MaybeExitSlowStart() {}
+
+func (b *bbrSender) OnPacketAcked(
+	encLevel protocol.EncryptionLevel,
+	packetNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime time.Time,
+) {
+	b.roundStart = false
+	b.delivered += ackedBytes
+	b.deliveredTime = eventTime
+	if b.appLimitedUntil != 0 && b.delivered > b.appLimitedUntil {
+		b.appLimitedUntil = 0
+	}
+	var appLimited bool
+	if p, ok := b.packets.Remove(encLevel, packetNumber); ok {
+		appLimited = p.appLimited
+		b.updateRound(p)
+		b.updateBandwidth(p, eventTime)
+		b.firstSentTime = p.sentTime
+	}
+	b.updateMinRTT(eventTime)
+	b.updateRecovery(packetNumber)
+
+	bytesInFlight := priorInFlight - ackedBytes
+	b.checkFullPipe(appLimited)
+	b.checkDrain(bytesInFlight, eventTime)
+	b.updateCyclePhase(bytesInFlight, eventTime)
+	b.checkProbeRTT(bytesInFlight, eventTime)
+
+	b.setPacingRate()
+	b.setCongestionWindow(bytesInFlight, ackedBytes)
+	b.traceState()
+}
+
+func (b *bbrSender) OnCongestionEvent(encLevel protocol.EncryptionLevel, packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	// ECN-CE marks are reported with lostBytes = 0. BBR (version 1) doesn't react to them.
+	if lostBytes == 0 {
+		return
+	}
+	b.packets.Remove(encLevel, packetNumber)
+	b.hadLossInCycle = true
+	bytesInFlight := priorInFlight - lostBytes
+	if !b.inRecovery {
+		// Enter loss recovery: use packet conservation for one round trip.
+		b.inRecovery = true
+		b.packetConservation = true
+		b.recoveryEndPacket = b.largestSentPacket
+		b.nextRoundDelivered = b.delivered
+		b.priorCongestionWindow = b.saveCongestionWindow()
+		b.congestionWindow = max(bytesInFlight+b.maxDatagramSize, b.minCongestionWindow())
+	} else {
+		b.congestionWindow = max(b.congestionWindow-min(b.congestionWindow, lostBytes), b.minCongestionWindow())
+	}
+	b.traceState()
+}
+
+func (b *bbrSender) OnPacketDiscarded(encLevel protocol.EncryptionLevel, packetNumber protocol.PacketNumber) {
+	b.packets.Remove(encLevel, packetNumber)
+}
+
+func (b *bbrSender) OnPacketsDropped(encLevel protocol.EncryptionLevel) {
+	b.packets.DropEncryptionLevel(encLevel)
+}
+
+// OnRetransmissionTimeout is called on an retransmission timeout
+func (b *bbrSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	if !packetsRetransmitted {
+		return
+	}
+	b.priorCongestionWindow = b.saveCongestionWindow()
+	b.congestionWindow = b.minCongestionWindow()
+}
+
+func (b *bbrSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	if s < b.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", b.maxDatagramSize, s))
+	}
+	cwndIsMinCwnd := b.congestionWindow == b.minCongestionWindow()
+	b.maxDatagramSize = s
+	if cwndIsMinCwnd {
+		b.congestionWindow = b.minCongestionWindow()
+	}
+	b.pacer.SetMaxDatagramSize(s)
+}
+
+// BandwidthEstimate returns the current estimate of the bottleneck bandwidth
+func (b *bbrSender) BandwidthEstimate() Bandwidth {
+	return b.maxBandwidth
+}
+
+func (b *bbrSender) minCongestionWindow() protocol.ByteCount {
+	return bbrMinCongestionWindowPackets * b.maxDatagramSize
+}
+
+func (b *bbrSender) updateRound(p bbrPacket) {
+	if p.delivered >= b.nextRoundDelivered {
+		b.nextRoundDelivered = b.delivered
+		b.roundCount++
+		b.roundStart = true
+		b.packetConservation = false
+	}
+}
+
+func (b *bbrSender) updateBandwidth(p bbrPacket, now time.Time) {
+	// The delivery rate is limited by both the send rate and the ACK rate.
+	interval := max(p.sentTime.Sub(p.firstSentTime), now.Sub(p.deliveredTime))
+	if interval <= 0 || interval < b.minRTT {
+		return
+	}
+	bw := BandwidthFromDelta(b.delivered-p.delivered, interval)
+	// An application-limited sample underestimates the bottleneck bandwidth,
+	// unless it exceeds the current estimate.
+	if p.appLimited && bw <= b.maxBandwidth {
+		return
+	}
+	idx := b.roundCount % bbrBandwidthFilterRounds
+	if b.bandwidthRounds[idx] != b.roundCount {
+		b.bandwidthRounds[idx] = b.roundCount
+		b.bandwidthSamples[idx] = 0
+	}
+	b.bandwidthSamples[idx] = max(b.bandwidthSamples[idx], bw)
+	b.maxBandwidth = 0
+	for i, sample := range b.bandwidthSamples {
+		if b.roundCount-b.bandwidthRounds[i] < bbrBandwidthFilterRounds {
+			b.maxBandwidth = max(b.maxBandwidth, sample)
+		}
+	}
+}
+
+func (b *bbrSender) updateMinRTT(now time.Time) {
+	b.minRTTExpired = !b.minRTTStamp.IsZero() && now.Sub(b.minRTTStamp) > bbrMinRTTFilterLength
+	rtt := b.rttStats.LatestRTT()
+	if rtt == 0 {
+		return
+	}
+	if b.minRTT == 0 || rtt <= b.minRTT || b.minRTTExpired {
+		b.minRTT = rtt
+		b.minRTTStamp = now
+	}
+}
+
+func (b *bbrSender) updateRecovery(packetNumber protocol.PacketNumber) {
+	if !b.inRecovery || packetNumber <= b.recoveryEndPacket {
+		return
+	}
+	// A packet sent after entering recovery was acknowledged.
+	b.inRecovery = false
+	b.packetConservation = false
+	b.congestionWindow = max(b.congestionWindow, b.priorCongestionWindow)
+}
+
+func (b *bbrSender) checkFullPipe(appLimited bool) {
+	// An application-limited sample doesn't tell if the bandwidth estimate would grow further.
+	if b.filledPipe || !b.roundStart || appLimited {
+		return
+	}
+	if float64(b.maxBandwidth) >= float64(b.fullBandwidth)*bbrStartupGrowthTarget {
+		b.fullBandwidth = b.maxBandwidth
+		b.fullBandwidthCount = 0
+		return
+	}
+	b.fullBandwidthCount++
+	if b.fullBandwidthCount >= bbrStartupFullBandwidthRounds {
+		b.filledPipe = true
+	}
+}
+
+func (b *bbrSender) checkDrain(bytesInFlight protocol.ByteCount, now time.Time) {
+	if b.mode == bbrModeStartup && b.filledPipe {
+		b.mode = bbrModeDrain
+		b.pacingGain = bbrDrainGain
+		b.cwndGain = bbrHighGain
+	}
+	if b.mode == bbrModeDrain && bytesInFlight <= b.inflight(1) {
+		b.enterProbeBW(now)
+	}
+}
+
+func (b *bbrSender) enterProbeBW(now time.Time) {
+	b.mode = bbrModeProbeBW
+	b.cwndGain = bbrCwndGain
+	// Start at a random phase, but never in the phase that drains the queue.
+	b.cycleIndex = len(bbrPacingGainCycle) - 1 - int(b.rand.Int31n(int32(len(bbrPacingGainCycle)-1)))
+	b.advanceCyclePhase(now)
+}
+
+func (b *bbrSender) advanceCyclePhase(now time.Time) {
+	b.cycleStamp = now
+	b.cycleIndex = (b.cycleIndex + 1) % len(bbrPacingGainCycle)
+	b.pacingGain = bbrPacingGainCycle[b.cycleIndex]
+	b.hadLossInCycle = false
+}
+
+func (b *bbrSender) updateCyclePhase(bytesInFlight protocol.ByteCount, now time.Time) {
+	if b.mode != bbrModeProbeBW {
+		return
+	}
+	isFullLength := now.Sub(b.cycleStamp) > b.minRTT
+	var advance bool
+	switch {
+	case b.pacingGain > 1:
+		// Probe until the queue is built up, or until losses occur.
+		advance = isFullLength && (b.hadLossInCycle || bytesInFlight >= b.inflight(b.pacingGain))
+	case b.pacingGain < 1:
+		// Drain until the queue created by probing is gone.
+		advance = isFullLength || bytesInFlight <= b.inflight(1)
+	default:
+		advance = isFullLength
+	}
+	if advance {
+		b.advanceCyclePhase(now)
+	}
+}
+
+func (b *bbrSender) checkProbeRTT(bytesInFlight protocol.ByteCount, now time.Time) {
+	if b.mode != bbrModeProbeRTT && b.minRTTExpired {
+		b.mode = bbrModeProbeRTT
+		b.pacingGain = 1
+		b.cwndGain = 1
+		b.priorCongestionWindow = b.saveCongestionWindow()
+		b.probeRTTDoneStamp = time.Time{}
+	}
+	if b.mode != bbrModeProbeRTT {
+		return
+	}
+	if b.probeRTTDoneStamp.IsZero() {
+		if bytesInFlight <= b.minCongestionWindow() {
+			b.probeRTTDoneStamp = now.Add(bbrProbeRTTDuration)
+			b.probeRTTRoundDone = false
+			b.nextRoundDelivered = b.delivered
+		}
+		return
+	}
+	if b.roundStart {
+		b.probeRTTRoundDone = true
+	}
+	if b.probeRTTRoundDone && now.After(b.probeRTTDoneStamp) {
+		b.minRTTStamp = now
+		b.congestionWindow = max(b.congestionWindow, b.priorCongestionWindow)
+		if b.filledPipe {
+			b.enterProbeBW(now)
+		} else {
+			b.mode = bbrModeStartup
+			b.pacingGain = bbrHighGain
+			b.cwndGain = bbrHighGain
+		}
+	}
+}
+
+func (b *bbrSender) saveCongestionWindow() protocol.ByteCount {
+	if !b.inRecovery && b.mode != bbrModeProbeRTT {
+		return b.congestionWindow
+	}
+	return max(b.priorCongestionWindow, b.congestionWindow)
+}
+
+// inflight returns the bandwidth-delay product, multiplied by the gain.
+func (b *bbrSender) inflight(gain float64) protocol.ByteCount {
+	if b.maxBandwidth == 0 || b.minRTT == 0 {
+		return b.initialCongestionWindow
+	}
+	bdp := float64(b.maxBandwidth/BytesPerSecond) * b.minRTT.Seconds()
+	return protocol.ByteCount(gain * bdp)
+}
+
+func (b *bbrSender) setPacingRate() {
+	if b.maxBandwidth == 0 {
+		return
+	}
+	rate := Bandwidth(b.pacingGain * float64(b.maxBandwidth))
+	// Don't reduce the pacing rate in Startup, before a bandwidth sample is available for every round trip.
+	if b.filledPipe || rate > b.pacingRate {
+		b.pacingRate = rate
+	}
+}
+
+func (b *bbrSender) setCongestionWindow(bytesInFlight, ackedBytes protocol.ByteCount) {
+	if b.mode == bbrModeProbeRTT {
+		b.congestionWindow = b.minCongestionWindow()
+		return
+	}
+	if b.packetConservation {
+		b.congestionWindow = max(b.congestionWindow, bytesInFlight+ackedBytes)
+		return
+	}
+	target := b.inflight(b.cwndGain) + bbrSendQuantumPackets*b.maxDatagramSize
+	if b.filledPipe {
+		b.congestionWindow = min(b.congestionWindow+ackedBytes, target)
+	} else if b.congestionWindow < target || b.delivered < b.initialCongestionWindow {
+		b.congestionWindow += ackedBytes
+	}
+	b.congestionWindow = min(max(b.congestionWindow, b.minCongestionWindow()), b.maxCongestionWindow)
+}
+
+func (b *bbrSender) traceState() {
+	if b.tracer == nil || b.tracer.UpdatedCongestionState == nil {
+		return
+	}
+	var state logging.CongestionState
+	switch {
+	case b.inRecovery:
+		state = logging.CongestionStateRecovery
+	case b.mode == bbrModeStartup:
+		state = logging.CongestionStateSlowStart
+	default:
+		state = logging.CongestionStateCongestionAvoidance
+	}
+	if state == b.lastState {
+		return
+	}
+	b.tracer.UpdatedCongestionState(state)
+	b.lastState = state
+}
@@ -0,0 +1,223 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BBR Sender", func() {
+	const (
+		packetSize = protocol.ByteCount(1200)
+		linkRate   = 10 * 1000 * 1000 * BitsPerSecond // 10 Mbit/s
+		baseRTT    = 40 * time.Millisecond
+	)
+
+	type sentPacket struct {
+		pn       protocol.PacketNumber
+		sendTime time.Time
+		ackTime  time.Time
+	}
+
+	var (
+		sender        *bbrSender
+		rttStats      *utils.RTTStats
+		now           time.Time
+		packetNumber  protocol.PacketNumber
+		bytesInFlight protocol.ByteCount
+		outstanding   []sentPacket
+		linkFree      time.Time
+	)
+
+	BeforeEach(func() {
+		rttStats = utils.NewRTTStats()
+		sender = NewBBRSender(rttStats, packetSize, nil)
+		now = time.Now()
+		packetNumber = 0
+		bytesInFlight = 0
+		outstanding = nil
+		linkFree = time.Time{}
+	})
+
+	sendPacket := func() protocol.PacketNumber {
+		packetNumber++
+		bytesInFlight += packetSize
+		sender.OnPacketSent(now, bytesInFlight, protocol.Encryption1RTT, packetNumber, packetSize, true)
+		// the packet is serialized at the bottleneck link, which delivers packets at linkRate
+		departure := now
+		if linkFree.After(departure) {
+			departure = linkFree
+		}
+		departure = departure.Add(time.Duration(packetSize) * time.Second / time.Duration(linkRate/BytesPerSecond))
+		linkFree = departure
+		outstanding = append(outstanding, sentPacket{pn: packetNumber, sendTime: now, ackTime: departure.Add(baseRTT)})
+		return packetNumber
+	}
+
+	receiveAcks := func(onAck func()) {
+		for len(outstanding) > 0 && !outstanding[0].ackTime.After(now) {
+			p := outstanding[0]
+			outstanding = outstanding[1:]
+			rttStats.UpdateRTT(now.Sub(p.sendTime), 0, now)
+			sender.OnPacketAcked(protocol.Encryption1RTT, p.pn, packetSize, bytesInFlight, now)
+			bytesInFlight -= packetSize
+			if onAck != nil {
+				onAck()
+			}
+		}
+	}
+
+	// simulate runs a bulk transfer over a link with a bandwidth of linkRate and a minimum RTT of baseRTT
+	simulate := func(d time.Duration, onAck func()) {
+		end := now.Add(d)
+		for now.Before(end) {
+			for sender.CanSend(bytesInFlight) && sender.HasPacingBudget(now) {
+				sendPacket()
+			}
+			next := outstanding[0].ackTime
+			if sender.CanSend(bytesInFlight) {
+				if t := sender.TimeUntilSend(bytesInFlight); t.After(now) && t.Before(next) {
+					next = t
+				}
+			}
+			now = next
+			receiveAcks(onAck)
+		}
+	}
+
+	// simulateAppLimited runs a transfer where the application sends one packet every interval
+	simulateAppLimited := func(d, interval time.Duration) {
+		end := now.Add(d)
+		nextSend := now
+		for now.Before(end) {
+			if !now.Before(nextSend) {
+				if sender.CanSend(bytesInFlight) {
+					sendPacket()
+				}
+				nextSend = nextSend.Add(interval)
+			}
+			next := nextSend
+			if len(outstanding) > 0 && outstanding[0].ackTime.Before(next) {
+				next = outstanding[0].ackTime
+			}
+			now = next
+			receiveAcks(nil)
+		}
+	}
+
+	It("starts in Startup", func() {
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * packetSize))
+		Expect(sender.BandwidthEstimate()).To(BeZero())
+	})
+
+	It("estimates the bottleneck bandwidth and the minimum RTT", func() {
+		simulate(3*time.Second, nil)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", linkRate, linkRate/10))
+		Expect(sender.minRTT).To(BeNumerically("~", baseRTT, time.Millisecond))
+		bdp := protocol.ByteCount(linkRate/BytesPerSecond) * protocol.ByteCount(baseRTT) / protocol.ByteCount(time.Second)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("~", 2*bdp, bdp/2))
+	})
+
+	It("stays in Startup when the sender is application-limited", func() {
+		const appRate = linkRate / 10
+		interval := time.Duration(packetSize) * time.Second / time.Duration(appRate/BytesPerSecond)
+		simulateAppLimited(2*time.Second, interval)
+		Expect(sender.roundCount).To(BeNumerically(">", 3*bbrStartupFullBandwidthRounds))
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", appRate, appRate/5))
+		// once the application sends as fast as possible, the bandwidth estimate grows to the bottleneck bandwidth
+		simulate(3*time.Second, nil)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", linkRate, linkRate/10))
+	})
+
+	It("doesn't build up a large queue", func() {
+		simulate(2*time.Second, nil)
+		var maxRTT time.Duration
+		simulate(5*time.Second, func() { maxRTT = max(maxRTT, rttStats.LatestRTT()) })
+		Expect(maxRTT).To(BeNumerically("<", 3*baseRTT/2))
+	})
+
+	It("enters ProbeRTT when the minimum RTT estimate expires", func() {
+		simulate(2*time.Second, nil)
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		// make sure the minimum RTT isn't refreshed by a new minimum
+		sender.minRTT = baseRTT / 2
+		var enteredProbeRTT bool
+		simulate(bbrMinRTTFilterLength+time.Second, func() {
+			if sender.mode == bbrModeProbeRTT {
+				enteredProbeRTT = true
+				Expect(sender.GetCongestionWindow()).To(Equal(bbrMinCongestionWindowPackets * packetSize))
+			}
+		})
+		Expect(enteredProbeRTT).To(BeTrue())
+		simulate(time.Second, nil)
+		Expect(sender.mode).To(Equal(bbrModeProbeBW))
+		Expect(sender.minRTT).To(BeNumerically("~", baseRTT, time.Millisecond))
+	})
+
+	It("uses packet conservation during loss recovery", func() {
+		simulate(2*time.Second, nil)
+		cwnd := sender.GetCongestionWindow()
+		lost := outstanding[0]
+		outstanding = outstanding[1:]
+		sender.OnCongestionEvent(protocol.Encryption1RTT, lost.pn, packetSize, bytesInFlight)
+		bytesInFlight -= packetSize
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(sender.GetCongestionWindow()).To(Equal(bytesInFlight + packetSize))
+		// recovery ends once a packet sent after the loss is acknowledged
+		pn := sendPacket()
+		simulate(baseRTT, nil)
+		Expect(sender.InRecovery()).To(BeTrue())
+		simulate(2*baseRTT, nil)
+		Expect(outstanding[0].pn).To(BeNumerically(">", pn))
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">=", cwnd))
+	})
+
+	It("doesn't react to ECN-CE marks", func() {
+		simulate(2*time.Second, nil)
+		cwnd := sender.GetCongestionWindow()
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[0].pn, 0, bytesInFlight)
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+	})
+
+	It("keeps track of packets per encryption level", func() {
+		sender.OnPacketSent(now, packetSize, protocol.EncryptionInitial, 0, packetSize, true)
+		sender.OnPacketSent(now, 2*packetSize, protocol.EncryptionHandshake, 0, packetSize, true)
+		sender.OnPacketSent(now, 3*packetSize, protocol.Encryption1RTT, 0, packetSize, true)
+		sender.OnPacketSent(now, 4*packetSize, protocol.Encryption1RTT, 1, packetSize, true)
+		Expect(sender.packets.Len()).To(Equal(4))
+		sender.OnPacketAcked(protocol.EncryptionInitial, 0, packetSize, 4*packetSize, now.Add(baseRTT))
+		Expect(sender.packets.Len()).To(Equal(3))
+		sender.OnPacketDiscarded(protocol.Encryption1RTT, 1)
+		Expect(sender.packets.Len()).To(Equal(2))
+		sender.OnPacketsDropped(protocol.EncryptionHandshake)
+		Expect(sender.packets.Len()).To(Equal(1))
+		sender.OnCongestionEvent(protocol.Encryption1RTT, 0, packetSize, 2*packetSize)
+		Expect(sender.packets.Len()).To(BeZero())
+	})
+
+	It("reduces the congestion window on a retransmission timeout", func() {
+		sender.OnRetransmissionTimeout(false)
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * packetSize))
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinCongestionWindowPackets * packetSize))
+	})
+
+	It("adjusts the minimum congestion window when the max datagram size changes", func() {
+		sender.OnRetransmissionTimeout(true)
+		sender.SetMaxDatagramSize(1400)
+		Expect(sender.GetCongestionWindow()).To(Equal(bbrMinCongestionWindowPackets * protocol.ByteCount(1400)))
+		Expect(func() { sender.SetMaxDatagramSize(1300) }).To(Panic())
+	})
+})
@@ -0,0 +1,151 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// This implements Careful Resume (draft-ietf-tsvwg-careful-resume).
+// The congestion window and the RTT of a previous connection to the same peer are used to
+// skip (part of) slow start. The saved congestion window is only used after confirming that the RTT
+// didn't change significantly, and the sender retreats to a safe congestion window if packets sent
+// using the saved state are lost.
+
+type carefulResumePhase uint8
+
+const (
+	// The sender uses the initial congestion window, and measures the RTT.
+	carefulResumeReconnaissance carefulResumePhase = iota
+	// The sender uses the jump window, i.e. half the saved congestion window.
+	carefulResumeUnvalidated
+	// The sender waits for packets sent in the Unvalidated phase to be acknowledged.
+	carefulResumeValidating
+	// Packets sent in the Unvalidated phase were lost.
+	carefulResumeSafeRetreat
+	// Careful Resume is not (or no longer) used.
+	carefulResumeNormal
+)
+
+type carefulResume struct {
+	phase carefulResumePhase
+
+	savedRTT              time.Duration
+	savedCongestionWindow protocol.ByteCount
+
+	// the number of bytes acknowledged since entering the Unvalidated phase
+	pipeSize protocol.ByteCount
+	// the range of packets sent in the Unvalidated phase
+	firstUnvalidatedPacket, lastUnvalidatedPacket protocol.PacketNumber
+}
+
+var _ CarefulResumeSendAlgorithm = &cubicSender{}
+
+// SetResumeState sets the RTT and the congestion window of a previous connection.
+func (c *cubicSender) SetResumeState(savedRTT time.Duration, savedCongestionWindow protocol.ByteCount) {
+	if savedRTT <= 0 || savedCongestionWindow <= c.congestionWindow {
+		return
+	}
+	c.resume = &carefulResume{
+		phase:                  carefulResumeReconnaissance,
+		savedRTT:               savedRTT,
+		savedCongestionWindow:  savedCongestionWindow,
+		firstUnvalidatedPacket: protocol.InvalidPacketNumber,
+		lastUnvalidatedPacket:  protocol.InvalidPacketNumber,
+	}
+}
+
+func (c *cubicSender) carefulResumePhase() carefulResumePhase {
+	if c.resume == nil {
+		return carefulResumeNormal
+	}
+	return c.resume.phase
+}
+
+func (c *cubicSender) carefulResumeOnPacketSent(pn protocol.PacketNumber) {
+	if c.carefulResumePhase() != carefulResumeUnvalidated {
+		return
+	}
+	if c.resume.firstUnvalidatedPacket == protocol.InvalidPacketNumber {
+		c.resume.firstUnvalidatedPacket = pn
+	}
+	c.resume.lastUnvalidatedPacket = pn
+}
+
+// carefulResumeOnPacketAcked is called for every acknowledged packet.
+// It returns true if the congestion window must not be increased.
+func (c *cubicSender) carefulResumeOnPacketAcked(pn protocol.PacketNumber, ackedBytes, priorInFlight protocol.ByteCount) bool {
+	r := c.resume
+	switch c.carefulResumePhase() {
+	case carefulResumeReconnaissance:
+		// Wait until the sender has more data to send than the initial congestion window allows.
+		if !c.isCwndLimited(priorInFlight) {
+			return false
+		}
+		// The path might have changed if the RTT changed significantly.
+		rtt := c.rttStats.MinRTT()
+		jumpWindow := min(r.savedCongestionWindow/2, c.maxCongestionWindow())
+		if rtt == 0 || rtt < r.savedRTT/2 || rtt >= 10*r.savedRTT || jumpWindow <= c.congestionWindow {
+			c.setCarefulResumePhase(carefulResumeNormal)
+			return false
+		}
+		c.setCarefulResumePhase(carefulResumeUnvalidated)
+		r.pipeSize = priorInFlight
+		c.congestionWindow = jumpWindow
+		return true
+	case carefulResumeUnvalidated:
+		r.pipeSize += ackedBytes
+		if r.firstUnvalidatedPacket == protocol.InvalidPacketNumber || pn < r.firstUnvalidatedPacket {
+			return true
+		}
+		// The first packet sent using the jump window was acknowledged.
+		// Only keep the part of the jump window that was actually used.
+		c.setCarefulResumePhase(carefulResumeValidating)
+		c.congestionWindow = max(min(c.congestionWindow, priorInFlight), c.minCongestionWindow())
+		return c.carefulResumeOnPacketAcked(pn, 0, priorInFlight)
+	case carefulResumeValidating:
+		r.pipeSize += ackedBytes
+		if pn >= r.lastUnvalidatedPacket {
+			c.setCarefulResumePhase(carefulResumeNormal)
+		}
+		return false
+	case carefulResumeSafeRetreat:
+		if pn >= r.lastUnvalidatedPacket {
+			c.setCarefulResumePhase(carefulResumeNormal)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// carefulResumeOnCongestionEvent is called when a packet is lost or CE-marked.
+// It returns true if the congestion event was handled.
+func (c *cubicSender) carefulResumeOnCongestionEvent() bool {
+	switch c.carefulResumePhase() {
+	case carefulResumeReconnaissance:
+		c.setCarefulResumePhase(carefulResumeNormal)
+		return false
+	case carefulResumeUnvalidated, carefulResumeValidating:
+		// Only retreat to the capacity that was actually validated.
+		c.setCarefulResumePhase(carefulResumeSafeRetreat)
+		c.congestionWindow = max(c.resume.pipeSize/2, c.minCongestionWindow())
+		c.slowStartThreshold = c.congestionWindow
+		c.largestSentAtLastCutback = c.largestSentPacketNumber
+		c.numAckedPackets = 0
+		c.maybeTraceStateChange(logging.CongestionStateRecovery)
+		return true
+	default:
+		// In the Safe Retreat phase, losses of packets sent in the Unvalidated phase
+		// are ignored, since the sender is in recovery until all of them are acknowledged.
+		return false
+	}
+}
+
+func (c *cubicSender) setCarefulResumePhase(phase carefulResumePhase) {
+	c.resume.phase = phase
+	if phase == carefulResumeNormal {
+		c.resume = nil
+	}
+}
@@ -0,0 +1,175 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Careful Resume", func() {
+	const (
+		rtt       = 50 * time.Millisecond
+		savedCwnd = 400 * maxDatagramSize
+	)
+
+	var (
+		sender        *cubicSender
+		clock         mockClock
+		rttStats      *utils.RTTStats
+		packetNumber  protocol.PacketNumber
+		bytesInFlight protocol.ByteCount
+		outstanding   []protocol.PacketNumber
+	)
+
+	BeforeEach(func() {
+		clock = mockClock{}
+		rttStats = utils.NewRTTStats()
+		sender = newCubicSender(&clock, rttStats, true, false, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow*4, nil)
+		packetNumber = 0
+		bytesInFlight = 0
+		outstanding = nil
+	})
+
+	sendAvailableWindow := func() {
+		for sender.CanSend(bytesInFlight) {
+			packetNumber++
+			sender.OnPacketSent(clock.Now(), bytesInFlight, protocol.Encryption1RTT, packetNumber, maxDatagramSize, true)
+			bytesInFlight += maxDatagramSize
+			outstanding = append(outstanding, packetNumber)
+		}
+	}
+
+	ackPackets := func(n int) {
+		rttStats.UpdateRTT(rtt, 0, clock.Now())
+		sender.MaybeExitSlowStart()
+		for i := 0; i < n; i++ {
+			sender.OnPacketAcked(protocol.Encryption1RTT, outstanding[0], maxDatagramSize, bytesInFlight, clock.Now())
+			outstanding = outstanding[1:]
+			bytesInFlight -= maxDatagramSize
+		}
+	}
+
+	It("doesn't use careful resume if the saved congestion window is small", func() {
+		sender.SetResumeState(rtt, initialCongestionWindowPackets*maxDatagramSize)
+		Expect(sender.resume).To(BeNil())
+	})
+
+	It("jumps to half the saved congestion window, and validates it", func() {
+		sender.SetResumeState(rtt, savedCwnd)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeReconnaissance))
+		sendAvailableWindow()
+		clock.Advance(rtt)
+		ackPackets(1)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeUnvalidated))
+		Expect(sender.GetCongestionWindow()).To(Equal(savedCwnd / 2))
+		// the congestion window doesn't grow in the Unvalidated phase
+		ackPackets(1)
+		Expect(sender.GetCongestionWindow()).To(Equal(savedCwnd / 2))
+		sendAvailableWindow()
+		Expect(bytesInFlight).To(Equal(savedCwnd / 2))
+		lastUnvalidated := packetNumber
+		// acknowledge the remaining packets sent in the Reconnaissance phase
+		ackPackets(initialCongestionWindowPackets - 2)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeUnvalidated))
+		clock.Advance(rtt)
+		ackPackets(1)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeValidating))
+		for outstanding[0] < lastUnvalidated {
+			ackPackets(1)
+			Expect(sender.carefulResumePhase()).To(Equal(carefulResumeValidating))
+		}
+		ackPackets(1)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeNormal))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">", savedCwnd/2))
+		Expect(sender.InSlowStart()).To(BeTrue())
+	})
+
+	It("reduces the congestion window to the flight size when entering the Validating phase", func() {
+		sender.SetResumeState(rtt, savedCwnd)
+		sendAvailableWindow()
+		clock.Advance(rtt)
+		ackPackets(1)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeUnvalidated))
+		// the application only sends a few packets
+		for i := 0; i < 20; i++ {
+			packetNumber++
+			sender.OnPacketSent(clock.Now(), bytesInFlight, protocol.Encryption1RTT, packetNumber, maxDatagramSize, true)
+			bytesInFlight += maxDatagramSize
+			outstanding = append(outstanding, packetNumber)
+		}
+		ackPackets(initialCongestionWindowPackets - 1)
+		clock.Advance(rtt)
+		flightSize := bytesInFlight
+		ackPackets(1)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeValidating))
+		Expect(sender.GetCongestionWindow()).To(Equal(flightSize + maxDatagramSize))
+	})
+
+	for _, r := range []time.Duration{rtt/2 - time.Millisecond, 10 * rtt} {
+		currentRTT := r
+
+		It("doesn't jump if the RTT changed significantly: "+currentRTT.String(), func() {
+			sender.SetResumeState(rtt, savedCwnd)
+			sendAvailableWindow()
+			rttStats.UpdateRTT(currentRTT, 0, clock.Now())
+			sender.OnPacketAcked(protocol.Encryption1RTT, outstanding[0], maxDatagramSize, bytesInFlight, clock.Now())
+			Expect(sender.carefulResumePhase()).To(Equal(carefulResumeNormal))
+			Expect(sender.GetCongestionWindow()).To(Equal((initialCongestionWindowPackets + 1) * maxDatagramSize))
+		})
+	}
+
+	It("retreats if packets sent in the Unvalidated phase are lost", func() {
+		sender.SetResumeState(rtt, savedCwnd)
+		sendAvailableWindow()
+		clock.Advance(rtt)
+		ackPackets(initialCongestionWindowPackets / 2)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeUnvalidated))
+		sendAvailableWindow()
+		lastUnvalidated := packetNumber
+		ackPackets(initialCongestionWindowPackets / 2)
+		pipeSize := sender.resume.pipeSize
+		// the flight size when entering the Unvalidated phase, plus the bytes acknowledged since then
+		Expect(pipeSize).To(Equal((2*initialCongestionWindowPackets - 1) * maxDatagramSize))
+		// lose a packet sent in the Unvalidated phase
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[0], maxDatagramSize, bytesInFlight)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeSafeRetreat))
+		Expect(sender.GetCongestionWindow()).To(Equal(pipeSize / 2))
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.InRecovery()).To(BeTrue())
+		cwnd := sender.GetCongestionWindow()
+		// further losses don't reduce the congestion window
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[1], maxDatagramSize, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		outstanding = outstanding[2:]
+		bytesInFlight -= 2 * maxDatagramSize
+		for outstanding[0] < lastUnvalidated {
+			ackPackets(1)
+		}
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeSafeRetreat))
+		ackPackets(1)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeNormal))
+	})
+
+	It("stops using careful resume if packets are lost in the Reconnaissance phase", func() {
+		sender.SetResumeState(rtt, savedCwnd)
+		sendAvailableWindow()
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[0], maxDatagramSize, bytesInFlight)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeNormal))
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(renoBeta * float32(initialCongestionWindowPackets*maxDatagramSize))))
+	})
+
+	It("stops using careful resume on a retransmission timeout", func() {
+		sender.SetResumeState(rtt, savedCwnd)
+		sendAvailableWindow()
+		clock.Advance(rtt)
+		ackPackets(1)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeUnvalidated))
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.carefulResumePhase()).To(Equal(carefulResumeNormal))
+		Expect(sender.GetCongestionWindow()).To(Equal(sender.minCongestionWindow()))
+	})
+})
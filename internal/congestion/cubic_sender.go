@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
-	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/logging"
 )
 
@@ -19,12 +18,23 @@ const (
 	initialCongestionWindow    = 32
 )
 
+// A slowStartAlgorithm decides when to exit slow start before a packet is lost.
+type slowStartAlgorithm interface {
+	OnPacketSent(protocol.PacketNumber)
+	OnPacketAcked(protocol.PacketNumber)
+	ShouldExitSlowStart(latestRTT, minRTT time.Duration, congestionWindow protocol.ByteCount) bool
+	// GrowthDivisor is the factor by which the growth of the congestion window in slow start is reduced.
+	GrowthDivisor() protocol.ByteCount
+	Started() bool
+	Restart()
+}
+
 type cubicSender struct {
-	hybridSlowStart HybridSlowStart
-	rttStats        *utils.RTTStats
-	cubic           *Cubic
-	pacer           *pacer
-	clock           Clock
+	slowStart slowStartAlgorithm
+	rttStats  RTTStats
+	cubic     *Cubic
+	pacer     *pacer
+	clock     Clock
 
 	reno bool
 
@@ -50,6 +60,9 @@ type cubicSender struct {
 	// ACK counter for the Reno implementation.
 	numAckedPackets uint64
 
+	// only set when using the congestion state of a previous connection
+	resume *carefulResume
+
 	initialCongestionWindow    protocol.ByteCount
 	initialMaxCongestionWindow protocol.ByteCount
 
@@ -64,18 +77,21 @@ var (
 	_ SendAlgorithmWithDebugInfos = &cubicSender{}
 )
 
-// NewCubicSender makes a new cubic sender
+// NewCubicSender makes a new cubic sender.
+// If hystartPlusPlus is set, HyStart++ (RFC 9406) is used instead of HyStart to exit slow start.
 func NewCubicSender(
 	clock Clock,
-	rttStats *utils.RTTStats,
+	rttStats RTTStats,
 	initialMaxDatagramSize protocol.ByteCount,
 	reno bool,
+	hystartPlusPlus bool,
 	tracer *logging.ConnectionTracer,
 ) *cubicSender {
 	return newCubicSender(
 		clock,
 		rttStats,
 		reno,
+		hystartPlusPlus,
 		initialMaxDatagramSize,
 		initialCongestionWindow*initialMaxDatagramSize,
 		protocol.MaxCongestionWindowPackets*initialMaxDatagramSize,
@@ -85,8 +101,9 @@ func NewCubicSender(
 
 func newCubicSender(
 	clock Clock,
-	rttStats *utils.RTTStats,
+	rttStats RTTStats,
 	reno bool,
+	hystartPlusPlus bool,
 	initialMaxDatagramSize,
 	initialCongestionWindow,
 	initialMaxCongestionWindow protocol.ByteCount,
@@ -107,6 +124,11 @@ func newCubicSender(
 		tracer:                     tracer,
 		maxDatagramSize:            initialMaxDatagramSize,
 	}
+	if hystartPlusPlus {
+		c.slowStart = &HyStartPlusPlus{}
+	} else {
+		c.slowStart = &HybridSlowStart{}
+	}
 	c.pacer = newPacer(c.BandwidthEstimate)
 	if c.tracer != nil && c.tracer.UpdatedCongestionState != nil {
 		c.lastState = logging.CongestionStateSlowStart
@@ -135,6 +157,7 @@ func (c *cubicSender) minCongestionWindow() protocol.ByteCount {
 func (c *cubicSender) OnPacketSent(
 	sentTime time.Time,
 	_ protocol.ByteCount,
+	_ protocol.EncryptionLevel,
 	packetNumber protocol.PacketNumber,
 	bytes protocol.ByteCount,
 	isRetransmittable bool,
@@ -144,7 +167,8 @@ func (c *cubicSender) OnPacketSent(
 		return
 	}
 	c.largestSentPacketNumber = packetNumber
-	c.hybridSlowStart.OnPacketSent(packetNumber)
+	c.slowStart.OnPacketSent(packetNumber)
+	c.carefulResumeOnPacketSent(packetNumber)
 }
 
 func (c *cubicSender) CanSend(bytesInFlight protocol.ByteCount) bool {
@@ -165,7 +189,7 @@ func (c *cubicSender) GetCongestionWindow() protocol.ByteCount {
 
 func (c *cubicSender) MaybeExitSlowStart() {
 	if c.InSlowStart() &&
-		c.hybridSlowStart.ShouldExitSlowStart(c.rttStats.LatestRTT(), c.rttStats.MinRTT(), c.GetCongestionWindow()/c.maxDatagramSize) {
+		c.slowStart.ShouldExitSlowStart(c.rttStats.LatestRTT(), c.rttStats.MinRTT(), c.GetCongestionWindow()/c.maxDatagramSize) {
 		// exit slow start
 		c.slowStartThreshold = c.congestionWindow
 		c.maybeTraceStateChange(logging.CongestionStateCongestionAvoidance)
@@ -173,27 +197,34 @@ func (c *cubicSender) MaybeExitSlowStart() {
 }
 
 func (c *cubicSender) OnPacketAcked(
+	_ protocol.EncryptionLevel,
 	ackedPacketNumber protocol.PacketNumber,
 	ackedBytes protocol.ByteCount,
 	priorInFlight protocol.ByteCount,
 	eventTime time.Time,
 ) {
 	c.largestAckedPacketNumber = max(ackedPacketNumber, c.largestAckedPacketNumber)
+	if c.carefulResumeOnPacketAcked(ackedPacketNumber, ackedBytes, priorInFlight) {
+		return
+	}
 	if c.InRecovery() {
 		return
 	}
 	c.maybeIncreaseCwnd(ackedPacketNumber, ackedBytes, priorInFlight, eventTime)
 	if c.InSlowStart() {
-		c.hybridSlowStart.OnPacketAcked(ackedPacketNumber)
+		c.slowStart.OnPacketAcked(ackedPacketNumber)
 	}
 }
 
-func (c *cubicSender) OnCongestionEvent(packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+func (c *cubicSender) OnCongestionEvent(_ protocol.EncryptionLevel, packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
 	// TCP NewReno (RFC6582) says that once a loss occurs, any losses in packets
 	// already sent should be treated as a single loss event, since it's expected.
 	if packetNumber <= c.largestSentAtLastCutback {
 		return
 	}
+	if c.carefulResumeOnCongestionEvent() {
+		return
+	}
 	c.lastCutbackExitedSlowstart = c.InSlowStart()
 	c.maybeTraceStateChange(logging.CongestionStateRecovery)
 
@@ -232,7 +263,8 @@ func (c *cubicSender) maybeIncreaseCwnd(
 	}
 	if c.InSlowStart() {
 		// TCP slow start, exponential growth, increase by one for each ACK.
-		c.congestionWindow += c.maxDatagramSize
+		// HyStart++ slows down the growth during Conservative Slow Start.
+		c.congestionWindow += c.maxDatagramSize / c.slowStart.GrowthDivisor()
 		c.maybeTraceStateChange(logging.CongestionStateSlowStart)
 		return
 	}
@@ -270,13 +302,20 @@ func (c *cubicSender) BandwidthEstimate() Bandwidth {
 	return BandwidthFromDelta(c.GetCongestionWindow(), srtt)
 }
 
+// OnPacketDiscarded is a no-op: the cubic sender doesn't keep any state for sent packets.
+func (c *cubicSender) OnPacketDiscarded(protocol.EncryptionLevel, protocol.PacketNumber) {}
+
+// OnPacketsDropped is a no-op: the cubic sender doesn't keep any state for sent packets.
+func (c *cubicSender) OnPacketsDropped(protocol.EncryptionLevel) {}
+
 // OnRetransmissionTimeout is called on an retransmission timeout
 func (c *cubicSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
 	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
 	if !packetsRetransmitted {
 		return
 	}
-	c.hybridSlowStart.Restart()
+	c.resume = nil
+	c.slowStart.Restart()
 	c.cubic.Reset()
 	c.slowStartThreshold = c.congestionWindow / 2
 	c.congestionWindow = c.minCongestionWindow()
@@ -284,13 +323,14 @@ func (c *cubicSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
 
 // OnConnectionMigration is called when the connection is migrated (?)
 func (c *cubicSender) OnConnectionMigration() {
-	c.hybridSlowStart.Restart()
+	c.slowStart.Restart()
 	c.largestSentPacketNumber = protocol.InvalidPacketNumber
 	c.largestAckedPacketNumber = protocol.InvalidPacketNumber
 	c.largestSentAtLastCutback = protocol.InvalidPacketNumber
 	c.lastCutbackExitedSlowstart = false
 	c.cubic.Reset()
 	c.numAckedPackets = 0
+	c.resume = nil
 	c.congestionWindow = c.initialCongestionWindow
 	c.slowStartThreshold = c.initialMaxCongestionWindow
 }
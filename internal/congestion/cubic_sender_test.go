@@ -28,6 +28,60 @@ func (c *mockClock) Advance(d time.Duration) {
 const MaxCongestionWindow = 200 * maxDatagramSize
 
 var _ = Describe("Cubic Sender", func() {
+	testCubicSender(false)
+})
+
+var _ = Describe("Cubic Sender, using HyStart++", func() {
+	testCubicSender(true)
+
+	It("doesn't exit slow start on a transient RTT increase", func() {
+		runSlowStart := func(hystartPlusPlus bool) *cubicSender {
+			var clock mockClock
+			rttStats := utils.NewRTTStats()
+			sender := newCubicSender(&clock, rttStats, false, hystartPlusPlus, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
+			var pn protocol.PacketNumber
+			for round := 0; round < 5; round++ {
+				rtt := 60 * time.Millisecond
+				if round == 2 {
+					rtt = 80 * time.Millisecond
+				}
+				first := pn + 1
+				var bytesInFlight protocol.ByteCount
+				for sender.CanSend(bytesInFlight) {
+					pn++
+					sender.OnPacketSent(clock.Now(), bytesInFlight, protocol.Encryption1RTT, pn, maxDatagramSize, true)
+					bytesInFlight += maxDatagramSize
+				}
+				clock.Advance(rtt)
+				for p := first; p <= pn; p++ {
+					rttStats.UpdateRTT(rtt, 0, clock.Now())
+					sender.MaybeExitSlowStart()
+					sender.OnPacketAcked(protocol.Encryption1RTT, p, maxDatagramSize, bytesInFlight, clock.Now())
+					bytesInFlight -= maxDatagramSize
+				}
+			}
+			return sender
+		}
+
+		hystart := runSlowStart(false)
+		Expect(hystart.InSlowStart()).To(BeFalse())
+		hystartPlusPlus := runSlowStart(true)
+		Expect(hystartPlusPlus.InSlowStart()).To(BeTrue())
+		Expect(hystartPlusPlus.GetCongestionWindow()).To(BeNumerically(">", hystart.GetCongestionWindow()))
+	})
+
+	It("slows down the growth of the congestion window in Conservative Slow Start", func() {
+		var clock mockClock
+		sender := newCubicSender(&clock, utils.NewRTTStats(), false, true, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
+		sender.slowStart.(*HyStartPlusPlus).inCSS = true
+		cwnd := sender.GetCongestionWindow()
+		sender.OnPacketAcked(protocol.Encryption1RTT, 1, maxDatagramSize, cwnd, clock.Now())
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd + maxDatagramSize/hystartPPCSSGrowthDivisor))
+	})
+})
+
+// testCubicSender runs the tests that apply to both HyStart and HyStart++.
+func testCubicSender(hystartPlusPlus bool) {
 	var (
 		sender            *cubicSender
 		clock             mockClock
@@ -47,6 +101,7 @@ var _ = Describe("Cubic Sender", func() {
 			&clock,
 			rttStats,
 			true, /*reno*/
+			hystartPlusPlus,
 			protocol.InitialPacketSize,
 			initialCongestionWindowPackets*maxDatagramSize,
 			MaxCongestionWindow,
@@ -57,7 +112,7 @@ var _ = Describe("Cubic Sender", func() {
 	SendAvailableSendWindowLen := func(packetLength protocol.ByteCount) int {
 		var packetsSent int
 		for sender.CanSend(bytesInFlight) {
-			sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, packetLength, true)
+			sender.OnPacketSent(clock.Now(), bytesInFlight, protocol.Encryption1RTT, packetNumber, packetLength, true)
 			packetNumber++
 			packetsSent++
 			bytesInFlight += packetLength
@@ -71,7 +126,7 @@ var _ = Describe("Cubic Sender", func() {
 		sender.MaybeExitSlowStart()
 		for i := 0; i < n; i++ {
 			ackedPacketNumber++
-			sender.OnPacketAcked(ackedPacketNumber, maxDatagramSize, bytesInFlight, clock.Now())
+			sender.OnPacketAcked(protocol.Encryption1RTT, ackedPacketNumber, maxDatagramSize, bytesInFlight, clock.Now())
 		}
 		bytesInFlight -= protocol.ByteCount(n) * maxDatagramSize
 		clock.Advance(time.Millisecond)
@@ -80,14 +135,14 @@ var _ = Describe("Cubic Sender", func() {
 	LoseNPacketsLen := func(n int, packetLength protocol.ByteCount) {
 		for i := 0; i < n; i++ {
 			ackedPacketNumber++
-			sender.OnCongestionEvent(ackedPacketNumber, packetLength, bytesInFlight)
+			sender.OnCongestionEvent(protocol.Encryption1RTT, ackedPacketNumber, packetLength, bytesInFlight)
 		}
 		bytesInFlight -= protocol.ByteCount(n) * packetLength
 	}
 
 	// Does not increment acked_packet_number_.
 	LosePacket := func(number protocol.PacketNumber) {
-		sender.OnCongestionEvent(number, maxDatagramSize, bytesInFlight)
+		sender.OnCongestionEvent(protocol.Encryption1RTT, number, maxDatagramSize, bytesInFlight)
 		bytesInFlight -= maxDatagramSize
 	}
 
@@ -192,9 +247,9 @@ var _ = Describe("Cubic Sender", func() {
 		Expect(sender.GetCongestionWindow()).To(Equal(expectedSendWindow))
 
 		// Now RTO and ensure slow start gets reset.
-		Expect(sender.hybridSlowStart.Started()).To(BeTrue())
+		Expect(sender.slowStart.Started()).To(BeTrue())
 		sender.OnRetransmissionTimeout(true)
-		Expect(sender.hybridSlowStart.Started()).To(BeFalse())
+		Expect(sender.slowStart.Started()).To(BeFalse())
 	})
 
 	It("slow start packet loss PRR", func() {
@@ -319,7 +374,7 @@ var _ = Describe("Cubic Sender", func() {
 	It("tcp cubic reset epoch on quiescence", func() {
 		const maxCongestionWindow = 50
 		const maxCongestionWindowBytes = maxCongestionWindow * maxDatagramSize
-		sender = newCubicSender(&clock, rttStats, false, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, maxCongestionWindowBytes, nil)
+		sender = newCubicSender(&clock, rttStats, false, hystartPlusPlus, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, maxCongestionWindowBytes, nil)
 
 		numSent := SendAvailableSendWindow()
 
@@ -455,16 +510,16 @@ var _ = Describe("Cubic Sender", func() {
 		sender.OnConnectionMigration()
 		Expect(sender.GetCongestionWindow()).To(Equal(defaultWindowTCP))
 		Expect(sender.slowStartThreshold).To(Equal(MaxCongestionWindow))
-		Expect(sender.hybridSlowStart.Started()).To(BeFalse())
+		Expect(sender.slowStart.Started()).To(BeFalse())
 	})
 
 	It("slow starts up to the maximum congestion window", func() {
 		const initialMaxCongestionWindow = protocol.MaxCongestionWindowPackets * initialMaxDatagramSize
-		sender = newCubicSender(&clock, rttStats, true, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, true, hystartPlusPlus, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
 
 		for i := 1; i < protocol.MaxCongestionWindowPackets; i++ {
 			sender.MaybeExitSlowStart()
-			sender.OnPacketAcked(protocol.PacketNumber(i), 1350, sender.GetCongestionWindow(), clock.Now())
+			sender.OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(i), 1350, sender.GetCongestionWindow(), clock.Now())
 		}
 		Expect(sender.GetCongestionWindow()).To(Equal(initialMaxCongestionWindow))
 	})
@@ -475,11 +530,11 @@ var _ = Describe("Cubic Sender", func() {
 
 	It("slow starts up to maximum congestion window, if larger packets are sent", func() {
 		const initialMaxCongestionWindow = protocol.MaxCongestionWindowPackets * initialMaxDatagramSize
-		sender = newCubicSender(&clock, rttStats, true, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, true, hystartPlusPlus, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, initialMaxCongestionWindow, nil)
 		const packetSize = initialMaxDatagramSize + 100
 		sender.SetMaxDatagramSize(packetSize)
 		for i := 1; i < protocol.MaxCongestionWindowPackets; i++ {
-			sender.OnPacketAcked(protocol.PacketNumber(i), packetSize, sender.GetCongestionWindow(), clock.Now())
+			sender.OnPacketAcked(protocol.Encryption1RTT, protocol.PacketNumber(i), packetSize, sender.GetCongestionWindow(), clock.Now())
 		}
 		const maxCwnd = protocol.MaxCongestionWindowPackets * packetSize
 		Expect(sender.GetCongestionWindow()).To(And(
@@ -490,7 +545,7 @@ var _ = Describe("Cubic Sender", func() {
 
 	It("limit cwnd increase in congestion avoidance", func() {
 		// Enable Cubic.
-		sender = newCubicSender(&clock, rttStats, false, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
+		sender = newCubicSender(&clock, rttStats, false, hystartPlusPlus, protocol.InitialPacketSize, initialCongestionWindowPackets*maxDatagramSize, MaxCongestionWindow, nil)
 		numSent := SendAvailableSendWindow()
 
 		// Make sure we fall out of slow start.
@@ -524,4 +579,4 @@ var _ = Describe("Cubic Sender", func() {
 		AckNPackets(2)
 		Expect(sender.GetCongestionWindow()).To(Equal(savedCwnd + maxDatagramSize))
 	})
-})
+}
@@ -31,6 +31,8 @@ type HybridSlowStart struct {
 	hystartFound         bool
 }
 
+var _ slowStartAlgorithm = &HybridSlowStart{}
+
 // StartReceiveRound is called for the start of each receive round (burst) in the slow start phase.
 func (s *HybridSlowStart) StartReceiveRound(lastSent protocol.PacketNumber) {
 	s.endPacketNumber = lastSent
@@ -100,6 +102,12 @@ func (s *HybridSlowStart) OnPacketAcked(ackedPacketNumber protocol.PacketNumber)
 	}
 }
 
+// GrowthDivisor returns the factor by which the growth of the congestion window is slowed down.
+// HyStart doesn't slow down the growth.
+func (s *HybridSlowStart) GrowthDivisor() protocol.ByteCount {
+	return 1
+}
+
 // Started returns true if started
 func (s *HybridSlowStart) Started() bool {
 	return s.started
@@ -0,0 +1,127 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// Constants of HyStart++, as recommended in section 4.3 of RFC 9406.
+const (
+	hystartPPMinRTTThresh = 4 * time.Millisecond
+	hystartPPMaxRTTThresh = 16 * time.Millisecond
+	// The RTT threshold is 1/8th of the minimum RTT of the last round.
+	hystartPPMinRTTDivisor = 8
+	// The number of RTT samples required per round to detect an RTT increase.
+	hystartPPNumRTTSamples = 8
+	// The congestion window grows 4 times slower during Conservative Slow Start (CSS).
+	hystartPPCSSGrowthDivisor = 4
+	// The number of rounds spent in CSS before entering congestion avoidance.
+	hystartPPCSSRounds = 5
+)
+
+// HyStartPlusPlus implements HyStart++ (RFC 9406).
+// When an increase in RTT is detected, slow start is not exited immediately.
+// Instead, the congestion window grows more slowly in the Conservative Slow Start (CSS) phase.
+// If the RTT increase turns out to be spurious, slow start is resumed.
+// This avoids exiting slow start prematurely on paths with a noisy RTT.
+type HyStartPlusPlus struct {
+	lastSentPacketNumber protocol.PacketNumber
+	windowEnd            protocol.PacketNumber
+	started              bool
+
+	lastRoundMinRTT    time.Duration
+	currentRoundMinRTT time.Duration
+	rttSampleCount     uint32
+
+	inCSS             bool
+	cssBaselineMinRTT time.Duration
+	cssRounds         int
+}
+
+var _ slowStartAlgorithm = &HyStartPlusPlus{}
+
+// StartReceiveRound is called for the start of each round in the slow start phase.
+func (s *HyStartPlusPlus) StartReceiveRound(lastSent protocol.PacketNumber) {
+	s.windowEnd = lastSent
+	s.lastRoundMinRTT = s.currentRoundMinRTT
+	s.currentRoundMinRTT = 0
+	s.rttSampleCount = 0
+	s.started = true
+}
+
+// ShouldExitSlowStart should be called on every new ack frame, since a new
+// RTT measurement can be made then.
+// It returns true once the sender has spent hystartPPCSSRounds rounds in CSS.
+func (s *HyStartPlusPlus) ShouldExitSlowStart(latestRTT time.Duration, _ time.Duration, _ protocol.ByteCount) bool {
+	if !s.started {
+		s.StartReceiveRound(s.lastSentPacketNumber)
+	}
+	if s.currentRoundMinRTT == 0 || latestRTT < s.currentRoundMinRTT {
+		s.currentRoundMinRTT = latestRTT
+	}
+	s.rttSampleCount++
+	if s.rttSampleCount >= hystartPPNumRTTSamples {
+		if s.inCSS {
+			// The RTT increase was spurious. Resume slow start.
+			if s.currentRoundMinRTT < s.cssBaselineMinRTT {
+				s.inCSS = false
+				s.cssBaselineMinRTT = 0
+			}
+		} else if s.lastRoundMinRTT != 0 {
+			rttThresh := min(max(s.lastRoundMinRTT/hystartPPMinRTTDivisor, hystartPPMinRTTThresh), hystartPPMaxRTTThresh)
+			if s.currentRoundMinRTT >= s.lastRoundMinRTT+rttThresh {
+				s.inCSS = true
+				s.cssBaselineMinRTT = s.currentRoundMinRTT
+				s.cssRounds = 0
+			}
+		}
+	}
+	return s.inCSS && s.cssRounds >= hystartPPCSSRounds
+}
+
+// OnPacketSent is called when a packet was sent
+func (s *HyStartPlusPlus) OnPacketSent(packetNumber protocol.PacketNumber) {
+	s.lastSentPacketNumber = packetNumber
+}
+
+// OnPacketAcked is called for every acknowledged packet during slow start.
+// It ends the round when the last packet of the round is acknowledged.
+func (s *HyStartPlusPlus) OnPacketAcked(ackedPacketNumber protocol.PacketNumber) {
+	if ackedPacketNumber <= s.windowEnd {
+		return
+	}
+	if s.started && s.inCSS {
+		s.cssRounds++
+	}
+	s.started = false
+}
+
+// GrowthDivisor returns the factor by which the growth of the congestion window is slowed down.
+func (s *HyStartPlusPlus) GrowthDivisor() protocol.ByteCount {
+	if s.inCSS {
+		return hystartPPCSSGrowthDivisor
+	}
+	return 1
+}
+
+// InConservativeSlowStart says if the sender is in the CSS phase
+func (s *HyStartPlusPlus) InConservativeSlowStart() bool {
+	return s.inCSS
+}
+
+// Started returns true if started
+func (s *HyStartPlusPlus) Started() bool {
+	return s.started
+}
+
+// Restart the slow start phase
+func (s *HyStartPlusPlus) Restart() {
+	s.started = false
+	s.lastRoundMinRTT = 0
+	s.currentRoundMinRTT = 0
+	s.rttSampleCount = 0
+	s.inCSS = false
+	s.cssBaselineMinRTT = 0
+	s.cssRounds = 0
+}
@@ -0,0 +1,122 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HyStart++", func() {
+	const packetsPerRound = 10
+
+	var (
+		slowStart    *HyStartPlusPlus
+		packetNumber protocol.PacketNumber
+	)
+
+	BeforeEach(func() {
+		slowStart = &HyStartPlusPlus{}
+		packetNumber = 0
+	})
+
+	// runRound sends a round of packets, and acknowledges all of them with the same RTT.
+	// It returns true if the sender should exit slow start.
+	runRound := func(rtt time.Duration) bool {
+		first := packetNumber + 1
+		for i := 0; i < packetsPerRound; i++ {
+			packetNumber++
+			slowStart.OnPacketSent(packetNumber)
+		}
+		var exit bool
+		for pn := first; pn <= packetNumber; pn++ {
+			exit = slowStart.ShouldExitSlowStart(rtt, 0, 0)
+			slowStart.OnPacketAcked(pn)
+		}
+		return exit
+	}
+
+	It("doesn't enter Conservative Slow Start if the RTT is constant", func() {
+		for i := 0; i < 20; i++ {
+			Expect(runRound(60 * time.Millisecond)).To(BeFalse())
+			Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+			Expect(slowStart.GrowthDivisor()).To(BeEquivalentTo(1))
+		}
+	})
+
+	It("enters Conservative Slow Start when the RTT increases, and exits slow start after a few rounds", func() {
+		runRound(60 * time.Millisecond)
+		runRound(60 * time.Millisecond)
+		// The threshold is 60ms / 8 = 7.5ms.
+		Expect(runRound(67 * time.Millisecond)).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+		// The RTT is compared to the last round: 67ms + 67ms / 8 = 75.375ms.
+		Expect(runRound(76 * time.Millisecond)).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+		Expect(slowStart.GrowthDivisor()).To(BeEquivalentTo(hystartPPCSSGrowthDivisor))
+		var rounds int
+		for !runRound(80 * time.Millisecond) {
+			rounds++
+			Expect(rounds).To(BeNumerically("<", 2*hystartPPCSSRounds))
+		}
+		Expect(rounds).To(Equal(hystartPPCSSRounds - 1))
+	})
+
+	It("resumes slow start if the RTT increase was spurious", func() {
+		runRound(60 * time.Millisecond)
+		runRound(60 * time.Millisecond)
+		runRound(80 * time.Millisecond)
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+		Expect(runRound(70 * time.Millisecond)).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+		Expect(slowStart.GrowthDivisor()).To(BeEquivalentTo(1))
+	})
+
+	It("uses a minimum RTT threshold", func() {
+		runRound(10 * time.Millisecond)
+		runRound(10 * time.Millisecond)
+		runRound(13 * time.Millisecond)
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+		runRound(17 * time.Millisecond)
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+	})
+
+	It("uses a maximum RTT threshold", func() {
+		runRound(200 * time.Millisecond)
+		runRound(200 * time.Millisecond)
+		runRound(215 * time.Millisecond)
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+		runRound(231 * time.Millisecond)
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+	})
+
+	It("requires a minimum number of RTT samples per round", func() {
+		runRound(60 * time.Millisecond)
+		runRound(60 * time.Millisecond)
+		first := packetNumber + 1
+		for i := 0; i < hystartPPNumRTTSamples; i++ {
+			packetNumber++
+			slowStart.OnPacketSent(packetNumber)
+		}
+		for pn := first; pn < packetNumber; pn++ {
+			slowStart.ShouldExitSlowStart(100*time.Millisecond, 0, 0)
+			slowStart.OnPacketAcked(pn)
+		}
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+	})
+
+	It("restarts", func() {
+		runRound(60 * time.Millisecond)
+		runRound(60 * time.Millisecond)
+		runRound(80 * time.Millisecond)
+		Expect(slowStart.InConservativeSlowStart()).To(BeTrue())
+		slowStart.Restart()
+		Expect(slowStart.Started()).To(BeFalse())
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+		// the RTT of the previous rounds is forgotten
+		runRound(80 * time.Millisecond)
+		Expect(slowStart.InConservativeSlowStart()).To(BeFalse())
+	})
+})
@@ -6,15 +6,32 @@ import (
 	"github.com/quic-go/quic-go/internal/protocol"
 )
 
-// A SendAlgorithm performs congestion control
+// RTTStats are the RTT measurements a SendAlgorithm has read access to.
+type RTTStats interface {
+	MinRTT() time.Duration
+	LatestRTT() time.Duration
+	SmoothedRTT() time.Duration
+	MeanDeviation() time.Duration
+	PTO(includeMaxAckDelay bool) time.Duration
+}
+
+// A SendAlgorithm performs congestion control.
+// Packet numbers are only unique within a packet number space, so the encryption level is passed
+// along with the packet number.
 type SendAlgorithm interface {
 	TimeUntilSend(bytesInFlight protocol.ByteCount) time.Time
 	HasPacingBudget(now time.Time) bool
-	OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool)
+	OnPacketSent(sentTime time.Time, bytesInFlight protocol.ByteCount, encLevel protocol.EncryptionLevel, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool)
 	CanSend(bytesInFlight protocol.ByteCount) bool
 	MaybeExitSlowStart()
-	OnPacketAcked(number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time)
-	OnCongestionEvent(number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount)
+	OnPacketAcked(encLevel protocol.EncryptionLevel, number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time)
+	OnCongestionEvent(encLevel protocol.EncryptionLevel, number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount)
+	// OnPacketDiscarded is called for packets that will neither be acknowledged nor declared lost,
+	// e.g. for lost Path MTU probe packets.
+	OnPacketDiscarded(encLevel protocol.EncryptionLevel, number protocol.PacketNumber)
+	// OnPacketsDropped is called when the packets of an encryption level are dropped,
+	// i.e. when the Initial or Handshake keys are dropped, or when 0-RTT is rejected.
+	OnPacketsDropped(encLevel protocol.EncryptionLevel)
 	OnRetransmissionTimeout(packetsRetransmitted bool)
 	SetMaxDatagramSize(protocol.ByteCount)
 }
@@ -26,3 +43,22 @@ type SendAlgorithmWithDebugInfos interface {
 	InRecovery() bool
 	GetCongestionWindow() protocol.ByteCount
 }
+
+// A L4SSendAlgorithm is a scalable congestion controller for L4S (RFC 9330).
+// Packets are sent with the ECT(1) codepoint, and the controller is informed about the number of CE marks
+// for every ACK frame.
+type L4SSendAlgorithm interface {
+	SendAlgorithmWithDebugInfos
+	// OnECNFeedback is called for every ACK frame that increases the largest acknowledged packet number.
+	// ackedPackets is the number of newly acknowledged packets, newECNCE is the increase of the ECN-CE count.
+	// It is called before OnPacketAcked is called for the newly acknowledged packets.
+	OnECNFeedback(ackedPackets, newECNCE int64)
+}
+
+// A CarefulResumeSendAlgorithm can use the congestion state of a previous connection to the same peer
+// to ramp up faster (draft-ietf-tsvwg-careful-resume).
+type CarefulResumeSendAlgorithm interface {
+	SendAlgorithmWithDebugInfos
+	// SetResumeState must be called before any packet is sent.
+	SetResumeState(savedRTT time.Duration, savedCongestionWindow protocol.ByteCount)
+}
@@ -0,0 +1,348 @@
+package congestion
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// The mediaSender is a delay-based congestion controller for interactive media,
+// in the spirit of Google Congestion Control (draft-ietf-rmcat-gcc-02) and SCReAM (RFC 8298).
+// Instead of filling the bottleneck queue until packets are lost, it detects the build-up of a queue
+// from the gradient of the queueing delay, and adjusts a target bitrate using AIMD.
+// Packets are paced at the target bitrate, which is reported to the application,
+// so that media encoders can adapt their output.
+
+const (
+	// DefaultMediaMinBitrate is the default minimum target bitrate.
+	DefaultMediaMinBitrate = 100 * 1000 * BitsPerSecond
+	// DefaultMediaMaxBitrate is the default maximum target bitrate.
+	DefaultMediaMaxBitrate = 50 * 1000 * 1000 * BitsPerSecond
+	// DefaultMediaInitialBitrate is the default initial target bitrate.
+	DefaultMediaInitialBitrate = 1000 * 1000 * BitsPerSecond
+
+	// The target bitrate is reduced to this fraction of the acknowledged bitrate when overuse is detected.
+	mediaDelayBeta = 0.85
+	// The target bitrate is reduced by this factor when packets are lost, at most once per RTT.
+	mediaLossBeta = 0.8
+	// The target bitrate is reduced by this factor when packets are ECN-CE marked, at most once per RTT.
+	mediaECNBeta = 0.9
+	// The target bitrate grows by 8% per second, when no congestion is detected.
+	mediaIncreaseRate = 0.08
+	// The target bitrate is never increased above this multiple of the acknowledged bitrate.
+	mediaMaxAckedBitrateRatio = 1.5
+	// The acknowledged bitrate is measured over windows of this duration.
+	mediaAckedBitrateWindow = 250 * time.Millisecond
+	// The base delay (the RTT without any queueing) is the minimum RTT over this duration.
+	mediaBaseDelayWindow = 30 * time.Second
+	// The minimum congestion window, in packets.
+	mediaMinCongestionWindowPackets = 4
+	// The RTT used to calculate the congestion window before the first RTT sample is available.
+	mediaInitialRTT = 100 * time.Millisecond
+	// Changes of the target bitrate smaller than 1/mediaReportThreshold are not reported to the application.
+	mediaReportThreshold = 100
+)
+
+type mediaSender struct {
+	rttStats RTTStats
+	pacer    *pacer
+
+	minBitrate, maxBitrate Bandwidth
+	targetBitrate          Bandwidth
+	reportedBitrate        Bandwidth
+	onTargetBitrate        func(Bandwidth)
+
+	sentTimes sentPackets[time.Time]
+	trendline *trendlineEstimator
+	usage     bandwidthUsage
+
+	// The base delay is tracked in two buckets, each covering half of the base delay window.
+	baseDelay, prevBaseDelay time.Duration
+	baseDelayStart           time.Time
+
+	ackedBitrate    Bandwidth
+	ackWindowStart  time.Time
+	ackWindowBytes  protocol.ByteCount
+	lastIncrease    time.Time
+	lastDecrease    time.Time
+	largestSent     protocol.PacketNumber
+	recoveryEnd     protocol.PacketNumber
+	maxDatagramSize protocol.ByteCount
+
+	lastState logging.CongestionState
+	tracer    *logging.ConnectionTracer
+}
+
+var (
+	_ SendAlgorithm               = &mediaSender{}
+	_ SendAlgorithmWithDebugInfos = &mediaSender{}
+)
+
+// NewMediaSender makes a new delay-based congestion controller for interactive media.
+// onTargetBitrate is called (synchronously) every time the target bitrate changes. It may be nil.
+func NewMediaSender(
+	rttStats RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	minBitrate, maxBitrate, initialBitrate Bandwidth,
+	onTargetBitrate func(Bandwidth),
+	tracer *logging.ConnectionTracer,
+) *mediaSender {
+	if minBitrate == 0 {
+		minBitrate = DefaultMediaMinBitrate
+	}
+	if maxBitrate == 0 {
+		maxBitrate = DefaultMediaMaxBitrate
+	}
+	if initialBitrate == 0 {
+		initialBitrate = DefaultMediaInitialBitrate
+	}
+	s := &mediaSender{
+		rttStats:        rttStats,
+		minBitrate:      minBitrate,
+		maxBitrate:      max(minBitrate, maxBitrate),
+		onTargetBitrate: onTargetBitrate,
+		sentTimes:       newSentPackets[time.Time](),
+		trendline:       newTrendlineEstimator(),
+		largestSent:     protocol.InvalidPacketNumber,
+		recoveryEnd:     protocol.InvalidPacketNumber,
+		maxDatagramSize: initialMaxDatagramSize,
+		tracer:          tracer,
+	}
+	s.targetBitrate = s.clamp(initialBitrate)
+	s.reportedBitrate = s.targetBitrate
+	// Packets are sent at the target bitrate, so that the bitrate measured by the peer matches the target bitrate.
+	s.pacer = newRatePacer(func() Bandwidth { return s.targetBitrate })
+	s.pacer.SetMaxDatagramSize(initialMaxDatagramSize)
+	if s.tracer != nil && s.tracer.UpdatedCongestionState != nil {
+		s.lastState = logging.CongestionStateCongestionAvoidance
+		s.tracer.UpdatedCongestionState(logging.CongestionStateCongestionAvoidance)
+	}
+	return s
+}
+
+// TargetBitrate returns the current target bitrate.
+func (s *mediaSender) TargetBitrate() Bandwidth {
+	return s.targetBitrate
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (s *mediaSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return s.pacer.TimeUntilSend()
+}
+
+func (s *mediaSender) HasPacingBudget(now time.Time) bool {
+	return s.pacer.Budget(now) >= s.maxDatagramSize
+}
+
+func (s *mediaSender) OnPacketSent(
+	sentTime time.Time,
+	_ protocol.ByteCount,
+	encLevel protocol.EncryptionLevel,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	s.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	s.largestSent = packetNumber
+	s.sentTimes.Add(encLevel, packetNumber, sentTime)
+}
+
+func (s *mediaSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < s.GetCongestionWindow()
+}
+
+func (s *mediaSender) InRecovery() bool {
+	return s.recoveryEnd != protocol.InvalidPacketNumber
+}
+
+// InSlowStart returns false: there's no slow start phase.
+func (s *mediaSender) InSlowStart() bool {
+	return false
+}
+
+// GetCongestionWindow returns the congestion window.
+// It allows twice the bandwidth-delay product at the target bitrate in flight,
+// so that the sending rate is determined by the pacer.
+func (s *mediaSender) GetCongestionWindow() protocol.ByteCount {
+	rtt := s.rttStats.SmoothedRTT()
+	if s.baseDelay > 0 {
+		rtt = s.baseDelay
+	}
+	if rtt == 0 {
+		rtt = mediaInitialRTT
+	}
+	bdp := protocol.ByteCount(uint64(s.targetBitrate/BytesPerSecond) * uint64(rtt) / uint64(time.Second))
+	return max(2*bdp, mediaMinCongestionWindowPackets*s.maxDatagramSize)
+}
+
+func (s *mediaSender) MaybeExitSlowStart() {}
+
+func (s *mediaSender) OnPacketAcked(
+	encLevel protocol.EncryptionLevel,
+	packetNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	_ protocol.ByteCount,
+	eventTime time.Time,
+) {
+	if s.InRecovery() && packetNumber > s.recoveryEnd {
+		s.recoveryEnd = protocol.InvalidPacketNumber
+	}
+	s.updateAckedBitrate(ackedBytes, eventTime)
+	sentTime, ok := s.sentTimes.Remove(encLevel, packetNumber)
+	if !ok {
+		return
+	}
+	// This RTT sample includes the peer's ACK delay.
+	// Since the ACK delay is roughly constant, this doesn't affect the delay gradient.
+	rtt := eventTime.Sub(sentTime)
+	s.updateBaseDelay(rtt, eventTime)
+	s.usage = s.trendline.Update(eventTime, rtt-s.baseDelay)
+	s.updateTargetBitrate(eventTime)
+}
+
+func (s *mediaSender) updateAckedBitrate(ackedBytes protocol.ByteCount, now time.Time) {
+	if s.ackWindowStart.IsZero() {
+		s.ackWindowStart = now
+	}
+	s.ackWindowBytes += ackedBytes
+	elapsed := now.Sub(s.ackWindowStart)
+	if elapsed < mediaAckedBitrateWindow {
+		return
+	}
+	bw := BandwidthFromDelta(s.ackWindowBytes, elapsed)
+	if s.ackedBitrate == 0 {
+		s.ackedBitrate = bw
+	} else {
+		s.ackedBitrate = (s.ackedBitrate + bw) / 2
+	}
+	s.ackWindowStart = now
+	s.ackWindowBytes = 0
+}
+
+func (s *mediaSender) updateBaseDelay(rtt time.Duration, now time.Time) {
+	if s.baseDelayStart.IsZero() || now.Sub(s.baseDelayStart) > mediaBaseDelayWindow/2 {
+		s.prevBaseDelay = s.baseDelay
+		s.baseDelay = 0
+		s.baseDelayStart = now
+	}
+	if s.baseDelay == 0 || rtt < s.baseDelay {
+		s.baseDelay = rtt
+	}
+	if s.prevBaseDelay > 0 && s.prevBaseDelay < s.baseDelay {
+		s.baseDelay = s.prevBaseDelay
+	}
+}
+
+func (s *mediaSender) updateTargetBitrate(now time.Time) {
+	switch s.usage {
+	case bandwidthUsageOverusing:
+		if s.recentlyDecreased(now) {
+			break
+		}
+		rate := s.targetBitrate
+		if s.ackedBitrate > 0 {
+			rate = min(rate, s.ackedBitrate)
+		}
+		s.lastDecrease = now
+		s.setTargetBitrate(Bandwidth(mediaDelayBeta * float64(rate)))
+	case bandwidthUsageUnderusing:
+		// The queue is draining. Hold the bitrate until the queue is empty.
+	case bandwidthUsageNormal:
+		if s.lastIncrease.IsZero() {
+			break
+		}
+		elapsed := min(now.Sub(s.lastIncrease), time.Second)
+		rate := Bandwidth(float64(s.targetBitrate) * (1 + mediaIncreaseRate*elapsed.Seconds()))
+		// Don't increase the target bitrate far beyond what is actually sent, e.g. if the encoder can't keep up.
+		if s.ackedBitrate > 0 {
+			rate = min(rate, max(s.targetBitrate, Bandwidth(mediaMaxAckedBitrateRatio*float64(s.ackedBitrate))))
+		}
+		s.setTargetBitrate(rate)
+	}
+	s.lastIncrease = now
+}
+
+func (s *mediaSender) recentlyDecreased(now time.Time) bool {
+	return !s.lastDecrease.IsZero() && now.Sub(s.lastDecrease) < max(s.rttStats.SmoothedRTT(), s.baseDelay)
+}
+
+func (s *mediaSender) OnCongestionEvent(encLevel protocol.EncryptionLevel, packetNumber protocol.PacketNumber, lostBytes, _ protocol.ByteCount) {
+	// ECN-CE marks are reported with lostBytes = 0.
+	// In that case, the packet is still acknowledged afterwards.
+	if lostBytes > 0 {
+		s.sentTimes.Remove(encLevel, packetNumber)
+	}
+	// Only react once per round trip.
+	if s.InRecovery() {
+		return
+	}
+	s.recoveryEnd = s.largestSent
+	beta := mediaLossBeta
+	if lostBytes == 0 { // ECN-CE
+		beta = mediaECNBeta
+	}
+	s.setTargetBitrate(Bandwidth(beta * float64(s.targetBitrate)))
+}
+
+func (s *mediaSender) OnPacketDiscarded(encLevel protocol.EncryptionLevel, packetNumber protocol.PacketNumber) {
+	s.sentTimes.Remove(encLevel, packetNumber)
+}
+
+func (s *mediaSender) OnPacketsDropped(encLevel protocol.EncryptionLevel) {
+	s.sentTimes.DropEncryptionLevel(encLevel)
+}
+
+// OnRetransmissionTimeout is called on an retransmission timeout
+func (s *mediaSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	if !packetsRetransmitted {
+		return
+	}
+	s.setTargetBitrate(s.minBitrate)
+}
+
+func (s *mediaSender) SetMaxDatagramSize(size protocol.ByteCount) {
+	if size < s.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", s.maxDatagramSize, size))
+	}
+	s.maxDatagramSize = size
+	s.pacer.SetMaxDatagramSize(size)
+}
+
+func (s *mediaSender) clamp(bw Bandwidth) Bandwidth {
+	return min(max(bw, s.minBitrate), s.maxBitrate)
+}
+
+func (s *mediaSender) setTargetBitrate(bw Bandwidth) {
+	s.targetBitrate = s.clamp(bw)
+	s.traceState()
+	if s.onTargetBitrate == nil || s.targetBitrate == s.reportedBitrate {
+		return
+	}
+	// Don't report small changes, unless the target bitrate reached one of the bounds.
+	diff := max(s.targetBitrate, s.reportedBitrate) - min(s.targetBitrate, s.reportedBitrate)
+	if diff < s.reportedBitrate/mediaReportThreshold && s.targetBitrate != s.minBitrate && s.targetBitrate != s.maxBitrate {
+		return
+	}
+	s.reportedBitrate = s.targetBitrate
+	s.onTargetBitrate(s.targetBitrate)
+}
+
+func (s *mediaSender) traceState() {
+	if s.tracer == nil || s.tracer.UpdatedCongestionState == nil {
+		return
+	}
+	state := logging.CongestionStateCongestionAvoidance
+	if s.InRecovery() {
+		state = logging.CongestionStateRecovery
+	}
+	if state == s.lastState {
+		return
+	}
+	s.tracer.UpdatedCongestionState(state)
+	s.lastState = state
+}
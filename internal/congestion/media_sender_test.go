@@ -0,0 +1,197 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Media Sender", func() {
+	const (
+		packetSize = protocol.ByteCount(1200)
+		baseRTT    = 40 * time.Millisecond
+	)
+
+	type sentPacket struct {
+		pn       protocol.PacketNumber
+		sendTime time.Time
+		ackTime  time.Time
+	}
+
+	var (
+		sender          *mediaSender
+		rttStats        *utils.RTTStats
+		reportedBitrate []Bandwidth
+		now             time.Time
+		packetNumber    protocol.PacketNumber
+		bytesInFlight   protocol.ByteCount
+		outstanding     []sentPacket
+		linkFree        time.Time
+		linkRate        Bandwidth
+	)
+
+	BeforeEach(func() {
+		rttStats = utils.NewRTTStats()
+		reportedBitrate = nil
+		sender = NewMediaSender(
+			rttStats,
+			packetSize,
+			0, 0, 0,
+			func(bw Bandwidth) { reportedBitrate = append(reportedBitrate, bw) },
+			nil,
+		)
+		now = time.Now()
+		packetNumber = 0
+		bytesInFlight = 0
+		outstanding = nil
+		linkFree = time.Time{}
+		linkRate = 4 * 1000 * 1000 * BitsPerSecond
+	})
+
+	sendPacket := func() {
+		packetNumber++
+		bytesInFlight += packetSize
+		sender.OnPacketSent(now, bytesInFlight, protocol.Encryption1RTT, packetNumber, packetSize, true)
+		departure := now
+		if linkFree.After(departure) {
+			departure = linkFree
+		}
+		departure = departure.Add(time.Duration(packetSize) * time.Second / time.Duration(linkRate/BytesPerSecond))
+		linkFree = departure
+		outstanding = append(outstanding, sentPacket{pn: packetNumber, sendTime: now, ackTime: departure.Add(baseRTT)})
+	}
+
+	// simulate sends as much data as the sender allows over a link with a bandwidth of linkRate
+	simulate := func(d time.Duration, onAck func()) {
+		end := now.Add(d)
+		for now.Before(end) {
+			for sender.CanSend(bytesInFlight) && sender.HasPacingBudget(now) {
+				sendPacket()
+			}
+			next := end
+			if len(outstanding) > 0 {
+				next = outstanding[0].ackTime
+			}
+			if sender.CanSend(bytesInFlight) {
+				if t := sender.TimeUntilSend(bytesInFlight); t.After(now) && t.Before(next) {
+					next = t
+				}
+			}
+			now = next
+			for len(outstanding) > 0 && !outstanding[0].ackTime.After(now) {
+				p := outstanding[0]
+				outstanding = outstanding[1:]
+				rttStats.UpdateRTT(now.Sub(p.sendTime), 0, now)
+				sender.OnPacketAcked(protocol.Encryption1RTT, p.pn, packetSize, bytesInFlight, now)
+				bytesInFlight -= packetSize
+				if onAck != nil {
+					onAck()
+				}
+			}
+		}
+	}
+
+	It("starts at the initial bitrate", func() {
+		Expect(sender.TargetBitrate()).To(Equal(DefaultMediaInitialBitrate))
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(reportedBitrate).To(BeEmpty())
+	})
+
+	It("converges to the link rate without building up a queue", func() {
+		simulate(30*time.Second, nil)
+		Expect(reportedBitrate).ToNot(BeEmpty())
+		Expect(sender.TargetBitrate()).To(BeNumerically("~", linkRate, linkRate/3))
+		var maxRTT time.Duration
+		simulate(30*time.Second, func() { maxRTT = max(maxRTT, rttStats.LatestRTT()) })
+		Expect(maxRTT).To(BeNumerically("<", baseRTT+50*time.Millisecond))
+	})
+
+	It("reduces the bitrate when the link rate decreases", func() {
+		simulate(30*time.Second, nil)
+		linkRate /= 2
+		simulate(5*time.Second, nil)
+		Expect(sender.TargetBitrate()).To(BeNumerically("<=", linkRate))
+		var maxRTT time.Duration
+		simulate(30*time.Second, func() { maxRTT = max(maxRTT, rttStats.LatestRTT()) })
+		Expect(sender.TargetBitrate()).To(BeNumerically("~", linkRate, linkRate/3))
+		Expect(maxRTT).To(BeNumerically("<", baseRTT+50*time.Millisecond))
+	})
+	It("reduces the bitrate when packets are lost, once per RTT", func() {
+		simulate(5*time.Second, nil)
+		bitrate := sender.TargetBitrate()
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[0].pn, packetSize, bytesInFlight)
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(sender.TargetBitrate()).To(Equal(Bandwidth(mediaLossBeta * float64(bitrate))))
+		Expect(reportedBitrate[len(reportedBitrate)-1]).To(Equal(sender.TargetBitrate()))
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[1].pn, packetSize, bytesInFlight)
+		Expect(sender.TargetBitrate()).To(Equal(Bandwidth(mediaLossBeta * float64(bitrate))))
+		// recovery ends once a packet sent after the loss is acknowledged
+		simulate(time.Second, nil)
+		Expect(sender.InRecovery()).To(BeFalse())
+	})
+
+	It("reduces the bitrate when packets are ECN-CE marked", func() {
+		simulate(5*time.Second, nil)
+		bitrate := sender.TargetBitrate()
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[0].pn, 0, bytesInFlight)
+		Expect(sender.TargetBitrate()).To(Equal(Bandwidth(mediaECNBeta * float64(bitrate))))
+	})
+
+	It("keeps track of packets per encryption level", func() {
+		sender.OnPacketSent(now, packetSize, protocol.EncryptionInitial, 0, packetSize, true)
+		sender.OnPacketSent(now, 2*packetSize, protocol.EncryptionHandshake, 0, packetSize, true)
+		sender.OnPacketSent(now, 3*packetSize, protocol.Encryption1RTT, 0, packetSize, true)
+		sender.OnPacketSent(now, 4*packetSize, protocol.Encryption1RTT, 1, packetSize, true)
+		Expect(sender.sentTimes.Len()).To(Equal(4))
+		// ECN-CE marks don't remove the packet, since it is acknowledged afterwards
+		sender.OnCongestionEvent(protocol.Encryption1RTT, 0, 0, 4*packetSize)
+		Expect(sender.sentTimes.Len()).To(Equal(4))
+		sender.OnPacketAcked(protocol.Encryption1RTT, 0, packetSize, 4*packetSize, now.Add(baseRTT))
+		Expect(sender.sentTimes.Len()).To(Equal(3))
+		sender.OnPacketDiscarded(protocol.Encryption1RTT, 1)
+		Expect(sender.sentTimes.Len()).To(Equal(2))
+		sender.OnPacketsDropped(protocol.EncryptionInitial)
+		Expect(sender.sentTimes.Len()).To(Equal(1))
+		sender.OnCongestionEvent(protocol.EncryptionHandshake, 0, packetSize, packetSize)
+		Expect(sender.sentTimes.Len()).To(BeZero())
+	})
+
+	It("reduces the bitrate to the minimum bitrate on a retransmission timeout", func() {
+		sender.OnRetransmissionTimeout(false)
+		Expect(sender.TargetBitrate()).To(Equal(DefaultMediaInitialBitrate))
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.TargetBitrate()).To(Equal(DefaultMediaMinBitrate))
+		Expect(reportedBitrate).To(Equal([]Bandwidth{DefaultMediaMinBitrate}))
+	})
+
+	It("respects the configured bounds", func() {
+		sender = NewMediaSender(
+			rttStats,
+			packetSize,
+			500*1000*BitsPerSecond, 2*1000*1000*BitsPerSecond, 10*1000*1000*BitsPerSecond,
+			func(bw Bandwidth) { reportedBitrate = append(reportedBitrate, bw) },
+			nil,
+		)
+		Expect(sender.TargetBitrate()).To(Equal(2 * 1000 * 1000 * BitsPerSecond))
+		simulate(30*time.Second, nil)
+		Expect(sender.TargetBitrate()).To(BeNumerically("<=", 2*1000*1000*BitsPerSecond))
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.TargetBitrate()).To(Equal(500 * 1000 * BitsPerSecond))
+	})
+
+	It("doesn't report small changes of the target bitrate", func() {
+		simulate(10*time.Second, nil)
+		for i := 1; i < len(reportedBitrate); i++ {
+			prev, cur := float64(reportedBitrate[i-1]), float64(reportedBitrate[i])
+			Expect(cur).To(Or(
+				BeNumerically(">=", prev*(1+1.0/mediaReportThreshold)),
+				BeNumerically("<=", prev*(1-1.0/mediaReportThreshold)),
+			))
+		}
+	})
+})
@@ -16,6 +16,29 @@ type pacer struct {
 	adjustedBandwidth func() uint64 // in bytes/s
 }
 
+// A Pacer paces the sending of packets, based on a bandwidth estimate.
+type Pacer interface {
+	SentPacket(sendTime time.Time, size protocol.ByteCount)
+	Budget(now time.Time) protocol.ByteCount
+	TimeUntilSend() time.Time
+	SetMaxDatagramSize(protocol.ByteCount)
+}
+
+var _ Pacer = &pacer{}
+
+// NewPacer creates a new token bucket pacer.
+// It is exported for use by congestion controllers implemented outside of this package.
+func NewPacer(getBandwidth func() Bandwidth) Pacer {
+	return newPacer(getBandwidth)
+}
+
+// newRatePacer creates a pacer that sends at exactly the rate returned by getRate.
+// The pacer created by newPacer sends slightly faster than the bandwidth it is given.
+// Rate-based congestion controllers (like BBR) control the sending rate precisely, and need to compensate for this.
+func newRatePacer(getRate func() Bandwidth) *pacer {
+	return newPacer(func() Bandwidth { return getRate() * 4 / 5 })
+}
+
 func newPacer(getBandwidth func() Bandwidth) *pacer {
 	p := &pacer{
 		maxDatagramSize: initialMaxDatagramSize,
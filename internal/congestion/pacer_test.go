@@ -19,8 +19,8 @@ var _ = Describe("Pacer", func() {
 	BeforeEach(func() {
 		bandwidth = uint64(packetsPerSecond * initialMaxDatagramSize) // 50 full-size packets per second
 		// The pacer will multiply the bandwidth with 1.25 to achieve a slightly higher pacing speed.
-		// For the tests, cancel out this factor, so we can do the math using the exact bandwidth.
-		p = newPacer(func() Bandwidth { return Bandwidth(bandwidth) * BytesPerSecond * 4 / 5 })
+		// For the tests, use a pacer that cancels out this factor, so we can do the math using the exact bandwidth.
+		p = newRatePacer(func() Bandwidth { return Bandwidth(bandwidth) * BytesPerSecond })
 	})
 
 	It("allows a burst at the beginning", func() {
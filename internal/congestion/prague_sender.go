@@ -0,0 +1,274 @@
+package congestion
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// The pragueSender implements the Prague congestion controller for L4S (RFC 9330, RFC 9332, draft-briscoe-iccrg-prague-congestion-control).
+// Packets are sent with the ECT(1) codepoint. L4S bottlenecks mark packets as CE as soon as a very shallow queue builds up.
+// Like DCTCP, Prague reduces the congestion window in proportion to the fraction of CE-marked packets,
+// resulting in small sawteeth and very low queueing delay.
+// On paths that don't support ECN, it falls back to a Reno-style response to packet loss.
+
+const (
+	// The gain of the EWMA used to estimate the fraction of CE-marked packets (1/16, as in DCTCP).
+	pragueAlphaGain = 1.0 / 16
+	// The additive increase is scaled such that the congestion window grows as if the RTT was at least this value.
+	// This makes the throughput less dependent on the RTT (RFC 9331, section 4.3).
+	pragueReferenceRTT = 25 * time.Millisecond
+	// The minimum congestion window, in packets.
+	pragueMinCongestionWindowPackets = 2
+)
+
+type pragueSender struct {
+	rttStats RTTStats
+	pacer    *pacer
+
+	congestionWindow   protocol.ByteCount
+	slowStartThreshold protocol.ByteCount
+	// the congestion window is increased in multiples of the max datagram size
+	bytesAckedInCA protocol.ByteCount
+
+	// alpha is the estimated fraction of CE-marked packets
+	alpha float64
+	// counters for the current round trip
+	roundAcked, roundMarked int64
+	roundEnd                protocol.PacketNumber
+
+	largestSent  protocol.PacketNumber
+	largestAcked protocol.PacketNumber
+	// The window is reduced at most once per round trip.
+	// This is the largest packet number sent at the time of the last reduction.
+	largestSentAtLastCutback protocol.PacketNumber
+	inLossRecovery           bool
+
+	maxCongestionWindow protocol.ByteCount
+	maxDatagramSize     protocol.ByteCount
+
+	lastState logging.CongestionState
+	tracer    *logging.ConnectionTracer
+}
+
+var (
+	_ SendAlgorithm               = &pragueSender{}
+	_ SendAlgorithmWithDebugInfos = &pragueSender{}
+	_ L4SSendAlgorithm            = &pragueSender{}
+)
+
+// NewPragueSender makes a new Prague sender
+func NewPragueSender(
+	rttStats RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	tracer *logging.ConnectionTracer,
+) *pragueSender {
+	p := &pragueSender{
+		rttStats:                 rttStats,
+		congestionWindow:         initialCongestionWindow * initialMaxDatagramSize,
+		slowStartThreshold:       protocol.MaxByteCount,
+		alpha:                    1,
+		roundEnd:                 protocol.InvalidPacketNumber,
+		largestSent:              protocol.InvalidPacketNumber,
+		largestAcked:             protocol.InvalidPacketNumber,
+		largestSentAtLastCutback: protocol.InvalidPacketNumber,
+		maxCongestionWindow:      protocol.MaxCongestionWindowPackets * initialMaxDatagramSize,
+		maxDatagramSize:          initialMaxDatagramSize,
+		tracer:                   tracer,
+	}
+	p.pacer = newPacer(p.BandwidthEstimate)
+	p.pacer.SetMaxDatagramSize(initialMaxDatagramSize)
+	if p.tracer != nil && p.tracer.UpdatedCongestionState != nil {
+		p.lastState = logging.CongestionStateSlowStart
+		p.tracer.UpdatedCongestionState(logging.CongestionStateSlowStart)
+	}
+	return p
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (p *pragueSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return p.pacer.TimeUntilSend()
+}
+
+func (p *pragueSender) HasPacingBudget(now time.Time) bool {
+	return p.pacer.Budget(now) >= p.maxDatagramSize
+}
+
+func (p *pragueSender) OnPacketSent(
+	sentTime time.Time,
+	_ protocol.ByteCount,
+	_ protocol.EncryptionLevel,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	p.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	p.largestSent = packetNumber
+}
+
+func (p *pragueSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < p.congestionWindow
+}
+
+func (p *pragueSender) InRecovery() bool {
+	return p.largestAckedBeforeCutback() && p.largestSentAtLastCutback != protocol.InvalidPacketNumber
+}
+
+func (p *pragueSender) largestAckedBeforeCutback() bool {
+	return p.largestAcked == protocol.InvalidPacketNumber || p.largestAcked <= p.largestSentAtLastCutback
+}
+
+func (p *pragueSender) InSlowStart() bool {
+	return p.congestionWindow < p.slowStartThreshold
+}
+
+func (p *pragueSender) GetCongestionWindow() protocol.ByteCount {
+	return p.congestionWindow
+}
+
+func (p *pragueSender) MaybeExitSlowStart() {}
+
+// BandwidthEstimate returns the current bandwidth estimate
+func (p *pragueSender) BandwidthEstimate() Bandwidth {
+	srtt := p.rttStats.SmoothedRTT()
+	if srtt == 0 {
+		// If we haven't measured an rtt, the bandwidth estimate is unknown.
+		return infBandwidth
+	}
+	return BandwidthFromDelta(p.congestionWindow, srtt)
+}
+
+// OnECNFeedback updates the estimate of the fraction of CE-marked packets,
+// and reduces the congestion window in proportion to it.
+func (p *pragueSender) OnECNFeedback(ackedPackets, newECNCE int64) {
+	p.roundAcked += ackedPackets
+	p.roundMarked += newECNCE
+	if newECNCE > 0 && !p.InRecovery() {
+		// Exit slow start on the first CE mark.
+		p.slowStartThreshold = 0
+		p.reduceCongestionWindow(1 - p.alpha/2)
+	}
+}
+
+func (p *pragueSender) OnPacketAcked(
+	_ protocol.EncryptionLevel,
+	ackedPacketNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	_ protocol.ByteCount,
+	_ time.Time,
+) {
+	p.largestAcked = max(ackedPacketNumber, p.largestAcked)
+	if p.roundEnd == protocol.InvalidPacketNumber || ackedPacketNumber > p.roundEnd {
+		p.updateAlpha()
+	}
+	if p.InRecovery() {
+		return
+	}
+	p.inLossRecovery = false
+	if p.InSlowStart() {
+		p.congestionWindow += ackedBytes
+	} else {
+		p.bytesAckedInCA += ackedBytes
+		// Increase the congestion window by one packet per (reference) RTT.
+		increase := p.maxDatagramSize
+		if srtt := p.rttStats.SmoothedRTT(); srtt > 0 && srtt < pragueReferenceRTT {
+			scale := float64(srtt) / float64(pragueReferenceRTT)
+			increase = max(1, protocol.ByteCount(scale*scale*float64(p.maxDatagramSize)))
+		}
+		if p.bytesAckedInCA >= p.congestionWindow {
+			p.bytesAckedInCA -= p.congestionWindow
+			p.congestionWindow += increase
+		}
+	}
+	p.congestionWindow = min(p.congestionWindow, p.maxCongestionWindow)
+	p.traceState()
+}
+
+// updateAlpha is called once per round trip
+func (p *pragueSender) updateAlpha() {
+	if p.roundAcked > 0 {
+		frac := float64(p.roundMarked) / float64(p.roundAcked)
+		p.alpha = (1-pragueAlphaGain)*p.alpha + pragueAlphaGain*min(frac, 1)
+	}
+	p.roundAcked = 0
+	p.roundMarked = 0
+	p.roundEnd = p.largestSent
+}
+
+func (p *pragueSender) OnCongestionEvent(_ protocol.EncryptionLevel, _ protocol.PacketNumber, lostBytes, _ protocol.ByteCount) {
+	// CE marks are handled by OnECNFeedback.
+	if lostBytes == 0 {
+		return
+	}
+	// A loss is treated as a classic congestion signal.
+	if p.InRecovery() && p.inLossRecovery {
+		return
+	}
+	p.slowStartThreshold = 0
+	p.reduceCongestionWindow(renoBeta)
+	p.inLossRecovery = true
+}
+
+func (p *pragueSender) reduceCongestionWindow(factor float64) {
+	p.congestionWindow = max(protocol.ByteCount(factor*float64(p.congestionWindow)), p.minCongestionWindow())
+	p.bytesAckedInCA = 0
+	p.largestSentAtLastCutback = p.largestSent
+	p.traceState()
+}
+
+// OnPacketDiscarded is a no-op: the Prague sender doesn't keep any state for sent packets.
+func (p *pragueSender) OnPacketDiscarded(protocol.EncryptionLevel, protocol.PacketNumber) {}
+
+// OnPacketsDropped is a no-op: the Prague sender doesn't keep any state for sent packets.
+func (p *pragueSender) OnPacketsDropped(protocol.EncryptionLevel) {}
+
+// OnRetransmissionTimeout is called on an retransmission timeout
+func (p *pragueSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	p.largestSentAtLastCutback = protocol.InvalidPacketNumber
+	if !packetsRetransmitted {
+		return
+	}
+	p.slowStartThreshold = p.congestionWindow / 2
+	p.congestionWindow = p.minCongestionWindow()
+}
+
+func (p *pragueSender) minCongestionWindow() protocol.ByteCount {
+	return pragueMinCongestionWindowPackets * p.maxDatagramSize
+}
+
+func (p *pragueSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	if s < p.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", p.maxDatagramSize, s))
+	}
+	cwndIsMinCwnd := p.congestionWindow == p.minCongestionWindow()
+	p.maxDatagramSize = s
+	if cwndIsMinCwnd {
+		p.congestionWindow = p.minCongestionWindow()
+	}
+	p.pacer.SetMaxDatagramSize(s)
+}
+
+func (p *pragueSender) traceState() {
+	if p.tracer == nil || p.tracer.UpdatedCongestionState == nil {
+		return
+	}
+	var state logging.CongestionState
+	switch {
+	case p.InRecovery():
+		state = logging.CongestionStateRecovery
+	case p.InSlowStart():
+		state = logging.CongestionStateSlowStart
+	default:
+		state = logging.CongestionStateCongestionAvoidance
+	}
+	if state == p.lastState {
+		return
+	}
+	p.tracer.UpdatedCongestionState(state)
+	p.lastState = state
+}
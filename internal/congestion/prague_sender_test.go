@@ -0,0 +1,175 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Prague Sender", func() {
+	const (
+		packetSize = protocol.ByteCount(1200)
+		linkRate   = 10 * 1000 * 1000 * BitsPerSecond // 10 Mbit/s
+		baseRTT    = 20 * time.Millisecond
+		// an L4S AQM marks packets when the queueing delay exceeds this threshold
+		markingThreshold = time.Millisecond
+	)
+
+	type sentPacket struct {
+		pn       protocol.PacketNumber
+		sendTime time.Time
+		ackTime  time.Time
+		ce       bool
+	}
+
+	var (
+		sender        *pragueSender
+		rttStats      *utils.RTTStats
+		now           time.Time
+		packetNumber  protocol.PacketNumber
+		bytesInFlight protocol.ByteCount
+		outstanding   []sentPacket
+		linkFree      time.Time
+	)
+
+	BeforeEach(func() {
+		rttStats = utils.NewRTTStats()
+		sender = NewPragueSender(rttStats, packetSize, nil)
+		now = time.Now()
+		packetNumber = 0
+		bytesInFlight = 0
+		outstanding = nil
+		linkFree = time.Time{}
+	})
+
+	sendPacket := func() {
+		packetNumber++
+		bytesInFlight += packetSize
+		sender.OnPacketSent(now, bytesInFlight, protocol.Encryption1RTT, packetNumber, packetSize, true)
+		departure := now
+		if linkFree.After(departure) {
+			departure = linkFree
+		}
+		queueingDelay := departure.Sub(now)
+		departure = departure.Add(time.Duration(packetSize) * time.Second / time.Duration(linkRate/BytesPerSecond))
+		linkFree = departure
+		outstanding = append(outstanding, sentPacket{
+			pn:       packetNumber,
+			sendTime: now,
+			ackTime:  departure.Add(baseRTT),
+			ce:       queueingDelay > markingThreshold,
+		})
+	}
+
+	// simulate runs a bulk transfer over an L4S bottleneck with a bandwidth of linkRate
+	simulate := func(d time.Duration, onAck func()) {
+		end := now.Add(d)
+		for now.Before(end) {
+			for sender.CanSend(bytesInFlight) && sender.HasPacingBudget(now) {
+				sendPacket()
+			}
+			next := outstanding[0].ackTime
+			if sender.CanSend(bytesInFlight) {
+				if t := sender.TimeUntilSend(bytesInFlight); t.After(now) && t.Before(next) {
+					next = t
+				}
+			}
+			now = next
+			for len(outstanding) > 0 && !outstanding[0].ackTime.After(now) {
+				p := outstanding[0]
+				outstanding = outstanding[1:]
+				rttStats.UpdateRTT(now.Sub(p.sendTime), 0, now)
+				var ce int64
+				if p.ce {
+					ce = 1
+				}
+				sender.OnECNFeedback(1, ce)
+				sender.OnPacketAcked(protocol.Encryption1RTT, p.pn, packetSize, bytesInFlight, now)
+				bytesInFlight -= packetSize
+				if onAck != nil {
+					onAck()
+				}
+			}
+		}
+	}
+
+	It("starts in slow start", func() {
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * packetSize))
+	})
+
+	It("exits slow start on the first CE mark", func() {
+		sendPacket()
+		cwnd := sender.GetCongestionWindow()
+		sender.OnECNFeedback(1, 1)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.InRecovery()).To(BeTrue())
+		// alpha is initialized to 1
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd / 2))
+	})
+
+	It("utilizes the link while keeping the queue very short", func() {
+		simulate(3*time.Second, nil)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		var maxRTT time.Duration
+		var acked protocol.ByteCount
+		start := now
+		simulate(5*time.Second, func() {
+			acked += packetSize
+			maxRTT = max(maxRTT, rttStats.LatestRTT())
+		})
+		throughput := BandwidthFromDelta(acked, now.Sub(start))
+		Expect(throughput).To(BeNumerically(">", linkRate*9/10))
+		Expect(maxRTT).To(BeNumerically("<", baseRTT+5*markingThreshold))
+		Expect(sender.alpha).To(BeNumerically("<", 0.5))
+	})
+
+	It("reduces the congestion window at most once per RTT", func() {
+		simulate(3*time.Second, nil)
+		sender.OnECNFeedback(1, 1)
+		cwnd := sender.GetCongestionWindow()
+		Expect(sender.InRecovery()).To(BeTrue())
+		sender.OnECNFeedback(1, 1)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+	})
+
+	It("reduces the congestion window when packets are lost", func() {
+		simulate(3*time.Second, nil)
+		// wait until the sender is not in recovery
+		for sender.InRecovery() {
+			simulate(time.Millisecond, nil)
+		}
+		cwnd := sender.GetCongestionWindow()
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[0].pn, packetSize, bytesInFlight)
+		Expect(sender.InRecovery()).To(BeTrue())
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(renoBeta * float64(cwnd))))
+		sender.OnCongestionEvent(protocol.Encryption1RTT, outstanding[1].pn, packetSize, bytesInFlight)
+		Expect(sender.GetCongestionWindow()).To(Equal(protocol.ByteCount(renoBeta * float64(cwnd))))
+	})
+
+	It("ignores ECN-CE congestion events", func() {
+		cwnd := sender.GetCongestionWindow()
+		sender.OnCongestionEvent(protocol.Encryption1RTT, 1, 0, 0)
+		Expect(sender.GetCongestionWindow()).To(Equal(cwnd))
+		Expect(sender.InSlowStart()).To(BeTrue())
+	})
+
+	It("reduces the congestion window on a retransmission timeout", func() {
+		sender.OnRetransmissionTimeout(false)
+		Expect(sender.GetCongestionWindow()).To(Equal(initialCongestionWindow * packetSize))
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.GetCongestionWindow()).To(Equal(pragueMinCongestionWindowPackets * packetSize))
+	})
+
+	It("adjusts the minimum congestion window when the max datagram size changes", func() {
+		sender.OnRetransmissionTimeout(true)
+		sender.SetMaxDatagramSize(1400)
+		Expect(sender.GetCongestionWindow()).To(Equal(pragueMinCongestionWindowPackets * protocol.ByteCount(1400)))
+		Expect(func() { sender.SetMaxDatagramSize(1300) }).To(Panic())
+	})
+})
@@ -0,0 +1,46 @@
+package congestion
+
+import "github.com/quic-go/quic-go/internal/protocol"
+
+type sentPacketKey struct {
+	encLevel     protocol.EncryptionLevel
+	packetNumber protocol.PacketNumber
+}
+
+// sentPackets stores state for every packet sent, until the packet is acknowledged or lost.
+// Packet numbers are only unique within a packet number space, so packets are identified
+// by their encryption level and packet number.
+type sentPackets[T any] struct {
+	packets map[sentPacketKey]T
+}
+
+func newSentPackets[T any]() sentPackets[T] {
+	return sentPackets[T]{packets: make(map[sentPacketKey]T)}
+}
+
+func (s *sentPackets[T]) Add(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, p T) {
+	s.packets[sentPacketKey{encLevel: encLevel, packetNumber: pn}] = p
+}
+
+// Remove removes a packet, and returns its state.
+func (s *sentPackets[T]) Remove(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber) (T, bool) {
+	key := sentPacketKey{encLevel: encLevel, packetNumber: pn}
+	p, ok := s.packets[key]
+	if ok {
+		delete(s.packets, key)
+	}
+	return p, ok
+}
+
+// DropEncryptionLevel removes all packets sent at an encryption level.
+func (s *sentPackets[T]) DropEncryptionLevel(encLevel protocol.EncryptionLevel) {
+	for key := range s.packets {
+		if key.encLevel == encLevel {
+			delete(s.packets, key)
+		}
+	}
+}
+
+func (s *sentPackets[T]) Len() int {
+	return len(s.packets)
+}
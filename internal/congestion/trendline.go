@@ -0,0 +1,159 @@
+package congestion
+
+import (
+	"math"
+	"time"
+)
+
+// This is a delay gradient estimator, modeled after the trendline filter of Google Congestion Control
+// (draft-ietf-rmcat-gcc-02 and its implementation in WebRTC).
+// It estimates the slope of the queueing delay over the most recent samples using linear regression,
+// and compares it to an adaptive threshold.
+
+const (
+	// The number of delay samples used for the linear regression.
+	trendlineWindowSize = 20
+	// The smoothing coefficient applied to the delay samples.
+	trendlineSmoothingCoef = 0.9
+	// The gain applied to the trend before comparing it to the threshold.
+	trendlineThresholdGain = 4
+	// The trend is multiplied by the number of samples, up to this limit.
+	trendlineMaxDeltas = 60
+	// Overuse is only signaled if the trend stayed above the threshold for this long.
+	trendlineOveruseTime = 10 * time.Millisecond
+	// Adaptation rate of the threshold, when the trend is above / below the threshold.
+	trendlineThresholdUp   = 0.0087
+	trendlineThresholdDown = 0.039
+	// Bounds of the adaptive threshold, in milliseconds.
+	trendlineMinThreshold     = 6
+	trendlineMaxThreshold     = 600
+	trendlineInitialThreshold = 12.5
+)
+
+type bandwidthUsage uint8
+
+const (
+	bandwidthUsageNormal bandwidthUsage = iota
+	bandwidthUsageUnderusing
+	bandwidthUsageOverusing
+)
+
+type trendlineSample struct {
+	time  float64 // in ms, relative to the first sample
+	delay float64 // smoothed delay, in ms
+}
+
+type trendlineEstimator struct {
+	firstSample    time.Time
+	lastSample     time.Time
+	numDeltas      int
+	smoothedDelay  float64
+	samples        []trendlineSample
+	prevTrend      float64
+	threshold      float64
+	lastThreshold  time.Time
+	timeOverUsing  time.Duration
+	overuseCounter int
+	usage          bandwidthUsage
+}
+
+func newTrendlineEstimator() *trendlineEstimator {
+	return &trendlineEstimator{
+		threshold: trendlineInitialThreshold,
+		samples:   make([]trendlineSample, 0, trendlineWindowSize),
+	}
+}
+
+// Update adds a new sample of the queueing delay, and returns the current bandwidth usage.
+func (e *trendlineEstimator) Update(now time.Time, queueingDelay time.Duration) bandwidthUsage {
+	delay := float64(queueingDelay) / float64(time.Millisecond)
+	if e.firstSample.IsZero() {
+		e.firstSample = now
+		e.lastSample = now
+		e.smoothedDelay = delay
+	}
+	sinceLast := now.Sub(e.lastSample)
+	e.lastSample = now
+	e.numDeltas = min(e.numDeltas+1, trendlineMaxDeltas)
+	e.smoothedDelay = trendlineSmoothingCoef*e.smoothedDelay + (1-trendlineSmoothingCoef)*delay
+	if len(e.samples) == trendlineWindowSize {
+		copy(e.samples, e.samples[1:])
+		e.samples = e.samples[:trendlineWindowSize-1]
+	}
+	e.samples = append(e.samples, trendlineSample{
+		time:  float64(now.Sub(e.firstSample)) / float64(time.Millisecond),
+		delay: e.smoothedDelay,
+	})
+	trend := e.prevTrend
+	if len(e.samples) == trendlineWindowSize {
+		if slope, ok := linearFitSlope(e.samples); ok {
+			trend = slope
+		}
+	}
+	e.detect(trend, sinceLast, now)
+	return e.usage
+}
+
+func (e *trendlineEstimator) detect(trend float64, sinceLast time.Duration, now time.Time) {
+	modifiedTrend := float64(e.numDeltas) * trend * trendlineThresholdGain
+	switch {
+	case modifiedTrend > e.threshold:
+		e.timeOverUsing += sinceLast
+		e.overuseCounter++
+		if e.timeOverUsing > trendlineOveruseTime && e.overuseCounter > 1 && trend >= e.prevTrend {
+			e.timeOverUsing = 0
+			e.overuseCounter = 0
+			e.usage = bandwidthUsageOverusing
+		}
+	case modifiedTrend < -e.threshold:
+		e.timeOverUsing = 0
+		e.overuseCounter = 0
+		e.usage = bandwidthUsageUnderusing
+	default:
+		e.timeOverUsing = 0
+		e.overuseCounter = 0
+		e.usage = bandwidthUsageNormal
+	}
+	e.prevTrend = trend
+	e.updateThreshold(modifiedTrend, now)
+}
+
+func (e *trendlineEstimator) updateThreshold(modifiedTrend float64, now time.Time) {
+	if e.lastThreshold.IsZero() {
+		e.lastThreshold = now
+	}
+	absTrend := math.Abs(modifiedTrend)
+	// Don't adapt the threshold to sudden large delay spikes.
+	if absTrend > e.threshold+15 {
+		e.lastThreshold = now
+		return
+	}
+	k := trendlineThresholdUp
+	if absTrend < e.threshold {
+		k = trendlineThresholdDown
+	}
+	elapsed := min(now.Sub(e.lastThreshold), 100*time.Millisecond)
+	e.threshold += k * (absTrend - e.threshold) * float64(elapsed) / float64(time.Millisecond)
+	e.threshold = min(max(e.threshold, trendlineMinThreshold), trendlineMaxThreshold)
+	e.lastThreshold = now
+}
+
+// linearFitSlope calculates the slope of the least-squares fit of the samples.
+func linearFitSlope(samples []trendlineSample) (float64, bool) {
+	var sumX, sumY float64
+	for _, s := range samples {
+		sumX += s.time
+		sumY += s.delay
+	}
+	avgX := sumX / float64(len(samples))
+	avgY := sumY / float64(len(samples))
+	var num, denom float64
+	for _, s := range samples {
+		num += (s.time - avgX) * (s.delay - avgY)
+		denom += (s.time - avgX) * (s.time - avgX)
+	}
+	if denom == 0 {
+		return 0, false
+	}
+	return num / denom, true
+}
@@ -0,0 +1,54 @@
+package congestion
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Trendline Estimator", func() {
+	var (
+		estimator *trendlineEstimator
+		now       time.Time
+	)
+
+	BeforeEach(func() {
+		estimator = newTrendlineEstimator()
+		now = time.Now()
+	})
+
+	// feed adds n samples, 5ms apart, with the queueing delay growing by slope for every sample
+	feed := func(n int, delay, slope time.Duration) (bandwidthUsage, time.Duration) {
+		var usage bandwidthUsage
+		for i := 0; i < n; i++ {
+			now = now.Add(5 * time.Millisecond)
+			delay += slope
+			usage = estimator.Update(now, max(delay, 0))
+		}
+		return usage, delay
+	}
+
+	It("detects normal usage if the delay is constant", func() {
+		usage, _ := feed(100, 10*time.Millisecond, 0)
+		Expect(usage).To(Equal(bandwidthUsageNormal))
+	})
+
+	It("detects overuse if the delay is increasing", func() {
+		usage, _ := feed(100, 0, time.Millisecond)
+		Expect(usage).To(Equal(bandwidthUsageOverusing))
+	})
+
+	It("detects underuse if the delay is decreasing", func() {
+		_, delay := feed(100, 0, time.Millisecond)
+		usage, _ := feed(20, delay, -time.Millisecond)
+		Expect(usage).To(Equal(bandwidthUsageUnderusing))
+	})
+
+	It("keeps the threshold within bounds", func() {
+		feed(1000, 0, 0)
+		Expect(estimator.threshold).To(BeNumerically(">=", trendlineMinThreshold))
+		feed(1000, 0, 10*time.Millisecond)
+		Expect(estimator.threshold).To(BeNumerically("<=", trendlineMaxThreshold))
+	})
+})
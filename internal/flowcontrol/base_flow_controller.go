@@ -22,6 +22,8 @@ type baseFlowController struct {
 	receiveWindow        protocol.ByteCount
 	receiveWindowSize    protocol.ByteCount
 	maxReceiveWindowSize protocol.ByteCount
+	// set when the receive window size was increased, and a window update needs to be sent
+	receiveWindowIncreased bool
 
 	allowWindowIncrease func(size protocol.ByteCount) bool
 
@@ -83,9 +85,10 @@ func (c *baseFlowController) hasWindowUpdate() bool {
 // getWindowUpdate updates the receive window, if necessary
 // it returns the new offset
 func (c *baseFlowController) getWindowUpdate() protocol.ByteCount {
-	if !c.hasWindowUpdate() {
+	if !c.hasWindowUpdate() && !c.receiveWindowIncreased {
 		return 0
 	}
+	c.receiveWindowIncreased = false
 
 	c.maybeAdjustWindowSize()
 	c.receiveWindow = c.bytesRead + c.receiveWindowSize
@@ -117,6 +120,19 @@ func (c *baseFlowController) maybeAdjustWindowSize() {
 	c.startNewAutoTuningEpoch(now)
 }
 
+// setReceiveWindowSize sets the size of the receive window.
+// It returns true if the peer can be granted additional flow control credit.
+// needs to be called with locked mutex
+func (c *baseFlowController) setReceiveWindowSize(size protocol.ByteCount) bool {
+	c.receiveWindowSize = size
+	c.maxReceiveWindowSize = size
+	if c.bytesRead+size <= c.receiveWindow {
+		return false
+	}
+	c.receiveWindowIncreased = true
+	return true
+}
+
 func (c *baseFlowController) startNewAutoTuningEpoch(now time.Time) {
 	c.epochStartTime = now
 	c.epochStartOffset = c.bytesRead
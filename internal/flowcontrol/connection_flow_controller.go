@@ -14,21 +14,26 @@ type connectionFlowController struct {
 	baseFlowController
 
 	queueWindowUpdate func()
+
+	memoryBudget *MemoryBudget
+	closed       bool
 }
 
 var _ ConnectionFlowController = &connectionFlowController{}
 
 // NewConnectionFlowController gets a new flow controller for the connection
 // It is created before we receive the peer's transport parameters, thus it starts with a sendWindow of 0.
+// If a MemoryBudget is passed, increases of the receive window are drawn from that budget.
 func NewConnectionFlowController(
 	receiveWindow protocol.ByteCount,
 	maxReceiveWindow protocol.ByteCount,
 	queueWindowUpdate func(),
 	allowWindowIncrease func(size protocol.ByteCount) bool,
+	memoryBudget *MemoryBudget,
 	rttStats *utils.RTTStats,
 	logger utils.Logger,
 ) ConnectionFlowController {
-	return &connectionFlowController{
+	c := &connectionFlowController{
 		baseFlowController: baseFlowController{
 			rttStats:             rttStats,
 			receiveWindow:        receiveWindow,
@@ -38,7 +43,19 @@ func NewConnectionFlowController(
 			logger:               logger,
 		},
 		queueWindowUpdate: queueWindowUpdate,
+		memoryBudget:      memoryBudget,
+	}
+	if memoryBudget != nil {
+		memoryBudget.register(receiveWindow)
+		c.allowWindowIncrease = func(size protocol.ByteCount) bool {
+			if allowWindowIncrease != nil && !allowWindowIncrease(size) {
+				return false
+			}
+			// called with the mutex held
+			return memoryBudget.reserve(c.receiveWindowSize, size)
+		}
 	}
+	return c
 }
 
 func (c *connectionFlowController) SendWindowSize() protocol.ByteCount {
@@ -96,6 +113,20 @@ func (c *connectionFlowController) EnsureMinimumWindowSize(inc protocol.ByteCoun
 	c.mutex.Unlock()
 }
 
+// Close releases the memory reserved from the memory budget.
+func (c *connectionFlowController) Close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	if c.memoryBudget != nil {
+		c.memoryBudget.unregister(c.receiveWindowSize)
+	}
+}
+
 // Reset rests the flow controller. This happens when 0-RTT is rejected.
 // All stream data is invalidated, it's if we had never opened a stream and never sent any data.
 // At that point, we only have sent stream data, but we didn't have the keys to open 1-RTT keys yet.
@@ -43,6 +43,7 @@ var _ = Describe("Connection Flow controller", func() {
 				maxReceiveWindow,
 				nil,
 				func(protocol.ByteCount) bool { return true },
+				nil,
 				rttStats,
 				utils.DefaultLogger).(*connectionFlowController)
 			Expect(fc.receiveWindow).To(Equal(receiveWindow))
@@ -160,6 +161,50 @@ var _ = Describe("Connection Flow controller", func() {
 		})
 	})
 
+	Context("memory budget", func() {
+		newController := func(budget *MemoryBudget) *connectionFlowController {
+			return NewConnectionFlowController(
+				100,
+				1000,
+				func() {},
+				func(protocol.ByteCount) bool { return true },
+				budget,
+				&utils.RTTStats{},
+				utils.DefaultLogger,
+			).(*connectionFlowController)
+		}
+
+		It("draws window increases from the budget", func() {
+			budget := NewMemoryBudget(1000)
+			fc := newController(budget)
+			Expect(budget.Stats().Used).To(Equal(protocol.ByteCount(100)))
+			fc.EnsureMinimumWindowSize(300)
+			Expect(fc.receiveWindowSize).To(Equal(protocol.ByteCount(300)))
+			Expect(budget.Stats().Used).To(Equal(protocol.ByteCount(300)))
+			fc.Close()
+			Expect(budget.Stats()).To(Equal(MemoryBudgetStats{Limit: 1000}))
+			// closing multiple times doesn't release the memory twice
+			fc.Close()
+			Expect(budget.Stats()).To(Equal(MemoryBudgetStats{Limit: 1000}))
+		})
+
+		It("doesn't increase the window beyond the budget", func() {
+			budget := NewMemoryBudget(250)
+			fc := newController(budget)
+			fc.EnsureMinimumWindowSize(300)
+			Expect(fc.receiveWindowSize).To(Equal(protocol.ByteCount(100)))
+			Expect(budget.Stats().DeniedIncreases).To(BeEquivalentTo(1))
+		})
+
+		It("doesn't draw from the budget if the increase is not allowed", func() {
+			budget := NewMemoryBudget(1000)
+			fc := NewConnectionFlowController(100, 1000, func() {}, func(protocol.ByteCount) bool { return false }, budget, &utils.RTTStats{}, utils.DefaultLogger)
+			fc.(*connectionFlowController).EnsureMinimumWindowSize(300)
+			Expect(budget.Stats().Used).To(Equal(protocol.ByteCount(100)))
+			Expect(budget.Stats().DeniedIncreases).To(BeZero())
+		})
+	})
+
 	Context("resetting", func() {
 		It("resets", func() {
 			const initialWindow protocol.ByteCount = 1337
@@ -23,12 +23,18 @@ type StreamFlowController interface {
 	// Abandon is called when reading from the stream is aborted early,
 	// and there won't be any further calls to AddBytesRead.
 	Abandon()
+	// SetReceiveWindowSize sets the size of the receive window, disabling auto-tuning beyond that size.
+	// If the window is increased, a window update is queued.
+	SetReceiveWindowSize(protocol.ByteCount)
 }
 
 // The ConnectionFlowController is the flow controller for the connection.
 type ConnectionFlowController interface {
 	flowController
 	Reset() error
+	// Close is called when the connection is closed.
+	// It releases the memory reserved from the memory budget.
+	Close()
 }
 
 type connectionFlowControllerI interface {
@@ -0,0 +1,77 @@
+package flowcontrol
+
+import (
+	"sync"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// A MemoryBudget limits the total size of the receive windows of all connection flow controllers drawing from it.
+// The initial receive window of a connection is always granted.
+// Increases of the window size (due to auto-tuning) are only granted as long as the total stays within the budget.
+// To share the budget fairly, a connection can't grow its window beyond the budget divided by the number of connections.
+// Stream flow controllers don't draw from the budget: the data buffered on all streams is bounded by the connection window.
+type MemoryBudget struct {
+	mutex sync.Mutex
+
+	limit        protocol.ByteCount // 0 means unlimited
+	used         protocol.ByteCount
+	numConsumers int
+	numDenied    uint64
+}
+
+// MemoryBudgetStats contains statistics about the usage of a MemoryBudget.
+type MemoryBudgetStats struct {
+	Limit           protocol.ByteCount
+	Used            protocol.ByteCount
+	NumConsumers    int
+	DeniedIncreases uint64
+}
+
+// NewMemoryBudget creates a new memory budget.
+// If limit is 0, the memory usage is tracked, but not limited.
+func NewMemoryBudget(limit protocol.ByteCount) *MemoryBudget {
+	return &MemoryBudget{limit: limit}
+}
+
+func (b *MemoryBudget) register(initial protocol.ByteCount) {
+	b.mutex.Lock()
+	b.used += initial
+	b.numConsumers++
+	b.mutex.Unlock()
+}
+
+func (b *MemoryBudget) unregister(reserved protocol.ByteCount) {
+	b.mutex.Lock()
+	b.used -= reserved
+	b.numConsumers--
+	b.mutex.Unlock()
+}
+
+// reserve is called when a consumer that currently holds reserved bytes wants to increase its window by delta.
+func (b *MemoryBudget) reserve(reserved, delta protocol.ByteCount) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.limit > 0 {
+		fairShare := b.limit / protocol.ByteCount(max(b.numConsumers, 1))
+		if b.used+delta > b.limit || reserved+delta > fairShare {
+			b.numDenied++
+			return false
+		}
+	}
+	b.used += delta
+	return true
+}
+
+// Stats returns statistics about the memory usage.
+func (b *MemoryBudget) Stats() MemoryBudgetStats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return MemoryBudgetStats{
+		Limit:           b.limit,
+		Used:            b.used,
+		NumConsumers:    b.numConsumers,
+		DeniedIncreases: b.numDenied,
+	}
+}
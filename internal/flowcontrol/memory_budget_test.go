@@ -0,0 +1,52 @@
+package flowcontrol
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Memory Budget", func() {
+	It("tracks the memory usage without a limit", func() {
+		b := NewMemoryBudget(0)
+		b.register(100)
+		b.register(200)
+		Expect(b.reserve(100, 1<<30)).To(BeTrue())
+		Expect(b.Stats()).To(Equal(MemoryBudgetStats{Used: 300 + 1<<30, NumConsumers: 2}))
+		b.unregister(100 + 1<<30)
+		Expect(b.Stats()).To(Equal(MemoryBudgetStats{Used: 200, NumConsumers: 1}))
+	})
+
+	It("doesn't exceed the limit", func() {
+		b := NewMemoryBudget(1000)
+		b.register(100)
+		Expect(b.reserve(100, 901)).To(BeFalse())
+		Expect(b.reserve(100, 900)).To(BeTrue())
+		Expect(b.reserve(1000, 1)).To(BeFalse())
+		Expect(b.Stats()).To(Equal(MemoryBudgetStats{Limit: 1000, Used: 1000, NumConsumers: 1, DeniedIncreases: 2}))
+	})
+
+	It("always grants the initial window", func() {
+		b := NewMemoryBudget(100)
+		b.register(80)
+		b.register(80)
+		Expect(b.Stats().Used).To(Equal(protocol.ByteCount(160)))
+		Expect(b.reserve(80, 1)).To(BeFalse())
+	})
+
+	It("shares the budget fairly", func() {
+		b := NewMemoryBudget(1000)
+		b.register(100)
+		b.register(100)
+		// each consumer can grow up to half of the budget
+		Expect(b.reserve(100, 401)).To(BeFalse())
+		Expect(b.reserve(100, 400)).To(BeTrue())
+		Expect(b.reserve(100, 400)).To(BeTrue())
+		Expect(b.Stats().Used).To(Equal(protocol.ByteCount(1000)))
+		// When a consumer goes away, the remaining consumer can use the whole budget.
+		b.unregister(500)
+		Expect(b.reserve(500, 500)).To(BeTrue())
+		Expect(b.Stats().Used).To(Equal(protocol.ByteCount(1000)))
+	})
+})
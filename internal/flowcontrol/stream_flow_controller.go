@@ -118,6 +118,18 @@ func (c *streamFlowController) Abandon() {
 	}
 }
 
+func (c *streamFlowController) SetReceiveWindowSize(size protocol.ByteCount) {
+	c.mutex.Lock()
+	increased := c.baseFlowController.setReceiveWindowSize(size)
+	shouldQueueWindowUpdate := increased && !c.receivedFinalOffset
+	c.mutex.Unlock()
+	if !shouldQueueWindowUpdate {
+		return
+	}
+	c.connection.EnsureMinimumWindowSize(protocol.ByteCount(float64(size) * protocol.ConnectionFlowControlMultiplier))
+	c.queueWindowUpdate()
+}
+
 func (c *streamFlowController) AddBytesSent(n protocol.ByteCount) {
 	c.baseFlowController.AddBytesSent(n)
 	c.connection.AddBytesSent(n)
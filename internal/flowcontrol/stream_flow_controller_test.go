@@ -27,6 +27,7 @@ var _ = Describe("Stream Flow controller", func() {
 				1000,
 				func() {},
 				func(protocol.ByteCount) bool { return true },
+				nil,
 				rttStats,
 				utils.DefaultLogger,
 			).(*connectionFlowController),
@@ -44,7 +45,7 @@ var _ = Describe("Stream Flow controller", func() {
 		const sendWindow protocol.ByteCount = 4000
 
 		It("sets the send and receive windows", func() {
-			cc := NewConnectionFlowController(0, 0, nil, func(protocol.ByteCount) bool { return true }, nil, utils.DefaultLogger)
+			cc := NewConnectionFlowController(0, 0, nil, func(protocol.ByteCount) bool { return true }, nil, nil, utils.DefaultLogger)
 			fc := NewStreamFlowController(5, cc, receiveWindow, maxReceiveWindow, sendWindow, nil, rttStats, utils.DefaultLogger).(*streamFlowController)
 			Expect(fc.streamID).To(Equal(protocol.StreamID(5)))
 			Expect(fc.receiveWindow).To(Equal(receiveWindow))
@@ -59,7 +60,7 @@ var _ = Describe("Stream Flow controller", func() {
 				queued = true
 			}
 
-			cc := NewConnectionFlowController(receiveWindow, maxReceiveWindow, func() {}, func(protocol.ByteCount) bool { return true }, nil, utils.DefaultLogger)
+			cc := NewConnectionFlowController(receiveWindow, maxReceiveWindow, func() {}, func(protocol.ByteCount) bool { return true }, nil, nil, utils.DefaultLogger)
 			fc := NewStreamFlowController(5, cc, receiveWindow, maxReceiveWindow, sendWindow, queueWindowUpdate, rttStats, utils.DefaultLogger).(*streamFlowController)
 			fc.AddBytesRead(receiveWindow)
 			Expect(queued).To(BeTrue())
@@ -237,6 +238,32 @@ var _ = Describe("Stream Flow controller", func() {
 				Expect(controller.connection.(*connectionFlowController).receiveWindowSize).To(Equal(oldConnectionSize))
 			})
 
+			It("queues a window update when the window size is increased", func() {
+				controller.SetReceiveWindowSize(200)
+				Expect(queuedWindowUpdate).To(BeTrue())
+				Expect(controller.receiveWindowSize).To(Equal(protocol.ByteCount(200)))
+				Expect(controller.maxReceiveWindowSize).To(Equal(protocol.ByteCount(200)))
+				Expect(controller.connection.(*connectionFlowController).receiveWindowSize).To(Equal(protocol.ByteCount(200 * protocol.ConnectionFlowControlMultiplier)))
+				Expect(controller.GetWindowUpdate()).To(Equal(protocol.ByteCount(40 + 200)))
+				Expect(controller.GetWindowUpdate()).To(BeZero())
+			})
+
+			It("doesn't queue a window update when the window size is decreased", func() {
+				controller.SetReceiveWindowSize(30)
+				Expect(queuedWindowUpdate).To(BeFalse())
+				Expect(controller.GetWindowUpdate()).To(BeZero())
+				// the new window size is used once the peer used up its flow control credit
+				controller.AddBytesRead(40)
+				Expect(queuedWindowUpdate).To(BeTrue())
+				Expect(controller.GetWindowUpdate()).To(Equal(protocol.ByteCount(80 + 30)))
+			})
+
+			It("doesn't queue a window update after a final offset was already received", func() {
+				Expect(controller.UpdateHighestReceived(90, true)).To(Succeed())
+				controller.SetReceiveWindowSize(200)
+				Expect(queuedWindowUpdate).To(BeFalse())
+			})
+
 			It("sends a connection-level window update when a large stream is abandoned", func() {
 				Expect(controller.UpdateHighestReceived(90, true)).To(Succeed())
 				Expect(controller.connection.GetWindowUpdate()).To(BeZero())
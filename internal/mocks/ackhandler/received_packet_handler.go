@@ -191,6 +191,78 @@ func (c *MockReceivedPacketHandlerIsPotentiallyDuplicateCall) DoAndReturn(f func
 	return c
 }
 
+// ReceivedAckFrequencyFrame mocks base method.
+func (m *MockReceivedPacketHandler) ReceivedAckFrequencyFrame(arg0 *wire.AckFrequencyFrame) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReceivedAckFrequencyFrame", arg0)
+}
+
+// ReceivedAckFrequencyFrame indicates an expected call of ReceivedAckFrequencyFrame.
+func (mr *MockReceivedPacketHandlerMockRecorder) ReceivedAckFrequencyFrame(arg0 any) *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedAckFrequencyFrame", reflect.TypeOf((*MockReceivedPacketHandler)(nil).ReceivedAckFrequencyFrame), arg0)
+	return &MockReceivedPacketHandlerReceivedAckFrequencyFrameCall{Call: call}
+}
+
+// MockReceivedPacketHandlerReceivedAckFrequencyFrameCall wrap *gomock.Call
+type MockReceivedPacketHandlerReceivedAckFrequencyFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall) Return() *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall) Do(f func(*wire.AckFrequencyFrame)) *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall) DoAndReturn(f func(*wire.AckFrequencyFrame)) *MockReceivedPacketHandlerReceivedAckFrequencyFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ReceivedImmediateAckFrame mocks base method.
+func (m *MockReceivedPacketHandler) ReceivedImmediateAckFrame() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReceivedImmediateAckFrame")
+}
+
+// ReceivedImmediateAckFrame indicates an expected call of ReceivedImmediateAckFrame.
+func (mr *MockReceivedPacketHandlerMockRecorder) ReceivedImmediateAckFrame() *MockReceivedPacketHandlerReceivedImmediateAckFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceivedImmediateAckFrame", reflect.TypeOf((*MockReceivedPacketHandler)(nil).ReceivedImmediateAckFrame))
+	return &MockReceivedPacketHandlerReceivedImmediateAckFrameCall{Call: call}
+}
+
+// MockReceivedPacketHandlerReceivedImmediateAckFrameCall wrap *gomock.Call
+type MockReceivedPacketHandlerReceivedImmediateAckFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceivedPacketHandlerReceivedImmediateAckFrameCall) Return() *MockReceivedPacketHandlerReceivedImmediateAckFrameCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceivedPacketHandlerReceivedImmediateAckFrameCall) Do(f func()) *MockReceivedPacketHandlerReceivedImmediateAckFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceivedPacketHandlerReceivedImmediateAckFrameCall) DoAndReturn(f func()) *MockReceivedPacketHandlerReceivedImmediateAckFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ReceivedPacket mocks base method.
 func (m *MockReceivedPacketHandler) ReceivedPacket(arg0 protocol.PacketNumber, arg1 protocol.ECN, arg2 protocol.EncryptionLevel, arg3 time.Time, arg4 bool) error {
 	m.ctrl.T.Helper()
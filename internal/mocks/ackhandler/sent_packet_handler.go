@@ -42,6 +42,44 @@ func (m *MockSentPacketHandler) EXPECT() *MockSentPacketHandlerMockRecorder {
 	return m.recorder
 }
 
+// CongestionWindow mocks base method.
+func (m *MockSentPacketHandler) CongestionWindow() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CongestionWindow")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// CongestionWindow indicates an expected call of CongestionWindow.
+func (mr *MockSentPacketHandlerMockRecorder) CongestionWindow() *MockSentPacketHandlerCongestionWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CongestionWindow", reflect.TypeOf((*MockSentPacketHandler)(nil).CongestionWindow))
+	return &MockSentPacketHandlerCongestionWindowCall{Call: call}
+}
+
+// MockSentPacketHandlerCongestionWindowCall wrap *gomock.Call
+type MockSentPacketHandlerCongestionWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerCongestionWindowCall) Return(arg0 protocol.ByteCount) *MockSentPacketHandlerCongestionWindowCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerCongestionWindowCall) Do(f func() protocol.ByteCount) *MockSentPacketHandlerCongestionWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerCongestionWindowCall) DoAndReturn(f func() protocol.ByteCount) *MockSentPacketHandlerCongestionWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // DropPackets mocks base method.
 func (m *MockSentPacketHandler) DropPackets(arg0 protocol.EncryptionLevel) {
 	m.ctrl.T.Helper()
@@ -116,6 +154,80 @@ func (c *MockSentPacketHandlerECNModeCall) DoAndReturn(f func(bool) protocol.ECN
 	return c
 }
 
+// EnableAckFrequency mocks base method.
+func (m *MockSentPacketHandler) EnableAckFrequency(arg0 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EnableAckFrequency", arg0)
+}
+
+// EnableAckFrequency indicates an expected call of EnableAckFrequency.
+func (mr *MockSentPacketHandlerMockRecorder) EnableAckFrequency(arg0 any) *MockSentPacketHandlerEnableAckFrequencyCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableAckFrequency", reflect.TypeOf((*MockSentPacketHandler)(nil).EnableAckFrequency), arg0)
+	return &MockSentPacketHandlerEnableAckFrequencyCall{Call: call}
+}
+
+// MockSentPacketHandlerEnableAckFrequencyCall wrap *gomock.Call
+type MockSentPacketHandlerEnableAckFrequencyCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerEnableAckFrequencyCall) Return() *MockSentPacketHandlerEnableAckFrequencyCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerEnableAckFrequencyCall) Do(f func(time.Duration)) *MockSentPacketHandlerEnableAckFrequencyCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerEnableAckFrequencyCall) DoAndReturn(f func(time.Duration)) *MockSentPacketHandlerEnableAckFrequencyCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetAckFrequencyFrame mocks base method.
+func (m *MockSentPacketHandler) GetAckFrequencyFrame(arg0 time.Time) *wire.AckFrequencyFrame {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAckFrequencyFrame", arg0)
+	ret0, _ := ret[0].(*wire.AckFrequencyFrame)
+	return ret0
+}
+
+// GetAckFrequencyFrame indicates an expected call of GetAckFrequencyFrame.
+func (mr *MockSentPacketHandlerMockRecorder) GetAckFrequencyFrame(arg0 any) *MockSentPacketHandlerGetAckFrequencyFrameCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAckFrequencyFrame", reflect.TypeOf((*MockSentPacketHandler)(nil).GetAckFrequencyFrame), arg0)
+	return &MockSentPacketHandlerGetAckFrequencyFrameCall{Call: call}
+}
+
+// MockSentPacketHandlerGetAckFrequencyFrameCall wrap *gomock.Call
+type MockSentPacketHandlerGetAckFrequencyFrameCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerGetAckFrequencyFrameCall) Return(arg0 *wire.AckFrequencyFrame) *MockSentPacketHandlerGetAckFrequencyFrameCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerGetAckFrequencyFrameCall) Do(f func(time.Time) *wire.AckFrequencyFrame) *MockSentPacketHandlerGetAckFrequencyFrameCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerGetAckFrequencyFrameCall) DoAndReturn(f func(time.Time) *wire.AckFrequencyFrame) *MockSentPacketHandlerGetAckFrequencyFrameCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetLossDetectionTimeout mocks base method.
 func (m *MockSentPacketHandler) GetLossDetectionTimeout() time.Time {
 	m.ctrl.T.Helper()
@@ -154,6 +266,78 @@ func (c *MockSentPacketHandlerGetLossDetectionTimeoutCall) DoAndReturn(f func()
 	return c
 }
 
+// MigratedPath mocks base method.
+func (m *MockSentPacketHandler) MigratedPath(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MigratedPath", arg0)
+}
+
+// MigratedPath indicates an expected call of MigratedPath.
+func (mr *MockSentPacketHandlerMockRecorder) MigratedPath(arg0 any) *MockSentPacketHandlerMigratedPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratedPath", reflect.TypeOf((*MockSentPacketHandler)(nil).MigratedPath), arg0)
+	return &MockSentPacketHandlerMigratedPathCall{Call: call}
+}
+
+// MockSentPacketHandlerMigratedPathCall wrap *gomock.Call
+type MockSentPacketHandlerMigratedPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerMigratedPathCall) Return() *MockSentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerMigratedPathCall) Do(f func(protocol.ByteCount)) *MockSentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerMigratedPathCall) DoAndReturn(f func(protocol.ByteCount)) *MockSentPacketHandlerMigratedPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MigratedToUnvalidatedPath mocks base method.
+func (m *MockSentPacketHandler) MigratedToUnvalidatedPath(arg0, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MigratedToUnvalidatedPath", arg0, arg1)
+}
+
+// MigratedToUnvalidatedPath indicates an expected call of MigratedToUnvalidatedPath.
+func (mr *MockSentPacketHandlerMockRecorder) MigratedToUnvalidatedPath(arg0, arg1 any) *MockSentPacketHandlerMigratedToUnvalidatedPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratedToUnvalidatedPath", reflect.TypeOf((*MockSentPacketHandler)(nil).MigratedToUnvalidatedPath), arg0, arg1)
+	return &MockSentPacketHandlerMigratedToUnvalidatedPathCall{Call: call}
+}
+
+// MockSentPacketHandlerMigratedToUnvalidatedPathCall wrap *gomock.Call
+type MockSentPacketHandlerMigratedToUnvalidatedPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerMigratedToUnvalidatedPathCall) Return() *MockSentPacketHandlerMigratedToUnvalidatedPathCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerMigratedToUnvalidatedPathCall) Do(f func(protocol.ByteCount, protocol.ByteCount)) *MockSentPacketHandlerMigratedToUnvalidatedPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerMigratedToUnvalidatedPathCall) DoAndReturn(f func(protocol.ByteCount, protocol.ByteCount)) *MockSentPacketHandlerMigratedToUnvalidatedPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // OnLossDetectionTimeout mocks base method.
 func (m *MockSentPacketHandler) OnLossDetectionTimeout() error {
 	m.ctrl.T.Helper()
@@ -192,6 +376,42 @@ func (c *MockSentPacketHandlerOnLossDetectionTimeoutCall) DoAndReturn(f func() e
 	return c
 }
 
+// PathValidated mocks base method.
+func (m *MockSentPacketHandler) PathValidated() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PathValidated")
+}
+
+// PathValidated indicates an expected call of PathValidated.
+func (mr *MockSentPacketHandlerMockRecorder) PathValidated() *MockSentPacketHandlerPathValidatedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PathValidated", reflect.TypeOf((*MockSentPacketHandler)(nil).PathValidated))
+	return &MockSentPacketHandlerPathValidatedCall{Call: call}
+}
+
+// MockSentPacketHandlerPathValidatedCall wrap *gomock.Call
+type MockSentPacketHandlerPathValidatedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerPathValidatedCall) Return() *MockSentPacketHandlerPathValidatedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerPathValidatedCall) Do(f func()) *MockSentPacketHandlerPathValidatedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerPathValidatedCall) DoAndReturn(f func()) *MockSentPacketHandlerPathValidatedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // PeekPacketNumber mocks base method.
 func (m *MockSentPacketHandler) PeekPacketNumber(arg0 protocol.EncryptionLevel) (protocol.PacketNumber, protocol.PacketNumberLen) {
 	m.ctrl.T.Helper()
@@ -459,15 +679,15 @@ func (c *MockSentPacketHandlerSendModeCall) DoAndReturn(f func(time.Time) ackhan
 }
 
 // SentPacket mocks base method.
-func (m *MockSentPacketHandler) SentPacket(arg0 time.Time, arg1, arg2 protocol.PacketNumber, arg3 []ackhandler.StreamFrame, arg4 []ackhandler.Frame, arg5 protocol.EncryptionLevel, arg6 protocol.ECN, arg7 protocol.ByteCount, arg8 bool) {
+func (m *MockSentPacketHandler) SentPacket(arg0 time.Time, arg1, arg2 protocol.PacketNumber, arg3 []ackhandler.StreamFrame, arg4 []ackhandler.Frame, arg5 protocol.EncryptionLevel, arg6 protocol.ECN, arg7 protocol.ByteCount, arg8, arg9 bool) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "SentPacket", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+	m.ctrl.Call(m, "SentPacket", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
 }
 
 // SentPacket indicates an expected call of SentPacket.
-func (mr *MockSentPacketHandlerMockRecorder) SentPacket(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8 any) *MockSentPacketHandlerSentPacketCall {
+func (mr *MockSentPacketHandlerMockRecorder) SentPacket(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9 any) *MockSentPacketHandlerSentPacketCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentPacket", reflect.TypeOf((*MockSentPacketHandler)(nil).SentPacket), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SentPacket", reflect.TypeOf((*MockSentPacketHandler)(nil).SentPacket), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9)
 	return &MockSentPacketHandlerSentPacketCall{Call: call}
 }
 
@@ -483,13 +703,13 @@ func (c *MockSentPacketHandlerSentPacketCall) Return() *MockSentPacketHandlerSen
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockSentPacketHandlerSentPacketCall) Do(f func(time.Time, protocol.PacketNumber, protocol.PacketNumber, []ackhandler.StreamFrame, []ackhandler.Frame, protocol.EncryptionLevel, protocol.ECN, protocol.ByteCount, bool)) *MockSentPacketHandlerSentPacketCall {
+func (c *MockSentPacketHandlerSentPacketCall) Do(f func(time.Time, protocol.PacketNumber, protocol.PacketNumber, []ackhandler.StreamFrame, []ackhandler.Frame, protocol.EncryptionLevel, protocol.ECN, protocol.ByteCount, bool, bool)) *MockSentPacketHandlerSentPacketCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockSentPacketHandlerSentPacketCall) DoAndReturn(f func(time.Time, protocol.PacketNumber, protocol.PacketNumber, []ackhandler.StreamFrame, []ackhandler.Frame, protocol.EncryptionLevel, protocol.ECN, protocol.ByteCount, bool)) *MockSentPacketHandlerSentPacketCall {
+func (c *MockSentPacketHandlerSentPacketCall) DoAndReturn(f func(time.Time, protocol.PacketNumber, protocol.PacketNumber, []ackhandler.StreamFrame, []ackhandler.Frame, protocol.EncryptionLevel, protocol.ECN, protocol.ByteCount, bool, bool)) *MockSentPacketHandlerSentPacketCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -566,6 +786,80 @@ func (c *MockSentPacketHandlerSetMaxDatagramSizeCall) DoAndReturn(f func(protoco
 	return c
 }
 
+// SetResumeState mocks base method.
+func (m *MockSentPacketHandler) SetResumeState(arg0 time.Duration, arg1 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetResumeState", arg0, arg1)
+}
+
+// SetResumeState indicates an expected call of SetResumeState.
+func (mr *MockSentPacketHandlerMockRecorder) SetResumeState(arg0, arg1 any) *MockSentPacketHandlerSetResumeStateCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetResumeState", reflect.TypeOf((*MockSentPacketHandler)(nil).SetResumeState), arg0, arg1)
+	return &MockSentPacketHandlerSetResumeStateCall{Call: call}
+}
+
+// MockSentPacketHandlerSetResumeStateCall wrap *gomock.Call
+type MockSentPacketHandlerSetResumeStateCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerSetResumeStateCall) Return() *MockSentPacketHandlerSetResumeStateCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerSetResumeStateCall) Do(f func(time.Duration, protocol.ByteCount)) *MockSentPacketHandlerSetResumeStateCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerSetResumeStateCall) DoAndReturn(f func(time.Duration, protocol.ByteCount)) *MockSentPacketHandlerSetResumeStateCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stats mocks base method.
+func (m *MockSentPacketHandler) Stats() ackhandler.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ackhandler.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockSentPacketHandlerMockRecorder) Stats() *MockSentPacketHandlerStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockSentPacketHandler)(nil).Stats))
+	return &MockSentPacketHandlerStatsCall{Call: call}
+}
+
+// MockSentPacketHandlerStatsCall wrap *gomock.Call
+type MockSentPacketHandlerStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSentPacketHandlerStatsCall) Return(arg0 ackhandler.Stats) *MockSentPacketHandlerStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSentPacketHandlerStatsCall) Do(f func() ackhandler.Stats) *MockSentPacketHandlerStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSentPacketHandlerStatsCall) DoAndReturn(f func() ackhandler.Stats) *MockSentPacketHandlerStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // TimeUntilSend mocks base method.
 func (m *MockSentPacketHandler) TimeUntilSend() time.Time {
 	m.ctrl.T.Helper()
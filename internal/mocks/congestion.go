@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/quic-go/quic-go/internal/congestion (interfaces: SendAlgorithmWithDebugInfos)
+// Source: github.com/quic-go/quic-go/internal/congestion (interfaces: SendAlgorithmWithDebugInfos,L4SSendAlgorithm)
 //
 // Generated by this command:
 //
-//	mockgen -typed -build_flags=-tags=gomock -package mocks -destination congestion.go github.com/quic-go/quic-go/internal/congestion SendAlgorithmWithDebugInfos
+//	mockgen -typed -build_flags=-tags=gomock -package mocks -destination congestion.go github.com/quic-go/quic-go/internal/congestion SendAlgorithmWithDebugInfos,L4SSendAlgorithm
 //
 
 // Package mocks is a generated GoMock package.
@@ -267,15 +267,15 @@ func (c *MockSendAlgorithmWithDebugInfosMaybeExitSlowStartCall) DoAndReturn(f fu
 }
 
 // OnCongestionEvent mocks base method.
-func (m *MockSendAlgorithmWithDebugInfos) OnCongestionEvent(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount) {
+func (m *MockSendAlgorithmWithDebugInfos) OnCongestionEvent(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber, arg2, arg3 protocol.ByteCount) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "OnCongestionEvent", arg0, arg1, arg2)
+	m.ctrl.Call(m, "OnCongestionEvent", arg0, arg1, arg2, arg3)
 }
 
 // OnCongestionEvent indicates an expected call of OnCongestionEvent.
-func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnCongestionEvent(arg0, arg1, arg2 any) *MockSendAlgorithmWithDebugInfosOnCongestionEventCall {
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnCongestionEvent(arg0, arg1, arg2, arg3 any) *MockSendAlgorithmWithDebugInfosOnCongestionEventCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnCongestionEvent", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnCongestionEvent), arg0, arg1, arg2)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnCongestionEvent", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnCongestionEvent), arg0, arg1, arg2, arg3)
 	return &MockSendAlgorithmWithDebugInfosOnCongestionEventCall{Call: call}
 }
 
@@ -291,27 +291,27 @@ func (c *MockSendAlgorithmWithDebugInfosOnCongestionEventCall) Return() *MockSen
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockSendAlgorithmWithDebugInfosOnCongestionEventCall) Do(f func(protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount)) *MockSendAlgorithmWithDebugInfosOnCongestionEventCall {
+func (c *MockSendAlgorithmWithDebugInfosOnCongestionEventCall) Do(f func(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount)) *MockSendAlgorithmWithDebugInfosOnCongestionEventCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockSendAlgorithmWithDebugInfosOnCongestionEventCall) DoAndReturn(f func(protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount)) *MockSendAlgorithmWithDebugInfosOnCongestionEventCall {
+func (c *MockSendAlgorithmWithDebugInfosOnCongestionEventCall) DoAndReturn(f func(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount)) *MockSendAlgorithmWithDebugInfosOnCongestionEventCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
 // OnPacketAcked mocks base method.
-func (m *MockSendAlgorithmWithDebugInfos) OnPacketAcked(arg0 protocol.PacketNumber, arg1, arg2 protocol.ByteCount, arg3 time.Time) {
+func (m *MockSendAlgorithmWithDebugInfos) OnPacketAcked(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber, arg2, arg3 protocol.ByteCount, arg4 time.Time) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "OnPacketAcked", arg0, arg1, arg2, arg3)
+	m.ctrl.Call(m, "OnPacketAcked", arg0, arg1, arg2, arg3, arg4)
 }
 
 // OnPacketAcked indicates an expected call of OnPacketAcked.
-func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnPacketAcked(arg0, arg1, arg2, arg3 any) *MockSendAlgorithmWithDebugInfosOnPacketAckedCall {
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnPacketAcked(arg0, arg1, arg2, arg3, arg4 any) *MockSendAlgorithmWithDebugInfosOnPacketAckedCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketAcked", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnPacketAcked), arg0, arg1, arg2, arg3)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketAcked", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnPacketAcked), arg0, arg1, arg2, arg3, arg4)
 	return &MockSendAlgorithmWithDebugInfosOnPacketAckedCall{Call: call}
 }
 
@@ -327,27 +327,63 @@ func (c *MockSendAlgorithmWithDebugInfosOnPacketAckedCall) Return() *MockSendAlg
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockSendAlgorithmWithDebugInfosOnPacketAckedCall) Do(f func(protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount, time.Time)) *MockSendAlgorithmWithDebugInfosOnPacketAckedCall {
+func (c *MockSendAlgorithmWithDebugInfosOnPacketAckedCall) Do(f func(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount, time.Time)) *MockSendAlgorithmWithDebugInfosOnPacketAckedCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockSendAlgorithmWithDebugInfosOnPacketAckedCall) DoAndReturn(f func(protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount, time.Time)) *MockSendAlgorithmWithDebugInfosOnPacketAckedCall {
+func (c *MockSendAlgorithmWithDebugInfosOnPacketAckedCall) DoAndReturn(f func(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount, time.Time)) *MockSendAlgorithmWithDebugInfosOnPacketAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnPacketDiscarded mocks base method.
+func (m *MockSendAlgorithmWithDebugInfos) OnPacketDiscarded(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketDiscarded", arg0, arg1)
+}
+
+// OnPacketDiscarded indicates an expected call of OnPacketDiscarded.
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnPacketDiscarded(arg0, arg1 any) *MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketDiscarded", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnPacketDiscarded), arg0, arg1)
+	return &MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall{Call: call}
+}
+
+// MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall wrap *gomock.Call
+type MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall) Return() *MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall) Do(f func(protocol.EncryptionLevel, protocol.PacketNumber)) *MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall) DoAndReturn(f func(protocol.EncryptionLevel, protocol.PacketNumber)) *MockSendAlgorithmWithDebugInfosOnPacketDiscardedCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
 
 // OnPacketSent mocks base method.
-func (m *MockSendAlgorithmWithDebugInfos) OnPacketSent(arg0 time.Time, arg1 protocol.ByteCount, arg2 protocol.PacketNumber, arg3 protocol.ByteCount, arg4 bool) {
+func (m *MockSendAlgorithmWithDebugInfos) OnPacketSent(arg0 time.Time, arg1 protocol.ByteCount, arg2 protocol.EncryptionLevel, arg3 protocol.PacketNumber, arg4 protocol.ByteCount, arg5 bool) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "OnPacketSent", arg0, arg1, arg2, arg3, arg4)
+	m.ctrl.Call(m, "OnPacketSent", arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
 // OnPacketSent indicates an expected call of OnPacketSent.
-func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnPacketSent(arg0, arg1, arg2, arg3, arg4 any) *MockSendAlgorithmWithDebugInfosOnPacketSentCall {
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnPacketSent(arg0, arg1, arg2, arg3, arg4, arg5 any) *MockSendAlgorithmWithDebugInfosOnPacketSentCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketSent", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnPacketSent), arg0, arg1, arg2, arg3, arg4)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketSent", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnPacketSent), arg0, arg1, arg2, arg3, arg4, arg5)
 	return &MockSendAlgorithmWithDebugInfosOnPacketSentCall{Call: call}
 }
 
@@ -363,13 +399,49 @@ func (c *MockSendAlgorithmWithDebugInfosOnPacketSentCall) Return() *MockSendAlgo
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockSendAlgorithmWithDebugInfosOnPacketSentCall) Do(f func(time.Time, protocol.ByteCount, protocol.PacketNumber, protocol.ByteCount, bool)) *MockSendAlgorithmWithDebugInfosOnPacketSentCall {
+func (c *MockSendAlgorithmWithDebugInfosOnPacketSentCall) Do(f func(time.Time, protocol.ByteCount, protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, bool)) *MockSendAlgorithmWithDebugInfosOnPacketSentCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockSendAlgorithmWithDebugInfosOnPacketSentCall) DoAndReturn(f func(time.Time, protocol.ByteCount, protocol.PacketNumber, protocol.ByteCount, bool)) *MockSendAlgorithmWithDebugInfosOnPacketSentCall {
+func (c *MockSendAlgorithmWithDebugInfosOnPacketSentCall) DoAndReturn(f func(time.Time, protocol.ByteCount, protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, bool)) *MockSendAlgorithmWithDebugInfosOnPacketSentCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnPacketsDropped mocks base method.
+func (m *MockSendAlgorithmWithDebugInfos) OnPacketsDropped(arg0 protocol.EncryptionLevel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketsDropped", arg0)
+}
+
+// OnPacketsDropped indicates an expected call of OnPacketsDropped.
+func (mr *MockSendAlgorithmWithDebugInfosMockRecorder) OnPacketsDropped(arg0 any) *MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketsDropped", reflect.TypeOf((*MockSendAlgorithmWithDebugInfos)(nil).OnPacketsDropped), arg0)
+	return &MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall{Call: call}
+}
+
+// MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall wrap *gomock.Call
+type MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall) Return() *MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall) Do(f func(protocol.EncryptionLevel)) *MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall) DoAndReturn(f func(protocol.EncryptionLevel)) *MockSendAlgorithmWithDebugInfosOnPacketsDroppedCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -483,3 +555,578 @@ func (c *MockSendAlgorithmWithDebugInfosTimeUntilSendCall) DoAndReturn(f func(pr
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// MockL4SSendAlgorithm is a mock of L4SSendAlgorithm interface.
+type MockL4SSendAlgorithm struct {
+	ctrl     *gomock.Controller
+	recorder *MockL4SSendAlgorithmMockRecorder
+}
+
+// MockL4SSendAlgorithmMockRecorder is the mock recorder for MockL4SSendAlgorithm.
+type MockL4SSendAlgorithmMockRecorder struct {
+	mock *MockL4SSendAlgorithm
+}
+
+// NewMockL4SSendAlgorithm creates a new mock instance.
+func NewMockL4SSendAlgorithm(ctrl *gomock.Controller) *MockL4SSendAlgorithm {
+	mock := &MockL4SSendAlgorithm{ctrl: ctrl}
+	mock.recorder = &MockL4SSendAlgorithmMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockL4SSendAlgorithm) EXPECT() *MockL4SSendAlgorithmMockRecorder {
+	return m.recorder
+}
+
+// CanSend mocks base method.
+func (m *MockL4SSendAlgorithm) CanSend(arg0 protocol.ByteCount) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CanSend", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// CanSend indicates an expected call of CanSend.
+func (mr *MockL4SSendAlgorithmMockRecorder) CanSend(arg0 any) *MockL4SSendAlgorithmCanSendCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanSend", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).CanSend), arg0)
+	return &MockL4SSendAlgorithmCanSendCall{Call: call}
+}
+
+// MockL4SSendAlgorithmCanSendCall wrap *gomock.Call
+type MockL4SSendAlgorithmCanSendCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmCanSendCall) Return(arg0 bool) *MockL4SSendAlgorithmCanSendCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmCanSendCall) Do(f func(protocol.ByteCount) bool) *MockL4SSendAlgorithmCanSendCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmCanSendCall) DoAndReturn(f func(protocol.ByteCount) bool) *MockL4SSendAlgorithmCanSendCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetCongestionWindow mocks base method.
+func (m *MockL4SSendAlgorithm) GetCongestionWindow() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCongestionWindow")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// GetCongestionWindow indicates an expected call of GetCongestionWindow.
+func (mr *MockL4SSendAlgorithmMockRecorder) GetCongestionWindow() *MockL4SSendAlgorithmGetCongestionWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCongestionWindow", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).GetCongestionWindow))
+	return &MockL4SSendAlgorithmGetCongestionWindowCall{Call: call}
+}
+
+// MockL4SSendAlgorithmGetCongestionWindowCall wrap *gomock.Call
+type MockL4SSendAlgorithmGetCongestionWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmGetCongestionWindowCall) Return(arg0 protocol.ByteCount) *MockL4SSendAlgorithmGetCongestionWindowCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmGetCongestionWindowCall) Do(f func() protocol.ByteCount) *MockL4SSendAlgorithmGetCongestionWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmGetCongestionWindowCall) DoAndReturn(f func() protocol.ByteCount) *MockL4SSendAlgorithmGetCongestionWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// HasPacingBudget mocks base method.
+func (m *MockL4SSendAlgorithm) HasPacingBudget(arg0 time.Time) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPacingBudget", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasPacingBudget indicates an expected call of HasPacingBudget.
+func (mr *MockL4SSendAlgorithmMockRecorder) HasPacingBudget(arg0 any) *MockL4SSendAlgorithmHasPacingBudgetCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPacingBudget", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).HasPacingBudget), arg0)
+	return &MockL4SSendAlgorithmHasPacingBudgetCall{Call: call}
+}
+
+// MockL4SSendAlgorithmHasPacingBudgetCall wrap *gomock.Call
+type MockL4SSendAlgorithmHasPacingBudgetCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmHasPacingBudgetCall) Return(arg0 bool) *MockL4SSendAlgorithmHasPacingBudgetCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmHasPacingBudgetCall) Do(f func(time.Time) bool) *MockL4SSendAlgorithmHasPacingBudgetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmHasPacingBudgetCall) DoAndReturn(f func(time.Time) bool) *MockL4SSendAlgorithmHasPacingBudgetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// InRecovery mocks base method.
+func (m *MockL4SSendAlgorithm) InRecovery() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InRecovery")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InRecovery indicates an expected call of InRecovery.
+func (mr *MockL4SSendAlgorithmMockRecorder) InRecovery() *MockL4SSendAlgorithmInRecoveryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InRecovery", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).InRecovery))
+	return &MockL4SSendAlgorithmInRecoveryCall{Call: call}
+}
+
+// MockL4SSendAlgorithmInRecoveryCall wrap *gomock.Call
+type MockL4SSendAlgorithmInRecoveryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmInRecoveryCall) Return(arg0 bool) *MockL4SSendAlgorithmInRecoveryCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmInRecoveryCall) Do(f func() bool) *MockL4SSendAlgorithmInRecoveryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmInRecoveryCall) DoAndReturn(f func() bool) *MockL4SSendAlgorithmInRecoveryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// InSlowStart mocks base method.
+func (m *MockL4SSendAlgorithm) InSlowStart() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InSlowStart")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InSlowStart indicates an expected call of InSlowStart.
+func (mr *MockL4SSendAlgorithmMockRecorder) InSlowStart() *MockL4SSendAlgorithmInSlowStartCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InSlowStart", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).InSlowStart))
+	return &MockL4SSendAlgorithmInSlowStartCall{Call: call}
+}
+
+// MockL4SSendAlgorithmInSlowStartCall wrap *gomock.Call
+type MockL4SSendAlgorithmInSlowStartCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmInSlowStartCall) Return(arg0 bool) *MockL4SSendAlgorithmInSlowStartCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmInSlowStartCall) Do(f func() bool) *MockL4SSendAlgorithmInSlowStartCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmInSlowStartCall) DoAndReturn(f func() bool) *MockL4SSendAlgorithmInSlowStartCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MaybeExitSlowStart mocks base method.
+func (m *MockL4SSendAlgorithm) MaybeExitSlowStart() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MaybeExitSlowStart")
+}
+
+// MaybeExitSlowStart indicates an expected call of MaybeExitSlowStart.
+func (mr *MockL4SSendAlgorithmMockRecorder) MaybeExitSlowStart() *MockL4SSendAlgorithmMaybeExitSlowStartCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaybeExitSlowStart", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).MaybeExitSlowStart))
+	return &MockL4SSendAlgorithmMaybeExitSlowStartCall{Call: call}
+}
+
+// MockL4SSendAlgorithmMaybeExitSlowStartCall wrap *gomock.Call
+type MockL4SSendAlgorithmMaybeExitSlowStartCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmMaybeExitSlowStartCall) Return() *MockL4SSendAlgorithmMaybeExitSlowStartCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmMaybeExitSlowStartCall) Do(f func()) *MockL4SSendAlgorithmMaybeExitSlowStartCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmMaybeExitSlowStartCall) DoAndReturn(f func()) *MockL4SSendAlgorithmMaybeExitSlowStartCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnCongestionEvent mocks base method.
+func (m *MockL4SSendAlgorithm) OnCongestionEvent(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber, arg2, arg3 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnCongestionEvent", arg0, arg1, arg2, arg3)
+}
+
+// OnCongestionEvent indicates an expected call of OnCongestionEvent.
+func (mr *MockL4SSendAlgorithmMockRecorder) OnCongestionEvent(arg0, arg1, arg2, arg3 any) *MockL4SSendAlgorithmOnCongestionEventCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnCongestionEvent", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).OnCongestionEvent), arg0, arg1, arg2, arg3)
+	return &MockL4SSendAlgorithmOnCongestionEventCall{Call: call}
+}
+
+// MockL4SSendAlgorithmOnCongestionEventCall wrap *gomock.Call
+type MockL4SSendAlgorithmOnCongestionEventCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmOnCongestionEventCall) Return() *MockL4SSendAlgorithmOnCongestionEventCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmOnCongestionEventCall) Do(f func(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount)) *MockL4SSendAlgorithmOnCongestionEventCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmOnCongestionEventCall) DoAndReturn(f func(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount)) *MockL4SSendAlgorithmOnCongestionEventCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnECNFeedback mocks base method.
+func (m *MockL4SSendAlgorithm) OnECNFeedback(arg0, arg1 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnECNFeedback", arg0, arg1)
+}
+
+// OnECNFeedback indicates an expected call of OnECNFeedback.
+func (mr *MockL4SSendAlgorithmMockRecorder) OnECNFeedback(arg0, arg1 any) *MockL4SSendAlgorithmOnECNFeedbackCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnECNFeedback", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).OnECNFeedback), arg0, arg1)
+	return &MockL4SSendAlgorithmOnECNFeedbackCall{Call: call}
+}
+
+// MockL4SSendAlgorithmOnECNFeedbackCall wrap *gomock.Call
+type MockL4SSendAlgorithmOnECNFeedbackCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmOnECNFeedbackCall) Return() *MockL4SSendAlgorithmOnECNFeedbackCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmOnECNFeedbackCall) Do(f func(int64, int64)) *MockL4SSendAlgorithmOnECNFeedbackCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmOnECNFeedbackCall) DoAndReturn(f func(int64, int64)) *MockL4SSendAlgorithmOnECNFeedbackCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnPacketAcked mocks base method.
+func (m *MockL4SSendAlgorithm) OnPacketAcked(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber, arg2, arg3 protocol.ByteCount, arg4 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketAcked", arg0, arg1, arg2, arg3, arg4)
+}
+
+// OnPacketAcked indicates an expected call of OnPacketAcked.
+func (mr *MockL4SSendAlgorithmMockRecorder) OnPacketAcked(arg0, arg1, arg2, arg3, arg4 any) *MockL4SSendAlgorithmOnPacketAckedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketAcked", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).OnPacketAcked), arg0, arg1, arg2, arg3, arg4)
+	return &MockL4SSendAlgorithmOnPacketAckedCall{Call: call}
+}
+
+// MockL4SSendAlgorithmOnPacketAckedCall wrap *gomock.Call
+type MockL4SSendAlgorithmOnPacketAckedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmOnPacketAckedCall) Return() *MockL4SSendAlgorithmOnPacketAckedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmOnPacketAckedCall) Do(f func(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount, time.Time)) *MockL4SSendAlgorithmOnPacketAckedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmOnPacketAckedCall) DoAndReturn(f func(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount, time.Time)) *MockL4SSendAlgorithmOnPacketAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnPacketDiscarded mocks base method.
+func (m *MockL4SSendAlgorithm) OnPacketDiscarded(arg0 protocol.EncryptionLevel, arg1 protocol.PacketNumber) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketDiscarded", arg0, arg1)
+}
+
+// OnPacketDiscarded indicates an expected call of OnPacketDiscarded.
+func (mr *MockL4SSendAlgorithmMockRecorder) OnPacketDiscarded(arg0, arg1 any) *MockL4SSendAlgorithmOnPacketDiscardedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketDiscarded", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).OnPacketDiscarded), arg0, arg1)
+	return &MockL4SSendAlgorithmOnPacketDiscardedCall{Call: call}
+}
+
+// MockL4SSendAlgorithmOnPacketDiscardedCall wrap *gomock.Call
+type MockL4SSendAlgorithmOnPacketDiscardedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmOnPacketDiscardedCall) Return() *MockL4SSendAlgorithmOnPacketDiscardedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmOnPacketDiscardedCall) Do(f func(protocol.EncryptionLevel, protocol.PacketNumber)) *MockL4SSendAlgorithmOnPacketDiscardedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmOnPacketDiscardedCall) DoAndReturn(f func(protocol.EncryptionLevel, protocol.PacketNumber)) *MockL4SSendAlgorithmOnPacketDiscardedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnPacketSent mocks base method.
+func (m *MockL4SSendAlgorithm) OnPacketSent(arg0 time.Time, arg1 protocol.ByteCount, arg2 protocol.EncryptionLevel, arg3 protocol.PacketNumber, arg4 protocol.ByteCount, arg5 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketSent", arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// OnPacketSent indicates an expected call of OnPacketSent.
+func (mr *MockL4SSendAlgorithmMockRecorder) OnPacketSent(arg0, arg1, arg2, arg3, arg4, arg5 any) *MockL4SSendAlgorithmOnPacketSentCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketSent", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).OnPacketSent), arg0, arg1, arg2, arg3, arg4, arg5)
+	return &MockL4SSendAlgorithmOnPacketSentCall{Call: call}
+}
+
+// MockL4SSendAlgorithmOnPacketSentCall wrap *gomock.Call
+type MockL4SSendAlgorithmOnPacketSentCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmOnPacketSentCall) Return() *MockL4SSendAlgorithmOnPacketSentCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmOnPacketSentCall) Do(f func(time.Time, protocol.ByteCount, protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, bool)) *MockL4SSendAlgorithmOnPacketSentCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmOnPacketSentCall) DoAndReturn(f func(time.Time, protocol.ByteCount, protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, bool)) *MockL4SSendAlgorithmOnPacketSentCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnPacketsDropped mocks base method.
+func (m *MockL4SSendAlgorithm) OnPacketsDropped(arg0 protocol.EncryptionLevel) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnPacketsDropped", arg0)
+}
+
+// OnPacketsDropped indicates an expected call of OnPacketsDropped.
+func (mr *MockL4SSendAlgorithmMockRecorder) OnPacketsDropped(arg0 any) *MockL4SSendAlgorithmOnPacketsDroppedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnPacketsDropped", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).OnPacketsDropped), arg0)
+	return &MockL4SSendAlgorithmOnPacketsDroppedCall{Call: call}
+}
+
+// MockL4SSendAlgorithmOnPacketsDroppedCall wrap *gomock.Call
+type MockL4SSendAlgorithmOnPacketsDroppedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmOnPacketsDroppedCall) Return() *MockL4SSendAlgorithmOnPacketsDroppedCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmOnPacketsDroppedCall) Do(f func(protocol.EncryptionLevel)) *MockL4SSendAlgorithmOnPacketsDroppedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmOnPacketsDroppedCall) DoAndReturn(f func(protocol.EncryptionLevel)) *MockL4SSendAlgorithmOnPacketsDroppedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// OnRetransmissionTimeout mocks base method.
+func (m *MockL4SSendAlgorithm) OnRetransmissionTimeout(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnRetransmissionTimeout", arg0)
+}
+
+// OnRetransmissionTimeout indicates an expected call of OnRetransmissionTimeout.
+func (mr *MockL4SSendAlgorithmMockRecorder) OnRetransmissionTimeout(arg0 any) *MockL4SSendAlgorithmOnRetransmissionTimeoutCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnRetransmissionTimeout", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).OnRetransmissionTimeout), arg0)
+	return &MockL4SSendAlgorithmOnRetransmissionTimeoutCall{Call: call}
+}
+
+// MockL4SSendAlgorithmOnRetransmissionTimeoutCall wrap *gomock.Call
+type MockL4SSendAlgorithmOnRetransmissionTimeoutCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmOnRetransmissionTimeoutCall) Return() *MockL4SSendAlgorithmOnRetransmissionTimeoutCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmOnRetransmissionTimeoutCall) Do(f func(bool)) *MockL4SSendAlgorithmOnRetransmissionTimeoutCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmOnRetransmissionTimeoutCall) DoAndReturn(f func(bool)) *MockL4SSendAlgorithmOnRetransmissionTimeoutCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxDatagramSize mocks base method.
+func (m *MockL4SSendAlgorithm) SetMaxDatagramSize(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxDatagramSize", arg0)
+}
+
+// SetMaxDatagramSize indicates an expected call of SetMaxDatagramSize.
+func (mr *MockL4SSendAlgorithmMockRecorder) SetMaxDatagramSize(arg0 any) *MockL4SSendAlgorithmSetMaxDatagramSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxDatagramSize", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).SetMaxDatagramSize), arg0)
+	return &MockL4SSendAlgorithmSetMaxDatagramSizeCall{Call: call}
+}
+
+// MockL4SSendAlgorithmSetMaxDatagramSizeCall wrap *gomock.Call
+type MockL4SSendAlgorithmSetMaxDatagramSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmSetMaxDatagramSizeCall) Return() *MockL4SSendAlgorithmSetMaxDatagramSizeCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmSetMaxDatagramSizeCall) Do(f func(protocol.ByteCount)) *MockL4SSendAlgorithmSetMaxDatagramSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmSetMaxDatagramSizeCall) DoAndReturn(f func(protocol.ByteCount)) *MockL4SSendAlgorithmSetMaxDatagramSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// TimeUntilSend mocks base method.
+func (m *MockL4SSendAlgorithm) TimeUntilSend(arg0 protocol.ByteCount) time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeUntilSend", arg0)
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// TimeUntilSend indicates an expected call of TimeUntilSend.
+func (mr *MockL4SSendAlgorithmMockRecorder) TimeUntilSend(arg0 any) *MockL4SSendAlgorithmTimeUntilSendCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeUntilSend", reflect.TypeOf((*MockL4SSendAlgorithm)(nil).TimeUntilSend), arg0)
+	return &MockL4SSendAlgorithmTimeUntilSendCall{Call: call}
+}
+
+// MockL4SSendAlgorithmTimeUntilSendCall wrap *gomock.Call
+type MockL4SSendAlgorithmTimeUntilSendCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockL4SSendAlgorithmTimeUntilSendCall) Return(arg0 time.Time) *MockL4SSendAlgorithmTimeUntilSendCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockL4SSendAlgorithmTimeUntilSendCall) Do(f func(protocol.ByteCount) time.Time) *MockL4SSendAlgorithmTimeUntilSendCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockL4SSendAlgorithmTimeUntilSendCall) DoAndReturn(f func(protocol.ByteCount) time.Time) *MockL4SSendAlgorithmTimeUntilSendCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -111,6 +111,42 @@ func (c *MockConnectionFlowControllerAddBytesSentCall) DoAndReturn(f func(protoc
 	return c
 }
 
+// Close mocks base method.
+func (m *MockConnectionFlowController) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockConnectionFlowControllerMockRecorder) Close() *MockConnectionFlowControllerCloseCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockConnectionFlowController)(nil).Close))
+	return &MockConnectionFlowControllerCloseCall{Call: call}
+}
+
+// MockConnectionFlowControllerCloseCall wrap *gomock.Call
+type MockConnectionFlowControllerCloseCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionFlowControllerCloseCall) Return() *MockConnectionFlowControllerCloseCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionFlowControllerCloseCall) Do(f func()) *MockConnectionFlowControllerCloseCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionFlowControllerCloseCall) DoAndReturn(f func()) *MockConnectionFlowControllerCloseCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetWindowUpdate mocks base method.
 func (m *MockConnectionFlowController) GetWindowUpdate() protocol.ByteCount {
 	m.ctrl.T.Helper()
@@ -59,6 +59,9 @@ func NewMockConnectionTracer(ctrl *gomock.Controller) (*logging.ConnectionTracer
 		DroppedPacket: func(typ logging.PacketType, pn logging.PacketNumber, size logging.ByteCount, reason logging.PacketDropReason) {
 			t.DroppedPacket(typ, pn, size, reason)
 		},
+		DroppedDatagram: func(length logging.ByteCount) {
+			t.DroppedDatagram(length)
+		},
 		UpdatedMetrics: func(rttStats *logging.RTTStats, cwnd, bytesInFlight logging.ByteCount, packetsInFlight int) {
 			t.UpdatedMetrics(rttStats, cwnd, bytesInFlight, packetsInFlight)
 		},
@@ -104,6 +107,9 @@ func NewMockConnectionTracer(ctrl *gomock.Controller) (*logging.ConnectionTracer
 		ChoseALPN: func(protocol string) {
 			t.ChoseALPN(protocol)
 		},
+		MigratedPath: func(oldRemote, newRemote net.Addr) {
+			t.MigratedPath(oldRemote, newRemote)
+		},
 		Close: func() {
 			t.Close()
 		},
@@ -260,6 +260,42 @@ func (c *MockConnectionTracerDebugCall) DoAndReturn(f func(string, string)) *Moc
 	return c
 }
 
+// DroppedDatagram mocks base method.
+func (m *MockConnectionTracer) DroppedDatagram(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DroppedDatagram", arg0)
+}
+
+// DroppedDatagram indicates an expected call of DroppedDatagram.
+func (mr *MockConnectionTracerMockRecorder) DroppedDatagram(arg0 any) *MockConnectionTracerDroppedDatagramCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DroppedDatagram", reflect.TypeOf((*MockConnectionTracer)(nil).DroppedDatagram), arg0)
+	return &MockConnectionTracerDroppedDatagramCall{Call: call}
+}
+
+// MockConnectionTracerDroppedDatagramCall wrap *gomock.Call
+type MockConnectionTracerDroppedDatagramCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerDroppedDatagramCall) Return() *MockConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerDroppedDatagramCall) Do(f func(protocol.ByteCount)) *MockConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerDroppedDatagramCall) DoAndReturn(f func(protocol.ByteCount)) *MockConnectionTracerDroppedDatagramCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // DroppedEncryptionLevel mocks base method.
 func (m *MockConnectionTracer) DroppedEncryptionLevel(arg0 protocol.EncryptionLevel) {
 	m.ctrl.T.Helper()
@@ -512,6 +548,42 @@ func (c *MockConnectionTracerLostPacketCall) DoAndReturn(f func(protocol.Encrypt
 	return c
 }
 
+// MigratedPath mocks base method.
+func (m *MockConnectionTracer) MigratedPath(arg0, arg1 net.Addr) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MigratedPath", arg0, arg1)
+}
+
+// MigratedPath indicates an expected call of MigratedPath.
+func (mr *MockConnectionTracerMockRecorder) MigratedPath(arg0, arg1 any) *MockConnectionTracerMigratedPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratedPath", reflect.TypeOf((*MockConnectionTracer)(nil).MigratedPath), arg0, arg1)
+	return &MockConnectionTracerMigratedPathCall{Call: call}
+}
+
+// MockConnectionTracerMigratedPathCall wrap *gomock.Call
+type MockConnectionTracerMigratedPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockConnectionTracerMigratedPathCall) Return() *MockConnectionTracerMigratedPathCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockConnectionTracerMigratedPathCall) Do(f func(net.Addr, net.Addr)) *MockConnectionTracerMigratedPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockConnectionTracerMigratedPathCall) DoAndReturn(f func(net.Addr, net.Addr)) *MockConnectionTracerMigratedPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // NegotiatedVersion mocks base method.
 func (m *MockConnectionTracer) NegotiatedVersion(arg0 protocol.Version, arg1, arg2 []protocol.Version) {
 	m.ctrl.T.Helper()
@@ -150,6 +150,42 @@ func (c *MockTracerDroppedPacketCall) DoAndReturn(f func(net.Addr, logging.Packe
 	return c
 }
 
+// RejectedConnection mocks base method.
+func (m *MockTracer) RejectedConnection(arg0 net.Addr, arg1 logging.ConnectionRejectReason) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RejectedConnection", arg0, arg1)
+}
+
+// RejectedConnection indicates an expected call of RejectedConnection.
+func (mr *MockTracerMockRecorder) RejectedConnection(arg0, arg1 any) *MockTracerRejectedConnectionCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectedConnection", reflect.TypeOf((*MockTracer)(nil).RejectedConnection), arg0, arg1)
+	return &MockTracerRejectedConnectionCall{Call: call}
+}
+
+// MockTracerRejectedConnectionCall wrap *gomock.Call
+type MockTracerRejectedConnectionCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockTracerRejectedConnectionCall) Return() *MockTracerRejectedConnectionCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockTracerRejectedConnectionCall) Do(f func(net.Addr, logging.ConnectionRejectReason)) *MockTracerRejectedConnectionCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockTracerRejectedConnectionCall) DoAndReturn(f func(net.Addr, logging.ConnectionRejectReason)) *MockTracerRejectedConnectionCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SentPacket mocks base method.
 func (m *MockTracer) SentPacket(arg0 net.Addr, arg1 *wire.Header, arg2 protocol.ByteCount, arg3 []logging.Frame) {
 	m.ctrl.T.Helper()
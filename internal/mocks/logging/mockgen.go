@@ -14,6 +14,7 @@ type Tracer interface {
 	SentPacket(net.Addr, *logging.Header, logging.ByteCount, []logging.Frame)
 	SentVersionNegotiationPacket(_ net.Addr, dest, src logging.ArbitraryLenConnectionID, _ []logging.VersionNumber)
 	DroppedPacket(net.Addr, logging.PacketType, logging.ByteCount, logging.PacketDropReason)
+	RejectedConnection(net.Addr, logging.ConnectionRejectReason)
 	Debug(name, msg string)
 	Close()
 }
@@ -34,6 +35,7 @@ type ConnectionTracer interface {
 	ReceivedShortHeaderPacket(*logging.ShortHeader, logging.ByteCount, logging.ECN, []logging.Frame)
 	BufferedPacket(logging.PacketType, logging.ByteCount)
 	DroppedPacket(logging.PacketType, logging.PacketNumber, logging.ByteCount, logging.PacketDropReason)
+	DroppedDatagram(length logging.ByteCount)
 	UpdatedMTU(mtu logging.ByteCount, done bool)
 	UpdatedMetrics(rttStats *logging.RTTStats, cwnd, bytesInFlight logging.ByteCount, packetsInFlight int)
 	AcknowledgedPacket(logging.EncryptionLevel, logging.PacketNumber)
@@ -49,6 +51,7 @@ type ConnectionTracer interface {
 	LossTimerCanceled()
 	ECNStateUpdated(state logging.ECNState, trigger logging.ECNStateTrigger)
 	ChoseALPN(protocol string)
+	MigratedPath(oldRemote, newRemote net.Addr)
 	// Close is called when the connection is closed.
 	Close()
 	Debug(name, msg string)
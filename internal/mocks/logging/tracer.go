@@ -25,6 +25,9 @@ func NewMockTracer(ctrl *gomock.Controller) (*logging.Tracer, *MockTracer) {
 		DroppedPacket: func(remote net.Addr, typ logging.PacketType, size logging.ByteCount, reason logging.PacketDropReason) {
 			t.DroppedPacket(remote, typ, size, reason)
 		},
+		RejectedConnection: func(remote net.Addr, reason logging.ConnectionRejectReason) {
+			t.RejectedConnection(remote, reason)
+		},
 		Debug: func(name, msg string) {
 			t.Debug(name, msg)
 		},
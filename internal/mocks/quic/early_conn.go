@@ -15,6 +15,7 @@ import (
 	reflect "reflect"
 
 	quic "github.com/quic-go/quic-go"
+	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -120,6 +121,45 @@ func (c *MockEarlyConnectionAcceptUniStreamCall) DoAndReturn(f func(context.Cont
 	return c
 }
 
+// AddPath mocks base method.
+func (m *MockEarlyConnection) AddPath(arg0 *quic.Transport) (*quic.Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0)
+	ret0, _ := ret[0].(*quic.Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockEarlyConnectionMockRecorder) AddPath(arg0 any) *MockEarlyConnectionAddPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockEarlyConnection)(nil).AddPath), arg0)
+	return &MockEarlyConnectionAddPathCall{Call: call}
+}
+
+// MockEarlyConnectionAddPathCall wrap *gomock.Call
+type MockEarlyConnectionAddPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionAddPathCall) Return(arg0 *quic.Path, arg1 error) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionAddPathCall) Do(f func(*quic.Transport) (*quic.Path, error)) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionAddPathCall) DoAndReturn(f func(*quic.Transport) (*quic.Path, error)) *MockEarlyConnectionAddPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CloseWithError mocks base method.
 func (m *MockEarlyConnection) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -544,6 +584,45 @@ func (c *MockEarlyConnectionReceiveDatagramCall) DoAndReturn(f func(context.Cont
 	return c
 }
 
+// ReceiveDatagrams mocks base method.
+func (m *MockEarlyConnection) ReceiveDatagrams(arg0 context.Context, arg1 []quic.ReceivedDatagram) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagrams", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveDatagrams indicates an expected call of ReceiveDatagrams.
+func (mr *MockEarlyConnectionMockRecorder) ReceiveDatagrams(arg0, arg1 any) *MockEarlyConnectionReceiveDatagramsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagrams", reflect.TypeOf((*MockEarlyConnection)(nil).ReceiveDatagrams), arg0, arg1)
+	return &MockEarlyConnectionReceiveDatagramsCall{Call: call}
+}
+
+// MockEarlyConnectionReceiveDatagramsCall wrap *gomock.Call
+type MockEarlyConnectionReceiveDatagramsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionReceiveDatagramsCall) Return(arg0 int, arg1 error) *MockEarlyConnectionReceiveDatagramsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionReceiveDatagramsCall) Do(f func(context.Context, []quic.ReceivedDatagram) (int, error)) *MockEarlyConnectionReceiveDatagramsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionReceiveDatagramsCall) DoAndReturn(f func(context.Context, []quic.ReceivedDatagram) (int, error)) *MockEarlyConnectionReceiveDatagramsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // RemoteAddr mocks base method.
 func (m *MockEarlyConnection) RemoteAddr() net.Addr {
 	m.ctrl.T.Helper()
@@ -619,3 +698,189 @@ func (c *MockEarlyConnectionSendDatagramCall) DoAndReturn(f func([]byte) error)
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SendDatagramWithOptions mocks base method.
+func (m *MockEarlyConnection) SendDatagramWithOptions(arg0 []byte, arg1 *quic.DatagramOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagramWithOptions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagramWithOptions indicates an expected call of SendDatagramWithOptions.
+func (mr *MockEarlyConnectionMockRecorder) SendDatagramWithOptions(arg0, arg1 any) *MockEarlyConnectionSendDatagramWithOptionsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagramWithOptions", reflect.TypeOf((*MockEarlyConnection)(nil).SendDatagramWithOptions), arg0, arg1)
+	return &MockEarlyConnectionSendDatagramWithOptionsCall{Call: call}
+}
+
+// MockEarlyConnectionSendDatagramWithOptionsCall wrap *gomock.Call
+type MockEarlyConnectionSendDatagramWithOptionsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSendDatagramWithOptionsCall) Return(arg0 error) *MockEarlyConnectionSendDatagramWithOptionsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSendDatagramWithOptionsCall) Do(f func([]byte, *quic.DatagramOptions) error) *MockEarlyConnectionSendDatagramWithOptionsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSendDatagramWithOptionsCall) DoAndReturn(f func([]byte, *quic.DatagramOptions) error) *MockEarlyConnectionSendDatagramWithOptionsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingStreams mocks base method.
+func (m *MockEarlyConnection) SetMaxIncomingStreams(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingStreams", arg0)
+}
+
+// SetMaxIncomingStreams indicates an expected call of SetMaxIncomingStreams.
+func (mr *MockEarlyConnectionMockRecorder) SetMaxIncomingStreams(arg0 any) *MockEarlyConnectionSetMaxIncomingStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingStreams", reflect.TypeOf((*MockEarlyConnection)(nil).SetMaxIncomingStreams), arg0)
+	return &MockEarlyConnectionSetMaxIncomingStreamsCall{Call: call}
+}
+
+// MockEarlyConnectionSetMaxIncomingStreamsCall wrap *gomock.Call
+type MockEarlyConnectionSetMaxIncomingStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSetMaxIncomingStreamsCall) Return() *MockEarlyConnectionSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSetMaxIncomingStreamsCall) Do(f func(int64)) *MockEarlyConnectionSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSetMaxIncomingStreamsCall) DoAndReturn(f func(int64)) *MockEarlyConnectionSetMaxIncomingStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingUniStreams mocks base method.
+func (m *MockEarlyConnection) SetMaxIncomingUniStreams(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingUniStreams", arg0)
+}
+
+// SetMaxIncomingUniStreams indicates an expected call of SetMaxIncomingUniStreams.
+func (mr *MockEarlyConnectionMockRecorder) SetMaxIncomingUniStreams(arg0 any) *MockEarlyConnectionSetMaxIncomingUniStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingUniStreams", reflect.TypeOf((*MockEarlyConnection)(nil).SetMaxIncomingUniStreams), arg0)
+	return &MockEarlyConnectionSetMaxIncomingUniStreamsCall{Call: call}
+}
+
+// MockEarlyConnectionSetMaxIncomingUniStreamsCall wrap *gomock.Call
+type MockEarlyConnectionSetMaxIncomingUniStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSetMaxIncomingUniStreamsCall) Return() *MockEarlyConnectionSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSetMaxIncomingUniStreamsCall) Do(f func(int64)) *MockEarlyConnectionSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSetMaxIncomingUniStreamsCall) DoAndReturn(f func(int64)) *MockEarlyConnectionSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetStreamReceiveWindow mocks base method.
+func (m *MockEarlyConnection) SetStreamReceiveWindow(arg0 protocol.StreamID, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStreamReceiveWindow", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStreamReceiveWindow indicates an expected call of SetStreamReceiveWindow.
+func (mr *MockEarlyConnectionMockRecorder) SetStreamReceiveWindow(arg0, arg1 any) *MockEarlyConnectionSetStreamReceiveWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStreamReceiveWindow", reflect.TypeOf((*MockEarlyConnection)(nil).SetStreamReceiveWindow), arg0, arg1)
+	return &MockEarlyConnectionSetStreamReceiveWindowCall{Call: call}
+}
+
+// MockEarlyConnectionSetStreamReceiveWindowCall wrap *gomock.Call
+type MockEarlyConnectionSetStreamReceiveWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionSetStreamReceiveWindowCall) Return(arg0 error) *MockEarlyConnectionSetStreamReceiveWindowCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionSetStreamReceiveWindowCall) Do(f func(protocol.StreamID, uint64) error) *MockEarlyConnectionSetStreamReceiveWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionSetStreamReceiveWindowCall) DoAndReturn(f func(protocol.StreamID, uint64) error) *MockEarlyConnectionSetStreamReceiveWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stats mocks base method.
+func (m *MockEarlyConnection) Stats() quic.ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(quic.ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockEarlyConnectionMockRecorder) Stats() *MockEarlyConnectionStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockEarlyConnection)(nil).Stats))
+	return &MockEarlyConnectionStatsCall{Call: call}
+}
+
+// MockEarlyConnectionStatsCall wrap *gomock.Call
+type MockEarlyConnectionStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockEarlyConnectionStatsCall) Return(arg0 quic.ConnectionStats) *MockEarlyConnectionStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockEarlyConnectionStatsCall) Do(f func() quic.ConnectionStats) *MockEarlyConnectionStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockEarlyConnectionStatsCall) DoAndReturn(f func() quic.ConnectionStats) *MockEarlyConnectionStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -14,6 +14,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	quic "github.com/quic-go/quic-go"
 	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
@@ -114,6 +115,44 @@ func (c *MockStreamCancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode)) *M
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockStream) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockStreamMockRecorder) CancelWriteAt(arg0, arg1 any) *MockStreamCancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockStream)(nil).CancelWriteAt), arg0, arg1)
+	return &MockStreamCancelWriteAtCall{Call: call}
+}
+
+// MockStreamCancelWriteAtCall wrap *gomock.Call
+type MockStreamCancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamCancelWriteAtCall) Return(arg0 error) *MockStreamCancelWriteAtCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamCancelWriteAtCall) Do(f func(qerr.StreamErrorCode, int64) error) *MockStreamCancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamCancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, int64) error) *MockStreamCancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockStream) Close() error {
 	m.ctrl.T.Helper()
@@ -190,6 +229,82 @@ func (c *MockStreamContextCall) DoAndReturn(f func() context.Context) *MockStrea
 	return c
 }
 
+// NotifyAcked mocks base method.
+func (m *MockStream) NotifyAcked(arg0 int64) <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyAcked", arg0)
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// NotifyAcked indicates an expected call of NotifyAcked.
+func (mr *MockStreamMockRecorder) NotifyAcked(arg0 any) *MockStreamNotifyAckedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyAcked", reflect.TypeOf((*MockStream)(nil).NotifyAcked), arg0)
+	return &MockStreamNotifyAckedCall{Call: call}
+}
+
+// MockStreamNotifyAckedCall wrap *gomock.Call
+type MockStreamNotifyAckedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamNotifyAckedCall) Return(arg0 <-chan struct{}) *MockStreamNotifyAckedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamNotifyAckedCall) Do(f func(int64) <-chan struct{}) *MockStreamNotifyAckedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamNotifyAckedCall) DoAndReturn(f func(int64) <-chan struct{}) *MockStreamNotifyAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Priority mocks base method.
+func (m *MockStream) Priority() quic.StreamPriority {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Priority")
+	ret0, _ := ret[0].(quic.StreamPriority)
+	return ret0
+}
+
+// Priority indicates an expected call of Priority.
+func (mr *MockStreamMockRecorder) Priority() *MockStreamPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Priority", reflect.TypeOf((*MockStream)(nil).Priority))
+	return &MockStreamPriorityCall{Call: call}
+}
+
+// MockStreamPriorityCall wrap *gomock.Call
+type MockStreamPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamPriorityCall) Return(arg0 quic.StreamPriority) *MockStreamPriorityCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamPriorityCall) Do(f func() quic.StreamPriority) *MockStreamPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamPriorityCall) DoAndReturn(f func() quic.StreamPriority) *MockStreamPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Read mocks base method.
 func (m *MockStream) Read(arg0 []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -229,6 +344,46 @@ func (c *MockStreamReadCall) DoAndReturn(f func([]byte) (int, error)) *MockStrea
 	return c
 }
 
+// ReadChunk mocks base method.
+func (m *MockStream) ReadChunk() ([]byte, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadChunk")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadChunk indicates an expected call of ReadChunk.
+func (mr *MockStreamMockRecorder) ReadChunk() *MockStreamReadChunkCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChunk", reflect.TypeOf((*MockStream)(nil).ReadChunk))
+	return &MockStreamReadChunkCall{Call: call}
+}
+
+// MockStreamReadChunkCall wrap *gomock.Call
+type MockStreamReadChunkCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamReadChunkCall) Return(arg0 []byte, arg1 func(), arg2 error) *MockStreamReadChunkCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamReadChunkCall) Do(f func() ([]byte, func(), error)) *MockStreamReadChunkCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamReadChunkCall) DoAndReturn(f func() ([]byte, func(), error)) *MockStreamReadChunkCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetDeadline mocks base method.
 func (m *MockStream) SetDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -267,6 +422,42 @@ func (c *MockStreamSetDeadlineCall) DoAndReturn(f func(time.Time) error) *MockSt
 	return c
 }
 
+// SetPriority mocks base method.
+func (m *MockStream) SetPriority(arg0 quic.StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamMockRecorder) SetPriority(arg0 any) *MockStreamSetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStream)(nil).SetPriority), arg0)
+	return &MockStreamSetPriorityCall{Call: call}
+}
+
+// MockStreamSetPriorityCall wrap *gomock.Call
+type MockStreamSetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamSetPriorityCall) Return() *MockStreamSetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamSetPriorityCall) Do(f func(quic.StreamPriority)) *MockStreamSetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamSetPriorityCall) DoAndReturn(f func(quic.StreamPriority)) *MockStreamSetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStream) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -343,6 +534,44 @@ func (c *MockStreamSetWriteDeadlineCall) DoAndReturn(f func(time.Time) error) *M
 	return c
 }
 
+// Stats mocks base method.
+func (m *MockStream) Stats() quic.SendStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(quic.SendStreamStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStreamMockRecorder) Stats() *MockStreamStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStream)(nil).Stats))
+	return &MockStreamStatsCall{Call: call}
+}
+
+// MockStreamStatsCall wrap *gomock.Call
+type MockStreamStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamStatsCall) Return(arg0 quic.SendStreamStats) *MockStreamStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamStatsCall) Do(f func() quic.SendStreamStats) *MockStreamStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamStatsCall) DoAndReturn(f func() quic.SendStreamStats) *MockStreamStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockStream) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -419,3 +648,42 @@ func (c *MockStreamWriteCall) DoAndReturn(f func([]byte) (int, error)) *MockStre
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// WriteOwned mocks base method.
+func (m *MockStream) WriteOwned(arg0 []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteOwned", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteOwned indicates an expected call of WriteOwned.
+func (mr *MockStreamMockRecorder) WriteOwned(arg0 any) *MockStreamWriteOwnedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteOwned", reflect.TypeOf((*MockStream)(nil).WriteOwned), arg0)
+	return &MockStreamWriteOwnedCall{Call: call}
+}
+
+// MockStreamWriteOwnedCall wrap *gomock.Call
+type MockStreamWriteOwnedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamWriteOwnedCall) Return(arg0 int, arg1 error) *MockStreamWriteOwnedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamWriteOwnedCall) Do(f func([]byte) (int, error)) *MockStreamWriteOwnedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamWriteOwnedCall) DoAndReturn(f func([]byte) (int, error)) *MockStreamWriteOwnedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -42,12 +42,18 @@ const DefaultMaxIncomingStreams = 100
 // DefaultMaxIncomingUniStreams is the maximum number of unidirectional streams that a peer may open
 const DefaultMaxIncomingUniStreams = 100
 
+// DefaultMaxDatagramReceiveQueueLen is the maximum number of received DATAGRAM frames that are queued
+const DefaultMaxDatagramReceiveQueueLen = 128
+
 // MaxServerUnprocessedPackets is the max number of packets stored in the server that are not yet processed.
 const MaxServerUnprocessedPackets = 1024
 
 // MaxConnUnprocessedPackets is the max number of packets stored in each connection that are not yet processed.
 const MaxConnUnprocessedPackets = 256
 
+// MaxReceiveBatchSize is the max number of packets the transport reads from the socket, before handing them to the connections.
+const MaxReceiveBatchSize = 64
+
 // SkipPacketInitialPeriod is the initial period length used for packet number skipping to prevent an Optimistic ACK attack.
 // Every time a packet number is skipped, the period is doubled, up to SkipPacketMaxPeriod.
 const SkipPacketInitialPeriod PacketNumber = 256
@@ -164,6 +170,10 @@ const MaxAckDelay = 25 * time.Millisecond
 // This is the value that should be advertised to the peer.
 const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
 
+// MinAckDelay is the min_ack_delay advertised to the peer (draft-ietf-quic-ack-frequency).
+// It is the smallest max_ack_delay that the peer can request using an ACK_FREQUENCY frame.
+const MinAckDelay = TimerGranularity
+
 // KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
 const KeyUpdateInterval = 100 * 1000
 
@@ -178,3 +188,7 @@ const Max0RTTQueues = 32
 // To avoid blocking, this value has to be smaller than MaxConnUnprocessedPackets.
 // To avoid packets being dropped as undecryptable by the connection, this value has to be smaller than MaxUndecryptablePackets.
 const Max0RTTQueueLen = 31
+
+// CarefulResumeMaxAge is the maximum age of the congestion state saved for careful resume.
+// Older state is not used, since the path characteristics might have changed in the meantime.
+const CarefulResumeMaxAge = time.Hour
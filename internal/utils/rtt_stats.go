@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -15,16 +16,17 @@ const (
 	defaultInitialRTT = 100 * time.Millisecond
 )
 
-// RTTStats provides round-trip statistics
+// RTTStats provides round-trip statistics.
+// It is only updated from a single goroutine, but the getters are safe for concurrent use.
 type RTTStats struct {
 	hasMeasurement bool
 
-	minRTT        time.Duration
-	latestRTT     time.Duration
-	smoothedRTT   time.Duration
-	meanDeviation time.Duration
+	minRTT        atomic.Int64
+	latestRTT     atomic.Int64
+	smoothedRTT   atomic.Int64
+	meanDeviation atomic.Int64
 
-	maxAckDelay time.Duration
+	maxAckDelay atomic.Int64
 }
 
 // NewRTTStats makes a properly initialized RTTStats object
@@ -34,21 +36,21 @@ func NewRTTStats() *RTTStats {
 
 // MinRTT Returns the minRTT for the entire connection.
 // May return Zero if no valid updates have occurred.
-func (r *RTTStats) MinRTT() time.Duration { return r.minRTT }
+func (r *RTTStats) MinRTT() time.Duration { return time.Duration(r.minRTT.Load()) }
 
 // LatestRTT returns the most recent rtt measurement.
 // May return Zero if no valid updates have occurred.
-func (r *RTTStats) LatestRTT() time.Duration { return r.latestRTT }
+func (r *RTTStats) LatestRTT() time.Duration { return time.Duration(r.latestRTT.Load()) }
 
 // SmoothedRTT returns the smoothed RTT for the connection.
 // May return Zero if no valid updates have occurred.
-func (r *RTTStats) SmoothedRTT() time.Duration { return r.smoothedRTT }
+func (r *RTTStats) SmoothedRTT() time.Duration { return time.Duration(r.smoothedRTT.Load()) }
 
 // MeanDeviation gets the mean deviation
-func (r *RTTStats) MeanDeviation() time.Duration { return r.meanDeviation }
+func (r *RTTStats) MeanDeviation() time.Duration { return time.Duration(r.meanDeviation.Load()) }
 
 // MaxAckDelay gets the max_ack_delay advertised by the peer
-func (r *RTTStats) MaxAckDelay() time.Duration { return r.maxAckDelay }
+func (r *RTTStats) MaxAckDelay() time.Duration { return time.Duration(r.maxAckDelay.Load()) }
 
 // PTO gets the probe timeout duration.
 func (r *RTTStats) PTO(includeMaxAckDelay bool) time.Duration {
@@ -72,32 +74,35 @@ func (r *RTTStats) UpdateRTT(sendDelta, ackDelay time.Duration, now time.Time) {
 	// ackDelay but the raw observed sendDelta, since poor clock granularity at
 	// the client may cause a high ackDelay to result in underestimation of the
 	// r.minRTT.
-	if r.minRTT == 0 || r.minRTT > sendDelta {
-		r.minRTT = sendDelta
+	minRTT := r.MinRTT()
+	if minRTT == 0 || minRTT > sendDelta {
+		minRTT = sendDelta
+		r.minRTT.Store(int64(sendDelta))
 	}
 
 	// Correct for ackDelay if information received from the peer results in a
 	// an RTT sample at least as large as minRTT. Otherwise, only use the
 	// sendDelta.
 	sample := sendDelta
-	if sample-r.minRTT >= ackDelay {
+	if sample-minRTT >= ackDelay {
 		sample -= ackDelay
 	}
-	r.latestRTT = sample
+	r.latestRTT.Store(int64(sample))
 	// First time call.
 	if !r.hasMeasurement {
 		r.hasMeasurement = true
-		r.smoothedRTT = sample
-		r.meanDeviation = sample / 2
+		r.smoothedRTT.Store(int64(sample))
+		r.meanDeviation.Store(int64(sample / 2))
 	} else {
-		r.meanDeviation = time.Duration(oneMinusBeta*float32(r.meanDeviation/time.Microsecond)+rttBeta*float32((r.smoothedRTT-sample).Abs()/time.Microsecond)) * time.Microsecond
-		r.smoothedRTT = time.Duration((float32(r.smoothedRTT/time.Microsecond)*oneMinusAlpha)+(float32(sample/time.Microsecond)*rttAlpha)) * time.Microsecond
+		smoothedRTT := r.SmoothedRTT()
+		r.meanDeviation.Store(int64(time.Duration(oneMinusBeta*float32(r.MeanDeviation()/time.Microsecond)+rttBeta*float32((smoothedRTT-sample).Abs()/time.Microsecond)) * time.Microsecond))
+		r.smoothedRTT.Store(int64(time.Duration((float32(smoothedRTT/time.Microsecond)*oneMinusAlpha)+(float32(sample/time.Microsecond)*rttAlpha)) * time.Microsecond))
 	}
 }
 
 // SetMaxAckDelay sets the max_ack_delay
 func (r *RTTStats) SetMaxAckDelay(mad time.Duration) {
-	r.maxAckDelay = mad
+	r.maxAckDelay.Store(int64(mad))
 }
 
 // SetInitialRTT sets the initial RTT.
@@ -110,22 +115,24 @@ func (r *RTTStats) SetInitialRTT(t time.Duration) {
 	if r.hasMeasurement {
 		return
 	}
-	r.smoothedRTT = t
-	r.latestRTT = t
+	r.smoothedRTT.Store(int64(t))
+	r.latestRTT.Store(int64(t))
 }
 
 // OnConnectionMigration is called when connection migrates and rtt measurement needs to be reset.
 func (r *RTTStats) OnConnectionMigration() {
-	r.latestRTT = 0
-	r.minRTT = 0
-	r.smoothedRTT = 0
-	r.meanDeviation = 0
+	r.hasMeasurement = false
+	r.latestRTT.Store(0)
+	r.minRTT.Store(0)
+	r.smoothedRTT.Store(0)
+	r.meanDeviation.Store(0)
 }
 
 // ExpireSmoothedMetrics causes the smoothed_rtt to be increased to the latest_rtt if the latest_rtt
 // is larger. The mean deviation is increased to the most recent deviation if
 // it's larger.
 func (r *RTTStats) ExpireSmoothedMetrics() {
-	r.meanDeviation = max(r.meanDeviation, (r.smoothedRTT - r.latestRTT).Abs())
-	r.smoothedRTT = max(r.smoothedRTT, r.latestRTT)
+	smoothedRTT, latestRTT := r.SmoothedRTT(), r.LatestRTT()
+	r.meanDeviation.Store(int64(max(r.MeanDeviation(), (smoothedRTT - latestRTT).Abs())))
+	r.smoothedRTT.Store(int64(max(smoothedRTT, latestRTT)))
 }
@@ -0,0 +1,70 @@
+package wire
+
+import (
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+)
+
+// An AckFrequencyFrame is an ACK_FREQUENCY frame (draft-ietf-quic-ack-frequency).
+type AckFrequencyFrame struct {
+	// The SequenceNumber is increased for every ACK_FREQUENCY frame sent,
+	// allowing the receiver to ignore reordered frames.
+	SequenceNumber uint64
+	// AckElicitingThreshold is the maximum number of ack-eliciting packets
+	// the peer receives before sending an acknowledgment.
+	AckElicitingThreshold uint64
+	// RequestMaxAckDelay is the maximum time the peer delays sending an acknowledgment.
+	RequestMaxAckDelay time.Duration
+	// ReorderingThreshold is the number of out-of-order packets that trigger an immediate acknowledgment.
+	// A value of 0 disables immediate acknowledgments of out-of-order packets.
+	ReorderingThreshold protocol.PacketNumber
+}
+
+func parseAckFrequencyFrame(b []byte, _ protocol.Version) (*AckFrequencyFrame, int, error) {
+	startLen := len(b)
+	seq, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	aeth, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	mad, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	rth, l, err := quicvarint.Parse(b)
+	if err != nil {
+		return nil, 0, replaceUnexpectedEOF(err)
+	}
+	b = b[l:]
+	return &AckFrequencyFrame{
+		SequenceNumber:        seq,
+		AckElicitingThreshold: aeth,
+		RequestMaxAckDelay:    time.Duration(mad) * time.Microsecond,
+		ReorderingThreshold:   protocol.PacketNumber(rth),
+	}, startLen - len(b), nil
+}
+
+func (f *AckFrequencyFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
+	b = quicvarint.Append(b, ackFrequencyFrameType)
+	b = quicvarint.Append(b, f.SequenceNumber)
+	b = quicvarint.Append(b, f.AckElicitingThreshold)
+	b = quicvarint.Append(b, uint64(f.RequestMaxAckDelay/time.Microsecond))
+	return quicvarint.Append(b, uint64(f.ReorderingThreshold)), nil
+}
+
+// Length of a written frame
+func (f *AckFrequencyFrame) Length(_ protocol.Version) protocol.ByteCount {
+	return protocol.ByteCount(quicvarint.Len(ackFrequencyFrameType) +
+		quicvarint.Len(f.SequenceNumber) +
+		quicvarint.Len(f.AckElicitingThreshold) +
+		quicvarint.Len(uint64(f.RequestMaxAckDelay/time.Microsecond)) +
+		quicvarint.Len(uint64(f.ReorderingThreshold)))
+}
@@ -0,0 +1,75 @@
+package wire
+
+import (
+	"io"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_FREQUENCY frame", func() {
+	Context("when parsing", func() {
+		It("accepts a sample frame", func() {
+			data := encodeVarInt(0xdecafbad)           // sequence number
+			data = append(data, encodeVarInt(0x42)...) // ack-eliciting threshold
+			data = append(data, encodeVarInt(5000)...) // request max ack delay, in microseconds
+			data = append(data, encodeVarInt(3)...)    // reordering threshold
+			f, l, err := parseAckFrequencyFrame(data, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(f.SequenceNumber).To(Equal(uint64(0xdecafbad)))
+			Expect(f.AckElicitingThreshold).To(Equal(uint64(0x42)))
+			Expect(f.RequestMaxAckDelay).To(Equal(5 * time.Millisecond))
+			Expect(f.ReorderingThreshold).To(Equal(protocol.PacketNumber(3)))
+			Expect(l).To(Equal(len(data)))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0xdecafbad)           // sequence number
+			data = append(data, encodeVarInt(0x42)...) // ack-eliciting threshold
+			data = append(data, encodeVarInt(5000)...) // request max ack delay, in microseconds
+			data = append(data, encodeVarInt(3)...)    // reordering threshold
+			_, l, err := parseAckFrequencyFrame(data, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(l).To(Equal(len(data)))
+			for i := range data {
+				_, _, err := parseAckFrequencyFrame(data[:i], protocol.Version1)
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			f := &AckFrequencyFrame{
+				SequenceNumber:        0x1337,
+				AckElicitingThreshold: 0x42,
+				RequestMaxAckDelay:    12 * time.Millisecond,
+				ReorderingThreshold:   1,
+			}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := quicvarint.Append(nil, ackFrequencyFrameType)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0x42)...)
+			expected = append(expected, encodeVarInt(12000)...)
+			expected = append(expected, encodeVarInt(1)...)
+			Expect(b).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			f := &AckFrequencyFrame{
+				SequenceNumber:        0x1337,
+				AckElicitingThreshold: 0x42,
+				RequestMaxAckDelay:    12 * time.Millisecond,
+				ReorderingThreshold:   1,
+			}
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+		})
+	})
+})
@@ -34,12 +34,21 @@ const (
 	connectionCloseFrameType    = 0x1c
 	applicationCloseFrameType   = 0x1d
 	handshakeDoneFrameType      = 0x1e
+	resetStreamAtFrameType      = 0x24
+)
+
+// frame types defined by draft-ietf-quic-ack-frequency
+const (
+	ackFrequencyFrameType = 0xaf
+	immediateAckFrameType = 0x1f
 )
 
 // The FrameParser parses QUIC frames, one by one.
 type FrameParser struct {
-	ackDelayExponent  uint8
-	supportsDatagrams bool
+	ackDelayExponent      uint8
+	supportsDatagrams     bool
+	supportsResetStreamAt bool
+	supportsAckFrequency  bool
 
 	// To avoid allocating when parsing, keep a single ACK frame struct.
 	// It is used over and over again.
@@ -47,10 +56,12 @@ type FrameParser struct {
 }
 
 // NewFrameParser creates a new frame parser.
-func NewFrameParser(supportsDatagrams bool) *FrameParser {
+func NewFrameParser(supportsDatagrams, supportsResetStreamAt, supportsAckFrequency bool) *FrameParser {
 	return &FrameParser{
-		supportsDatagrams: supportsDatagrams,
-		ackFrame:          &AckFrame{},
+		supportsDatagrams:     supportsDatagrams,
+		supportsResetStreamAt: supportsResetStreamAt,
+		supportsAckFrequency:  supportsAckFrequency,
+		ackFrame:              &AckFrame{},
 	}
 }
 
@@ -95,7 +106,7 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 	var frame Frame
 	var err error
 	var l int
-	if typ&0xf8 == 0x8 {
+	if typ&^0x7 == 0x8 {
 		frame, l, err = parseStreamFrame(b, typ, v)
 	} else {
 		switch typ {
@@ -110,7 +121,7 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 			l, err = parseAckFrame(p.ackFrame, b, typ, ackDelayExponent, v)
 			frame = p.ackFrame
 		case resetStreamFrameType:
-			frame, l, err = parseResetStreamFrame(b, v)
+			frame, l, err = parseResetStreamFrame(b, false, v)
 		case stopSendingFrameType:
 			frame, l, err = parseStopSendingFrame(b, v)
 		case cryptoFrameType:
@@ -146,7 +157,25 @@ func (p *FrameParser) parseFrame(b []byte, typ uint64, encLevel protocol.Encrypt
 				frame, l, err = parseDatagramFrame(b, typ, v)
 				break
 			}
-			fallthrough
+			err = errors.New("unknown frame type")
+		case resetStreamAtFrameType:
+			if p.supportsResetStreamAt {
+				frame, l, err = parseResetStreamFrame(b, true, v)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case ackFrequencyFrameType:
+			if p.supportsAckFrequency {
+				frame, l, err = parseAckFrequencyFrame(b, v)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case immediateAckFrameType:
+			if p.supportsAckFrequency {
+				frame = &ImmediateAckFrame{}
+				break
+			}
+			err = errors.New("unknown frame type")
 		default:
 			err = errors.New("unknown frame type")
 		}
@@ -18,7 +18,7 @@ var _ = Describe("Frame parsing", func() {
 	var parser FrameParser
 
 	BeforeEach(func() {
-		parser = *NewFrameParser(true)
+		parser = *NewFrameParser(true, true, true)
 	})
 
 	It("returns nil if there's nothing more to read", func() {
@@ -112,6 +112,34 @@ var _ = Describe("Frame parsing", func() {
 		Expect(l).To(Equal(len(b)))
 	})
 
+	It("unpacks RESET_STREAM_AT frames", func() {
+		f := &ResetStreamFrame{
+			StreamID:     0xdeadbeef,
+			FinalSize:    0xdecafbad1234,
+			ErrorCode:    0x1337,
+			ReliableSize: 0x42,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when RESET_STREAM_AT frames are not supported", func() {
+		parser = *NewFrameParser(true, false, true)
+		f := &ResetStreamFrame{StreamID: 0xdeadbeef, FinalSize: 0x1234, ReliableSize: 0x42}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.FrameEncodingError,
+			FrameType:    0x24,
+			ErrorMessage: "unknown frame type",
+		}))
+	})
+
 	It("unpacks STOP_SENDING frames", func() {
 		f := &StopSendingFrame{StreamID: 0x42}
 		b, err := f.Append(nil, protocol.Version1)
@@ -319,7 +347,7 @@ var _ = Describe("Frame parsing", func() {
 	})
 
 	It("errors when DATAGRAM frames are not supported", func() {
-		parser = *NewFrameParser(false)
+		parser = *NewFrameParser(false, false, false)
 		f := &DatagramFrame{Data: []byte("foobar")}
 		b, err := f.Append(nil, protocol.Version1)
 		Expect(err).ToNot(HaveOccurred())
@@ -331,6 +359,43 @@ var _ = Describe("Frame parsing", func() {
 		}))
 	})
 
+	It("unpacks ACK_FREQUENCY frames", func() {
+		f := &AckFrequencyFrame{
+			SequenceNumber:        3,
+			AckElicitingThreshold: 10,
+			RequestMaxAckDelay:    5 * time.Millisecond,
+			ReorderingThreshold:   2,
+		}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("unpacks IMMEDIATE_ACK frames", func() {
+		f := &ImmediateAckFrame{}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		l, frame, err := parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+		Expect(l).To(Equal(len(b)))
+	})
+
+	It("errors when ACK frequency frames are not supported", func() {
+		parser = *NewFrameParser(true, true, false)
+		for _, f := range []Frame{&AckFrequencyFrame{}, &ImmediateAckFrame{}} {
+			b, err := f.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = parser.ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*qerr.TransportError).ErrorCode).To(Equal(qerr.FrameEncodingError))
+			Expect(err.(*qerr.TransportError).ErrorMessage).To(Equal("unknown frame type"))
+		}
+	})
+
 	It("errors on invalid type", func() {
 		_, _, err := parser.ParseNext(encodeVarInt(0x42), protocol.Encryption1RTT, protocol.Version1)
 		Expect(err).To(MatchError(&qerr.TransportError{
@@ -340,6 +405,18 @@ var _ = Describe("Frame parsing", func() {
 		}))
 	})
 
+	It("doesn't parse frame types larger than 0xff as STREAM frames", func() {
+		// the least significant byte of this frame type is a STREAM frame type
+		for _, typ := range []uint64{0x108, 0x10f, 0x4242420a} {
+			_, _, err := parser.ParseNext(encodeVarInt(typ), protocol.Encryption1RTT, protocol.Version1)
+			Expect(err).To(MatchError(&qerr.TransportError{
+				ErrorCode:    qerr.FrameEncodingError,
+				FrameType:    typ,
+				ErrorMessage: "unknown frame type",
+			}))
+		}
+	})
+
 	It("errors on invalid frames", func() {
 		f := &MaxStreamDataFrame{
 			StreamID:          0x1337,
@@ -357,6 +434,7 @@ var _ = Describe("Frame parsing", func() {
 			&PingFrame{},
 			&AckFrame{AckRanges: []AckRange{{Smallest: 1, Largest: 42}}},
 			&ResetStreamFrame{},
+			&ResetStreamFrame{FinalSize: 2, ReliableSize: 1},
 			&StopSendingFrame{},
 			&CryptoFrame{},
 			&NewTokenFrame{Token: []byte("lorem ipsum")},
@@ -374,6 +452,8 @@ var _ = Describe("Frame parsing", func() {
 			&ConnectionCloseFrame{},
 			&HandshakeDoneFrame{},
 			&DatagramFrame{},
+			&AckFrequencyFrame{},
+			&ImmediateAckFrame{},
 		}
 
 		var framesSerialized [][]byte
@@ -467,7 +547,7 @@ func BenchmarkParseStreamAndACK(b *testing.B) {
 		b.Fatal(err)
 	}
 
-	parser := NewFrameParser(false)
+	parser := NewFrameParser(false, false, false)
 	parser.SetAckDelayExponent(3)
 
 	b.ResetTimer()
@@ -525,7 +605,7 @@ func BenchmarkParseOtherFrames(b *testing.B) {
 		}
 	}
 
-	parser := NewFrameParser(false)
+	parser := NewFrameParser(false, false, false)
 
 	b.ResetTimer()
 	b.ReportAllocs()
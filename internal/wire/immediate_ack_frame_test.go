@@ -0,0 +1,19 @@
+package wire
+
+import (
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IMMEDIATE_ACK frame", func() {
+	It("writes a sample frame", func() {
+		f := &ImmediateAckFrame{}
+		b, err := f.Append(nil, protocol.Version1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(Equal(quicvarint.Append(nil, immediateAckFrameType)))
+		Expect(b).To(HaveLen(int(f.Length(protocol.Version1))))
+	})
+})
@@ -0,0 +1,17 @@
+package wire
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Frames", func() {
+	It("identifies probing frames", func() {
+		Expect(IsProbingFrame(&PathChallengeFrame{})).To(BeTrue())
+		Expect(IsProbingFrame(&PathResponseFrame{})).To(BeTrue())
+		Expect(IsProbingFrame(&NewConnectionIDFrame{})).To(BeTrue())
+		Expect(IsProbingFrame(&PingFrame{})).To(BeFalse())
+		Expect(IsProbingFrame(&StreamFrame{})).To(BeFalse())
+		Expect(IsProbingFrame(&AckFrame{})).To(BeFalse())
+	})
+})
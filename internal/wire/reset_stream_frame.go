@@ -1,19 +1,24 @@
 package wire
 
 import (
+	"errors"
+
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
 	"github.com/quic-go/quic-go/quicvarint"
 )
 
-// A ResetStreamFrame is a RESET_STREAM frame in QUIC
+// A ResetStreamFrame is a RESET_STREAM or a RESET_STREAM_AT frame in QUIC
 type ResetStreamFrame struct {
 	StreamID  protocol.StreamID
 	ErrorCode qerr.StreamErrorCode
 	FinalSize protocol.ByteCount
+	// ReliableSize is the amount of data that is delivered reliably, despite the stream being reset.
+	// If it is larger than 0, the frame is serialized as a RESET_STREAM_AT frame (draft-ietf-quic-reliable-stream-reset).
+	ReliableSize protocol.ByteCount
 }
 
-func parseResetStreamFrame(b []byte, _ protocol.Version) (*ResetStreamFrame, int, error) {
+func parseResetStreamFrame(b []byte, isResetStreamAt bool, _ protocol.Version) (*ResetStreamFrame, int, error) {
 	startLen := len(b)
 	var streamID protocol.StreamID
 	var byteOffset protocol.ByteCount
@@ -32,24 +37,48 @@ func parseResetStreamFrame(b []byte, _ protocol.Version) (*ResetStreamFrame, int
 	if err != nil {
 		return nil, 0, replaceUnexpectedEOF(err)
 	}
+	b = b[l:]
 	byteOffset = protocol.ByteCount(bo)
+	var reliableSize uint64
+	if isResetStreamAt {
+		reliableSize, l, err = quicvarint.Parse(b)
+		if err != nil {
+			return nil, 0, replaceUnexpectedEOF(err)
+		}
+		b = b[l:]
+		if reliableSize > bo {
+			return nil, 0, errors.New("RESET_STREAM_AT: reliable size can't be larger than final size")
+		}
+	}
 
 	return &ResetStreamFrame{
-		StreamID:  streamID,
-		ErrorCode: qerr.StreamErrorCode(errorCode),
-		FinalSize: byteOffset,
-	}, startLen - len(b) + l, nil
+		StreamID:     streamID,
+		ErrorCode:    qerr.StreamErrorCode(errorCode),
+		FinalSize:    byteOffset,
+		ReliableSize: protocol.ByteCount(reliableSize),
+	}, startLen - len(b), nil
 }
 
 func (f *ResetStreamFrame) Append(b []byte, _ protocol.Version) ([]byte, error) {
-	b = append(b, resetStreamFrameType)
+	if f.ReliableSize == 0 {
+		b = append(b, resetStreamFrameType)
+	} else {
+		b = append(b, resetStreamAtFrameType)
+	}
 	b = quicvarint.Append(b, uint64(f.StreamID))
 	b = quicvarint.Append(b, uint64(f.ErrorCode))
 	b = quicvarint.Append(b, uint64(f.FinalSize))
+	if f.ReliableSize > 0 {
+		b = quicvarint.Append(b, uint64(f.ReliableSize))
+	}
 	return b, nil
 }
 
 // Length of a written frame
 func (f *ResetStreamFrame) Length(protocol.Version) protocol.ByteCount {
-	return 1 + protocol.ByteCount(quicvarint.Len(uint64(f.StreamID))+quicvarint.Len(uint64(f.ErrorCode))+quicvarint.Len(uint64(f.FinalSize)))
+	size := 1
+	if f.ReliableSize > 0 {
+		size += quicvarint.Len(uint64(f.ReliableSize))
+	}
+	return protocol.ByteCount(size + quicvarint.Len(uint64(f.StreamID)) + quicvarint.Len(uint64(f.ErrorCode)) + quicvarint.Len(uint64(f.FinalSize)))
 }
@@ -15,7 +15,7 @@ var _ = Describe("RESET_STREAM frame", func() {
 			data := encodeVarInt(0xdeadbeef)                  // stream ID
 			data = append(data, encodeVarInt(0x1337)...)      // error code
 			data = append(data, encodeVarInt(0x987654321)...) // byte offset
-			frame, l, err := parseResetStreamFrame(data, protocol.Version1)
+			frame, l, err := parseResetStreamFrame(data, false, protocol.Version1)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
 			Expect(frame.FinalSize).To(Equal(protocol.ByteCount(0x987654321)))
@@ -27,11 +27,48 @@ var _ = Describe("RESET_STREAM frame", func() {
 			data := encodeVarInt(0xdeadbeef)                  // stream ID
 			data = append(data, encodeVarInt(0x1337)...)      // error code
 			data = append(data, encodeVarInt(0x987654321)...) // byte offset
-			_, l, err := parseResetStreamFrame(data, protocol.Version1)
+			_, l, err := parseResetStreamFrame(data, false, protocol.Version1)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(l).To(Equal(len(data)))
 			for i := range data {
-				_, _, err := parseResetStreamFrame(data[:i], protocol.Version1)
+				_, _, err := parseResetStreamFrame(data[:i], false, protocol.Version1)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+
+		It("accepts a RESET_STREAM_AT frame", func() {
+			data := encodeVarInt(0xdeadbeef)              // stream ID
+			data = append(data, encodeVarInt(0x1337)...)  // error code
+			data = append(data, encodeVarInt(0x54321)...) // byte offset
+			data = append(data, encodeVarInt(0x4321)...)  // reliable size
+			frame, l, err := parseResetStreamFrame(data, true, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
+			Expect(frame.FinalSize).To(Equal(protocol.ByteCount(0x54321)))
+			Expect(frame.ReliableSize).To(Equal(protocol.ByteCount(0x4321)))
+			Expect(frame.ErrorCode).To(Equal(qerr.StreamErrorCode(0x1337)))
+			Expect(l).To(Equal(len(data)))
+		})
+
+		It("rejects RESET_STREAM_AT frames with a reliable size larger than the final size", func() {
+			data := encodeVarInt(0xdeadbeef)             // stream ID
+			data = append(data, encodeVarInt(0x1337)...) // error code
+			data = append(data, encodeVarInt(1000)...)   // byte offset
+			data = append(data, encodeVarInt(1001)...)   // reliable size
+			_, _, err := parseResetStreamFrame(data, true, protocol.Version1)
+			Expect(err).To(MatchError("RESET_STREAM_AT: reliable size can't be larger than final size"))
+		})
+
+		It("errors on EOFs, for RESET_STREAM_AT frames", func() {
+			data := encodeVarInt(0xdeadbeef)              // stream ID
+			data = append(data, encodeVarInt(0x1337)...)  // error code
+			data = append(data, encodeVarInt(0x54321)...) // byte offset
+			data = append(data, encodeVarInt(0x4321)...)  // reliable size
+			_, l, err := parseResetStreamFrame(data, true, protocol.Version1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(l).To(Equal(len(data)))
+			for i := range data {
+				_, _, err := parseResetStreamFrame(data[:i], true, protocol.Version1)
 				Expect(err).To(HaveOccurred())
 			}
 		})
@@ -62,5 +99,23 @@ var _ = Describe("RESET_STREAM frame", func() {
 			expectedLen := 1 + quicvarint.Len(0x1337) + quicvarint.Len(0x1234567) + 2
 			Expect(rst.Length(protocol.Version1)).To(BeEquivalentTo(expectedLen))
 		})
+
+		It("writes a RESET_STREAM_AT frame", func() {
+			frame := ResetStreamFrame{
+				StreamID:     0x1337,
+				FinalSize:    0x11223344decafbad,
+				ErrorCode:    0xcafe,
+				ReliableSize: 0x42,
+			}
+			b, err := frame.Append(nil, protocol.Version1)
+			Expect(err).ToNot(HaveOccurred())
+			expected := []byte{resetStreamAtFrameType}
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xcafe)...)
+			expected = append(expected, encodeVarInt(0x11223344decafbad)...)
+			expected = append(expected, encodeVarInt(0x42)...)
+			Expect(b).To(Equal(expected))
+			Expect(frame.Length(protocol.Version1)).To(BeEquivalentTo(len(b)))
+		})
 	})
 })
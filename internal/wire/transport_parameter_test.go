@@ -44,6 +44,7 @@ var _ = Describe("Transport Parameters", func() {
 
 	It("has a string representation", func() {
 		rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
+		minAckDelay := 2 * time.Millisecond
 		p := &TransportParameters{
 			InitialMaxStreamDataBidiLocal:   1234,
 			InitialMaxStreamDataBidiRemote:  2345,
@@ -60,8 +61,10 @@ var _ = Describe("Transport Parameters", func() {
 			StatelessResetToken:             &protocol.StatelessResetToken{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00},
 			ActiveConnectionIDLimit:         123,
 			MaxDatagramFrameSize:            876,
+			EnableResetStreamAt:             true,
+			MinAckDelay:                     &minAckDelay,
 		}
-		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"))
+		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876, EnableResetStreamAt: true, MinAckDelay: 2ms}"))
 	})
 
 	It("has a string representation, if there's no stateless reset token, no Retry source connection id and no datagram support", func() {
@@ -87,6 +90,7 @@ var _ = Describe("Transport Parameters", func() {
 		var token protocol.StatelessResetToken
 		rand.Read(token[:])
 		rcid := protocol.ParseConnectionID([]byte{0xde, 0xad, 0xc0, 0xde})
+		minAckDelay := 1337 * time.Microsecond
 		params := &TransportParameters{
 			InitialMaxStreamDataBidiLocal:   protocol.ByteCount(getRandomValue()),
 			InitialMaxStreamDataBidiRemote:  protocol.ByteCount(getRandomValue()),
@@ -105,6 +109,8 @@ var _ = Describe("Transport Parameters", func() {
 			ActiveConnectionIDLimit:         2 + getRandomValueUpTo(quicvarint.Max-2),
 			MaxUDPPayloadSize:               1200 + protocol.ByteCount(getRandomValueUpTo(quicvarint.Max-1200)),
 			MaxDatagramFrameSize:            protocol.ByteCount(getRandomValue()),
+			EnableResetStreamAt:             getRandomValue()%2 == 0,
+			MinAckDelay:                     &minAckDelay,
 		}
 		data := params.Marshal(protocol.PerspectiveServer)
 
@@ -127,6 +133,36 @@ var _ = Describe("Transport Parameters", func() {
 		Expect(p.ActiveConnectionIDLimit).To(Equal(params.ActiveConnectionIDLimit))
 		Expect(p.MaxUDPPayloadSize).To(Equal(params.MaxUDPPayloadSize))
 		Expect(p.MaxDatagramFrameSize).To(Equal(params.MaxDatagramFrameSize))
+		Expect(p.EnableResetStreamAt).To(Equal(params.EnableResetStreamAt))
+		Expect(p.MinAckDelay).To(Equal(&minAckDelay))
+	})
+
+	It("doesn't marshal the min_ack_delay, if the ACK frequency extension is not supported", func() {
+		data := (&TransportParameters{
+			OriginalDestinationConnectionID: protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}),
+			InitialSourceConnectionID:       protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}),
+			ActiveConnectionIDLimit:         2,
+			MaxDatagramFrameSize:            protocol.InvalidByteCount,
+		}).Marshal(protocol.PerspectiveServer)
+		p := &TransportParameters{}
+		Expect(p.Unmarshal(data, protocol.PerspectiveServer)).To(Succeed())
+		Expect(p.MinAckDelay).To(BeNil())
+	})
+
+	It("errors when the min_ack_delay is larger than the max_ack_delay", func() {
+		minAckDelay := 30 * time.Millisecond
+		data := (&TransportParameters{
+			OriginalDestinationConnectionID: protocol.ParseConnectionID([]byte{0xde, 0xad, 0xbe, 0xef}),
+			InitialSourceConnectionID:       protocol.ParseConnectionID([]byte{0xde, 0xca, 0xfb, 0xad}),
+			MaxAckDelay:                     25 * time.Millisecond,
+			MinAckDelay:                     &minAckDelay,
+			ActiveConnectionIDLimit:         2,
+			MaxDatagramFrameSize:            protocol.InvalidByteCount,
+		}).Marshal(protocol.PerspectiveServer)
+		Expect((&TransportParameters{}).Unmarshal(data, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "min_ack_delay (30ms) larger than max_ack_delay (25ms)",
+		}))
 	})
 
 	It("marshals additional transport parameters (used for testing large ClientHellos)", func() {
@@ -188,6 +224,16 @@ var _ = Describe("Transport Parameters", func() {
 		}))
 	})
 
+	It("errors when reset_stream_at has content", func() {
+		b := quicvarint.Append(nil, uint64(resetStreamAtParameterID))
+		b = quicvarint.Append(b, 6)
+		b = append(b, []byte("foobar")...)
+		Expect((&TransportParameters{}).Unmarshal(b, protocol.PerspectiveServer)).To(MatchError(&qerr.TransportError{
+			ErrorCode:    qerr.TransportParameterError,
+			ErrorMessage: "wrong length for reset_stream_at: 6 (expected empty)",
+		}))
+	})
+
 	It("errors when disable_active_migration has content", func() {
 		b := quicvarint.Append(nil, uint64(disableActiveMigrationParameterID))
 		b = quicvarint.Append(b, 6)
@@ -21,6 +21,7 @@ type ConnectionTracer struct {
 	ReceivedShortHeaderPacket        func(*ShortHeader, ByteCount, ECN, []Frame)
 	BufferedPacket                   func(PacketType, ByteCount)
 	DroppedPacket                    func(PacketType, PacketNumber, ByteCount, PacketDropReason)
+	DroppedDatagram                  func(length ByteCount) // a received DATAGRAM frame was dropped, since the receive queue was full
 	UpdatedMetrics                   func(rttStats *RTTStats, cwnd, bytesInFlight ByteCount, packetsInFlight int)
 	AcknowledgedPacket               func(EncryptionLevel, PacketNumber)
 	LostPacket                       func(EncryptionLevel, PacketNumber, PacketLossReason)
@@ -36,6 +37,7 @@ type ConnectionTracer struct {
 	LossTimerCanceled                func()
 	ECNStateUpdated                  func(state ECNState, trigger ECNStateTrigger)
 	ChoseALPN                        func(protocol string)
+	MigratedPath                     func(oldRemote, newRemote net.Addr)
 	// Close is called when the connection is closed.
 	Close func()
 	Debug func(name, msg string)
@@ -148,6 +150,13 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		DroppedDatagram: func(length ByteCount) {
+			for _, t := range tracers {
+				if t.DroppedDatagram != nil {
+					t.DroppedDatagram(length)
+				}
+			}
+		},
 		UpdatedMetrics: func(rttStats *RTTStats, cwnd, bytesInFlight ByteCount, packetsInFlight int) {
 			for _, t := range tracers {
 				if t.UpdatedMetrics != nil {
@@ -253,6 +262,13 @@ func NewMultiplexedConnectionTracer(tracers ...*ConnectionTracer) *ConnectionTra
 				}
 			}
 		},
+		MigratedPath: func(oldRemote, newRemote net.Addr) {
+			for _, t := range tracers {
+				if t.MigratedPath != nil {
+					t.MigratedPath(oldRemote, newRemote)
+				}
+			}
+		},
 		Close: func() {
 			for _, t := range tracers {
 				if t.Close != nil {
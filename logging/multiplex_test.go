@@ -65,6 +65,13 @@ var _ = Describe("Tracing", func() {
 				tracer.DroppedPacket(remote, PacketTypeRetry, 1024, PacketDropDuplicate)
 			})
 
+			It("traces the RejectedConnection event", func() {
+				remote := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1)}
+				tr1.EXPECT().RejectedConnection(remote, ConnectionRejectTooManyHandshakes)
+				tr2.EXPECT().RejectedConnection(remote, ConnectionRejectTooManyHandshakes)
+				tracer.RejectedConnection(remote, ConnectionRejectTooManyHandshakes)
+			})
+
 			It("traces the Debug event", func() {
 				tr1.EXPECT().Debug("foo", "bar")
 				tr2.EXPECT().Debug("foo", "bar")
@@ -201,6 +208,20 @@ var _ = Describe("Tracing", func() {
 			tracer.DroppedPacket(PacketTypeInitial, 42, 1337, PacketDropHeaderParseError)
 		})
 
+		It("traces the DroppedDatagram event", func() {
+			tr1.EXPECT().DroppedDatagram(ByteCount(1337))
+			tr2.EXPECT().DroppedDatagram(ByteCount(1337))
+			tracer.DroppedDatagram(1337)
+		})
+
+		It("traces the MigratedPath event", func() {
+			oldAddr := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+			newAddr := &net.UDPAddr{IP: net.IPv4(4, 3, 2, 1), Port: 4321}
+			tr1.EXPECT().MigratedPath(oldAddr, newAddr)
+			tr2.EXPECT().MigratedPath(oldAddr, newAddr)
+			tracer.MigratedPath(oldAddr, newAddr)
+		})
+
 		It("traces the UpdatedMTU event", func() {
 			tr1.EXPECT().UpdatedMTU(ByteCount(1337), true)
 			tr2.EXPECT().UpdatedMTU(ByteCount(1337), true)
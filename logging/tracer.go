@@ -7,6 +7,7 @@ type Tracer struct {
 	SentPacket                   func(net.Addr, *Header, ByteCount, []Frame)
 	SentVersionNegotiationPacket func(_ net.Addr, dest, src ArbitraryLenConnectionID, _ []VersionNumber)
 	DroppedPacket                func(net.Addr, PacketType, ByteCount, PacketDropReason)
+	RejectedConnection           func(net.Addr, ConnectionRejectReason)
 	Debug                        func(name, msg string)
 	Close                        func()
 }
@@ -41,6 +42,13 @@ func NewMultiplexedTracer(tracers ...*Tracer) *Tracer {
 				}
 			}
 		},
+		RejectedConnection: func(remote net.Addr, reason ConnectionRejectReason) {
+			for _, t := range tracers {
+				if t.RejectedConnection != nil {
+					t.RejectedConnection(remote, reason)
+				}
+			}
+		},
 		Debug: func(name, msg string) {
 			for _, t := range tracers {
 				if t.Debug != nil {
@@ -58,6 +58,26 @@ const (
 	PacketDropDuplicate
 )
 
+// ConnectionRejectReason is the reason why a connection attempt was rejected by the server's admission control
+type ConnectionRejectReason uint8
+
+const (
+	// ConnectionRejectRateLimited is used when an Initial packet is dropped because
+	// its source exceeded the Initial packet rate
+	ConnectionRejectRateLimited ConnectionRejectReason = iota
+	// ConnectionRejectTooManyHandshakes is used when a connection attempt is refused because
+	// its source already has too many handshakes in progress
+	ConnectionRejectTooManyHandshakes
+	// ConnectionRejectTooManyConnections is used when a connection attempt is refused because
+	// its source already has too many connections
+	ConnectionRejectTooManyConnections
+	// ConnectionRejectAcceptQueueFull is used when a connection is closed after completing the handshake,
+	// because the accept queue is full
+	ConnectionRejectAcceptQueueFull
+	// ConnectionRejectDraining is used when a connection attempt is refused because the server is draining
+	ConnectionRejectDraining
+)
+
 // TimerType is the type of the loss detection timer
 type TimerType uint8
 
@@ -34,6 +34,14 @@ var (
 		},
 		[]string{"ip_version", "reason"},
 	)
+	connsAdmissionRejected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricNamespace,
+			Name:      "server_connections_admission_rejected_total",
+			Help:      "Connection attempts rejected by admission control",
+		},
+		[]string{"ip_version", "reason"},
+	)
 	packetDropped = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: metricNamespace,
@@ -56,6 +64,7 @@ func NewTracer() *logging.Tracer {
 func NewTracerWithRegisterer(registerer prometheus.Registerer) *logging.Tracer {
 	for _, c := range [...]prometheus.Collector{
 		connsRejected,
+		connsAdmissionRejected,
 		packetDropped,
 	} {
 		if err := registerer.Register(c); err != nil {
@@ -143,5 +152,29 @@ func NewTracerWithRegisterer(registerer prometheus.Registerer) *logging.Tracer {
 			*tags = append(*tags, dropReason)
 			packetDropped.WithLabelValues(*tags...).Inc()
 		},
+		RejectedConnection: func(addr net.Addr, reason logging.ConnectionRejectReason) {
+			tags := getStringSlice()
+			defer putStringSlice(tags)
+
+			var rejectReason string
+			switch reason {
+			case logging.ConnectionRejectRateLimited:
+				rejectReason = "rate_limited"
+			case logging.ConnectionRejectTooManyHandshakes:
+				rejectReason = "too_many_handshakes"
+			case logging.ConnectionRejectTooManyConnections:
+				rejectReason = "too_many_connections"
+			case logging.ConnectionRejectAcceptQueueFull:
+				rejectReason = "accept_queue_full"
+			case logging.ConnectionRejectDraining:
+				rejectReason = "draining"
+			default:
+				rejectReason = "unknown"
+			}
+
+			*tags = append(*tags, getIPVersion(addr))
+			*tags = append(*tags, rejectReason)
+			connsAdmissionRejected.WithLabelValues(*tags...).Inc()
+		},
 	}
 }
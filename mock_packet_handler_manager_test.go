@@ -226,6 +226,42 @@ func (c *MockPacketHandlerManagerGetCall) DoAndReturn(f func(protocol.Connection
 	return c
 }
 
+// GetBatch mocks base method.
+func (m *MockPacketHandlerManager) GetBatch(arg0 []protocol.ConnectionID, arg1 []packetHandler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetBatch", arg0, arg1)
+}
+
+// GetBatch indicates an expected call of GetBatch.
+func (mr *MockPacketHandlerManagerMockRecorder) GetBatch(arg0, arg1 any) *MockPacketHandlerManagerGetBatchCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBatch", reflect.TypeOf((*MockPacketHandlerManager)(nil).GetBatch), arg0, arg1)
+	return &MockPacketHandlerManagerGetBatchCall{Call: call}
+}
+
+// MockPacketHandlerManagerGetBatchCall wrap *gomock.Call
+type MockPacketHandlerManagerGetBatchCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockPacketHandlerManagerGetBatchCall) Return() *MockPacketHandlerManagerGetBatchCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockPacketHandlerManagerGetBatchCall) Do(f func([]protocol.ConnectionID, []packetHandler)) *MockPacketHandlerManagerGetBatchCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockPacketHandlerManagerGetBatchCall) DoAndReturn(f func([]protocol.ConnectionID, []packetHandler)) *MockPacketHandlerManagerGetBatchCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetByResetToken mocks base method.
 func (m *MockPacketHandlerManager) GetByResetToken(arg0 protocol.StatelessResetToken) (packetHandler, bool) {
 	m.ctrl.T.Helper()
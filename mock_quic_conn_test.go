@@ -14,6 +14,7 @@ import (
 	net "net"
 	reflect "reflect"
 
+	protocol "github.com/quic-go/quic-go/internal/protocol"
 	qerr "github.com/quic-go/quic-go/internal/qerr"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -119,6 +120,45 @@ func (c *MockQUICConnAcceptUniStreamCall) DoAndReturn(f func(context.Context) (R
 	return c
 }
 
+// AddPath mocks base method.
+func (m *MockQUICConn) AddPath(arg0 *Transport) (*Path, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPath", arg0)
+	ret0, _ := ret[0].(*Path)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPath indicates an expected call of AddPath.
+func (mr *MockQUICConnMockRecorder) AddPath(arg0 any) *MockQUICConnAddPathCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPath", reflect.TypeOf((*MockQUICConn)(nil).AddPath), arg0)
+	return &MockQUICConnAddPathCall{Call: call}
+}
+
+// MockQUICConnAddPathCall wrap *gomock.Call
+type MockQUICConnAddPathCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnAddPathCall) Return(arg0 *Path, arg1 error) *MockQUICConnAddPathCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnAddPathCall) Do(f func(*Transport) (*Path, error)) *MockQUICConnAddPathCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnAddPathCall) DoAndReturn(f func(*Transport) (*Path, error)) *MockQUICConnAddPathCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // CloseWithError mocks base method.
 func (m *MockQUICConn) CloseWithError(arg0 qerr.ApplicationErrorCode, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -543,6 +583,45 @@ func (c *MockQUICConnReceiveDatagramCall) DoAndReturn(f func(context.Context) ([
 	return c
 }
 
+// ReceiveDatagrams mocks base method.
+func (m *MockQUICConn) ReceiveDatagrams(arg0 context.Context, arg1 []ReceivedDatagram) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveDatagrams", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveDatagrams indicates an expected call of ReceiveDatagrams.
+func (mr *MockQUICConnMockRecorder) ReceiveDatagrams(arg0, arg1 any) *MockQUICConnReceiveDatagramsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveDatagrams", reflect.TypeOf((*MockQUICConn)(nil).ReceiveDatagrams), arg0, arg1)
+	return &MockQUICConnReceiveDatagramsCall{Call: call}
+}
+
+// MockQUICConnReceiveDatagramsCall wrap *gomock.Call
+type MockQUICConnReceiveDatagramsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnReceiveDatagramsCall) Return(arg0 int, arg1 error) *MockQUICConnReceiveDatagramsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnReceiveDatagramsCall) Do(f func(context.Context, []ReceivedDatagram) (int, error)) *MockQUICConnReceiveDatagramsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnReceiveDatagramsCall) DoAndReturn(f func(context.Context, []ReceivedDatagram) (int, error)) *MockQUICConnReceiveDatagramsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // RemoteAddr mocks base method.
 func (m *MockQUICConn) RemoteAddr() net.Addr {
 	m.ctrl.T.Helper()
@@ -619,6 +698,192 @@ func (c *MockQUICConnSendDatagramCall) DoAndReturn(f func([]byte) error) *MockQU
 	return c
 }
 
+// SendDatagramWithOptions mocks base method.
+func (m *MockQUICConn) SendDatagramWithOptions(arg0 []byte, arg1 *DatagramOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagramWithOptions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagramWithOptions indicates an expected call of SendDatagramWithOptions.
+func (mr *MockQUICConnMockRecorder) SendDatagramWithOptions(arg0, arg1 any) *MockQUICConnSendDatagramWithOptionsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagramWithOptions", reflect.TypeOf((*MockQUICConn)(nil).SendDatagramWithOptions), arg0, arg1)
+	return &MockQUICConnSendDatagramWithOptionsCall{Call: call}
+}
+
+// MockQUICConnSendDatagramWithOptionsCall wrap *gomock.Call
+type MockQUICConnSendDatagramWithOptionsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSendDatagramWithOptionsCall) Return(arg0 error) *MockQUICConnSendDatagramWithOptionsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSendDatagramWithOptionsCall) Do(f func([]byte, *DatagramOptions) error) *MockQUICConnSendDatagramWithOptionsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSendDatagramWithOptionsCall) DoAndReturn(f func([]byte, *DatagramOptions) error) *MockQUICConnSendDatagramWithOptionsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingStreams mocks base method.
+func (m *MockQUICConn) SetMaxIncomingStreams(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingStreams", arg0)
+}
+
+// SetMaxIncomingStreams indicates an expected call of SetMaxIncomingStreams.
+func (mr *MockQUICConnMockRecorder) SetMaxIncomingStreams(arg0 any) *MockQUICConnSetMaxIncomingStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingStreams", reflect.TypeOf((*MockQUICConn)(nil).SetMaxIncomingStreams), arg0)
+	return &MockQUICConnSetMaxIncomingStreamsCall{Call: call}
+}
+
+// MockQUICConnSetMaxIncomingStreamsCall wrap *gomock.Call
+type MockQUICConnSetMaxIncomingStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSetMaxIncomingStreamsCall) Return() *MockQUICConnSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSetMaxIncomingStreamsCall) Do(f func(int64)) *MockQUICConnSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSetMaxIncomingStreamsCall) DoAndReturn(f func(int64)) *MockQUICConnSetMaxIncomingStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetMaxIncomingUniStreams mocks base method.
+func (m *MockQUICConn) SetMaxIncomingUniStreams(arg0 int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingUniStreams", arg0)
+}
+
+// SetMaxIncomingUniStreams indicates an expected call of SetMaxIncomingUniStreams.
+func (mr *MockQUICConnMockRecorder) SetMaxIncomingUniStreams(arg0 any) *MockQUICConnSetMaxIncomingUniStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingUniStreams", reflect.TypeOf((*MockQUICConn)(nil).SetMaxIncomingUniStreams), arg0)
+	return &MockQUICConnSetMaxIncomingUniStreamsCall{Call: call}
+}
+
+// MockQUICConnSetMaxIncomingUniStreamsCall wrap *gomock.Call
+type MockQUICConnSetMaxIncomingUniStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSetMaxIncomingUniStreamsCall) Return() *MockQUICConnSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSetMaxIncomingUniStreamsCall) Do(f func(int64)) *MockQUICConnSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSetMaxIncomingUniStreamsCall) DoAndReturn(f func(int64)) *MockQUICConnSetMaxIncomingUniStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetStreamReceiveWindow mocks base method.
+func (m *MockQUICConn) SetStreamReceiveWindow(arg0 protocol.StreamID, arg1 uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStreamReceiveWindow", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStreamReceiveWindow indicates an expected call of SetStreamReceiveWindow.
+func (mr *MockQUICConnMockRecorder) SetStreamReceiveWindow(arg0, arg1 any) *MockQUICConnSetStreamReceiveWindowCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStreamReceiveWindow", reflect.TypeOf((*MockQUICConn)(nil).SetStreamReceiveWindow), arg0, arg1)
+	return &MockQUICConnSetStreamReceiveWindowCall{Call: call}
+}
+
+// MockQUICConnSetStreamReceiveWindowCall wrap *gomock.Call
+type MockQUICConnSetStreamReceiveWindowCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnSetStreamReceiveWindowCall) Return(arg0 error) *MockQUICConnSetStreamReceiveWindowCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnSetStreamReceiveWindowCall) Do(f func(protocol.StreamID, uint64) error) *MockQUICConnSetStreamReceiveWindowCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnSetStreamReceiveWindowCall) DoAndReturn(f func(protocol.StreamID, uint64) error) *MockQUICConnSetStreamReceiveWindowCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Stats mocks base method.
+func (m *MockQUICConn) Stats() ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockQUICConnMockRecorder) Stats() *MockQUICConnStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockQUICConn)(nil).Stats))
+	return &MockQUICConnStatsCall{Call: call}
+}
+
+// MockQUICConnStatsCall wrap *gomock.Call
+type MockQUICConnStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnStatsCall) Return(arg0 ConnectionStats) *MockQUICConnStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnStatsCall) Do(f func() ConnectionStats) *MockQUICConnStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnStatsCall) DoAndReturn(f func() ConnectionStats) *MockQUICConnStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // closeWithTransportError mocks base method.
 func (m *MockQUICConn) closeWithTransportError(arg0 qerr.TransportErrorCode) {
 	m.ctrl.T.Helper()
@@ -765,6 +1030,42 @@ func (c *MockQUICConnhandlePacketCall) DoAndReturn(f func(receivedPacket)) *Mock
 	return c
 }
 
+// releaseFlowControlMemory mocks base method.
+func (m *MockQUICConn) releaseFlowControlMemory() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "releaseFlowControlMemory")
+}
+
+// releaseFlowControlMemory indicates an expected call of releaseFlowControlMemory.
+func (mr *MockQUICConnMockRecorder) releaseFlowControlMemory() *MockQUICConnreleaseFlowControlMemoryCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "releaseFlowControlMemory", reflect.TypeOf((*MockQUICConn)(nil).releaseFlowControlMemory))
+	return &MockQUICConnreleaseFlowControlMemoryCall{Call: call}
+}
+
+// MockQUICConnreleaseFlowControlMemoryCall wrap *gomock.Call
+type MockQUICConnreleaseFlowControlMemoryCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnreleaseFlowControlMemoryCall) Return() *MockQUICConnreleaseFlowControlMemoryCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnreleaseFlowControlMemoryCall) Do(f func()) *MockQUICConnreleaseFlowControlMemoryCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnreleaseFlowControlMemoryCall) DoAndReturn(f func()) *MockQUICConnreleaseFlowControlMemoryCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // run mocks base method.
 func (m *MockQUICConn) run() error {
 	m.ctrl.T.Helper()
@@ -802,3 +1103,39 @@ func (c *MockQUICConnrunCall) DoAndReturn(f func() error) *MockQUICConnrunCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// setHandshakeCompleteHook mocks base method.
+func (m *MockQUICConn) setHandshakeCompleteHook(arg0 func()) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "setHandshakeCompleteHook", arg0)
+}
+
+// setHandshakeCompleteHook indicates an expected call of setHandshakeCompleteHook.
+func (mr *MockQUICConnMockRecorder) setHandshakeCompleteHook(arg0 any) *MockQUICConnsetHandshakeCompleteHookCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "setHandshakeCompleteHook", reflect.TypeOf((*MockQUICConn)(nil).setHandshakeCompleteHook), arg0)
+	return &MockQUICConnsetHandshakeCompleteHookCall{Call: call}
+}
+
+// MockQUICConnsetHandshakeCompleteHookCall wrap *gomock.Call
+type MockQUICConnsetHandshakeCompleteHookCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockQUICConnsetHandshakeCompleteHookCall) Return() *MockQUICConnsetHandshakeCompleteHookCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockQUICConnsetHandshakeCompleteHookCall) Do(f func(func())) *MockQUICConnsetHandshakeCompleteHookCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockQUICConnsetHandshakeCompleteHookCall) DoAndReturn(f func(func())) *MockQUICConnsetHandshakeCompleteHookCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
@@ -117,6 +117,46 @@ func (c *MockReceiveStreamIReadCall) DoAndReturn(f func([]byte) (int, error)) *M
 	return c
 }
 
+// ReadChunk mocks base method.
+func (m *MockReceiveStreamI) ReadChunk() ([]byte, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadChunk")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadChunk indicates an expected call of ReadChunk.
+func (mr *MockReceiveStreamIMockRecorder) ReadChunk() *MockReceiveStreamIReadChunkCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChunk", reflect.TypeOf((*MockReceiveStreamI)(nil).ReadChunk))
+	return &MockReceiveStreamIReadChunkCall{Call: call}
+}
+
+// MockReceiveStreamIReadChunkCall wrap *gomock.Call
+type MockReceiveStreamIReadChunkCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceiveStreamIReadChunkCall) Return(arg0 []byte, arg1 func(), arg2 error) *MockReceiveStreamIReadChunkCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceiveStreamIReadChunkCall) Do(f func() ([]byte, func(), error)) *MockReceiveStreamIReadChunkCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceiveStreamIReadChunkCall) DoAndReturn(f func() ([]byte, func(), error)) *MockReceiveStreamIReadChunkCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockReceiveStreamI) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -342,3 +382,39 @@ func (c *MockReceiveStreamIhandleStreamFrameCall) DoAndReturn(f func(*wire.Strea
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// setReceiveWindowSize mocks base method.
+func (m *MockReceiveStreamI) setReceiveWindowSize(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "setReceiveWindowSize", arg0)
+}
+
+// setReceiveWindowSize indicates an expected call of setReceiveWindowSize.
+func (mr *MockReceiveStreamIMockRecorder) setReceiveWindowSize(arg0 any) *MockReceiveStreamIsetReceiveWindowSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "setReceiveWindowSize", reflect.TypeOf((*MockReceiveStreamI)(nil).setReceiveWindowSize), arg0)
+	return &MockReceiveStreamIsetReceiveWindowSizeCall{Call: call}
+}
+
+// MockReceiveStreamIsetReceiveWindowSizeCall wrap *gomock.Call
+type MockReceiveStreamIsetReceiveWindowSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockReceiveStreamIsetReceiveWindowSizeCall) Return() *MockReceiveStreamIsetReceiveWindowSizeCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockReceiveStreamIsetReceiveWindowSizeCall) Do(f func(protocol.ByteCount)) *MockReceiveStreamIsetReceiveWindowSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockReceiveStreamIsetReceiveWindowSizeCall) DoAndReturn(f func(protocol.ByteCount)) *MockReceiveStreamIsetReceiveWindowSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
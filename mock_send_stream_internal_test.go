@@ -80,6 +80,44 @@ func (c *MockSendStreamICancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockSendStreamI) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockSendStreamIMockRecorder) CancelWriteAt(arg0, arg1 any) *MockSendStreamICancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockSendStreamI)(nil).CancelWriteAt), arg0, arg1)
+	return &MockSendStreamICancelWriteAtCall{Call: call}
+}
+
+// MockSendStreamICancelWriteAtCall wrap *gomock.Call
+type MockSendStreamICancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamICancelWriteAtCall) Return(arg0 error) *MockSendStreamICancelWriteAtCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamICancelWriteAtCall) Do(f func(qerr.StreamErrorCode, int64) error) *MockSendStreamICancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamICancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, int64) error) *MockSendStreamICancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockSendStreamI) Close() error {
 	m.ctrl.T.Helper()
@@ -156,6 +194,118 @@ func (c *MockSendStreamIContextCall) DoAndReturn(f func() context.Context) *Mock
 	return c
 }
 
+// NotifyAcked mocks base method.
+func (m *MockSendStreamI) NotifyAcked(arg0 int64) <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyAcked", arg0)
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// NotifyAcked indicates an expected call of NotifyAcked.
+func (mr *MockSendStreamIMockRecorder) NotifyAcked(arg0 any) *MockSendStreamINotifyAckedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyAcked", reflect.TypeOf((*MockSendStreamI)(nil).NotifyAcked), arg0)
+	return &MockSendStreamINotifyAckedCall{Call: call}
+}
+
+// MockSendStreamINotifyAckedCall wrap *gomock.Call
+type MockSendStreamINotifyAckedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamINotifyAckedCall) Return(arg0 <-chan struct{}) *MockSendStreamINotifyAckedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamINotifyAckedCall) Do(f func(int64) <-chan struct{}) *MockSendStreamINotifyAckedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamINotifyAckedCall) DoAndReturn(f func(int64) <-chan struct{}) *MockSendStreamINotifyAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Priority mocks base method.
+func (m *MockSendStreamI) Priority() StreamPriority {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Priority")
+	ret0, _ := ret[0].(StreamPriority)
+	return ret0
+}
+
+// Priority indicates an expected call of Priority.
+func (mr *MockSendStreamIMockRecorder) Priority() *MockSendStreamIPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Priority", reflect.TypeOf((*MockSendStreamI)(nil).Priority))
+	return &MockSendStreamIPriorityCall{Call: call}
+}
+
+// MockSendStreamIPriorityCall wrap *gomock.Call
+type MockSendStreamIPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamIPriorityCall) Return(arg0 StreamPriority) *MockSendStreamIPriorityCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamIPriorityCall) Do(f func() StreamPriority) *MockSendStreamIPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamIPriorityCall) DoAndReturn(f func() StreamPriority) *MockSendStreamIPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// SetPriority mocks base method.
+func (m *MockSendStreamI) SetPriority(arg0 StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockSendStreamIMockRecorder) SetPriority(arg0 any) *MockSendStreamISetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockSendStreamI)(nil).SetPriority), arg0)
+	return &MockSendStreamISetPriorityCall{Call: call}
+}
+
+// MockSendStreamISetPriorityCall wrap *gomock.Call
+type MockSendStreamISetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamISetPriorityCall) Return() *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamISetPriorityCall) Do(f func(StreamPriority)) *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamISetPriorityCall) DoAndReturn(f func(StreamPriority)) *MockSendStreamISetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetWriteDeadline mocks base method.
 func (m *MockSendStreamI) SetWriteDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -194,6 +344,44 @@ func (c *MockSendStreamISetWriteDeadlineCall) DoAndReturn(f func(time.Time) erro
 	return c
 }
 
+// Stats mocks base method.
+func (m *MockSendStreamI) Stats() SendStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(SendStreamStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockSendStreamIMockRecorder) Stats() *MockSendStreamIStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockSendStreamI)(nil).Stats))
+	return &MockSendStreamIStatsCall{Call: call}
+}
+
+// MockSendStreamIStatsCall wrap *gomock.Call
+type MockSendStreamIStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamIStatsCall) Return(arg0 SendStreamStats) *MockSendStreamIStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamIStatsCall) Do(f func() SendStreamStats) *MockSendStreamIStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamIStatsCall) DoAndReturn(f func() SendStreamStats) *MockSendStreamIStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockSendStreamI) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -271,6 +459,45 @@ func (c *MockSendStreamIWriteCall) DoAndReturn(f func([]byte) (int, error)) *Moc
 	return c
 }
 
+// WriteOwned mocks base method.
+func (m *MockSendStreamI) WriteOwned(arg0 []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteOwned", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteOwned indicates an expected call of WriteOwned.
+func (mr *MockSendStreamIMockRecorder) WriteOwned(arg0 any) *MockSendStreamIWriteOwnedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteOwned", reflect.TypeOf((*MockSendStreamI)(nil).WriteOwned), arg0)
+	return &MockSendStreamIWriteOwnedCall{Call: call}
+}
+
+// MockSendStreamIWriteOwnedCall wrap *gomock.Call
+type MockSendStreamIWriteOwnedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockSendStreamIWriteOwnedCall) Return(arg0 int, arg1 error) *MockSendStreamIWriteOwnedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockSendStreamIWriteOwnedCall) Do(f func([]byte) (int, error)) *MockSendStreamIWriteOwnedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockSendStreamIWriteOwnedCall) DoAndReturn(f func([]byte) (int, error)) *MockSendStreamIWriteOwnedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // closeForShutdown mocks base method.
 func (m *MockSendStreamI) closeForShutdown(arg0 error) {
 	m.ctrl.T.Helper()
@@ -116,6 +116,44 @@ func (c *MockStreamICancelWriteCall) DoAndReturn(f func(qerr.StreamErrorCode)) *
 	return c
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockStreamI) CancelWriteAt(arg0 qerr.StreamErrorCode, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockStreamIMockRecorder) CancelWriteAt(arg0, arg1 any) *MockStreamICancelWriteAtCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockStreamI)(nil).CancelWriteAt), arg0, arg1)
+	return &MockStreamICancelWriteAtCall{Call: call}
+}
+
+// MockStreamICancelWriteAtCall wrap *gomock.Call
+type MockStreamICancelWriteAtCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamICancelWriteAtCall) Return(arg0 error) *MockStreamICancelWriteAtCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamICancelWriteAtCall) Do(f func(qerr.StreamErrorCode, int64) error) *MockStreamICancelWriteAtCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamICancelWriteAtCall) DoAndReturn(f func(qerr.StreamErrorCode, int64) error) *MockStreamICancelWriteAtCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Close mocks base method.
 func (m *MockStreamI) Close() error {
 	m.ctrl.T.Helper()
@@ -192,6 +230,82 @@ func (c *MockStreamIContextCall) DoAndReturn(f func() context.Context) *MockStre
 	return c
 }
 
+// NotifyAcked mocks base method.
+func (m *MockStreamI) NotifyAcked(arg0 int64) <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyAcked", arg0)
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// NotifyAcked indicates an expected call of NotifyAcked.
+func (mr *MockStreamIMockRecorder) NotifyAcked(arg0 any) *MockStreamINotifyAckedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyAcked", reflect.TypeOf((*MockStreamI)(nil).NotifyAcked), arg0)
+	return &MockStreamINotifyAckedCall{Call: call}
+}
+
+// MockStreamINotifyAckedCall wrap *gomock.Call
+type MockStreamINotifyAckedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamINotifyAckedCall) Return(arg0 <-chan struct{}) *MockStreamINotifyAckedCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamINotifyAckedCall) Do(f func(int64) <-chan struct{}) *MockStreamINotifyAckedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamINotifyAckedCall) DoAndReturn(f func(int64) <-chan struct{}) *MockStreamINotifyAckedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Priority mocks base method.
+func (m *MockStreamI) Priority() StreamPriority {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Priority")
+	ret0, _ := ret[0].(StreamPriority)
+	return ret0
+}
+
+// Priority indicates an expected call of Priority.
+func (mr *MockStreamIMockRecorder) Priority() *MockStreamIPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Priority", reflect.TypeOf((*MockStreamI)(nil).Priority))
+	return &MockStreamIPriorityCall{Call: call}
+}
+
+// MockStreamIPriorityCall wrap *gomock.Call
+type MockStreamIPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIPriorityCall) Return(arg0 StreamPriority) *MockStreamIPriorityCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIPriorityCall) Do(f func() StreamPriority) *MockStreamIPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIPriorityCall) DoAndReturn(f func() StreamPriority) *MockStreamIPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Read mocks base method.
 func (m *MockStreamI) Read(arg0 []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -231,6 +345,46 @@ func (c *MockStreamIReadCall) DoAndReturn(f func([]byte) (int, error)) *MockStre
 	return c
 }
 
+// ReadChunk mocks base method.
+func (m *MockStreamI) ReadChunk() ([]byte, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadChunk")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadChunk indicates an expected call of ReadChunk.
+func (mr *MockStreamIMockRecorder) ReadChunk() *MockStreamIReadChunkCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChunk", reflect.TypeOf((*MockStreamI)(nil).ReadChunk))
+	return &MockStreamIReadChunkCall{Call: call}
+}
+
+// MockStreamIReadChunkCall wrap *gomock.Call
+type MockStreamIReadChunkCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIReadChunkCall) Return(arg0 []byte, arg1 func(), arg2 error) *MockStreamIReadChunkCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIReadChunkCall) Do(f func() ([]byte, func(), error)) *MockStreamIReadChunkCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIReadChunkCall) DoAndReturn(f func() ([]byte, func(), error)) *MockStreamIReadChunkCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetDeadline mocks base method.
 func (m *MockStreamI) SetDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -269,6 +423,42 @@ func (c *MockStreamISetDeadlineCall) DoAndReturn(f func(time.Time) error) *MockS
 	return c
 }
 
+// SetPriority mocks base method.
+func (m *MockStreamI) SetPriority(arg0 StreamPriority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamIMockRecorder) SetPriority(arg0 any) *MockStreamISetPriorityCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStreamI)(nil).SetPriority), arg0)
+	return &MockStreamISetPriorityCall{Call: call}
+}
+
+// MockStreamISetPriorityCall wrap *gomock.Call
+type MockStreamISetPriorityCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamISetPriorityCall) Return() *MockStreamISetPriorityCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamISetPriorityCall) Do(f func(StreamPriority)) *MockStreamISetPriorityCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamISetPriorityCall) DoAndReturn(f func(StreamPriority)) *MockStreamISetPriorityCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStreamI) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -345,6 +535,44 @@ func (c *MockStreamISetWriteDeadlineCall) DoAndReturn(f func(time.Time) error) *
 	return c
 }
 
+// Stats mocks base method.
+func (m *MockStreamI) Stats() SendStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(SendStreamStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStreamIMockRecorder) Stats() *MockStreamIStatsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStreamI)(nil).Stats))
+	return &MockStreamIStatsCall{Call: call}
+}
+
+// MockStreamIStatsCall wrap *gomock.Call
+type MockStreamIStatsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIStatsCall) Return(arg0 SendStreamStats) *MockStreamIStatsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIStatsCall) Do(f func() SendStreamStats) *MockStreamIStatsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIStatsCall) DoAndReturn(f func() SendStreamStats) *MockStreamIStatsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // StreamID mocks base method.
 func (m *MockStreamI) StreamID() protocol.StreamID {
 	m.ctrl.T.Helper()
@@ -422,6 +650,45 @@ func (c *MockStreamIWriteCall) DoAndReturn(f func([]byte) (int, error)) *MockStr
 	return c
 }
 
+// WriteOwned mocks base method.
+func (m *MockStreamI) WriteOwned(arg0 []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteOwned", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteOwned indicates an expected call of WriteOwned.
+func (mr *MockStreamIMockRecorder) WriteOwned(arg0 any) *MockStreamIWriteOwnedCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteOwned", reflect.TypeOf((*MockStreamI)(nil).WriteOwned), arg0)
+	return &MockStreamIWriteOwnedCall{Call: call}
+}
+
+// MockStreamIWriteOwnedCall wrap *gomock.Call
+type MockStreamIWriteOwnedCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIWriteOwnedCall) Return(arg0 int, arg1 error) *MockStreamIWriteOwnedCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIWriteOwnedCall) Do(f func([]byte) (int, error)) *MockStreamIWriteOwnedCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIWriteOwnedCall) DoAndReturn(f func([]byte) (int, error)) *MockStreamIWriteOwnedCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // closeForShutdown mocks base method.
 func (m *MockStreamI) closeForShutdown(arg0 error) {
 	m.ctrl.T.Helper()
@@ -686,6 +953,42 @@ func (c *MockStreamIpopStreamFrameCall) DoAndReturn(f func(protocol.ByteCount, p
 	return c
 }
 
+// setReceiveWindowSize mocks base method.
+func (m *MockStreamI) setReceiveWindowSize(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "setReceiveWindowSize", arg0)
+}
+
+// setReceiveWindowSize indicates an expected call of setReceiveWindowSize.
+func (mr *MockStreamIMockRecorder) setReceiveWindowSize(arg0 any) *MockStreamIsetReceiveWindowSizeCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "setReceiveWindowSize", reflect.TypeOf((*MockStreamI)(nil).setReceiveWindowSize), arg0)
+	return &MockStreamIsetReceiveWindowSizeCall{Call: call}
+}
+
+// MockStreamIsetReceiveWindowSizeCall wrap *gomock.Call
+type MockStreamIsetReceiveWindowSizeCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamIsetReceiveWindowSizeCall) Return() *MockStreamIsetReceiveWindowSizeCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamIsetReceiveWindowSizeCall) Do(f func(protocol.ByteCount)) *MockStreamIsetReceiveWindowSizeCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamIsetReceiveWindowSizeCall) DoAndReturn(f func(protocol.ByteCount)) *MockStreamIsetReceiveWindowSizeCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // updateSendWindow mocks base method.
 func (m *MockStreamI) updateSendWindow(arg0 protocol.ByteCount) {
 	m.ctrl.T.Helper()
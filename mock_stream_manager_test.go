@@ -271,6 +271,45 @@ func (c *MockStreamManagerGetOrOpenSendStreamCall) DoAndReturn(f func(protocol.S
 	return c
 }
 
+// GetReceiveStream mocks base method.
+func (m *MockStreamManager) GetReceiveStream(arg0 protocol.StreamID) (receiveStreamI, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceiveStream", arg0)
+	ret0, _ := ret[0].(receiveStreamI)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetReceiveStream indicates an expected call of GetReceiveStream.
+func (mr *MockStreamManagerMockRecorder) GetReceiveStream(arg0 any) *MockStreamManagerGetReceiveStreamCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceiveStream", reflect.TypeOf((*MockStreamManager)(nil).GetReceiveStream), arg0)
+	return &MockStreamManagerGetReceiveStreamCall{Call: call}
+}
+
+// MockStreamManagerGetReceiveStreamCall wrap *gomock.Call
+type MockStreamManagerGetReceiveStreamCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamManagerGetReceiveStreamCall) Return(arg0 receiveStreamI, arg1 bool) *MockStreamManagerGetReceiveStreamCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamManagerGetReceiveStreamCall) Do(f func(protocol.StreamID) (receiveStreamI, bool)) *MockStreamManagerGetReceiveStreamCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamManagerGetReceiveStreamCall) DoAndReturn(f func(protocol.StreamID) (receiveStreamI, bool)) *MockStreamManagerGetReceiveStreamCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // HandleMaxStreamsFrame mocks base method.
 func (m *MockStreamManager) HandleMaxStreamsFrame(arg0 *wire.MaxStreamsFrame) {
 	m.ctrl.T.Helper()
@@ -499,6 +538,42 @@ func (c *MockStreamManagerResetFor0RTTCall) DoAndReturn(f func()) *MockStreamMan
 	return c
 }
 
+// SetMaxIncomingStreams mocks base method.
+func (m *MockStreamManager) SetMaxIncomingStreams(arg0 protocol.StreamType, arg1 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaxIncomingStreams", arg0, arg1)
+}
+
+// SetMaxIncomingStreams indicates an expected call of SetMaxIncomingStreams.
+func (mr *MockStreamManagerMockRecorder) SetMaxIncomingStreams(arg0, arg1 any) *MockStreamManagerSetMaxIncomingStreamsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxIncomingStreams", reflect.TypeOf((*MockStreamManager)(nil).SetMaxIncomingStreams), arg0, arg1)
+	return &MockStreamManagerSetMaxIncomingStreamsCall{Call: call}
+}
+
+// MockStreamManagerSetMaxIncomingStreamsCall wrap *gomock.Call
+type MockStreamManagerSetMaxIncomingStreamsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockStreamManagerSetMaxIncomingStreamsCall) Return() *MockStreamManagerSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockStreamManagerSetMaxIncomingStreamsCall) Do(f func(protocol.StreamType, uint64)) *MockStreamManagerSetMaxIncomingStreamsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockStreamManagerSetMaxIncomingStreamsCall) DoAndReturn(f func(protocol.StreamType, uint64)) *MockStreamManagerSetMaxIncomingStreamsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdateLimits mocks base method.
 func (m *MockStreamManager) UpdateLimits(arg0 *wire.TransportParameters) {
 	m.ctrl.T.Helper()
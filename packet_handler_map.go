@@ -22,6 +22,8 @@ type connCapabilities struct {
 	GSO bool
 	// ECN (Explicit Congestion Notifications) supported
 	ECN bool
+	// GRO (Generic Receive Offload) enabled
+	GRO bool
 }
 
 // rawConn is a connection that allow reading of a receivedPackeh.
@@ -38,6 +40,14 @@ type rawConn interface {
 	capabilities() connCapabilities
 }
 
+// A batchRawConn is a rawConn that can read multiple packets at once.
+type batchRawConn interface {
+	rawConn
+	// ReadPackets reads up to len(ps) packets.
+	// It returns the number of packets read.
+	ReadPackets(ps []receivedPacket) (int, error)
+}
+
 type closePacket struct {
 	payload []byte
 	addr    net.Addr
@@ -116,6 +126,19 @@ func (h *packetHandlerMap) Get(id protocol.ConnectionID) (packetHandler, bool) {
 	return handler, ok
 }
 
+// GetBatch looks up the handlers for multiple connection IDs, acquiring the lock only once.
+// For every connection ID, the handler is stored at the same index in handlers,
+// or nil if there's no handler for this connection ID.
+// handlers must be at least as long as ids.
+func (h *packetHandlerMap) GetBatch(ids []protocol.ConnectionID, handlers []packetHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for i, id := range ids {
+		handlers[i] = h.handlers[id]
+	}
+}
+
 func (h *packetHandlerMap) Add(id protocol.ConnectionID, handler packetHandler) bool /* was added */ {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
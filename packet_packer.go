@@ -25,6 +25,7 @@ type packer interface {
 	PackConnectionClose(*qerr.TransportError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackApplicationClose(*qerr.ApplicationError, protocol.ByteCount, protocol.Version) (*coalescedPacket, error)
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error)
+	PackPathProbePacket(protocol.ConnectionID, []ackhandler.Frame, protocol.ByteCount, protocol.Version) (shortHeaderPacket, *packetBuffer, error)
 
 	SetToken([]byte)
 }
@@ -56,6 +57,7 @@ type shortHeaderPacket struct {
 	Ack                  *wire.AckFrame
 	Length               protocol.ByteCount
 	IsPathMTUProbePacket bool
+	IsPathProbePacket    bool
 
 	// used for logging
 	DestConnID      protocol.ConnectionID
@@ -603,23 +605,13 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 	}
 
 	if p.datagramQueue != nil {
-		if f := p.datagramQueue.Peek(); f != nil {
-			size := f.Length(v)
-			if size <= maxFrameSize-pl.length { // DATAGRAM frame fits
-				pl.frames = append(pl.frames, ackhandler.Frame{Frame: f})
-				pl.length += size
-				p.datagramQueue.Pop()
-			} else if !hasAck {
-				// The DATAGRAM frame doesn't fit, and the packet doesn't contain an ACK.
-				// Discard this frame. There's no point in retrying this in the next packet,
-				// as it's unlikely that the available packet size will increase.
-				p.datagramQueue.Pop()
-			}
-			// If the DATAGRAM frame was too large and the packet contained an ACK, we'll try to send it out later.
-		}
+		p.appendDatagram(&pl, DatagramPriorityHigh, maxFrameSize, v)
 	}
 
 	if hasAck && !hasData && !hasRetransmission {
+		if p.datagramQueue != nil {
+			p.appendDatagram(&pl, DatagramPriorityLow, maxFrameSize, v)
+		}
 		return pl
 	}
 
@@ -646,9 +638,8 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 		// add handlers for the control frames that were added
 		for i := startLen; i < len(pl.frames); i++ {
 			switch pl.frames[i].Frame.(type) {
-			case *wire.PathChallengeFrame, *wire.PathResponseFrame:
-				// Path probing is currently not supported, therefore we don't need to set the OnAcked callback yet.
-				// PATH_CHALLENGE and PATH_RESPONSE are never retransmitted.
+			case *wire.PathChallengeFrame, *wire.PathResponseFrame, *wire.ImmediateAckFrame:
+				// PATH_CHALLENGE, PATH_RESPONSE and IMMEDIATE_ACK are never retransmitted.
 			default:
 				pl.frames[i].Handler = p.retransmissionQueue.AppDataAckHandler()
 			}
@@ -657,9 +648,33 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, onlyAc
 		pl.streamFrames, lengthAdded = p.framer.AppendStreamFrames(pl.streamFrames, maxFrameSize-pl.length, v)
 		pl.length += lengthAdded
 	}
+	if p.datagramQueue != nil {
+		p.appendDatagram(&pl, DatagramPriorityLow, maxFrameSize, v)
+	}
 	return pl
 }
 
+// appendDatagram adds the next queued DATAGRAM frame of the given priority, if it fits into the packet.
+func (p *packetPacker) appendDatagram(pl *payload, prio DatagramPriority, maxFrameSize protocol.ByteCount, v protocol.Version) {
+	f := p.datagramQueue.Peek(prio)
+	if f == nil {
+		return
+	}
+	size := f.Length(v)
+	if size <= maxFrameSize-pl.length { // DATAGRAM frame fits
+		pl.frames = append(pl.frames, ackhandler.Frame{Frame: f, Handler: p.datagramQueue.Pop(prio)})
+		pl.length += size
+		return
+	}
+	if pl.length == 0 {
+		// The DATAGRAM frame doesn't fit into an otherwise empty packet.
+		// Discard this frame. There's no point in retrying this in the next packet,
+		// as it's unlikely that the available packet size will increase.
+		p.datagramQueue.Drop(prio)
+	}
+	// If the DATAGRAM frame didn't fit because the packet contained other frames, we'll try to send it out later.
+}
+
 func (p *packetPacker) MaybePackProbePacket(encLevel protocol.EncryptionLevel, maxPacketSize protocol.ByteCount, v protocol.Version) (*coalescedPacket, error) {
 	if encLevel == protocol.Encryption1RTT {
 		s, err := p.cryptoSetup.Get1RTTSealer()
@@ -744,6 +759,34 @@ func (p *packetPacker) PackMTUProbePacket(ping ackhandler.Frame, size protocol.B
 	return packet, buffer, err
 }
 
+// PackPathProbePacket packs a packet that is sent on a path that is being probed.
+// It uses the connection ID that was set aside for this path,
+// and is padded to size. This is the minimum size required for path validation (see section 8.2.1 of RFC 9000),
+// unless the server is limited by the anti-amplification limit on this path.
+func (p *packetPacker) PackPathProbePacket(connID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount, v protocol.Version) (shortHeaderPacket, *packetBuffer, error) {
+	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
+	buf := getPacketBuffer()
+	s, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return shortHeaderPacket{}, nil, err
+	}
+	var l protocol.ByteCount
+	for _, f := range frames {
+		l += f.Frame.Length(v)
+	}
+	pl := payload{
+		frames: frames,
+		length: l,
+	}
+	padding := size - p.shortHeaderPacketLength(connID, pnLen, pl) - protocol.ByteCount(s.Overhead())
+	packet, err := p.appendShortHeaderPacket(buf, connID, pn, pnLen, s.KeyPhase(), pl, padding, size, s, false, v)
+	if err != nil {
+		return shortHeaderPacket{}, nil, err
+	}
+	packet.IsPathProbePacket = true
+	return packet, buf, nil
+}
+
 func (p *packetPacker) getLongHeader(encLevel protocol.EncryptionLevel, v protocol.Version) *wire.ExtendedHeader {
 	pn, pnLen := p.pnManager.PeekPacketNumber(encLevel)
 	hdr := &wire.ExtendedHeader{
@@ -94,7 +94,7 @@ var _ = Describe("Packet packer", func() {
 		ackFramer = NewMockAckFrameSource(mockCtrl)
 		sealingManager = NewMockSealingManager(mockCtrl)
 		pnManager = mockackhandler.NewMockSentPacketHandler(mockCtrl)
-		datagramQueue = newDatagramQueue(func() {}, utils.DefaultLogger)
+		datagramQueue = newDatagramQueue(func() {}, protocol.DefaultMaxDatagramReceiveQueueLen, utils.DefaultLogger)
 
 		packer = newPacketPacker(protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8}), func() protocol.ConnectionID { return connID }, initialStream, handshakeStream, pnManager, retransmissionQueue, sealingManager, framer, ackFramer, datagramQueue, protocol.PerspectiveServer)
 	})
@@ -541,7 +541,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(buffer.Len()).ToNot(BeZero())
 			})
 
-			It("packs PATH_CHALLENGE and PATH_RESPONSE frames", func() {
+			It("packs PATH_CHALLENGE, PATH_RESPONSE and IMMEDIATE_ACK frames", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
 				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
@@ -550,6 +550,7 @@ var _ = Describe("Packet packer", func() {
 				frames := []ackhandler.Frame{
 					{Frame: &wire.PathChallengeFrame{}},
 					{Frame: &wire.PathResponseFrame{}},
+					{Frame: &wire.ImmediateAckFrame{}},
 					{Frame: &wire.DataBlockedFrame{}},
 				}
 				expectAppendControlFrames(frames...)
@@ -557,11 +558,11 @@ var _ = Describe("Packet packer", func() {
 				buffer := getPacketBuffer()
 				p, err := packer.AppendPacket(buffer, maxPacketSize, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
-				Expect(p.Frames).To(HaveLen(3))
+				Expect(p.Frames).To(HaveLen(4))
 				for i, f := range p.Frames {
 					Expect(f).To(BeAssignableToTypeOf(frames[i]))
 					switch f.Frame.(type) {
-					case *wire.PathChallengeFrame, *wire.PathResponseFrame:
+					case *wire.PathChallengeFrame, *wire.PathResponseFrame, *wire.ImmediateAckFrame:
 						// This means that the frame won't be retransmitted.
 						Expect(f.Handler).To(BeNil())
 					default:
@@ -584,7 +585,7 @@ var _ = Describe("Packet packer", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					datagramQueue.Add(f)
+					datagramQueue.Add(f, nil)
 				}()
 				// make sure the DATAGRAM has actually been queued
 				time.Sleep(scaleDuration(20 * time.Millisecond))
@@ -612,7 +613,7 @@ var _ = Describe("Packet packer", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					datagramQueue.Add(f)
+					datagramQueue.Add(f, nil)
 				}()
 				// make sure the DATAGRAM has actually been queued
 				time.Sleep(scaleDuration(20 * time.Millisecond))
@@ -624,7 +625,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.Ack).ToNot(BeNil())
 				Expect(p.Frames).To(BeEmpty())
 				Expect(buffer.Data).ToNot(BeEmpty())
-				Expect(datagramQueue.Peek()).To(Equal(f)) // make sure the frame is still there
+				Expect(datagramQueue.Peek(DatagramPriorityHigh)).To(Equal(f)) // make sure the frame is still there
 				datagramQueue.CloseWithError(nil)
 				Eventually(done).Should(BeClosed())
 			})
@@ -641,7 +642,7 @@ var _ = Describe("Packet packer", func() {
 				go func() {
 					defer GinkgoRecover()
 					defer close(done)
-					datagramQueue.Add(f)
+					datagramQueue.Add(f, nil)
 				}()
 				// make sure the DATAGRAM has actually been queued
 				time.Sleep(scaleDuration(20 * time.Millisecond))
@@ -652,10 +653,55 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).To(MatchError(errNothingToPack))
 				Expect(p.Frames).To(BeEmpty())
 				Expect(p.Ack).To(BeNil())
-				Expect(datagramQueue.Peek()).To(BeNil())
+				Expect(datagramQueue.Peek(DatagramPriorityHigh)).To(BeNil())
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("packs low-priority DATAGRAM frames if there is space left after STREAM frames", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				framer.EXPECT().HasData().Return(true)
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false)
+				var acked bool
+				low := &wire.DatagramFrame{DataLenPresent: true, Data: []byte("low")}
+				Expect(datagramQueue.Add(low, &DatagramOptions{
+					Priority: DatagramPriorityLow,
+					OnAcked:  func() { acked = true },
+				})).To(Succeed())
+				high := &wire.DatagramFrame{DataLenPresent: true, Data: []byte("high")}
+				Expect(datagramQueue.Add(high, nil)).To(Succeed())
+				expectAppendControlFrames()
+				var maxLen protocol.ByteCount
+				sf := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}
+				framer.EXPECT().AppendStreamFrames(gomock.Any(), gomock.Any(), protocol.Version1).DoAndReturn(func(fs []ackhandler.StreamFrame, l protocol.ByteCount, v protocol.Version) ([]ackhandler.StreamFrame, protocol.ByteCount) {
+					maxLen = l
+					return append(fs, ackhandler.StreamFrame{Frame: sf}), sf.Length(v)
+				})
+				p, err := packer.AppendPacket(getPacketBuffer(), maxPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.StreamFrames).To(HaveLen(1))
+				Expect(p.Frames).To(HaveLen(2))
+				// the order of the frames is randomized
+				var highFrame, lowFrame ackhandler.Frame
+				for _, f := range p.Frames {
+					switch f.Frame {
+					case high:
+						highFrame = f
+					case low:
+						lowFrame = f
+					}
+				}
+				Expect(highFrame.Frame).ToNot(BeNil())
+				Expect(highFrame.Handler).To(BeNil())
+				Expect(lowFrame.Frame).ToNot(BeNil())
+				Expect(lowFrame.Handler).ToNot(BeNil())
+				lowFrame.Handler.OnAcked(low)
+				Expect(acked).To(BeTrue())
+				// the high-priority DATAGRAM frame was added before the STREAM frames
+				Expect(maxLen).To(BeNumerically("<", maxPacketSize-high.Length(protocol.Version1)))
+			})
+
 			It("accounts for the space consumed by control frames", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
@@ -709,7 +755,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false)
+				frameParser := wire.NewFrameParser(false, false, false)
 				l, frame, err := frameParser.ParseNext(data[len(data)-r.Len():], protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -746,7 +792,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(firstPayloadByte).To(Equal(byte(0)))
 				// ... followed by the STREAM frame
-				frameParser := wire.NewFrameParser(true)
+				frameParser := wire.NewFrameParser(true, false, false)
 				l, frame, err := frameParser.ParseNext(buffer.Data[len(data)-r.Len():], protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.StreamFrame{}))
@@ -1226,7 +1272,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false)
+				frameParser := wire.NewFrameParser(false, false, false)
 				l, frame, err := frameParser.ParseNext(data[len(data)-r.Len():], protocol.Encryption1RTT, protocol.Version1)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -1515,6 +1561,36 @@ var _ = Describe("Packet packer", func() {
 				Expect(buffer.Data).To(HaveLen(int(probePacketSize)))
 				Expect(p.IsPathMTUProbePacket).To(BeTrue())
 			})
+
+			It("packs a path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9})
+				frames := []ackhandler.Frame{{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}}
+				p, buffer, err := packer.PackPathProbePacket(connID, frames, protocol.MinInitialPacketSize, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+				Expect(p.PacketNumber).To(Equal(protocol.PacketNumber(0x43)))
+				Expect(p.DestConnID).To(Equal(connID))
+				Expect(p.Frames).To(Equal(frames))
+				Expect(buffer.Data).To(HaveLen(protocol.MinInitialPacketSize))
+				Expect(p.IsPathProbePacket).To(BeTrue())
+				Expect(p.IsPathMTUProbePacket).To(BeFalse())
+			})
+
+			It("packs a path probe packet smaller than 1200 bytes", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9})
+				frames := []ackhandler.Frame{{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}}
+				p, buffer, err := packer.PackPathProbePacket(connID, frames, 100, protocol.Version1)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.Length).To(BeEquivalentTo(100))
+				Expect(buffer.Data).To(HaveLen(100))
+				Expect(p.IsPathProbePacket).To(BeTrue())
+			})
 		})
 	})
 })
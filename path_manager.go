@@ -0,0 +1,235 @@
+package quic
+
+import (
+	"crypto/rand"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+// The maximum number of paths that are validated at the same time.
+// If packets from more remote addresses arrive, the oldest path is abandoned.
+const maxPaths = 3
+
+// maxUnpaddedPathProbeSize is the size of the largest path probe packet before padding:
+// a short header with the longest connection ID and packet number,
+// a PATH_RESPONSE and a PATH_CHALLENGE frame, and the AEAD tag.
+const maxUnpaddedPathProbeSize = 1 + protocol.MaxConnIDLen + 4 + 2*9 + 16
+
+type path struct {
+	id   pathID
+	addr net.Addr
+	// The server can receive packets on the preferred address.
+	// A client might use the same address to send packets to the original and to the preferred address,
+	// but these are two different paths.
+	onPreferredAddress bool
+	pathChallenge      [8]byte
+	challengeSent      time.Time
+	lastPacketTime     time.Time
+	validated          bool
+	// Until the path is validated, we're subject to the anti-amplification limit on this path.
+	bytesReceived protocol.ByteCount
+	bytesSent     protocol.ByteCount
+}
+
+// pathManager is used by the server to validate new paths,
+// i.e. when a packet is received from a new remote address, or on the preferred address.
+// This happens when the client migrates to a new path (or to the preferred address),
+// or when it is affected by a NAT rebinding.
+type pathManager struct {
+	nextPathID pathID
+	paths      []*path
+	// The connection switched to this path before it was validated.
+	// Its PATH_CHALLENGE frames are now sent on the current path.
+	current *path
+
+	getConnID    func(pathID) (protocol.ConnectionID, bool)
+	retireConnID func(pathID)
+	rttStats     *utils.RTTStats
+
+	logger utils.Logger
+}
+
+func newPathManager(
+	getConnID func(pathID) (protocol.ConnectionID, bool),
+	retireConnID func(pathID),
+	rttStats *utils.RTTStats,
+	logger utils.Logger,
+) *pathManager {
+	return &pathManager{
+		nextPathID:   1, // path 0 is the path used during the handshake
+		getConnID:    getConnID,
+		retireConnID: retireConnID,
+		rttStats:     rttStats,
+		logger:       logger,
+	}
+}
+
+// HandlePacket is called for every packet received from a remote address other than the current remote address,
+// or on a different local address (the preferred address) than the current path.
+// It returns the frames that need to be sent on this path, the connection ID to use for sending them,
+// and the size the probe packet is padded to.
+// Following section 9.3 of RFC 9000, shouldSwitch is true for non-probing packets,
+// even if the path hasn't been validated yet.
+func (pm *pathManager) HandlePacket(
+	addr net.Addr,
+	onPreferredAddress bool,
+	rcvTime time.Time,
+	size protocol.ByteCount,
+	pathChallenge *wire.PathChallengeFrame, // may be nil if the packet didn't contain a PATH_CHALLENGE
+	isNonProbing bool,
+) (_ protocol.ConnectionID, _ []ackhandler.Frame, probeSize protocol.ByteCount, shouldSwitch bool) {
+	p := pm.getPath(addr, onPreferredAddress)
+	if p == nil {
+		p = pm.addPath(addr, onPreferredAddress)
+	}
+	p.lastPacketTime = rcvTime
+	p.bytesReceived += size
+	if p.validated && pathChallenge == nil {
+		return protocol.ConnectionID{}, nil, 0, isNonProbing
+	}
+
+	var frames []ackhandler.Frame
+	if pathChallenge != nil {
+		frames = append(frames, ackhandler.Frame{Frame: &wire.PathResponseFrame{Data: pathChallenge.Data}})
+	}
+	// (Re)send a PATH_CHALLENGE if we haven't sent one yet, or if the last one is likely lost.
+	if f := pm.maybeNewPathChallenge(p, rcvTime); f != nil {
+		frames = append(frames, ackhandler.Frame{Frame: f})
+	}
+	if len(frames) == 0 {
+		return protocol.ConnectionID{}, nil, 0, isNonProbing
+	}
+	// Datagrams containing a PATH_CHALLENGE are padded to at least 1200 bytes, see section 8.2.1 of RFC 9000.
+	// Until the path is validated, we must not send more than 3x the amount of data received on this path.
+	// If that doesn't leave enough room for a 1200 byte datagram, the probe is only padded up to the limit.
+	probeSize = protocol.MinInitialPacketSize
+	if !p.validated {
+		probeSize = min(probeSize, 3*p.bytesReceived-p.bytesSent)
+		if probeSize < maxUnpaddedPathProbeSize {
+			pm.logger.Debugf("Not probing path to %s: blocked by the anti-amplification limit", addr)
+			p.challengeSent = time.Time{}
+			return protocol.ConnectionID{}, nil, 0, isNonProbing
+		}
+	}
+	connID, ok := pm.getConnID(p.id)
+	if !ok {
+		pm.logger.Debugf("Not probing path to %s: no connection ID available", addr)
+		p.challengeSent = time.Time{}
+		return protocol.ConnectionID{}, nil, 0, false
+	}
+	p.bytesSent += probeSize
+	return connID, frames, probeSize, isNonProbing
+}
+
+// HandlePacketOnCurrentPath is called for packets received on the current path.
+// If the connection switched to this path before it was validated, it returns a PATH_CHALLENGE
+// that needs to be sent on the current path, if we haven't sent one yet, or if the last one is likely lost.
+// The anti-amplification limit is then enforced by the sent packet handler.
+func (pm *pathManager) HandlePacketOnCurrentPath(rcvTime time.Time) *wire.PathChallengeFrame {
+	if pm.current == nil {
+		return nil
+	}
+	return pm.maybeNewPathChallenge(pm.current, rcvTime)
+}
+
+func (pm *pathManager) maybeNewPathChallenge(p *path, now time.Time) *wire.PathChallengeFrame {
+	if p.validated || (!p.challengeSent.IsZero() && now.Sub(p.challengeSent) <= pm.rttStats.PTO(true)) {
+		return nil
+	}
+	_, _ = rand.Read(p.pathChallenge[:])
+	p.challengeSent = now
+	return &wire.PathChallengeFrame{Data: p.pathChallenge}
+}
+
+// HandlePathResponseFrame handles a PATH_RESPONSE frame.
+// Note that a PATH_RESPONSE validates the path the PATH_CHALLENGE was sent on,
+// independent of the path the PATH_RESPONSE is received on, see section 8.2.3 of RFC 9000.
+// It returns true if the current path was validated.
+func (pm *pathManager) HandlePathResponseFrame(f *wire.PathResponseFrame) (currentPathValidated bool) {
+	if p := pm.current; p != nil && !p.challengeSent.IsZero() && p.pathChallenge == f.Data {
+		pm.logger.Debugf("Current path to %s validated", p.addr)
+		pm.current = nil
+		return true
+	}
+	for _, p := range pm.paths {
+		if !p.validated && !p.challengeSent.IsZero() && p.pathChallenge == f.Data {
+			pm.logger.Debugf("Path to %s validated", p.addr)
+			p.validated = true
+			return false
+		}
+	}
+	return false
+}
+
+// SwitchToPath is called when the connection switches to a new path.
+// The connection ID used for this path is now used as the active connection ID.
+// All other paths are abandoned.
+// If the path hasn't been validated yet, path validation continues on the current path,
+// and the caller needs to enforce the anti-amplification limit, starting from the bytes sent and received on this path.
+func (pm *pathManager) SwitchToPath(addr net.Addr, onPreferredAddress bool) (*path, bool) {
+	var switched *path
+	for _, p := range pm.paths {
+		if p.onPreferredAddress == onPreferredAddress && addrsEqual(p.addr, addr) {
+			switched = p
+			continue
+		}
+		pm.retireConnID(p.id)
+	}
+	pm.paths = pm.paths[:0]
+	pm.current = nil
+	if switched != nil && !switched.validated {
+		pm.current = switched
+	}
+	return switched, switched != nil
+}
+
+func (pm *pathManager) getPath(addr net.Addr, onPreferredAddress bool) *path {
+	for _, p := range pm.paths {
+		if p.onPreferredAddress == onPreferredAddress && addrsEqual(p.addr, addr) {
+			return p
+		}
+	}
+	return nil
+}
+
+func (pm *pathManager) addPath(addr net.Addr, onPreferredAddress bool) *path {
+	if len(pm.paths) >= maxPaths {
+		oldest := 0
+		for i, p := range pm.paths {
+			if p.lastPacketTime.Before(pm.paths[oldest].lastPacketTime) {
+				oldest = i
+			}
+		}
+		pm.logger.Debugf("Abandoning path to %s", pm.paths[oldest].addr)
+		pm.retireConnID(pm.paths[oldest].id)
+		pm.paths = append(pm.paths[:oldest], pm.paths[oldest+1:]...)
+	}
+	p := &path{id: pm.nextPathID, addr: addr, onPreferredAddress: onPreferredAddress}
+	pm.nextPathID++
+	pm.paths = append(pm.paths, p)
+	return p
+}
+
+func addrsEqual(addr1, addr2 net.Addr) bool {
+	if addr1 == nil || addr2 == nil {
+		return false
+	}
+	a1, ok1 := addr1.(*net.UDPAddr)
+	a2, ok2 := addr2.(*net.UDPAddr)
+	if ok1 && ok2 {
+		return a1.IP.Equal(a2.IP) && a1.Port == a2.Port
+	}
+	return addr1.String() == addr2.String()
+}
+
+// sameIP returns true if the two addresses only differ in their port number.
+func sameIP(addr1, addr2 net.Addr) bool {
+	a1, ok1 := addr1.(*net.UDPAddr)
+	a2, ok2 := addr2.(*net.UDPAddr)
+	return ok1 && ok2 && a1.IP.Equal(a2.IP)
+}
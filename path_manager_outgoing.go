@@ -0,0 +1,283 @@
+package quic
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/ackhandler"
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+)
+
+var (
+	// ErrPathClosed is returned when trying to use a path that was closed.
+	ErrPathClosed = errors.New("path closed")
+	// ErrPathNotValidated is returned when trying to switch to a path before path validation has completed.
+	ErrPathNotValidated = errors.New("path not yet validated")
+)
+
+// The initial time to wait for a PATH_RESPONSE before sending another PATH_CHALLENGE.
+// The timeout is doubled for every retransmission.
+const initialPathProbeTimeout = 200 * time.Millisecond
+
+type pathID int64
+
+// Path is a network path, created by Connection.AddPath.
+// A path needs to be validated by calling Probe before the connection can switch to it.
+type Path struct {
+	id          pathID
+	pathManager *pathManagerOutgoing
+	tr          *Transport
+
+	validated atomic.Bool
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Probe validates the path by sending PATH_CHALLENGE frames on it.
+// It blocks until a PATH_RESPONSE was received, the context is canceled, the path is closed,
+// or the connection is closed.
+// PATH_CHALLENGE frames are retransmitted with an exponential backoff.
+func (p *Path) Probe(ctx context.Context) error {
+	if p.validated.Load() {
+		return nil
+	}
+	path, err := p.pathManager.enqueueProbe(p.id)
+	if err != nil {
+		return err
+	}
+	timeout := initialPathProbeTimeout
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-p.pathManager.connClosed:
+			return context.Cause(p.pathManager.connCtx)
+		case <-p.closed:
+			return ErrPathClosed
+		case <-path.validated:
+			p.validated.Store(true)
+			return nil
+		case <-timer.C:
+			timeout *= 2
+			timer.Reset(timeout)
+			if _, err := p.pathManager.enqueueProbe(p.id); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Switch switches the connection to this path.
+// The path must have been validated using Probe.
+// After switching, all packets are sent on the new path.
+func (p *Path) Switch() error {
+	select {
+	case <-p.closed:
+		return ErrPathClosed
+	default:
+	}
+	if !p.validated.Load() {
+		return ErrPathNotValidated
+	}
+	return p.pathManager.switchToPath(p.id)
+}
+
+// Close abandons the path.
+// It is not possible to close the path that's currently in use.
+// A closed path can't be probed again.
+func (p *Path) Close() error {
+	if err := p.pathManager.removePath(p.id); err != nil {
+		return err
+	}
+	p.closeOnce.Do(func() { close(p.closed) })
+	return nil
+}
+
+type pathOutgoing struct {
+	tr             *Transport // nil if the path uses the connection's current Transport
+	remoteAddr     net.Addr   // nil if the path uses the connection's current remote address
+	enablePath     func()     // called before sending the first PATH_CHALLENGE
+	pathChallenges [][8]byte
+	validated      chan struct{} // closed when a matching PATH_RESPONSE is received
+	isValidated    bool
+}
+
+// pathManagerOutgoing manages the paths created by the client using Connection.AddPath,
+// as well as the path to the server's preferred address.
+// Methods exported on Path are called from the application's goroutines,
+// all other methods are called from the connection's run loop.
+type pathManagerOutgoing struct {
+	connCtx         context.Context
+	connClosed      <-chan struct{}
+	scheduleSending func()
+
+	mutex          sync.Mutex
+	nextPathID     pathID
+	activePath     pathID
+	paths          map[pathID]*pathOutgoing
+	pathsToProbe   []pathID
+	pathsToRetire  []pathID
+	pathToSwitchTo *pathID
+}
+
+func newPathManagerOutgoing(connCtx context.Context, scheduleSending func()) *pathManagerOutgoing {
+	return &pathManagerOutgoing{
+		connCtx:         connCtx,
+		connClosed:      connCtx.Done(),
+		scheduleSending: scheduleSending,
+		nextPathID:      1, // path 0 is the path used during the handshake
+		paths:           make(map[pathID]*pathOutgoing),
+	}
+}
+
+// NewPath creates a new path.
+// If t is nil, the path uses the connection's current Transport.
+// If remoteAddr is nil, the path uses the connection's current remote address.
+func (pm *pathManagerOutgoing) NewPath(t *Transport, remoteAddr net.Addr, enablePath func()) *Path {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	id := pm.nextPathID
+	pm.nextPathID++
+	pm.paths[id] = &pathOutgoing{
+		tr:         t,
+		remoteAddr: remoteAddr,
+		enablePath: enablePath,
+		validated:  make(chan struct{}),
+	}
+	return &Path{
+		id:          id,
+		pathManager: pm,
+		tr:          t,
+		closed:      make(chan struct{}),
+	}
+}
+
+func (pm *pathManagerOutgoing) enqueueProbe(id pathID) (*pathOutgoing, error) {
+	pm.mutex.Lock()
+	path, ok := pm.paths[id]
+	if !ok {
+		pm.mutex.Unlock()
+		return nil, ErrPathClosed
+	}
+	pm.pathsToProbe = append(pm.pathsToProbe, id)
+	pm.mutex.Unlock()
+	pm.scheduleSending()
+	return path, nil
+}
+
+func (pm *pathManagerOutgoing) switchToPath(id pathID) error {
+	pm.mutex.Lock()
+	if _, ok := pm.paths[id]; !ok {
+		pm.mutex.Unlock()
+		return ErrPathClosed
+	}
+	pm.pathToSwitchTo = &id
+	pm.mutex.Unlock()
+	pm.scheduleSending()
+	return nil
+}
+
+func (pm *pathManagerOutgoing) removePath(id pathID) error {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if id == pm.activePath || (pm.pathToSwitchTo != nil && *pm.pathToSwitchTo == id) {
+		return errors.New("can't close the active path")
+	}
+	if _, ok := pm.paths[id]; !ok {
+		return nil
+	}
+	delete(pm.paths, id)
+	pm.pathsToRetire = append(pm.pathsToRetire, id)
+	return nil
+}
+
+// NextPathToProbe returns the connection ID, the PATH_CHALLENGE frame, the Transport
+// and the remote address to use for probing the next path.
+// getConnID is used to obtain the connection ID for a path,
+// retireConnID is called for every path that was closed since the last call.
+func (pm *pathManagerOutgoing) NextPathToProbe(
+	getConnID func(pathID) (protocol.ConnectionID, bool),
+	retireConnID func(pathID),
+) (protocol.ConnectionID, ackhandler.Frame, *Transport, net.Addr, bool) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	for _, id := range pm.pathsToRetire {
+		retireConnID(id)
+	}
+	pm.pathsToRetire = pm.pathsToRetire[:0]
+
+	for len(pm.pathsToProbe) > 0 {
+		id := pm.pathsToProbe[0]
+		pm.pathsToProbe = pm.pathsToProbe[1:]
+		path, ok := pm.paths[id]
+		if !ok || path.isValidated {
+			continue
+		}
+		// If the peer didn't provide us with enough connection IDs, we can't probe the path (yet).
+		// Probe will try again after the probe timeout.
+		connID, ok := getConnID(id)
+		if !ok {
+			continue
+		}
+		var b [8]byte
+		_, _ = rand.Read(b[:])
+		path.pathChallenges = append(path.pathChallenges, b)
+		if path.enablePath != nil {
+			path.enablePath()
+			path.enablePath = nil
+		}
+		frame := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: b}}
+		return connID, frame, path.tr, path.remoteAddr, true
+	}
+	return protocol.ConnectionID{}, ackhandler.Frame{}, nil, nil, false
+}
+
+// HandlePathResponseFrame handles a PATH_RESPONSE frame.
+// PATH_RESPONSE frames that don't match any outstanding PATH_CHALLENGE are ignored.
+func (pm *pathManagerOutgoing) HandlePathResponseFrame(f *wire.PathResponseFrame) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	for _, path := range pm.paths {
+		if path.isValidated {
+			continue
+		}
+		for _, c := range path.pathChallenges {
+			if c == f.Data {
+				path.isValidated = true
+				path.pathChallenges = nil
+				close(path.validated)
+				return
+			}
+		}
+	}
+}
+
+// ShouldSwitchPath returns the path that the connection should switch to, if any.
+func (pm *pathManagerOutgoing) ShouldSwitchPath() (pathID, *Transport, net.Addr, bool) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if pm.pathToSwitchTo == nil {
+		return 0, nil, nil, false
+	}
+	id := *pm.pathToSwitchTo
+	pm.pathToSwitchTo = nil
+	path, ok := pm.paths[id]
+	if !ok || !path.isValidated || id == pm.activePath {
+		return 0, nil, nil, false
+	}
+	pm.activePath = id
+	return id, path.tr, path.remoteAddr, true
+}
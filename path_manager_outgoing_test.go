@@ -0,0 +1,155 @@
+package quic
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Manager (outgoing)", func() {
+	var (
+		pm               *pathManagerOutgoing
+		connCtx          context.Context
+		connCancel       context.CancelCauseFunc
+		scheduledSending chan struct{}
+		retiredPaths     []pathID
+	)
+
+	getConnID := func(id pathID) (protocol.ConnectionID, bool) {
+		return protocol.ParseConnectionID([]byte{byte(id), byte(id), byte(id), byte(id)}), true
+	}
+	retireConnID := func(id pathID) { retiredPaths = append(retiredPaths, id) }
+
+	BeforeEach(func() {
+		retiredPaths = nil
+		scheduledSending = make(chan struct{}, 100)
+		connCtx, connCancel = context.WithCancelCause(context.Background())
+		pm = newPathManagerOutgoing(connCtx, func() { scheduledSending <- struct{}{} })
+	})
+
+	AfterEach(func() { connCancel(nil) })
+
+	It("probes and validates a path", func() {
+		var enabled bool
+		tr := &Transport{}
+		p := pm.NewPath(tr, nil, func() { enabled = true })
+		_, _, _, _, ok := pm.NextPathToProbe(getConnID, retireConnID)
+		Expect(ok).To(BeFalse())
+
+		errChan := make(chan error, 1)
+		go func() { errChan <- p.Probe(context.Background()) }()
+		Eventually(scheduledSending).Should(Receive())
+		connID, f, t, addr, ok := pm.NextPathToProbe(getConnID, retireConnID)
+		Expect(ok).To(BeTrue())
+		Expect(enabled).To(BeTrue())
+		Expect(t).To(Equal(tr))
+		Expect(addr).To(BeNil())
+		Expect(connID).To(Equal(protocol.ParseConnectionID([]byte{1, 1, 1, 1})))
+		Expect(f.Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+		_, _, _, _, ok = pm.NextPathToProbe(getConnID, retireConnID)
+		Expect(ok).To(BeFalse())
+
+		// PATH_RESPONSE frames that don't match a PATH_CHALLENGE are ignored
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})
+		Consistently(errChan, 50*time.Millisecond).ShouldNot(Receive())
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f.Frame.(*wire.PathChallengeFrame).Data})
+		Eventually(errChan).Should(Receive(BeNil()))
+	})
+
+	It("retransmits PATH_CHALLENGE frames", func() {
+		p := pm.NewPath(&Transport{}, nil, func() {})
+		go p.Probe(context.Background())
+		Eventually(scheduledSending).Should(Receive())
+		_, f1, _, _, ok := pm.NextPathToProbe(getConnID, retireConnID)
+		Expect(ok).To(BeTrue())
+		Eventually(scheduledSending, 2*initialPathProbeTimeout).Should(Receive())
+		_, f2, _, _, ok := pm.NextPathToProbe(getConnID, retireConnID)
+		Expect(ok).To(BeTrue())
+		Expect(f2.Frame).ToNot(Equal(f1.Frame))
+		// a response to the first PATH_CHALLENGE validates the path
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f1.Frame.(*wire.PathChallengeFrame).Data})
+		Eventually(p.validated.Load).Should(BeTrue())
+	})
+
+	It("stops probing when the context is canceled", func() {
+		p := pm.NewPath(&Transport{}, nil, func() {})
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errors.New("canceled"))
+		Expect(p.Probe(ctx)).To(MatchError("canceled"))
+	})
+
+	It("stops probing when the connection is closed", func() {
+		p := pm.NewPath(&Transport{}, nil, func() {})
+		connCancel(errors.New("connection closed"))
+		Expect(p.Probe(context.Background())).To(MatchError("connection closed"))
+	})
+
+	It("doesn't switch to a path that wasn't validated", func() {
+		p := pm.NewPath(&Transport{}, nil, func() {})
+		Expect(p.Switch()).To(MatchError(ErrPathNotValidated))
+		_, _, _, ok := pm.ShouldSwitchPath()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("switches to a validated path", func() {
+		tr := &Transport{}
+		p := pm.NewPath(tr, nil, func() {})
+		errChan := make(chan error, 1)
+		go func() { errChan <- p.Probe(context.Background()) }()
+		Eventually(scheduledSending).Should(Receive())
+		_, f, _, _, ok := pm.NextPathToProbe(getConnID, retireConnID)
+		Expect(ok).To(BeTrue())
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f.Frame.(*wire.PathChallengeFrame).Data})
+		Eventually(errChan).Should(Receive(BeNil()))
+
+		Expect(p.Switch()).To(Succeed())
+		id, t, addr, ok := pm.ShouldSwitchPath()
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal(p.id))
+		Expect(t).To(Equal(tr))
+		Expect(addr).To(BeNil())
+		_, _, _, ok = pm.ShouldSwitchPath()
+		Expect(ok).To(BeFalse())
+		// the active path can't be closed
+		Expect(p.Close()).ToNot(Succeed())
+	})
+
+	It("uses a different remote address", func() {
+		addr := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+		p := pm.NewPath(nil, addr, nil)
+		errChan := make(chan error, 1)
+		go func() { errChan <- p.Probe(context.Background()) }()
+		Eventually(scheduledSending).Should(Receive())
+		_, f, t, remoteAddr, ok := pm.NextPathToProbe(getConnID, retireConnID)
+		Expect(ok).To(BeTrue())
+		Expect(t).To(BeNil())
+		Expect(remoteAddr).To(Equal(addr))
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f.Frame.(*wire.PathChallengeFrame).Data})
+		Eventually(errChan).Should(Receive(BeNil()))
+
+		Expect(p.Switch()).To(Succeed())
+		_, t, remoteAddr, ok = pm.ShouldSwitchPath()
+		Expect(ok).To(BeTrue())
+		Expect(t).To(BeNil())
+		Expect(remoteAddr).To(Equal(addr))
+	})
+
+	It("closes paths", func() {
+		p := pm.NewPath(&Transport{}, nil, func() {})
+		Expect(p.Close()).To(Succeed())
+		_, _, _, _, ok := pm.NextPathToProbe(getConnID, retireConnID)
+		Expect(ok).To(BeFalse())
+		Expect(retiredPaths).To(Equal([]pathID{p.id}))
+		Expect(p.Probe(context.Background())).To(MatchError(ErrPathClosed))
+		Expect(p.Switch()).To(MatchError(ErrPathClosed))
+		// closing a path multiple times is fine
+		Expect(p.Close()).To(Succeed())
+	})
+})
@@ -0,0 +1,165 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+	"github.com/quic-go/quic-go/internal/utils"
+	"github.com/quic-go/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Manager", func() {
+	var (
+		pm           *pathManager
+		connIDs      map[pathID]protocol.ConnectionID
+		retiredPaths []pathID
+	)
+	addr1 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1000}
+	addr2 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 2000}
+
+	BeforeEach(func() {
+		connIDs = make(map[pathID]protocol.ConnectionID)
+		retiredPaths = nil
+		pm = newPathManager(
+			func(id pathID) (protocol.ConnectionID, bool) {
+				connID := protocol.ParseConnectionID([]byte{byte(id), byte(id), byte(id), byte(id)})
+				connIDs[id] = connID
+				return connID, true
+			},
+			func(id pathID) { retiredPaths = append(retiredPaths, id) },
+			&utils.RTTStats{},
+			utils.DefaultLogger,
+		)
+	})
+
+	It("validates a path", func() {
+		now := time.Now()
+		connID, frames, probeSize, shouldSwitch := pm.HandlePacket(addr1, false, now, 1000, nil, false)
+		Expect(shouldSwitch).To(BeFalse())
+		Expect(connID).To(Equal(connIDs[1]))
+		Expect(probeSize).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+		Expect(frames).To(HaveLen(1))
+		Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+		challenge := frames[0].Frame.(*wire.PathChallengeFrame).Data
+
+		// no new PATH_CHALLENGE is sent right away
+		_, frames, _, shouldSwitch = pm.HandlePacket(addr1, false, now.Add(time.Millisecond), 1000, nil, false)
+		Expect(frames).To(BeEmpty())
+		Expect(shouldSwitch).To(BeFalse())
+
+		Expect(pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: challenge})).To(BeFalse())
+		_, frames, _, shouldSwitch = pm.HandlePacket(addr1, false, now.Add(2*time.Millisecond), 1000, nil, true)
+		Expect(frames).To(BeEmpty())
+		Expect(shouldSwitch).To(BeTrue())
+		// probing packets don't cause a switch
+		_, _, _, shouldSwitch = pm.HandlePacket(addr1, false, now.Add(3*time.Millisecond), 1000, nil, false)
+		Expect(shouldSwitch).To(BeFalse())
+		path, ok := pm.SwitchToPath(addr1, false)
+		Expect(ok).To(BeTrue())
+		Expect(path.validated).To(BeTrue())
+		Expect(pm.HandlePacketOnCurrentPath(now.Add(time.Hour))).To(BeNil())
+	})
+
+	It("switches to a path before it was validated", func() {
+		now := time.Now()
+		_, frames, _, shouldSwitch := pm.HandlePacket(addr1, false, now, 1000, nil, true)
+		Expect(shouldSwitch).To(BeTrue())
+		Expect(frames).To(HaveLen(1))
+		challenge := frames[0].Frame.(*wire.PathChallengeFrame).Data
+		path, ok := pm.SwitchToPath(addr1, false)
+		Expect(ok).To(BeTrue())
+		Expect(path.validated).To(BeFalse())
+		Expect(path.bytesReceived).To(BeEquivalentTo(1000))
+		Expect(path.bytesSent).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+		Expect(retiredPaths).To(BeEmpty())
+
+		// path validation continues on the current path
+		Expect(pm.HandlePacketOnCurrentPath(now.Add(time.Millisecond))).To(BeNil())
+		f := pm.HandlePacketOnCurrentPath(now.Add(time.Hour))
+		Expect(f).ToNot(BeNil())
+		Expect(f.Data).ToNot(Equal(challenge))
+		// only the response to the last PATH_CHALLENGE validates the path
+		Expect(pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: challenge})).To(BeFalse())
+		Expect(pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: f.Data})).To(BeTrue())
+		Expect(pm.HandlePacketOnCurrentPath(now.Add(2 * time.Hour))).To(BeNil())
+	})
+
+	It("retransmits PATH_CHALLENGE frames", func() {
+		now := time.Now()
+		_, frames, _, _ := pm.HandlePacket(addr1, false, now, 1000, nil, true)
+		Expect(frames).To(HaveLen(1))
+		challenge1 := frames[0].Frame.(*wire.PathChallengeFrame).Data
+		_, frames, _, _ = pm.HandlePacket(addr1, false, now.Add(time.Hour), 1000, nil, true)
+		Expect(frames).To(HaveLen(1))
+		challenge2 := frames[0].Frame.(*wire.PathChallengeFrame).Data
+		Expect(challenge2).ToNot(Equal(challenge1))
+	})
+
+	It("responds to PATH_CHALLENGE frames on the new path", func() {
+		now := time.Now()
+		_, frames, _, _ := pm.HandlePacket(addr1, false, now, 1000, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, false)
+		Expect(frames).To(HaveLen(2))
+		Expect(frames[0].Frame).To(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}))
+		Expect(frames[1].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+	})
+
+	It("enforces the anti-amplification limit", func() {
+		now := time.Now()
+		// 3 * 15 bytes is not enough to send a probe packet
+		_, frames, _, shouldSwitch := pm.HandlePacket(addr1, false, now, 15, nil, true)
+		Expect(frames).To(BeEmpty())
+		Expect(shouldSwitch).To(BeTrue())
+		// 3 * 300 bytes is not enough to send a 1200 byte probe packet, so the probe packet is padded to 900 bytes
+		_, frames, probeSize, _ := pm.HandlePacket(addr1, false, now, 285, nil, true)
+		Expect(frames).To(HaveLen(1))
+		Expect(probeSize).To(BeEquivalentTo(900))
+		// not enough budget for another probe packet
+		_, frames, _, _ = pm.HandlePacket(addr1, false, now.Add(time.Hour), 10, nil, true)
+		Expect(frames).To(BeEmpty())
+		_, frames, probeSize, _ = pm.HandlePacket(addr1, false, now.Add(time.Hour), 400, nil, true)
+		Expect(frames).To(HaveLen(1))
+		Expect(probeSize).To(BeEquivalentTo(protocol.MinInitialPacketSize))
+	})
+
+	It("abandons the oldest path when too many paths are in use", func() {
+		now := time.Now()
+		for i := 0; i < maxPaths; i++ {
+			addr := &net.UDPAddr{IP: net.IPv4(10, 0, 0, byte(i)), Port: 1000}
+			pm.HandlePacket(addr, false, now.Add(time.Duration(i)*time.Second), 1000, nil, true)
+		}
+		Expect(retiredPaths).To(BeEmpty())
+		pm.HandlePacket(addr2, false, now.Add(time.Minute), 1000, nil, true)
+		Expect(retiredPaths).To(Equal([]pathID{1}))
+	})
+
+	It("retires all other paths when switching", func() {
+		now := time.Now()
+		pm.HandlePacket(addr1, false, now, 1000, nil, true)
+		pm.HandlePacket(addr2, false, now, 1000, nil, true)
+		path, ok := pm.SwitchToPath(addr2, false)
+		Expect(ok).To(BeTrue())
+		Expect(path.id).To(Equal(pathID(2)))
+		Expect(retiredPaths).To(Equal([]pathID{1}))
+	})
+
+	It("treats packets received on the preferred address as a different path", func() {
+		now := time.Now()
+		_, frames, _, _ := pm.HandlePacket(addr1, false, now, 1000, nil, true)
+		Expect(frames).To(HaveLen(1))
+		_, frames, _, _ = pm.HandlePacket(addr1, true, now, 1000, nil, true)
+		Expect(frames).To(HaveLen(1))
+		pm.HandlePathResponseFrame(&wire.PathResponseFrame{Data: frames[0].Frame.(*wire.PathChallengeFrame).Data})
+		path := pm.getPath(addr1, false)
+		Expect(path.validated).To(BeFalse())
+		path = pm.getPath(addr1, true)
+		Expect(path.validated).To(BeTrue())
+		path, ok := pm.SwitchToPath(addr1, true)
+		Expect(ok).To(BeTrue())
+		Expect(path.id).To(Equal(pathID(2)))
+		Expect(path.validated).To(BeTrue())
+	})
+})
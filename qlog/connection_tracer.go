@@ -70,6 +70,9 @@ func NewConnectionTracer(w io.WriteCloser, p logging.Perspective, odcid protocol
 		DroppedPacket: func(pt logging.PacketType, pn logging.PacketNumber, size logging.ByteCount, reason logging.PacketDropReason) {
 			t.DroppedPacket(pt, pn, size, reason)
 		},
+		DroppedDatagram: func(length logging.ByteCount) {
+			t.recordEvent(time.Now(), &eventDatagramDropped{Length: length})
+		},
 		UpdatedMetrics: func(rttStats *utils.RTTStats, cwnd, bytesInFlight protocol.ByteCount, packetsInFlight int) {
 			t.UpdatedMetrics(rttStats, cwnd, bytesInFlight, packetsInFlight)
 		},
@@ -112,6 +115,9 @@ func NewConnectionTracer(w io.WriteCloser, p logging.Perspective, odcid protocol
 		ChoseALPN: func(protocol string) {
 			t.recordEvent(time.Now(), eventALPNInformation{chosenALPN: protocol})
 		},
+		MigratedPath: func(oldRemote, newRemote net.Addr) {
+			t.MigratedPath(oldRemote, newRemote)
+		},
 		Debug: func(name, msg string) {
 			t.Debug(name, msg)
 		},
@@ -147,6 +153,19 @@ func (t *connectionTracer) StartedConnection(local, remote net.Addr, srcConnID,
 	})
 }
 
+func (t *connectionTracer) MigratedPath(oldRemote, newRemote net.Addr) {
+	// ignore this event if we're not dealing with UDP addresses here
+	oldAddr, ok := oldRemote.(*net.UDPAddr)
+	if !ok {
+		return
+	}
+	newAddr, ok := newRemote.(*net.UDPAddr)
+	if !ok {
+		return
+	}
+	t.recordEvent(time.Now(), &eventPathMigrated{OldAddr: oldAddr, NewAddr: newAddr})
+}
+
 func (t *connectionTracer) NegotiatedVersion(chosen logging.VersionNumber, client, server []logging.VersionNumber) {
 	var clientVersions, serverVersions []versionNumber
 	if len(client) > 0 {
@@ -129,6 +129,22 @@ var _ = Describe("Tracing", func() {
 			Expect(ev).To(HaveKeyWithValue("dst_cid", "05060708"))
 		})
 
+		It("records path migrations", func() {
+			tracer.MigratedPath(
+				&net.UDPAddr{IP: net.IPv4(192, 168, 13, 37), Port: 42},
+				&net.UDPAddr{IP: net.IPv4(192, 168, 12, 34), Port: 24},
+			)
+			tracer.Close()
+			entry := exportAndParseSingle(buf)
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("connectivity:path_migrated"))
+			ev := entry.Event
+			Expect(ev).To(HaveKeyWithValue("old_ip", "192.168.13.37"))
+			Expect(ev).To(HaveKeyWithValue("old_port", float64(42)))
+			Expect(ev).To(HaveKeyWithValue("new_ip", "192.168.12.34"))
+			Expect(ev).To(HaveKeyWithValue("new_port", float64(24)))
+		})
+
 		It("records the version, if no version negotiation happened", func() {
 			tracer.NegotiatedVersion(0x1337, nil, nil)
 			tracer.Close()
@@ -614,6 +630,17 @@ var _ = Describe("Tracing", func() {
 			Expect(ev).To(HaveKeyWithValue("trigger", "payload_decrypt_error"))
 		})
 
+		It("records dropped DATAGRAM frames", func() {
+			tracer.DroppedDatagram(1337)
+			tracer.Close()
+			entry := exportAndParseSingle(buf)
+			Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+			Expect(entry.Name).To(Equal("transport:datagram_frame_dropped"))
+			ev := entry.Event
+			Expect(ev).To(HaveKeyWithValue("length", float64(1337)))
+			Expect(ev).To(HaveKeyWithValue("trigger", "receive_queue_full"))
+		})
+
 		It("records dropped packets with a packet number", func() {
 			tracer.DroppedPacket(logging.PacketTypeHandshake, 42, 1337, logging.PacketDropDuplicate)
 			tracer.Close()
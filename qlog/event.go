@@ -85,6 +85,24 @@ func (e eventConnectionStarted) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("dst_cid", e.DestConnectionID.String())
 }
 
+type eventPathMigrated struct {
+	OldAddr *net.UDPAddr
+	NewAddr *net.UDPAddr
+}
+
+var _ eventDetails = &eventPathMigrated{}
+
+func (e eventPathMigrated) Category() category { return categoryConnectivity }
+func (e eventPathMigrated) Name() string       { return "path_migrated" }
+func (e eventPathMigrated) IsNil() bool        { return false }
+
+func (e eventPathMigrated) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("old_ip", e.OldAddr.IP.String())
+	enc.IntKey("old_port", e.OldAddr.Port)
+	enc.StringKey("new_ip", e.NewAddr.IP.String())
+	enc.IntKey("new_port", e.NewAddr.Port)
+}
+
 type eventVersionNegotiated struct {
 	clientVersions, serverVersions []versionNumber
 	chosenVersion                  versionNumber
@@ -283,6 +301,19 @@ func (e eventPacketDropped) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("trigger", e.Trigger.String())
 }
 
+type eventDatagramDropped struct {
+	Length protocol.ByteCount
+}
+
+func (e eventDatagramDropped) Category() category { return categoryTransport }
+func (e eventDatagramDropped) Name() string       { return "datagram_frame_dropped" }
+func (e eventDatagramDropped) IsNil() bool        { return false }
+
+func (e eventDatagramDropped) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.Uint64Key("length", uint64(e.Length))
+	enc.StringKey("trigger", "receive_queue_full")
+}
+
 type metrics struct {
 	MinRTT      time.Duration
 	SmoothedRTT time.Duration
@@ -113,6 +113,24 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals RESET_STREAM_AT frames", func() {
+		check(
+			&logging.ResetStreamFrame{
+				StreamID:     987,
+				FinalSize:    1234,
+				ErrorCode:    42,
+				ReliableSize: 999,
+			},
+			map[string]interface{}{
+				"frame_type":    "reset_stream_at",
+				"stream_id":     987,
+				"error_code":    42,
+				"final_size":    1234,
+				"reliable_size": 999,
+			},
+		)
+	})
+
 	It("marshals STOP_SENDING frames", func() {
 		check(
 			&logging.StopSendingFrame{
@@ -364,6 +382,33 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals ACK_FREQUENCY frames", func() {
+		check(
+			&logging.AckFrequencyFrame{
+				SequenceNumber:        3,
+				AckElicitingThreshold: 10,
+				RequestMaxAckDelay:    5 * time.Millisecond,
+				ReorderingThreshold:   2,
+			},
+			map[string]interface{}{
+				"frame_type":              "ack_frequency",
+				"sequence_number":         3,
+				"ack_eliciting_threshold": 10,
+				"request_max_ack_delay":   5,
+				"reordering_threshold":    2,
+			},
+		)
+	})
+
+	It("marshals IMMEDIATE_ACK frames", func() {
+		check(
+			&logging.ImmediateAckFrame{},
+			map[string]interface{}{
+				"frame_type": "immediate_ack",
+			},
+		)
+	})
+
 	It("marshals DATAGRAM frames", func() {
 		check(
 			&logging.DatagramFrame{Length: 1337},
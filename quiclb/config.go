@@ -0,0 +1,169 @@
+// Package quiclb implements QUIC-LB compatible connection IDs, as specified in draft-ietf-quic-load-balancers.
+//
+// QUIC-LB allows a load balancer to route QUIC packets to the server that owns the connection,
+// by encoding the server ID into the connection IDs chosen by the server.
+// The server ID can be encoded in plaintext, or encrypted using AES-128.
+// Servers use a Generator as the quic.Transport's ConnectionIDGenerator,
+// and load balancers use a Decoder to extract the server ID from incoming packets.
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+)
+
+const (
+	// MaxConfigID is the largest config ID.
+	// The config ID 0b111 is reserved for unroutable connection IDs.
+	MaxConfigID        = 6
+	unroutableConfigID = 0b111
+
+	minNonceLen     = 4
+	maxPlaintextLen = 19 // the connection ID is at most 20 bytes long, including the first octet
+)
+
+// ErrUnroutable is returned when a connection ID doesn't contain a server ID that can be decoded.
+var ErrUnroutable = errors.New("quiclb: unroutable connection ID")
+
+// A Config is a QUIC-LB configuration.
+// The load balancer and all servers need to use the same configuration.
+type Config struct {
+	// ConfigID is encoded in the config rotation bits (the 3 most significant bits of the first octet).
+	// It allows the load balancer to use multiple configurations at the same time, e.g. during key rotation.
+	// It must be between 0 and MaxConfigID.
+	ConfigID uint8
+	// ServerIDLen is the length of the server ID, in bytes.
+	// It must be at least 1.
+	ServerIDLen int
+	// NonceLen is the length of the nonce, in bytes.
+	// It must be at least 4, and ServerIDLen + NonceLen must not exceed 19.
+	NonceLen int
+	// Key is the AES-128 key used to encrypt the server ID and the nonce.
+	// If nil, the server ID is encoded in plaintext.
+	Key []byte
+	// If LengthSelfEncoding is set, the length of the connection ID is encoded
+	// in the 5 least significant bits of the first octet.
+	// Otherwise, these bits are random.
+	LengthSelfEncoding bool
+}
+
+// ConnectionIDLen returns the length of the connection IDs.
+func (c *Config) ConnectionIDLen() int {
+	return 1 + c.ServerIDLen + c.NonceLen
+}
+
+func (c *Config) validate() error {
+	if c.ConfigID > MaxConfigID {
+		return fmt.Errorf("quiclb: invalid config ID: %d", c.ConfigID)
+	}
+	if c.ServerIDLen < 1 {
+		return fmt.Errorf("quiclb: invalid server ID length: %d", c.ServerIDLen)
+	}
+	if c.NonceLen < minNonceLen {
+		return fmt.Errorf("quiclb: nonce too short: %d bytes", c.NonceLen)
+	}
+	if c.ServerIDLen+c.NonceLen > maxPlaintextLen {
+		return fmt.Errorf("quiclb: server ID and nonce too long: %d bytes", c.ServerIDLen+c.NonceLen)
+	}
+	if c.Key != nil && len(c.Key) != 16 {
+		return fmt.Errorf("quiclb: invalid key length: %d bytes", len(c.Key))
+	}
+	return nil
+}
+
+// newCipher creates the cipher used to encrypt server ID and nonce.
+// It returns nil if the server ID is encoded in plaintext.
+func (c *Config) newCipher() (*lbCipher, error) {
+	if c.Key == nil {
+		return nil, nil
+	}
+	block, err := aes.NewCipher(c.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &lbCipher{block: block, plaintextLen: c.ServerIDLen + c.NonceLen}, nil
+}
+
+// The lbCipher encrypts the concatenation of server ID and nonce.
+// If the plaintext is 16 bytes long, it is encrypted using a single pass of AES-ECB.
+// Otherwise, a four-pass Feistel network is used, with AES-ECB as the round function.
+// The first pass modifies the right half, using the left half as the input of the round function.
+type lbCipher struct {
+	block        cipher.Block
+	plaintextLen int
+}
+
+func (c *lbCipher) Encrypt(dst, src []byte) {
+	if c.plaintextLen == aes.BlockSize {
+		c.block.Encrypt(dst, src)
+		return
+	}
+	left, right := c.split(src)
+	c.round(right, left, 1, false)
+	c.round(left, right, 2, true)
+	c.round(right, left, 3, false)
+	c.round(left, right, 4, true)
+	c.join(dst, left, right)
+}
+
+func (c *lbCipher) Decrypt(dst, src []byte) {
+	if c.plaintextLen == aes.BlockSize {
+		c.block.Decrypt(dst, src)
+		return
+	}
+	left, right := c.split(src)
+	c.round(left, right, 4, true)
+	c.round(right, left, 3, false)
+	c.round(left, right, 2, true)
+	c.round(right, left, 1, false)
+	c.join(dst, left, right)
+}
+
+// split splits b into two halves.
+// If the length is odd, both halves contain the middle byte:
+// the left half its 4 most significant bits, the right half its 4 least significant bits.
+func (c *lbCipher) split(b []byte) (left, right []byte) {
+	halfLen := (c.plaintextLen + 1) / 2
+	left = make([]byte, halfLen)
+	right = make([]byte, halfLen)
+	copy(left, b[:halfLen])
+	copy(right, b[c.plaintextLen-halfLen:c.plaintextLen])
+	if c.plaintextLen%2 == 1 {
+		left[halfLen-1] &= 0xf0
+		right[0] &= 0x0f
+	}
+	return left, right
+}
+
+func (c *lbCipher) join(dst, left, right []byte) {
+	halfLen := len(left)
+	copy(dst[c.plaintextLen-halfLen:], right)
+	if c.plaintextLen%2 == 1 {
+		dst[halfLen-1] = left[halfLen-1] | right[0]
+		copy(dst, left[:halfLen-1])
+		return
+	}
+	copy(dst, left)
+}
+
+// round XORs dst with the (truncated) encryption of the expanded src.
+func (c *lbCipher) round(dst, src []byte, pass byte, dstIsLeft bool) {
+	var block [aes.BlockSize]byte
+	// expand: src || zeros || plaintext length || pass
+	copy(block[:], src)
+	block[aes.BlockSize-2] = byte(c.plaintextLen)
+	block[aes.BlockSize-1] = pass
+	c.block.Encrypt(block[:], block[:])
+	for i := range dst {
+		dst[i] ^= block[i]
+	}
+	if c.plaintextLen%2 == 1 {
+		if dstIsLeft {
+			dst[len(dst)-1] &= 0xf0
+		} else {
+			dst[0] &= 0x0f
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package quiclb
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Config", func() {
+	key := bytes.Repeat([]byte{0x42}, 16)
+
+	It("validates the config", func() {
+		Expect((&Config{ServerIDLen: 2, NonceLen: 4}).validate()).To(Succeed())
+		Expect((&Config{ConfigID: 7, ServerIDLen: 2, NonceLen: 4}).validate()).To(MatchError("quiclb: invalid config ID: 7"))
+		Expect((&Config{ServerIDLen: 0, NonceLen: 4}).validate()).To(MatchError("quiclb: invalid server ID length: 0"))
+		Expect((&Config{ServerIDLen: 2, NonceLen: 3}).validate()).To(MatchError("quiclb: nonce too short: 3 bytes"))
+		Expect((&Config{ServerIDLen: 10, NonceLen: 10}).validate()).To(MatchError("quiclb: server ID and nonce too long: 20 bytes"))
+		Expect((&Config{ServerIDLen: 2, NonceLen: 4, Key: make([]byte, 32)}).validate()).To(MatchError("quiclb: invalid key length: 32 bytes"))
+	})
+
+	It("returns the connection ID length", func() {
+		Expect((&Config{ServerIDLen: 3, NonceLen: 5}).ConnectionIDLen()).To(Equal(9))
+	})
+
+	for l := 5; l <= maxPlaintextLen; l++ {
+		plaintextLen := l
+
+		It(fmt.Sprintf("encrypts and decrypts plaintexts of length %d", plaintextLen), func() {
+			c, err := (&Config{ServerIDLen: 1, NonceLen: plaintextLen - 1, Key: key}).newCipher()
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < 100; i++ {
+				plaintext := make([]byte, plaintextLen)
+				rand.Read(plaintext)
+				ciphertext := make([]byte, plaintextLen)
+				c.Encrypt(ciphertext, plaintext)
+				Expect(ciphertext).ToNot(Equal(plaintext))
+				decrypted := make([]byte, plaintextLen)
+				c.Decrypt(decrypted, ciphertext)
+				Expect(decrypted).To(Equal(plaintext))
+			}
+		})
+	}
+
+	It("uses all bits of the plaintext when encrypting an odd number of bytes", func() {
+		c, err := (&Config{ServerIDLen: 2, NonceLen: 5, Key: key}).newCipher()
+		Expect(err).ToNot(HaveOccurred())
+		plaintext := make([]byte, 7)
+		ciphertext1 := make([]byte, 7)
+		c.Encrypt(ciphertext1, plaintext)
+		for i := range plaintext {
+			for _, bit := range []byte{0x01, 0x10, 0x80} {
+				p := make([]byte, 7)
+				p[i] = bit
+				ciphertext2 := make([]byte, 7)
+				c.Encrypt(ciphertext2, p)
+				Expect(ciphertext2).ToNot(Equal(ciphertext1))
+			}
+		}
+	})
+
+	It("doesn't encrypt if no key is set", func() {
+		c, err := (&Config{ServerIDLen: 2, NonceLen: 4}).newCipher()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c).To(BeNil())
+	})
+})
@@ -0,0 +1,94 @@
+package quiclb
+
+import (
+	"errors"
+	"fmt"
+)
+
+type decoderConfig struct {
+	config Config
+	cipher *lbCipher
+}
+
+// A Decoder extracts the server ID from QUIC-LB connection IDs.
+// It is used by load balancers to route packets to the server that owns the connection.
+// It is safe for concurrent use.
+type Decoder struct {
+	configs [MaxConfigID + 1]*decoderConfig
+}
+
+// NewDecoder creates a new Decoder.
+// Multiple configs can be used at the same time (e.g. during key rotation), as long as they use different config IDs.
+func NewDecoder(configs ...*Config) (*Decoder, error) {
+	var d Decoder
+	for _, conf := range configs {
+		if err := conf.validate(); err != nil {
+			return nil, err
+		}
+		if d.configs[conf.ConfigID] != nil {
+			return nil, fmt.Errorf("quiclb: duplicate config ID: %d", conf.ConfigID)
+		}
+		c, err := conf.newCipher()
+		if err != nil {
+			return nil, err
+		}
+		d.configs[conf.ConfigID] = &decoderConfig{config: *conf, cipher: c}
+	}
+	return &d, nil
+}
+
+// ServerID returns the server ID encoded in a connection ID.
+// It returns ErrUnroutable if the connection ID doesn't belong to any of the configs.
+func (d *Decoder) ServerID(connID []byte) ([]byte, error) {
+	if len(connID) == 0 {
+		return nil, ErrUnroutable
+	}
+	configID := connID[0] >> 5
+	if configID == unroutableConfigID || d.configs[configID] == nil {
+		return nil, ErrUnroutable
+	}
+	c := d.configs[configID]
+	if len(connID) < c.config.ConnectionIDLen() {
+		return nil, ErrUnroutable
+	}
+	plaintext := make([]byte, c.config.ServerIDLen+c.config.NonceLen)
+	copy(plaintext, connID[1:])
+	if c.cipher != nil {
+		c.cipher.Decrypt(plaintext, plaintext)
+	}
+	return plaintext[:c.config.ServerIDLen], nil
+}
+
+// ServerIDFromPacket returns the server ID encoded in the Destination Connection ID of a QUIC packet.
+// For short header packets, the length of the connection ID is derived from the config.
+//
+// The first Initial and 0-RTT packets of a connection use a connection ID chosen by the client.
+// Unless it is rejected as unroutable, the server ID decoded from such a connection ID is random.
+// Load balancers need to route packets carrying an unknown server ID using a different mechanism,
+// e.g. by hashing the 4-tuple.
+func (d *Decoder) ServerIDFromPacket(packet []byte) ([]byte, error) {
+	if len(packet) == 0 {
+		return nil, errors.New("quiclb: empty packet")
+	}
+	if packet[0]&0x80 == 0 { // short header packet
+		return d.ServerID(packet[1:])
+	}
+	// Long header packets: 1 byte flags, 4 bytes version, 1 byte connection ID length
+	if len(packet) < 6 {
+		return nil, errors.New("quiclb: packet too short")
+	}
+	connIDLen := int(packet[5])
+	if len(packet) < 6+connIDLen {
+		return nil, errors.New("quiclb: packet too short")
+	}
+	connID := packet[6 : 6+connIDLen]
+	serverID, err := d.ServerID(connID)
+	if err != nil {
+		return nil, err
+	}
+	// The length of a server-chosen connection ID is determined by the config.
+	if connIDLen != d.configs[connID[0]>>5].config.ConnectionIDLen() {
+		return nil, ErrUnroutable
+	}
+	return serverID, nil
+}
@@ -0,0 +1,193 @@
+package quiclb
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decoder", func() {
+	serverID := []byte{0xde, 0xca, 0xfb, 0xad}
+
+	configs := map[string]*Config{
+		"plaintext":              {ConfigID: 0, ServerIDLen: 4, NonceLen: 6},
+		"single-pass":            {ConfigID: 1, ServerIDLen: 4, NonceLen: 12, Key: bytes.Repeat([]byte{1}, 16)},
+		"four-pass, even length": {ConfigID: 2, ServerIDLen: 4, NonceLen: 6, Key: bytes.Repeat([]byte{2}, 16)},
+		"four-pass, odd length":  {ConfigID: 3, ServerIDLen: 4, NonceLen: 5, Key: bytes.Repeat([]byte{3}, 16)},
+	}
+
+	for n, c := range configs {
+		name := n
+		conf := c
+
+		It("decodes "+name+" connection IDs", func() {
+			g, err := NewGenerator(conf, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			d, err := NewDecoder(conf)
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < 100; i++ {
+				connID, err := g.GenerateConnectionID()
+				Expect(err).ToNot(HaveOccurred())
+				sid, err := d.ServerID(connID.Bytes())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sid).To(Equal(serverID))
+			}
+		})
+	}
+
+	It("uses multiple configs at the same time", func() {
+		var confs []*Config
+		for _, conf := range configs {
+			confs = append(confs, conf)
+		}
+		d, err := NewDecoder(confs...)
+		Expect(err).ToNot(HaveOccurred())
+		for _, conf := range confs {
+			g, err := NewGenerator(conf, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			sid, err := d.ServerID(connID.Bytes())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sid).To(Equal(serverID))
+		}
+	})
+
+	It("rejects duplicate config IDs", func() {
+		_, err := NewDecoder(&Config{ConfigID: 1, ServerIDLen: 1, NonceLen: 4}, &Config{ConfigID: 1, ServerIDLen: 2, NonceLen: 4})
+		Expect(err).To(MatchError("quiclb: duplicate config ID: 1"))
+	})
+
+	It("rejects unroutable connection IDs", func() {
+		d, err := NewDecoder(configs["plaintext"])
+		Expect(err).ToNot(HaveOccurred())
+		_, err = d.ServerID(nil)
+		Expect(err).To(MatchError(ErrUnroutable))
+		// config ID 0b111
+		_, err = d.ServerID([]byte{0xe0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+		Expect(err).To(MatchError(ErrUnroutable))
+		// unknown config ID
+		_, err = d.ServerID([]byte{0x20, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+		Expect(err).To(MatchError(ErrUnroutable))
+		// too short
+		_, err = d.ServerID([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+		Expect(err).To(MatchError(ErrUnroutable))
+	})
+
+	Context("decoding packets", func() {
+		var (
+			d      *Decoder
+			connID []byte
+		)
+
+		BeforeEach(func() {
+			conf := configs["four-pass, odd length"]
+			var err error
+			d, err = NewDecoder(conf)
+			Expect(err).ToNot(HaveOccurred())
+			g, err := NewGenerator(conf, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			c, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			connID = c.Bytes()
+		})
+
+		It("decodes short header packets", func() {
+			packet := append([]byte{0x40}, connID...)
+			packet = append(packet, []byte("payload")...)
+			sid, err := d.ServerIDFromPacket(packet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sid).To(Equal(serverID))
+		})
+
+		It("decodes long header packets", func() {
+			packet := []byte{0xc0, 0, 0, 0, 1, byte(len(connID))}
+			packet = append(packet, connID...)
+			packet = append(packet, 8, 1, 2, 3, 4, 5, 6, 7, 8) // source connection ID
+			sid, err := d.ServerIDFromPacket(packet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sid).To(Equal(serverID))
+		})
+
+		It("rejects long header packets with a connection ID of the wrong length", func() {
+			packet := []byte{0xc0, 0, 0, 0, 1, byte(len(connID) + 1)}
+			packet = append(packet, connID...)
+			packet = append(packet, 0)
+			_, err := d.ServerIDFromPacket(packet)
+			Expect(err).To(MatchError(ErrUnroutable))
+		})
+
+		It("rejects packets that are too short", func() {
+			_, err := d.ServerIDFromPacket(nil)
+			Expect(err).To(MatchError("quiclb: empty packet"))
+			_, err = d.ServerIDFromPacket([]byte{0xc0, 0, 0, 0, 1})
+			Expect(err).To(MatchError("quiclb: packet too short"))
+			_, err = d.ServerIDFromPacket([]byte{0xc0, 0, 0, 0, 1, 8, 1, 2})
+			Expect(err).To(MatchError("quiclb: packet too short"))
+		})
+	})
+
+	// test vectors from draft-ietf-quic-load-balancers, Appendix B
+	Context("test vectors", func() {
+		const key = "8f95f09245765f80256934e50c66207f"
+
+		for _, v := range []struct {
+			name     string
+			configID uint8
+			serverID string
+			nonce    string
+			key      string
+			connID   string
+		}{
+			{name: "plaintext", configID: 0, serverID: "c4605e", nonce: "4504cc4f", connID: "07c4605e4504cc4f"},
+			{name: "four-pass, odd length", configID: 0, serverID: "ed793a", nonce: "ee080dbf", key: key, connID: "0720b1d07b359d3c"},
+			{name: "four-pass, odd length", configID: 1, serverID: "ed793a51d49b8f5fab65", nonce: "ee080dbf48", key: key, connID: "2fcc381bc74cb4fbad2823a3d1f8fed2"},
+			{name: "single-pass", configID: 2, serverID: "ed793a51d49b8f5f", nonce: "ee080dbf48c0d1e5", key: key, connID: "504dd2d05a7b0de9b2b9907afb5ecf8cc3"},
+			{name: "four-pass, even length", configID: 0, serverID: "ed793a51d49b8f5fab", nonce: "ee080dbf48c0d1e55d", key: key, connID: "125779c9cc86beb3a3a4a3ca96fce4bfe0cdbc"},
+		} {
+			vector := v
+
+			It(fmt.Sprintf("%s, server ID %s", vector.name, vector.serverID), func() {
+				serverID, err := hex.DecodeString(vector.serverID)
+				Expect(err).ToNot(HaveOccurred())
+				nonce, err := hex.DecodeString(vector.nonce)
+				Expect(err).ToNot(HaveOccurred())
+				connID, err := hex.DecodeString(vector.connID)
+				Expect(err).ToNot(HaveOccurred())
+				var key []byte
+				if vector.key != "" {
+					key, err = hex.DecodeString(vector.key)
+					Expect(err).ToNot(HaveOccurred())
+				}
+				conf := &Config{
+					ConfigID:           vector.configID,
+					ServerIDLen:        len(serverID),
+					NonceLen:           len(nonce),
+					Key:                key,
+					LengthSelfEncoding: true,
+				}
+				Expect(conf.ConnectionIDLen()).To(Equal(len(connID)))
+				Expect(connID[0]).To(Equal(vector.configID<<5 | byte(len(connID)-1)))
+
+				// encode
+				c, err := conf.newCipher()
+				Expect(err).ToNot(HaveOccurred())
+				encoded := append(append([]byte{}, serverID...), nonce...)
+				if c != nil {
+					c.Encrypt(encoded, encoded)
+				}
+				Expect(encoded).To(Equal(connID[1:]))
+
+				// decode
+				d, err := NewDecoder(conf)
+				Expect(err).ToNot(HaveOccurred())
+				sid, err := d.ServerID(connID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sid).To(Equal(serverID))
+			})
+		}
+	})
+})
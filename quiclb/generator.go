@@ -0,0 +1,63 @@
+package quiclb
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/quic-go/quic-go"
+)
+
+// A Generator generates QUIC-LB connection IDs encoding a server ID.
+// It implements the quic.ConnectionIDGenerator interface.
+type Generator struct {
+	config   Config
+	serverID []byte
+	cipher   *lbCipher
+}
+
+var _ quic.ConnectionIDGenerator = &Generator{}
+
+// NewGenerator creates a new Generator for the given server ID.
+// The length of the server ID must equal the ServerIDLen of the config.
+func NewGenerator(config *Config, serverID []byte) (*Generator, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if len(serverID) != config.ServerIDLen {
+		return nil, fmt.Errorf("quiclb: expected a %d byte server ID, got %d bytes", config.ServerIDLen, len(serverID))
+	}
+	c, err := config.newCipher()
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{
+		config:   *config,
+		serverID: append([]byte(nil), serverID...),
+		cipher:   c,
+	}, nil
+}
+
+// GenerateConnectionID generates a new connection ID, using a random nonce.
+func (g *Generator) GenerateConnectionID() (quic.ConnectionID, error) {
+	b := make([]byte, g.config.ConnectionIDLen())
+	if _, err := rand.Read(b); err != nil {
+		return quic.ConnectionID{}, err
+	}
+	if g.config.LengthSelfEncoding {
+		b[0] = byte(len(b) - 1)
+	} else {
+		b[0] &= 0x1f
+	}
+	b[0] |= g.config.ConfigID << 5
+	// the nonce was already filled with random bytes
+	copy(b[1:], g.serverID)
+	if g.cipher != nil {
+		g.cipher.Encrypt(b[1:], b[1:])
+	}
+	return quic.ConnectionIDFromBytes(b), nil
+}
+
+// ConnectionIDLen returns the length of the generated connection IDs.
+func (g *Generator) ConnectionIDLen() int {
+	return g.config.ConnectionIDLen()
+}
@@ -0,0 +1,58 @@
+package quiclb
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generator", func() {
+	It("rejects server IDs of the wrong length", func() {
+		_, err := NewGenerator(&Config{ServerIDLen: 2, NonceLen: 4}, []byte{1, 2, 3})
+		Expect(err).To(MatchError("quiclb: expected a 2 byte server ID, got 3 bytes"))
+	})
+
+	It("rejects invalid configs", func() {
+		_, err := NewGenerator(&Config{ServerIDLen: 2, NonceLen: 2}, []byte{1, 2})
+		Expect(err).To(MatchError("quiclb: nonce too short: 2 bytes"))
+	})
+
+	It("generates plaintext connection IDs", func() {
+		g, err := NewGenerator(&Config{ConfigID: 2, ServerIDLen: 3, NonceLen: 5}, []byte{0xa, 0xb, 0xc})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.ConnectionIDLen()).To(Equal(9))
+		seen := make(map[string]struct{})
+		for i := 0; i < 100; i++ {
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			b := connID.Bytes()
+			Expect(b).To(HaveLen(9))
+			Expect(b[0] >> 5).To(Equal(uint8(2)))
+			Expect(b[1:4]).To(Equal([]byte{0xa, 0xb, 0xc}))
+			seen[string(b)] = struct{}{}
+		}
+		Expect(seen).To(HaveLen(100))
+	})
+
+	It("encodes the length of the connection ID", func() {
+		g, err := NewGenerator(&Config{ConfigID: 1, ServerIDLen: 3, NonceLen: 5, LengthSelfEncoding: true}, []byte{0xa, 0xb, 0xc})
+		Expect(err).ToNot(HaveOccurred())
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Bytes()[0]).To(Equal(byte(1<<5 | 8)))
+	})
+
+	It("encrypts the server ID", func() {
+		conf := &Config{ServerIDLen: 3, NonceLen: 5, Key: bytes.Repeat([]byte{1}, 16)}
+		g, err := NewGenerator(conf, []byte{0xa, 0xb, 0xc})
+		Expect(err).ToNot(HaveOccurred())
+		connID1, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		connID2, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		// the server ID can't be read from the connection IDs, and the connection IDs can't be correlated
+		Expect(connID1.Bytes()[1:4]).ToNot(Equal([]byte{0xa, 0xb, 0xc}))
+		Expect(connID1.Bytes()[1:4]).ToNot(Equal(connID2.Bytes()[1:4]))
+	})
+})
@@ -0,0 +1,13 @@
+package quiclb
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestQuicLB(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "QUIC-LB Suite")
+}
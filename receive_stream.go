@@ -20,6 +20,7 @@ type receiveStreamI interface {
 	handleResetStreamFrame(*wire.ResetStreamFrame) error
 	closeForShutdown(error)
 	getWindowUpdate() protocol.ByteCount
+	setReceiveWindowSize(protocol.ByteCount)
 }
 
 type receiveStream struct {
@@ -31,6 +32,10 @@ type receiveStream struct {
 
 	frameQueue  *frameSorter
 	finalOffset protocol.ByteCount
+	readOffset  protocol.ByteCount // number of bytes delivered to the application
+	// When the stream was reset using RESET_STREAM_AT,
+	// data up to this offset is still delivered to the application.
+	reliableSize protocol.ByteCount
 
 	currentFrame       []byte
 	currentFrameDone   func()
@@ -45,6 +50,9 @@ type receiveStream struct {
 	cancelledLocally    bool
 	cancelErr           *StreamError
 	closeForShutdownErr error
+	// Set once the flow controller was abandoned after receiving a RESET_STREAM(_AT) frame.
+	// After a reliable reset, this only happens once all data up to the reliable size has been read.
+	flowControllerAbandoned bool
 
 	readChan chan struct{}
 	readOnce chan struct{} // cap: 1, to protect against concurrent use of Read
@@ -123,7 +131,7 @@ func (s *receiveStream) readImpl(p []byte) (int, error) {
 		s.errorRead = true
 		return 0, io.EOF
 	}
-	if s.cancelledRemotely || s.cancelledLocally {
+	if s.isCancelled() {
 		s.errorRead = true
 		return 0, s.cancelErr
 	}
@@ -133,6 +141,11 @@ func (s *receiveStream) readImpl(p []byte) (int, error) {
 
 	var bytesRead int
 	var deadlineTimer *utils.Timer
+	defer func() {
+		if deadlineTimer != nil {
+			deadlineTimer.Stop()
+		}
+	}()
 	for bytesRead < len(p) {
 		if s.currentFrame == nil || s.readPosInFrame >= len(s.currentFrame) {
 			s.dequeueNextFrame()
@@ -141,46 +154,8 @@ func (s *receiveStream) readImpl(p []byte) (int, error) {
 			return bytesRead, s.closeForShutdownErr
 		}
 
-		for {
-			// Stop waiting on errors
-			if s.closeForShutdownErr != nil {
-				return bytesRead, s.closeForShutdownErr
-			}
-			if s.cancelledRemotely || s.cancelledLocally {
-				s.errorRead = true
-				return 0, s.cancelErr
-			}
-
-			deadline := s.deadline
-			if !deadline.IsZero() {
-				if !time.Now().Before(deadline) {
-					return bytesRead, errDeadline
-				}
-				if deadlineTimer == nil {
-					deadlineTimer = utils.NewTimer()
-					defer deadlineTimer.Stop()
-				}
-				deadlineTimer.Reset(deadline)
-			}
-
-			if s.currentFrame != nil || s.currentFrameIsLast {
-				break
-			}
-
-			s.mutex.Unlock()
-			if deadline.IsZero() {
-				<-s.readChan
-			} else {
-				select {
-				case <-s.readChan:
-				case <-deadlineTimer.Chan():
-					deadlineTimer.SetRead()
-				}
-			}
-			s.mutex.Lock()
-			if s.currentFrame == nil {
-				s.dequeueNextFrame()
-			}
+		if err := s.waitForData(&deadlineTimer); err != nil {
+			return bytesRead, err
 		}
 
 		if bytesRead > len(p) {
@@ -190,16 +165,27 @@ func (s *receiveStream) readImpl(p []byte) (int, error) {
 			return bytesRead, fmt.Errorf("BUG: readPosInFrame (%d) > frame.DataLen (%d) in stream.Read", s.readPosInFrame, len(s.currentFrame))
 		}
 
-		m := copy(p[bytesRead:], s.currentFrame[s.readPosInFrame:])
+		data := s.currentFrame[s.readPosInFrame:]
+		// after a reliable reset, only data up to the reliable size is delivered
+		if s.cancelledRemotely && s.readOffset+protocol.ByteCount(len(data)) > s.reliableSize {
+			data = data[:s.reliableSize-s.readOffset]
+		}
+		m := copy(p[bytesRead:], data)
 		s.readPosInFrame += m
+		s.readOffset += protocol.ByteCount(m)
 		bytesRead += m
 
-		// when a RESET_STREAM was received, the flow controller was already
+		// once the flow controller was abandoned after a RESET_STREAM, it was already
 		// informed about the final byteOffset for this stream
-		if !s.cancelledRemotely {
+		if !s.flowControllerAbandoned {
 			s.flowController.AddBytesRead(protocol.ByteCount(m))
 		}
 
+		if s.cancelledRemotely && s.readOffset >= s.reliableSize {
+			s.abandonFlowController()
+			s.errorRead = true
+			return bytesRead, s.cancelErr
+		}
 		if s.readPosInFrame >= len(s.currentFrame) && s.currentFrameIsLast {
 			s.currentFrame = nil
 			if s.currentFrameDone != nil {
@@ -212,6 +198,134 @@ func (s *receiveStream) readImpl(p []byte) (int, error) {
 	return bytesRead, nil
 }
 
+// waitForData blocks until a frame is available for reading, or an error occurred.
+// The deadline timer is created when it's needed, and needs to be stopped by the caller.
+// It must be called with the mutex held.
+func (s *receiveStream) waitForData(deadlineTimer **utils.Timer) error {
+	for {
+		// Stop waiting on errors
+		if s.closeForShutdownErr != nil {
+			return s.closeForShutdownErr
+		}
+		if s.isCancelled() {
+			s.errorRead = true
+			return s.cancelErr
+		}
+
+		deadline := s.deadline
+		if !deadline.IsZero() {
+			if !time.Now().Before(deadline) {
+				return errDeadline
+			}
+			if *deadlineTimer == nil {
+				*deadlineTimer = utils.NewTimer()
+			}
+			(*deadlineTimer).Reset(deadline)
+		}
+
+		if s.currentFrame != nil || s.currentFrameIsLast {
+			return nil
+		}
+
+		s.mutex.Unlock()
+		if deadline.IsZero() {
+			<-s.readChan
+		} else {
+			select {
+			case <-s.readChan:
+			case <-(*deadlineTimer).Chan():
+				(*deadlineTimer).SetRead()
+			}
+		}
+		s.mutex.Lock()
+		if s.currentFrame == nil {
+			s.dequeueNextFrame()
+		}
+	}
+}
+
+// ReadChunk returns the data of the next received STREAM frame, without copying it.
+func (s *receiveStream) ReadChunk() ([]byte, func(), error) {
+	s.readOnce <- struct{}{}
+	defer func() { <-s.readOnce }()
+
+	s.mutex.Lock()
+	data, release, err := s.readChunkImpl()
+	completed := s.isNewlyCompleted()
+	s.mutex.Unlock()
+
+	if completed {
+		s.sender.onStreamCompleted(s.streamID)
+	}
+	return data, release, err
+}
+
+func (s *receiveStream) readChunkImpl() ([]byte, func(), error) {
+	if s.currentFrameIsLast && s.currentFrame == nil {
+		s.errorRead = true
+		return nil, releaseNothing, io.EOF
+	}
+	if s.isCancelled() {
+		s.errorRead = true
+		return nil, releaseNothing, s.cancelErr
+	}
+	if s.closeForShutdownErr != nil {
+		return nil, releaseNothing, s.closeForShutdownErr
+	}
+
+	if s.currentFrame == nil || s.readPosInFrame >= len(s.currentFrame) {
+		s.dequeueNextFrame()
+	}
+	var deadlineTimer *utils.Timer
+	err := s.waitForData(&deadlineTimer)
+	if deadlineTimer != nil {
+		deadlineTimer.Stop()
+	}
+	if err != nil {
+		return nil, releaseNothing, err
+	}
+
+	data := s.currentFrame[s.readPosInFrame:]
+	// after a reliable reset, only data up to the reliable size is delivered
+	if s.cancelledRemotely && s.readOffset+protocol.ByteCount(len(data)) > s.reliableSize {
+		data = data[:s.reliableSize-s.readOffset]
+	}
+	s.readOffset += protocol.ByteCount(len(data))
+	// once the flow controller was abandoned after a RESET_STREAM, it was already
+	// informed about the final byteOffset for this stream
+	if !s.flowControllerAbandoned {
+		s.flowController.AddBytesRead(protocol.ByteCount(len(data)))
+	}
+	// The ownership of the buffer is transferred to the application.
+	release := releaseNothing
+	if done := s.currentFrameDone; done != nil {
+		var once sync.Once
+		release = func() { once.Do(done) }
+	}
+	s.currentFrame = nil
+	s.currentFrameDone = nil
+	s.readPosInFrame = 0
+
+	if s.cancelledRemotely && s.readOffset >= s.reliableSize {
+		s.abandonFlowController()
+		s.errorRead = true
+		return data, release, s.cancelErr
+	}
+	if s.currentFrameIsLast {
+		s.errorRead = true
+		return data, release, io.EOF
+	}
+	return data, release, nil
+}
+
+func releaseNothing() {}
+
+// isCancelled says if the cancellation error should be returned to the application.
+// After a reliable reset, this is only the case once all data up to the reliable size has been read.
+func (s *receiveStream) isCancelled() bool {
+	return s.cancelledLocally || (s.cancelledRemotely && s.readOffset >= s.reliableSize)
+}
+
 func (s *receiveStream) dequeueNextFrame() {
 	var offset protocol.ByteCount
 	// We're done with the last frame. Release the buffer.
@@ -303,21 +417,45 @@ func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame)
 	}
 	s.finalOffset = frame.FinalSize
 
-	// ignore duplicate RESET_STREAM frames for this stream (after checking their final offset)
+	// ignore duplicate RESET_STREAM frames for this stream (after checking their final offset),
+	// but allow RESET_STREAM_AT frames to reduce the reliable size
 	if s.cancelledRemotely {
+		if frame.ReliableSize < s.reliableSize {
+			s.reliableSize = frame.ReliableSize
+			if s.readOffset >= s.reliableSize {
+				s.abandonFlowController()
+			}
+			s.signalRead()
+		}
 		return nil
 	}
-	s.flowController.Abandon()
 	// don't save the error if the RESET_STREAM frames was received after CancelRead was called
 	if s.cancelledLocally {
+		s.abandonFlowController()
 		return nil
 	}
 	s.cancelledRemotely = true
+	s.reliableSize = frame.ReliableSize
+	// Data up to the reliable size still needs to be read by the application,
+	// and is accounted for by the flow controller as it is read.
+	if s.readOffset >= s.reliableSize {
+		s.abandonFlowController()
+	}
 	s.cancelErr = &StreamError{StreamID: s.streamID, ErrorCode: frame.ErrorCode, Remote: true}
 	s.signalRead()
 	return nil
 }
 
+// abandonFlowController returns all flow control credit of the stream to the connection.
+// It must be called with the mutex held.
+func (s *receiveStream) abandonFlowController() {
+	if s.flowControllerAbandoned {
+		return
+	}
+	s.flowControllerAbandoned = true
+	s.flowController.Abandon()
+}
+
 func (s *receiveStream) SetReadDeadline(t time.Time) error {
 	s.mutex.Lock()
 	s.deadline = t
@@ -340,6 +478,10 @@ func (s *receiveStream) getWindowUpdate() protocol.ByteCount {
 	return s.flowController.GetWindowUpdate()
 }
 
+func (s *receiveStream) setReceiveWindowSize(size protocol.ByteCount) {
+	s.flowController.SetReceiveWindowSize(size)
+}
+
 // signalRead performs a non-blocking send on the readChan
 func (s *receiveStream) signalRead() {
 	select {
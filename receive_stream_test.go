@@ -218,6 +218,81 @@ var _ = Describe("Receive Stream", func() {
 			Expect(b).To(Equal([]byte("foobar")))
 		})
 
+		Context("reading chunks", func() {
+			It("returns the data without copying it", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(8), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+				frame1 := &wire.StreamFrame{Data: []byte("foob")}
+				frame2 := &wire.StreamFrame{Offset: 4, Data: []byte("arba")}
+				Expect(str.handleStreamFrame(frame1)).To(Succeed())
+				Expect(str.handleStreamFrame(frame2)).To(Succeed())
+				data, release, err := str.ReadChunk()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("foob")))
+				Expect(&data[0]).To(BeIdenticalTo(&frame1.Data[0]))
+				release()
+				release() // calling release multiple times is a no-op
+				data, release, err = str.ReadChunk()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("arba")))
+				Expect(&data[0]).To(BeIdenticalTo(&frame2.Data[0]))
+				release()
+			})
+
+			It("returns the remainder of a partially read frame", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				b := make([]byte, 2)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(2))
+				data, release, err := str.ReadChunk()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal([]byte("obar")))
+				release()
+			})
+
+			It("blocks until data is received", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(3), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					data, _, err := str.ReadChunk()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(data).To(Equal([]byte("foo")))
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foo")})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("returns io.EOF with the last chunk", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(3), true)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foo"), Fin: true})).To(Succeed())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				data, release, err := str.ReadChunk()
+				Expect(err).To(MatchError(io.EOF))
+				Expect(data).To(Equal([]byte("foo")))
+				release()
+				data, _, err = str.ReadChunk()
+				Expect(err).To(MatchError(io.EOF))
+				Expect(data).To(BeEmpty())
+			})
+
+			It("respects the read deadline", func() {
+				str.SetReadDeadline(time.Now().Add(scaleDuration(20 * time.Millisecond)))
+				_, _, err := str.ReadChunk()
+				Expect(err).To(MatchError(errDeadline))
+			})
+		})
+
 		Context("deadlines", func() {
 			It("the deadline error has the right net.Error properties", func() {
 				Expect(errDeadline.Timeout()).To(BeTrue())
@@ -703,6 +778,108 @@ var _ = Describe("Receive Stream", func() {
 				Expect(streamErr.Remote).To(BeFalse())
 			})
 		})
+		Context("receiving RESET_STREAM_AT frames", func() {
+			rst := &wire.ResetStreamFrame{
+				StreamID:     streamID,
+				FinalSize:    42,
+				ErrorCode:    1234,
+				ReliableSize: 6,
+			}
+			streamErr := &StreamError{StreamID: streamID, ErrorCode: 1234, Remote: true}
+
+			It("delivers data up to the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{
+					StreamID: streamID,
+					Data:     []byte("foobarbaz!"),
+				})).To(Succeed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				// the flow controller is only abandoned once the data up to the reliable size was read
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6)),
+					mockFC.EXPECT().Abandon(),
+				)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b := make([]byte, 20)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(Equal(streamErr))
+				Expect(b[:n]).To(Equal([]byte("foobar")))
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(Equal(streamErr))
+			})
+
+			It("blocks Read until the data up to the reliable size was received", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					b := make([]byte, 20)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(Equal(streamErr))
+					Expect(b[:n]).To(Equal([]byte("foobar")))
+					close(done)
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6)),
+					mockFC.EXPECT().Abandon(),
+				)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{
+					StreamID: streamID,
+					Data:     []byte("foobarbaz!"),
+				})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("allows reducing the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{
+					StreamID: streamID,
+					Data:     []byte("foobarbaz!"),
+				})).To(Succeed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true).Times(3)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				smaller := *rst
+				smaller.ReliableSize = 3
+				Expect(str.handleResetStreamFrame(&smaller)).To(Succeed())
+				// the reliable size can't be increased again
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3)),
+					mockFC.EXPECT().Abandon(),
+				)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b := make([]byte, 20)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(Equal(streamErr))
+				Expect(b[:n]).To(Equal([]byte("foo")))
+			})
+
+			It("abandons the flow controller right away if the data up to the reliable size was already read", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{
+					StreamID: streamID,
+					Data:     []byte("foobarbaz!"),
+				})).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(8))
+				b := make([]byte, 8)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(8))
+				gomock.InOrder(
+					mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(42), true),
+					mockFC.EXPECT().Abandon(),
+				)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamFrame(rst)).To(Succeed())
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(Equal(streamErr))
+			})
+		})
 	})
 
 	Context("flow control", func() {
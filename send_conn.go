@@ -10,6 +10,12 @@ import (
 // A sendConn allows sending using a simple Write() on a non-connected packet conn.
 type sendConn interface {
 	Write(b []byte, gsoSize uint16, ecn protocol.ECN) error
+	// WriteTo sends a packet to a remote address other than the current remote address.
+	// It is used for probing new paths.
+	WriteTo(b []byte, addr net.Addr) error
+	// ChangeRemoteAddr changes the remote address, after the peer migrated to a new path.
+	// It must not be called concurrently with Write.
+	ChangeRemoteAddr(addr net.Addr, info packetInfo)
 	Close() error
 	LocalAddr() net.Addr
 	RemoteAddr() net.Addr
@@ -82,6 +88,15 @@ func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error {
 	return err
 }
 
+func (c *sconn) WriteTo(p []byte, addr net.Addr) error {
+	_, err := c.WritePacket(p, addr, c.packetInfoOOB, 0, protocol.ECNUnsupported)
+	return err
+}
+
+func (c *sconn) ChangeRemoteAddr(addr net.Addr, info packetInfo) {
+	*c = *newSendConn(c.rawConn, addr, info, c.logger)
+}
+
 func (c *sconn) writePacket(p []byte, addr net.Addr, oob []byte, gsoSize uint16, ecn protocol.ECN) error {
 	_, err := c.WritePacket(p, addr, oob, gsoSize, ecn)
 	if err != nil && !c.wroteFirstPacket && isPermissionError(err) {
@@ -2,6 +2,7 @@ package quic
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -36,9 +37,19 @@ type sendStream struct {
 	sender   streamSender
 
 	writeOffset protocol.ByteCount
+	// the number of bytes that were retransmitted
+	bytesRetransmitted protocol.ByteCount
+	// all data below ackedOffset has been acknowledged
+	ackedOffset protocol.ByteCount
+	// acknowledged ranges above ackedOffset, sorted by offset
+	ackedRanges []byteInterval
+	ackWaiters  []ackWaiter
 
 	cancelWriteErr      error
 	closeForShutdownErr error
+	// When the stream was reset using RESET_STREAM_AT,
+	// data up to this offset is still delivered reliably.
+	reliableSize protocol.ByteCount
 
 	finishedWriting bool // set once Close() is called
 	finSent         bool // set when a STREAM_FRAME with FIN bit has been sent
@@ -49,15 +60,25 @@ type sendStream struct {
 	completed           bool // set when this stream has been reported to the streamSender as completed
 
 	dataForWriting []byte // during a Write() call, this slice is the part of p that still needs to be sent out
-	nextFrame      *wire.StreamFrame
+	// set during a WriteOwned() call: STREAM frames reference dataForWriting instead of copying it
+	dataForWritingOwned bool
+	nextFrame           *wire.StreamFrame
 
 	writeChan chan struct{}
 	writeOnce chan struct{}
 	deadline  time.Time
 
+	priorityMutex sync.Mutex     // serializes calls to SetPriority
+	priority      StreamPriority // protected by mutex
+
 	flowController flowcontrol.StreamFlowController
 }
 
+type ackWaiter struct {
+	offset protocol.ByteCount
+	done   chan struct{}
+}
+
 var (
 	_ SendStream  = &sendStream{}
 	_ sendStreamI = &sendStream{}
@@ -75,6 +96,7 @@ func newSendStream(
 		flowController: flowController,
 		writeChan:      make(chan struct{}, 1),
 		writeOnce:      make(chan struct{}, 1), // cap: 1, to protect against concurrent use of Write
+		priority:       defaultStreamPriority,
 	}
 	s.ctx, s.ctxCancel = context.WithCancelCause(ctx)
 	return s
@@ -91,14 +113,25 @@ func (s *sendStream) Write(p []byte) (int, error) {
 	s.writeOnce <- struct{}{}
 	defer func() { <-s.writeOnce }()
 
-	isNewlyCompleted, n, err := s.write(p)
+	isNewlyCompleted, n, err := s.write(p, false)
 	if isNewlyCompleted {
 		s.sender.onStreamCompleted(s.streamID)
 	}
 	return n, err
 }
 
-func (s *sendStream) write(p []byte) (bool /* is newly completed */, int, error) {
+func (s *sendStream) WriteOwned(p []byte) (int, error) {
+	s.writeOnce <- struct{}{}
+	defer func() { <-s.writeOnce }()
+
+	isNewlyCompleted, n, err := s.write(p, true)
+	if isNewlyCompleted {
+		s.sender.onStreamCompleted(s.streamID)
+	}
+	return n, err
+}
+
+func (s *sendStream) write(p []byte, owned bool) (bool /* is newly completed */, int, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
@@ -120,6 +153,8 @@ func (s *sendStream) write(p []byte) (bool /* is newly completed */, int, error)
 	}
 
 	s.dataForWriting = p
+	s.dataForWritingOwned = owned
+	defer func() { s.dataForWritingOwned = false }()
 
 	var (
 		deadlineTimer  *utils.Timer
@@ -134,7 +169,8 @@ func (s *sendStream) write(p []byte) (bool /* is newly completed */, int, error)
 		// This allows us to return Write() when all data but x bytes have been sent out.
 		// When the user now calls Close(), this is much more likely to happen before we popped that last STREAM frame,
 		// allowing us to set the FIN bit on that frame (instead of sending an empty STREAM frame with FIN).
-		if s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
+		// Data passed to WriteOwned is never copied, so we have to wait until all of it has been popped.
+		if !owned && s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
 			if s.nextFrame == nil {
 				f := wire.GetStreamFrame()
 				f.Offset = s.writeOffset
@@ -231,7 +267,7 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount, v protocol.Vers
 }
 
 func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCount, v protocol.Version) (*wire.StreamFrame, bool /* has more data to send */) {
-	if s.cancelWriteErr != nil || s.closeForShutdownErr != nil {
+	if s.closeForShutdownErr != nil || (s.cancelWriteErr != nil && s.reliableSize == 0) {
 		return nil, false
 	}
 
@@ -247,6 +283,11 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		}
 	}
 
+	// After a reliable reset, only the data buffered in nextFrame (up to the reliable size) is sent.
+	if s.cancelWriteErr != nil && s.nextFrame == nil {
+		return nil, false
+	}
+
 	if len(s.dataForWriting) == 0 && s.nextFrame == nil {
 		if s.finishedWriting && !s.finSent {
 			s.finSent = true
@@ -277,6 +318,9 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		s.writeOffset += f.DataLen()
 		s.flowController.AddBytesSent(f.DataLen())
 	}
+	if s.cancelWriteErr != nil {
+		return f, s.nextFrame != nil
+	}
 	f.Fin = s.finishedWriting && s.dataForWriting == nil && s.nextFrame == nil && !s.finSent
 	if f.Fin {
 		s.finSent = true
@@ -304,7 +348,13 @@ func (s *sendStream) popNewStreamFrame(maxBytes, sendWindow protocol.ByteCount,
 		return nextFrame, s.nextFrame != nil || s.dataForWriting != nil
 	}
 
-	f := wire.GetStreamFrame()
+	var f *wire.StreamFrame
+	if s.dataForWritingOwned {
+		// The frame references the application's buffer, so it must not be returned to the pool.
+		f = &wire.StreamFrame{}
+	} else {
+		f = wire.GetStreamFrame()
+	}
 	f.Fin = false
 	f.StreamID = s.streamID
 	f.Offset = s.writeOffset
@@ -333,9 +383,13 @@ func (s *sendStream) maybeGetRetransmission(maxBytes protocol.ByteCount, v proto
 	f := s.retransmissionQueue[0]
 	newFrame, needsSplit := f.MaybeSplitOffFrame(maxBytes, v)
 	if needsSplit {
+		if newFrame != nil {
+			s.bytesRetransmitted += newFrame.DataLen()
+		}
 		return newFrame, true
 	}
 	s.retransmissionQueue = s.retransmissionQueue[1:]
+	s.bytesRetransmitted += f.DataLen()
 	return f, len(s.retransmissionQueue) > 0
 }
 
@@ -347,6 +401,17 @@ func (s *sendStream) hasData() bool {
 }
 
 func (s *sendStream) getDataForWriting(f *wire.StreamFrame, maxBytes protocol.ByteCount) {
+	if s.dataForWritingOwned {
+		n := min(protocol.ByteCount(len(s.dataForWriting)), maxBytes)
+		f.Data = s.dataForWriting[:n:n]
+		if n == protocol.ByteCount(len(s.dataForWriting)) {
+			s.dataForWriting = nil
+		} else {
+			s.dataForWriting = s.dataForWriting[n:]
+		}
+		s.signalWrite()
+		return
+	}
 	if protocol.ByteCount(len(s.dataForWriting)) <= maxBytes {
 		f.Data = f.Data[:len(s.dataForWriting)]
 		copy(f.Data, s.dataForWriting)
@@ -362,6 +427,75 @@ func (s *sendStream) getDataForWriting(f *wire.StreamFrame, maxBytes protocol.By
 	}
 }
 
+// bytesWritten returns the number of bytes that were accepted from Write calls.
+func (s *sendStream) bytesWritten() protocol.ByteCount {
+	written := s.writeOffset
+	if s.nextFrame != nil {
+		written += s.nextFrame.DataLen()
+	}
+	return written
+}
+
+func (s *sendStream) Stats() SendStreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return SendStreamStats{
+		BytesWritten:       uint64(s.bytesWritten()),
+		BytesSent:          uint64(s.writeOffset),
+		BytesAcked:         uint64(s.ackedOffset),
+		BytesRetransmitted: uint64(s.bytesRetransmitted),
+	}
+}
+
+func (s *sendStream) NotifyAcked(offset int64) <-chan struct{} {
+	done := make(chan struct{})
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if protocol.ByteCount(offset) <= s.ackedOffset {
+		close(done)
+		return done
+	}
+	s.ackWaiters = append(s.ackWaiters, ackWaiter{offset: protocol.ByteCount(offset), done: done})
+	return done
+}
+
+// onDataAcked records that the data in the interval [start, end) was acknowledged.
+// It must be called with the mutex held.
+func (s *sendStream) onDataAcked(start, end protocol.ByteCount) {
+	if end <= s.ackedOffset {
+		return
+	}
+	if start > s.ackedOffset {
+		// insert the interval, keeping the ranges sorted and non-overlapping
+		i := 0
+		for i < len(s.ackedRanges) && s.ackedRanges[i].End < start {
+			i++
+		}
+		j := i
+		for j < len(s.ackedRanges) && s.ackedRanges[j].Start <= end {
+			start = min(start, s.ackedRanges[j].Start)
+			end = max(end, s.ackedRanges[j].End)
+			j++
+		}
+		s.ackedRanges = append(s.ackedRanges[:i], append([]byteInterval{{Start: start, End: end}}, s.ackedRanges[j:]...)...)
+		return
+	}
+	s.ackedOffset = end
+	for len(s.ackedRanges) > 0 && s.ackedRanges[0].Start <= s.ackedOffset {
+		s.ackedOffset = max(s.ackedOffset, s.ackedRanges[0].End)
+		s.ackedRanges = s.ackedRanges[1:]
+	}
+	waiters := s.ackWaiters[:0]
+	for _, w := range s.ackWaiters {
+		if w.offset <= s.ackedOffset {
+			close(w.done)
+			continue
+		}
+		waiters = append(waiters, w)
+	}
+	s.ackWaiters = waiters
+}
+
 func (s *sendStream) isNewlyCompleted() bool {
 	if s.completed {
 		return false
@@ -370,6 +504,10 @@ func (s *sendStream) isNewlyCompleted() bool {
 	if s.numOutstandingFrames > 0 || len(s.retransmissionQueue) > 0 {
 		return false
 	}
+	// After a reliable reset, all data up to the reliable size needs to be sent.
+	if s.cancelWriteErr != nil && s.reliableSize > 0 && s.nextFrame != nil {
+		return false
+	}
 	// The stream is completed if we sent the FIN.
 	if s.finSent {
 		s.completed = true
@@ -414,34 +552,95 @@ func (s *sendStream) Close() error {
 }
 
 func (s *sendStream) CancelWrite(errorCode StreamErrorCode) {
-	s.cancelWriteImpl(errorCode, false)
+	s.cancelWriteImpl(errorCode, false, 0)
+}
+
+func (s *sendStream) CancelWriteAt(errorCode StreamErrorCode, reliableSize int64) error {
+	if reliableSize < 0 {
+		return fmt.Errorf("invalid reliable size: %d", reliableSize)
+	}
+	if reliableSize > 0 && !s.sender.supportsResetStreamAt() {
+		return errors.New("reliable stream reset not negotiated")
+	}
+	return s.cancelWriteImpl(errorCode, false, protocol.ByteCount(reliableSize))
 }
 
-func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool) {
+func (s *sendStream) cancelWriteImpl(errorCode qerr.StreamErrorCode, remote bool, reliableSize protocol.ByteCount) error {
 	s.mutex.Lock()
+	if s.cancelWriteErr == nil && reliableSize > 0 {
+		if written := s.bytesWritten(); reliableSize > written {
+			s.mutex.Unlock()
+			return fmt.Errorf("reliable size (%d) larger than the number of bytes written (%d)", reliableSize, written)
+		}
+	}
 	if !remote {
 		s.cancellationFlagged = true
 	}
 	if s.cancelWriteErr != nil {
 		s.mutex.Unlock()
-		return
+		return nil
 	}
 	s.cancelWriteErr = &StreamError{StreamID: s.streamID, ErrorCode: errorCode, Remote: remote}
 	s.ctxCancel(s.cancelWriteErr)
-	s.numOutstandingFrames = 0
-	s.retransmissionQueue = nil
+	// Data that hasn't been sent yet wasn't checked against flow control.
+	// The final size must not exceed the flow control limit, so the reliable size is capped.
+	if reliableSize > s.writeOffset {
+		reliableSize = min(reliableSize, s.writeOffset+s.flowController.SendWindowSize())
+	}
+	s.reliableSize = reliableSize
+	finalSize := s.writeOffset
+	var hasStreamData bool
+	if reliableSize == 0 {
+		s.numOutstandingFrames = 0
+		s.retransmissionQueue = nil
+	} else {
+		s.truncateToReliableSize()
+		finalSize = max(finalSize, reliableSize)
+		hasStreamData = s.nextFrame != nil || len(s.retransmissionQueue) > 0
+	}
 	newlyCompleted := s.isNewlyCompleted()
 	s.mutex.Unlock()
 
 	s.signalWrite()
 	s.sender.queueControlFrame(&wire.ResetStreamFrame{
-		StreamID:  s.streamID,
-		FinalSize: s.writeOffset,
-		ErrorCode: errorCode,
+		StreamID:     s.streamID,
+		FinalSize:    finalSize,
+		ErrorCode:    errorCode,
+		ReliableSize: reliableSize,
 	})
+	if hasStreamData {
+		s.sender.onHasStreamData(s.streamID)
+	}
 	if newlyCompleted {
 		s.sender.onStreamCompleted(s.streamID)
 	}
+	return nil
+}
+
+// truncateToReliableSize drops all data beyond the reliable size
+// from the retransmission queue and the buffered STREAM frame.
+func (s *sendStream) truncateToReliableSize() {
+	queue := s.retransmissionQueue[:0]
+	for _, f := range s.retransmissionQueue {
+		if f.Offset >= s.reliableSize {
+			f.PutBack()
+			continue
+		}
+		if f.Offset+f.DataLen() > s.reliableSize {
+			f.Data = f.Data[:s.reliableSize-f.Offset]
+		}
+		f.Fin = false
+		queue = append(queue, f)
+	}
+	s.retransmissionQueue = queue
+	if s.nextFrame != nil {
+		if s.nextFrame.Offset >= s.reliableSize {
+			s.nextFrame.PutBack()
+			s.nextFrame = nil
+		} else if s.nextFrame.Offset+s.nextFrame.DataLen() > s.reliableSize {
+			s.nextFrame.Data = s.nextFrame.Data[:s.reliableSize-s.nextFrame.Offset]
+		}
+	}
 }
 
 func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
@@ -458,7 +657,7 @@ func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 }
 
 func (s *sendStream) handleStopSendingFrame(frame *wire.StopSendingFrame) {
-	s.cancelWriteImpl(frame.ErrorCode, true)
+	s.cancelWriteImpl(frame.ErrorCode, true, 0)
 }
 
 func (s *sendStream) Context() context.Context {
@@ -473,6 +672,28 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetPriority(p StreamPriority) {
+	p.Urgency = min(p.Urgency, maxStreamUrgency)
+	// Serialize concurrent calls, such that the stream sender is informed about
+	// priority changes in the same order as they are applied to the stream.
+	s.priorityMutex.Lock()
+	defer s.priorityMutex.Unlock()
+
+	s.mutex.Lock()
+	changed := s.priority != p
+	s.priority = p
+	s.mutex.Unlock()
+	if changed {
+		s.sender.onStreamPriorityChanged(s.streamID, p) // must be called without holding the mutex
+	}
+}
+
+func (s *sendStream) Priority() StreamPriority {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.priority
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
@@ -497,12 +718,14 @@ var _ ackhandler.FrameHandler = &sendStreamAckHandler{}
 
 func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
+	start, end := sf.Offset, sf.Offset+sf.DataLen()
 	sf.PutBack()
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
+	(*sendStream)(s).onDataAcked(start, end)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
@@ -518,16 +741,33 @@ func (s *sendStreamAckHandler) OnAcked(f wire.Frame) {
 func (s *sendStreamAckHandler) OnLost(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	s.mutex.Lock()
-	if s.cancelWriteErr != nil {
+	if s.cancelWriteErr != nil && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
-	sf.DataLenPresent = true
-	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
 	}
+	// After a reliable reset, data beyond the reliable size is not retransmitted.
+	if s.cancelWriteErr != nil && sf.Offset >= s.reliableSize {
+		sf.PutBack()
+		newlyCompleted := (*sendStream)(s).isNewlyCompleted()
+		s.mutex.Unlock()
+
+		if newlyCompleted {
+			s.sender.onStreamCompleted(s.streamID)
+		}
+		return
+	}
+	if s.cancelWriteErr != nil {
+		if sf.Offset+sf.DataLen() > s.reliableSize {
+			sf.Data = sf.Data[:s.reliableSize-sf.Offset]
+		}
+		sf.Fin = false
+	}
+	sf.DataLenPresent = true
+	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.mutex.Unlock()
 
 	s.sender.onHasStreamData(s.streamID)
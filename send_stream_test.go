@@ -7,6 +7,7 @@ import (
 	"io"
 	mrand "math/rand"
 	"runtime"
+	"sync"
 	"time"
 
 	"golang.org/x/exp/rand"
@@ -668,6 +669,45 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("priorities", func() {
+		It("uses the default priority", func() {
+			Expect(str.Priority()).To(Equal(StreamPriority{Urgency: 3, Incremental: true}))
+		})
+
+		It("informs the stream sender when the priority changes", func() {
+			mockSender.EXPECT().onStreamPriorityChanged(streamID, StreamPriority{Urgency: 1})
+			str.SetPriority(StreamPriority{Urgency: 1})
+			Expect(str.Priority()).To(Equal(StreamPriority{Urgency: 1}))
+			// no change, no call to the stream sender
+			str.SetPriority(StreamPriority{Urgency: 1})
+		})
+
+		It("informs the stream sender about concurrent priority changes in order", func() {
+			var last StreamPriority
+			mockSender.EXPECT().onStreamPriorityChanged(streamID, gomock.Any()).Do(func(_ protocol.StreamID, p StreamPriority) {
+				last = p
+			}).AnyTimes()
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func(urgency uint8) {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						str.SetPriority(StreamPriority{Urgency: urgency, Incremental: j%2 == 0})
+					}
+				}(uint8(i))
+			}
+			wg.Wait()
+			Expect(last).To(Equal(str.Priority()))
+		})
+
+		It("limits the urgency", func() {
+			mockSender.EXPECT().onStreamPriorityChanged(streamID, StreamPriority{Urgency: 7, Incremental: true})
+			str.SetPriority(StreamPriority{Urgency: 42, Incremental: true})
+			Expect(str.Priority()).To(Equal(StreamPriority{Urgency: 7, Incremental: true}))
+		})
+	})
+
 	Context("handling MAX_STREAM_DATA frames", func() {
 		It("informs the flow controller", func() {
 			mockFC.EXPECT().UpdateSendWindow(protocol.ByteCount(0x1337))
@@ -895,6 +935,124 @@ var _ = Describe("Send Stream", func() {
 			})
 		})
 
+		Context("canceling writing with a reliable size", func() {
+			var sendWindow protocol.ByteCount
+
+			BeforeEach(func() {
+				sendWindow = protocol.MaxByteCount
+				mockFC.EXPECT().SendWindowSize().DoAndReturn(func() protocol.ByteCount { return sendWindow }).AnyTimes()
+				mockFC.EXPECT().AddBytesSent(gomock.Any()).AnyTimes()
+			})
+
+			It("errors if the extension was not negotiated", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(false)
+				Expect(str.CancelWriteAt(1234, 10)).To(MatchError("reliable stream reset not negotiated"))
+			})
+
+			It("errors if the reliable size is larger than the number of bytes written", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(str.CancelWriteAt(1234, 101)).To(MatchError("reliable size (101) larger than the number of bytes written (100)"))
+			})
+
+			It("queues a RESET_STREAM_AT frame, and sends the data up to the reliable size", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				frame1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+30, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame1.Frame.Data).To(HaveLen(30))
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    80,
+					ReliableSize: 80,
+				})
+				Expect(str.CancelWriteAt(1234, 80)).To(Succeed())
+				Expect(str.Context().Done()).To(BeClosed())
+				_, err = strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(MatchError(&StreamError{StreamID: streamID, ErrorCode: 1234}))
+				frame2, ok, hasMore := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMore).To(BeFalse())
+				Expect(frame2.Frame.Offset).To(Equal(protocol.ByteCount(30)))
+				Expect(frame2.Frame.Data).To(Equal(getData(100)[30:80]))
+				Expect(frame2.Frame.Fin).To(BeFalse())
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				// the stream is completed once all data up to the reliable size has been acknowledged
+				frame1.Handler.OnAcked(frame1.Frame)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame2.Handler.OnAcked(frame2.Frame)
+			})
+
+			It("only retransmits data up to the reliable size", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				frame1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+30, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				frame2, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame2.Frame.Offset).To(Equal(protocol.ByteCount(30)))
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    100,
+					ReliableSize: 20,
+				})
+				Expect(str.CancelWriteAt(1234, 20)).To(Succeed())
+				// frame2 is beyond the reliable size, so it's not retransmitted
+				frame2.Handler.OnLost(frame2.Frame)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				frame1.Handler.OnLost(frame1.Frame)
+				frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame.Frame.Offset).To(BeZero())
+				Expect(frame.Frame.Data).To(Equal(getData(100)[:20]))
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame.Handler.OnAcked(frame.Frame)
+			})
+
+			It("caps the reliable size at the flow control limit", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+				frame1, ok, _ := str.popStreamFrame(expectedFrameHeaderLen(0)+30, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(frame1.Frame.Data).To(HaveLen(30))
+				// only 20 more bytes are allowed by flow control
+				sendWindow = 20
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    50,
+					ReliableSize: 50,
+				})
+				Expect(str.CancelWriteAt(1234, 80)).To(Succeed())
+				frame2, ok, hasMore := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeTrue())
+				Expect(hasMore).To(BeFalse())
+				Expect(frame2.Frame.Offset).To(Equal(protocol.ByteCount(30)))
+				Expect(frame2.Frame.Data).To(Equal(getData(100)[30:50]))
+				_, ok, _ = str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+				Expect(ok).To(BeFalse())
+			})
+
+			It("behaves like CancelWrite if the reliable size is 0", func() {
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{StreamID: streamID, ErrorCode: 1234})
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.CancelWriteAt(1234, 0)).To(Succeed())
+			})
+		})
+
 		Context("receiving STOP_SENDING frames", func() {
 			It("queues a RESET_STREAM frames, and copies the error code from the STOP_SENDING frame", func() {
 				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
@@ -1100,6 +1258,122 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("writing owned buffers", func() {
+		It("sends STREAM frames referencing the buffer", func() {
+			data := getData(100)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				mockSender.EXPECT().onHasStreamData(streamID)
+				n, err := str.WriteOwned(data)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(100))
+			}()
+			waitForWrite()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).Times(2)
+			mockFC.EXPECT().AddBytesSent(gomock.Any()).Times(2)
+			frame1, ok, hasMoreData := str.popStreamFrame(60, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			Expect(hasMoreData).To(BeTrue())
+			Expect(frame1.Frame.Offset).To(BeZero())
+			Expect(&frame1.Frame.Data[0]).To(BeIdenticalTo(&data[0]))
+			// the small remainder is not copied to a buffered frame
+			Consistently(done).ShouldNot(BeClosed())
+			frame2, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			Expect(frame2.Frame.Offset).To(Equal(frame1.Frame.DataLen()))
+			Expect(&frame2.Frame.Data[0]).To(BeIdenticalTo(&data[frame1.Frame.DataLen()]))
+			Expect(append(frame1.Frame.Data, frame2.Frame.Data...)).To(Equal(data))
+			Eventually(done).Should(BeClosed())
+
+			// retransmissions reference the buffer as well
+			mockSender.EXPECT().onHasStreamData(streamID)
+			frame1.Handler.OnLost(frame1.Frame)
+			frame2.Handler.OnAcked(frame2.Frame)
+			frame, ok, _ := str.popStreamFrame(protocol.MaxByteCount, protocol.Version1)
+			Expect(ok).To(BeTrue())
+			Expect(&frame.Frame.Data[0]).To(BeIdenticalTo(&data[0]))
+			frame.Handler.OnAcked(frame.Frame)
+			Expect(str.Stats().BytesAcked).To(BeEquivalentTo(100))
+		})
+	})
+
+	Context("statistics", func() {
+		BeforeEach(func() {
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
+			mockFC.EXPECT().AddBytesSent(gomock.Any()).AnyTimes()
+		})
+
+		popFrames := func(maxSize protocol.ByteCount) []ackhandler.StreamFrame {
+			var frames []ackhandler.StreamFrame
+			for {
+				frame, ok, hasMoreData := str.popStreamFrame(maxSize, protocol.Version1)
+				if ok {
+					frames = append(frames, frame)
+				}
+				if !hasMoreData {
+					return frames
+				}
+			}
+		}
+
+		It("counts written, sent, acknowledged and retransmitted bytes", func() {
+			mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+			_, err := strWithTimeout.Write(getData(100))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Stats()).To(Equal(SendStreamStats{BytesWritten: 100}))
+
+			frames := popFrames(50)
+			Expect(len(frames)).To(BeNumerically(">", 1))
+			Expect(str.Stats().BytesSent).To(BeEquivalentTo(100))
+
+			// lose the first frame, acknowledge all others
+			lost := frames[0]
+			lostLen := lost.Frame.DataLen()
+			lost.Handler.OnLost(lost.Frame)
+			for _, f := range frames[1:] {
+				f.Handler.OnAcked(f.Frame)
+			}
+			Expect(str.Stats()).To(Equal(SendStreamStats{BytesWritten: 100, BytesSent: 100}))
+
+			retransmissions := popFrames(protocol.MaxByteCount)
+			Expect(retransmissions).To(HaveLen(1))
+			Expect(str.Stats().BytesRetransmitted).To(BeEquivalentTo(lostLen))
+			retransmissions[0].Handler.OnAcked(retransmissions[0].Frame)
+			Expect(str.Stats()).To(Equal(SendStreamStats{
+				BytesWritten:       100,
+				BytesSent:          100,
+				BytesAcked:         100,
+				BytesRetransmitted: uint64(lostLen),
+			}))
+		})
+
+		It("notifies when an offset is acknowledged", func() {
+			mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+			Expect(str.NotifyAcked(0)).To(BeClosed())
+			_, err := strWithTimeout.Write(getData(100))
+			Expect(err).ToNot(HaveOccurred())
+			frames := popFrames(30)
+			Expect(len(frames)).To(BeNumerically(">", 2))
+			end := frames[1].Frame.Offset + frames[1].Frame.DataLen()
+			chan1 := str.NotifyAcked(int64(end))
+			chan2 := str.NotifyAcked(100)
+			// acknowledge out of order
+			frames[1].Handler.OnAcked(frames[1].Frame)
+			Expect(chan1).ToNot(BeClosed())
+			frames[0].Handler.OnAcked(frames[0].Frame)
+			Expect(chan1).To(BeClosed())
+			Expect(chan2).ToNot(BeClosed())
+			Expect(str.Stats().BytesAcked).To(BeEquivalentTo(end))
+			for _, f := range frames[2:] {
+				f.Handler.OnAcked(f.Frame)
+			}
+			Expect(chan2).To(BeClosed())
+			Expect(str.NotifyAcked(50)).To(BeClosed())
+		})
+	})
+
 	Context("determining when a stream is completed", func() {
 		BeforeEach(func() {
 			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount).AnyTimes()
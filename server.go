@@ -6,9 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/netip"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/handshake"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/qerr"
@@ -34,6 +37,7 @@ type packetHandler interface {
 
 type packetHandlerManager interface {
 	Get(protocol.ConnectionID) (packetHandler, bool)
+	GetBatch([]protocol.ConnectionID, []packetHandler)
 	GetByResetToken(protocol.StatelessResetToken) (packetHandler, bool)
 	AddWithConnID(destConnID, newConnID protocol.ConnectionID, h packetHandler) bool
 	Close(error)
@@ -47,6 +51,12 @@ type quicConn interface {
 	run() error
 	destroy(error)
 	closeWithTransportError(TransportErrorCode)
+	// setHandshakeCompleteHook sets a function that is called when the handshake completes.
+	// It must be called before the connection is run.
+	setHandshakeCompleteHook(func())
+	// releaseFlowControlMemory releases the memory reserved from the flow control memory budget.
+	// It is used for connections that are never run.
+	releaseFlowControlMemory()
 }
 
 type zeroRTTQueue struct {
@@ -70,12 +80,15 @@ type baseServer struct {
 	conn rawConn
 
 	tokenGenerator *handshake.TokenGenerator
+	memoryBudget   *flowcontrol.MemoryBudget
 	maxTokenAge    time.Duration
 
 	connIDGenerator ConnectionIDGenerator
 	connHandler     packetHandlerManager
 	onClose         func()
 
+	admission *admissionController // nil if no AdmissionPolicy is configured
+
 	receivedPackets chan receivedPacket
 
 	nextZeroRTTCleanup time.Time
@@ -99,7 +112,9 @@ type baseServer struct {
 		*Config,
 		*tls.Config,
 		*handshake.TokenGenerator,
+		*flowcontrol.MemoryBudget,
 		bool, /* client address validated by an address validation token */
+		[]byte, /* address validation token from a NEW_TOKEN frame, if it was valid */
 		*logging.ConnectionTracer,
 		utils.Logger,
 		protocol.Version,
@@ -119,6 +134,11 @@ type baseServer struct {
 
 	connQueue chan quicConn
 
+	connsMx  sync.Mutex
+	conns    map[quicConn]struct{} // connections created by this server that haven't been closed yet
+	draining bool
+	drained  chan struct{} // closed when the server is draining and all connections have been closed
+
 	tracer *logging.Tracer
 
 	logger utils.Logger
@@ -135,6 +155,18 @@ func (l *Listener) Accept(ctx context.Context) (Connection, error) {
 	return l.baseServer.Accept(ctx)
 }
 
+// Drain stops the listener from accepting new connections, while allowing existing connections to continue.
+// New connection attempts are rejected with a CONNECTION_REFUSED error.
+// Handshakes that are already in progress are allowed to complete, and the resulting connections
+// can still be accepted using Accept.
+// Drain blocks until all connections created by this listener have been closed, or until the context is canceled.
+// In the latter case, the remaining connections are closed with a NO_ERROR transport error,
+// and the context's error is returned.
+// The listener is closed when Drain returns.
+func (l *Listener) Drain(ctx context.Context) error {
+	return l.baseServer.Drain(ctx)
+}
+
 // Close closes the listener.
 // Accept will return ErrServerClosed as soon as all connections in the accept queue have been accepted.
 // QUIC handshakes that are still in flight will be rejected with a CONNECTION_REFUSED error.
@@ -165,6 +197,12 @@ func (l *EarlyListener) Accept(ctx context.Context) (EarlyConnection, error) {
 	return l.baseServer.accept(ctx)
 }
 
+// Drain stops the listener from accepting new connections, while allowing existing connections to continue.
+// See Listener.Drain for details.
+func (l *EarlyListener) Drain(ctx context.Context) error {
+	return l.baseServer.Drain(ctx)
+}
+
 // Close the server. All active connections will be closed.
 func (l *EarlyListener) Close() error {
 	return l.baseServer.Close()
@@ -246,7 +284,9 @@ func newServer(
 	onClose func(),
 	tokenGeneratorKey TokenGeneratorKey,
 	maxTokenAge time.Duration,
+	memoryBudget *flowcontrol.MemoryBudget,
 	verifySourceAddress func(net.Addr) bool,
+	admissionPolicy *AdmissionPolicy,
 	disableVersionNegotiation bool,
 	acceptEarly bool,
 ) *baseServer {
@@ -257,10 +297,13 @@ func newServer(
 		config:                    config,
 		tokenGenerator:            handshake.NewTokenGenerator(tokenGeneratorKey),
 		maxTokenAge:               maxTokenAge,
+		memoryBudget:              memoryBudget,
 		verifySourceAddress:       verifySourceAddress,
 		connIDGenerator:           connIDGenerator,
 		connHandler:               connHandler,
-		connQueue:                 make(chan quicConn, protocol.MaxAcceptQueueSize),
+		connQueue:                 make(chan quicConn, admissionPolicy.acceptQueueLen()),
+		conns:                     make(map[quicConn]struct{}),
+		drained:                   make(chan struct{}),
 		errorChan:                 make(chan struct{}),
 		running:                   make(chan struct{}),
 		receivedPackets:           make(chan receivedPacket, protocol.MaxServerUnprocessedPackets),
@@ -278,6 +321,9 @@ func newServer(
 	if acceptEarly {
 		s.zeroRTTQueues = map[protocol.ConnectionID]*zeroRTTQueue{}
 	}
+	if admissionPolicy != nil {
+		s.admission = newAdmissionController(*admissionPolicy)
+	}
 	go s.run()
 	go s.runSendQueue()
 	s.logger.Debugf("Listening for %s connections on %s", conn.LocalAddr().Network(), conn.LocalAddr().String())
@@ -342,6 +388,78 @@ func (s *baseServer) Close() error {
 	return nil
 }
 
+func (s *baseServer) Drain(ctx context.Context) error {
+	s.connsMx.Lock()
+	s.draining = true
+	s.maybeSignalDrained()
+	s.connsMx.Unlock()
+
+	var err error
+	select {
+	case <-s.drained:
+	case <-s.errorChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+		s.connsMx.Lock()
+		conns := make([]quicConn, 0, len(s.conns))
+		for conn := range s.conns {
+			conns = append(conns, conn)
+		}
+		s.connsMx.Unlock()
+
+		var wg sync.WaitGroup
+		wg.Add(len(conns))
+		for _, conn := range conns {
+			go func(conn quicConn) {
+				defer wg.Done()
+				conn.closeWithTransportError(NoError)
+			}(conn)
+		}
+		wg.Wait()
+	}
+	s.Close()
+	return err
+}
+
+func (s *baseServer) isDraining() bool {
+	s.connsMx.Lock()
+	defer s.connsMx.Unlock()
+	return s.draining
+}
+
+// addConn adds a new connection.
+// It returns false if the server is draining, in which case the connection is not added.
+func (s *baseServer) addConn(conn quicConn) bool {
+	s.connsMx.Lock()
+	defer s.connsMx.Unlock()
+
+	if s.draining {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *baseServer) removeConn(conn quicConn) {
+	s.connsMx.Lock()
+	delete(s.conns, conn)
+	s.maybeSignalDrained()
+	s.connsMx.Unlock()
+}
+
+// maybeSignalDrained closes the drained channel if the server is draining and all connections have been closed.
+// It must be called with connsMx held.
+func (s *baseServer) maybeSignalDrained() {
+	if !s.draining || len(s.conns) > 0 {
+		return
+	}
+	select {
+	case <-s.drained:
+	default:
+		close(s.drained)
+	}
+}
+
 func (s *baseServer) close(e error, notifyOnClose bool) {
 	s.closeMx.Lock()
 	if s.closeErr != nil {
@@ -569,10 +687,31 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		return nil
 	}
 
+	if s.isDraining() {
+		s.refuseWhileDraining(p, hdr)
+		return nil
+	}
+
+	if s.admission != nil {
+		if reason, ok := s.admission.allowInitial(p.remoteAddr, p.rcvTime); !ok {
+			s.logger.Debugf("Dropping Initial packet from %s due to admission policy", p.remoteAddr)
+			delete(s.zeroRTTQueues, hdr.DestConnectionID)
+			if s.tracer != nil && s.tracer.DroppedPacket != nil {
+				s.tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+			}
+			if s.tracer != nil && s.tracer.RejectedConnection != nil {
+				s.tracer.RejectedConnection(p.remoteAddr, reason)
+			}
+			p.buffer.Release()
+			return nil
+		}
+	}
+
 	var (
 		token              *handshake.Token
 		retrySrcConnID     *protocol.ConnectionID
 		clientAddrVerified bool
+		newToken           []byte // the token from a NEW_TOKEN frame, used to restore the congestion state
 	)
 	origDestConnID := hdr.DestConnectionID
 	if len(hdr.Token) > 0 {
@@ -608,6 +747,11 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		}
 	}
 
+	if token != nil && !token.IsRetryToken {
+		// the packet buffer is reused, so we need to copy the token
+		newToken = slices.Clone(hdr.Token)
+	}
+
 	if token == nil && s.verifySourceAddress != nil && s.verifySourceAddress(p.remoteAddr) {
 		// Retry invalidates all 0-RTT packets sent.
 		delete(s.zeroRTTQueues, hdr.DestConnectionID)
@@ -640,6 +784,26 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		config = populateConfig(conf)
 	}
 
+	var admittedSource netip.Prefix
+	if s.admission != nil {
+		prefix, reason, ok := s.admission.admit(p.remoteAddr, p.rcvTime)
+		if !ok {
+			s.logger.Debugf("Rejecting new connection from %s due to admission policy", p.remoteAddr)
+			if s.tracer != nil && s.tracer.RejectedConnection != nil {
+				s.tracer.RejectedConnection(p.remoteAddr, reason)
+			}
+			delete(s.zeroRTTQueues, hdr.DestConnectionID)
+			select {
+			case s.connectionRefusedQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+			default:
+				// drop packet if we can't send out the CONNECTION_REFUSED fast enough
+				p.buffer.Release()
+			}
+			return nil
+		}
+		admittedSource = prefix
+	}
+
 	var conn quicConn
 	var cancel context.CancelCauseFunc
 	ctx, cancel1 := context.WithCancelCause(context.Background())
@@ -672,6 +836,10 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 	}
 	connID, err := s.connIDGenerator.GenerateConnectionID()
 	if err != nil {
+		if s.admission != nil {
+			s.admission.handshakeDone(admittedSource)
+			s.admission.connectionClosed(admittedSource)
+		}
 		return err
 	}
 	s.logger.Debugf("Changing connection ID to %s.", connID)
@@ -685,16 +853,29 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 		hdr.DestConnectionID,
 		hdr.SrcConnectionID,
 		connID,
-		s.connIDGenerator,
+		connIDGeneratorForConn(s.connIDGenerator, connID),
 		s.connHandler.GetStatelessResetToken(connID),
 		config,
 		s.tlsConf,
 		s.tokenGenerator,
+		s.memoryBudget,
 		clientAddrVerified,
+		newToken,
 		tracer,
 		s.logger,
 		hdr.Version,
 	)
+	// The server might have started draining since the check above.
+	// Checking again when adding the connection makes sure that Drain doesn't miss this connection.
+	if added := s.addConn(conn); !added {
+		conn.releaseFlowControlMemory()
+		if s.admission != nil {
+			s.admission.handshakeDone(admittedSource)
+			s.admission.connectionClosed(admittedSource)
+		}
+		s.refuseWhileDraining(p, hdr)
+		return nil
+	}
 	conn.handlePacket(p)
 	// Adding the connection will fail if the client's chosen Destination Connection ID is already in use.
 	// This is very unlikely: Even if an attacker chooses a connection ID that's already in use,
@@ -702,9 +883,18 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 	// The only time this collision will occur if we receive the two Initial packets at the same time.
 	if added := s.connHandler.AddWithConnID(hdr.DestConnectionID, connID, conn); !added {
 		delete(s.zeroRTTQueues, hdr.DestConnectionID)
+		s.removeConn(conn)
+		conn.releaseFlowControlMemory()
 		conn.closeWithTransportError(qerr.ConnectionRefused)
+		if s.admission != nil {
+			s.admission.handshakeDone(admittedSource)
+			s.admission.connectionClosed(admittedSource)
+		}
 		return nil
 	}
+	if s.admission != nil {
+		s.admission.trackConnection(admittedSource, conn)
+	}
 	// Pass queued 0-RTT to the newly established connection.
 	if q, ok := s.zeroRTTQueues[hdr.DestConnectionID]; ok {
 		for _, p := range q.packets {
@@ -715,27 +905,47 @@ func (s *baseServer) handleInitialImpl(p receivedPacket, hdr *wire.Header) error
 
 	go conn.run()
 	go func() {
-		if completed := s.handleNewConn(conn); !completed {
+		ctx := conn.Context()
+		context.AfterFunc(ctx, func() { s.removeConn(conn) })
+		if completed := s.handleNewConn(ctx, conn); !completed {
 			return
 		}
 
 		select {
 		case s.connQueue <- conn:
 		default:
+			if s.tracer != nil && s.tracer.RejectedConnection != nil {
+				s.tracer.RejectedConnection(conn.RemoteAddr(), logging.ConnectionRejectAcceptQueueFull)
+			}
 			conn.closeWithTransportError(ConnectionRefused)
 		}
 	}()
 	return nil
 }
 
-func (s *baseServer) handleNewConn(conn quicConn) bool {
+// refuseWhileDraining refuses a new connection attempt, since the server is draining.
+func (s *baseServer) refuseWhileDraining(p receivedPacket, hdr *wire.Header) {
+	s.logger.Debugf("Rejecting new connection from %s, since the server is draining", p.remoteAddr)
+	if s.tracer != nil && s.tracer.RejectedConnection != nil {
+		s.tracer.RejectedConnection(p.remoteAddr, logging.ConnectionRejectDraining)
+	}
+	delete(s.zeroRTTQueues, hdr.DestConnectionID)
+	select {
+	case s.connectionRefusedQueue <- rejectedPacket{receivedPacket: p, hdr: hdr}:
+	default:
+		// drop packet if we can't send out the CONNECTION_REFUSED fast enough
+		p.buffer.Release()
+	}
+}
+
+func (s *baseServer) handleNewConn(ctx context.Context, conn quicConn) bool {
 	if s.acceptEarlyConns {
 		// wait until the early connection is ready, the handshake fails, or the server is closed
 		select {
 		case <-s.errorChan:
 			conn.closeWithTransportError(ConnectionRefused)
 			return false
-		case <-conn.Context().Done():
+		case <-ctx.Done():
 			return false
 		case <-conn.earlyConnReady():
 			return true
@@ -746,7 +956,7 @@ func (s *baseServer) handleNewConn(conn quicConn) bool {
 	case <-s.errorChan:
 		conn.closeWithTransportError(ConnectionRefused)
 		return false
-	case <-conn.Context().Done():
+	case <-ctx.Done():
 		return false
 	case <-conn.HandshakeComplete():
 		return true
@@ -6,12 +6,14 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"net/netip"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
 
+	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/handshake"
 	mocklogging "github.com/quic-go/quic-go/internal/mocks/logging"
 	"github.com/quic-go/quic-go/internal/protocol"
@@ -95,7 +97,7 @@ var _ = Describe("Server", func() {
 		Expect(err).ToNot(HaveOccurred())
 		data, err := opener.Open(nil, b[extHdr.ParsedLen():], extHdr.PacketNumber, b[:extHdr.ParsedLen()])
 		Expect(err).ToNot(HaveOccurred())
-		_, f, err := wire.NewFrameParser(false).ParseNext(data, protocol.EncryptionInitial, origHdr.Version)
+		_, f, err := wire.NewFrameParser(false, false, false).ParseNext(data, protocol.EncryptionInitial, origHdr.Version)
 		Expect(err).ToNot(HaveOccurred())
 		Expect(f).To(BeAssignableToTypeOf(&wire.ConnectionCloseFrame{}))
 		ccf := f.(*wire.ConnectionCloseFrame)
@@ -301,7 +303,9 @@ var _ = Describe("Server", func() {
 					_ *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -504,7 +508,9 @@ var _ = Describe("Server", func() {
 					_ *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -549,6 +555,62 @@ var _ = Describe("Server", func() {
 				conn.EXPECT().closeWithTransportError(gomock.Any()).MaxTimes(1)
 			})
 
+			It("passes a valid token from a NEW_TOKEN frame to the connection", func() {
+				serv.maxTokenAge = time.Hour
+				raddr := &net.UDPAddr{IP: net.IPv4(4, 5, 6, 7), Port: 456}
+				token, err := serv.tokenGenerator.NewToken(raddr)
+				Expect(err).ToNot(HaveOccurred())
+				connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+				hdr := &wire.Header{
+					Type:             protocol.PacketTypeInitial,
+					SrcConnectionID:  protocol.ParseConnectionID([]byte{5, 4, 3, 2, 1}),
+					DestConnectionID: connID,
+					Token:            token,
+					Version:          protocol.Version1,
+				}
+				p := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
+				p.remoteAddr = raddr
+				run := make(chan struct{})
+				conn := NewMockQUICConn(mockCtrl)
+				serv.newConn = func(
+					_ context.Context,
+					_ context.CancelCauseFunc,
+					_ sendConn,
+					_ connRunner,
+					_ protocol.ConnectionID,
+					_ *protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ ConnectionIDGenerator,
+					_ protocol.StatelessResetToken,
+					_ *Config,
+					_ *tls.Config,
+					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
+					clientAddrValidated bool,
+					clientToken []byte,
+					_ *logging.ConnectionTracer,
+					_ utils.Logger,
+					_ protocol.Version,
+				) quicConn {
+					Expect(clientAddrValidated).To(BeTrue())
+					Expect(clientToken).To(Equal(token))
+					conn.EXPECT().handlePacket(p)
+					conn.EXPECT().run().Do(func() error { close(run); return nil })
+					conn.EXPECT().Context().Return(context.Background())
+					conn.EXPECT().HandshakeComplete().Return(make(chan struct{}))
+					return conn
+				}
+				phm.EXPECT().Get(connID)
+				phm.EXPECT().GetStatelessResetToken(gomock.Any())
+				phm.EXPECT().AddWithConnID(connID, gomock.Any(), gomock.Any()).Return(true)
+				serv.handlePacket(p)
+				Eventually(run).Should(BeClosed())
+				// shutdown
+				conn.EXPECT().closeWithTransportError(gomock.Any()).MaxTimes(1)
+			})
+
 			It("drops packets if the receive queue is full", func() {
 				serv.verifySourceAddress = func(net.Addr) bool { return false }
 
@@ -573,7 +635,9 @@ var _ = Describe("Server", func() {
 					_ *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -629,13 +693,16 @@ var _ = Describe("Server", func() {
 					_ *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
 				) quicConn {
 					conn := NewMockQUICConn(mockCtrl)
 					conn.EXPECT().handlePacket(gomock.Any())
+					conn.EXPECT().releaseFlowControlMemory()
 					conn.EXPECT().closeWithTransportError(qerr.ConnectionRefused).Do(func(qerr.TransportErrorCode) {
 						close(done)
 					})
@@ -679,7 +746,9 @@ var _ = Describe("Server", func() {
 					_ *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -741,7 +810,9 @@ var _ = Describe("Server", func() {
 					_ *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -976,7 +1047,9 @@ var _ = Describe("Server", func() {
 					conf *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -1044,7 +1117,9 @@ var _ = Describe("Server", func() {
 					conf *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -1087,6 +1162,250 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("drops Initial packets exceeding the Initial packet rate of a source", func() {
+				serv.admission = newAdmissionController(AdmissionPolicy{InitialPacketRate: 0.001, InitialPacketBurst: 1})
+				p := getInitialWithRandomDestConnID()
+				Expect(isAllowed(serv.admission.allowInitial(p.remoteAddr, time.Now()))).To(BeTrue())
+
+				done := make(chan struct{})
+				phm.EXPECT().Get(gomock.Any())
+				tracer.EXPECT().DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+				tracer.EXPECT().RejectedConnection(p.remoteAddr, logging.ConnectionRejectRateLimited).Do(func(net.Addr, logging.ConnectionRejectReason) { close(done) })
+				serv.handlePacket(p)
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("refuses connection attempts from sources that have too many connections", func() {
+				serv.admission = newAdmissionController(AdmissionPolicy{MaxConnectionsPerSource: 1})
+				p := getInitialWithRandomDestConnID()
+				_, _, ok := serv.admission.admit(p.remoteAddr, time.Now())
+				Expect(ok).To(BeTrue())
+				serv.admission.handshakeDone(netip.MustParsePrefix("1.2.3.4/32"))
+
+				phm.EXPECT().Get(gomock.Any())
+				tracer.EXPECT().RejectedConnection(p.remoteAddr, logging.ConnectionRejectTooManyConnections)
+				tracer.EXPECT().SentPacket(p.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any())
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					checkConnectionCloseError(b, parseHeader(p.data), qerr.ConnectionRefused)
+					return len(b), nil
+				})
+				serv.handlePacket(p)
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("releases the resources of a source when the connection is closed", func() {
+				serv.admission = newAdmissionController(AdmissionPolicy{MaxHandshakesPerSource: 1})
+				prefix := netip.MustParsePrefix("1.2.3.4/32")
+				ctx, cancel := context.WithCancel(context.Background())
+				handshakeComplete := make(chan struct{})
+				hookChan := make(chan func(), 1)
+				serv.newConn = func(
+					_ context.Context,
+					_ context.CancelCauseFunc,
+					_ sendConn,
+					_ connRunner,
+					_ protocol.ConnectionID,
+					_ *protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ ConnectionIDGenerator,
+					_ protocol.StatelessResetToken,
+					_ *Config,
+					_ *tls.Config,
+					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
+					_ bool,
+					_ []byte,
+					_ *logging.ConnectionTracer,
+					_ utils.Logger,
+					_ protocol.Version,
+				) quicConn {
+					conn := NewMockQUICConn(mockCtrl)
+					conn.EXPECT().handlePacket(gomock.Any())
+					conn.EXPECT().run()
+					conn.EXPECT().Context().Return(ctx).AnyTimes()
+					conn.EXPECT().HandshakeComplete().Return(handshakeComplete).AnyTimes()
+					conn.EXPECT().setHandshakeCompleteHook(gomock.Any()).Do(func(f func()) { hookChan <- f })
+					return conn
+				}
+				phm.EXPECT().Get(gomock.Any())
+				phm.EXPECT().GetStatelessResetToken(gomock.Any())
+				phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+				serv.handlePacket(getInitialWithRandomDestConnID())
+				var hook func()
+				Eventually(hookChan).Should(Receive(&hook))
+
+				getSource := func() sourceState {
+					serv.admission.mutex.Lock()
+					defer serv.admission.mutex.Unlock()
+					if s, ok := serv.admission.sources[prefix]; ok {
+						return *s
+					}
+					return sourceState{}
+				}
+				Eventually(func() int { return getSource().handshakes }).Should(Equal(1))
+				Expect(getSource().connections).To(Equal(1))
+				hook()
+				close(handshakeComplete)
+				Eventually(func() int { return getSource().handshakes }).Should(BeZero())
+				Expect(getSource().connections).To(Equal(1))
+				cancel()
+				Eventually(func() int { return getSource().connections }).Should(BeZero())
+			})
+
+			It("refuses new connection attempts while draining", func() {
+				serv.connsMx.Lock()
+				serv.draining = true
+				serv.connsMx.Unlock()
+
+				p := getInitialWithRandomDestConnID()
+				phm.EXPECT().Get(gomock.Any())
+				tracer.EXPECT().RejectedConnection(p.remoteAddr, logging.ConnectionRejectDraining)
+				tracer.EXPECT().SentPacket(p.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any())
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					checkConnectionCloseError(b, parseHeader(p.data), qerr.ConnectionRefused)
+					return len(b), nil
+				})
+				serv.handlePacket(p)
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("refuses connection attempts if the server starts draining while the connection is created", func() {
+				serv.admission = newAdmissionController(AdmissionPolicy{MaxHandshakesPerSource: 1})
+				serv.newConn = func(
+					_ context.Context,
+					_ context.CancelCauseFunc,
+					_ sendConn,
+					_ connRunner,
+					_ protocol.ConnectionID,
+					_ *protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ protocol.ConnectionID,
+					_ ConnectionIDGenerator,
+					_ protocol.StatelessResetToken,
+					_ *Config,
+					_ *tls.Config,
+					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
+					_ bool,
+					_ []byte,
+					_ *logging.ConnectionTracer,
+					_ utils.Logger,
+					_ protocol.Version,
+				) quicConn {
+					// Drain is called after the server checked that it's not draining
+					serv.connsMx.Lock()
+					serv.draining = true
+					serv.connsMx.Unlock()
+					c := NewMockQUICConn(mockCtrl)
+					c.EXPECT().releaseFlowControlMemory()
+					return c
+				}
+
+				p := getInitialWithRandomDestConnID()
+				phm.EXPECT().Get(gomock.Any())
+				phm.EXPECT().GetStatelessResetToken(gomock.Any())
+				tracer.EXPECT().RejectedConnection(p.remoteAddr, logging.ConnectionRejectDraining)
+				tracer.EXPECT().SentPacket(p.remoteAddr, gomock.Any(), gomock.Any(), gomock.Any())
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), p.remoteAddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					checkConnectionCloseError(b, parseHeader(p.data), qerr.ConnectionRefused)
+					return len(b), nil
+				})
+				serv.handlePacket(p)
+				Eventually(done).Should(BeClosed())
+				serv.connsMx.Lock()
+				Expect(serv.conns).To(BeEmpty())
+				serv.connsMx.Unlock()
+				// the admission counters were released
+				serv.admission.mutex.Lock()
+				defer serv.admission.mutex.Unlock()
+				source, ok := serv.admission.sources[netip.MustParsePrefix("1.2.3.4/32")]
+				Expect(ok).To(BeTrue())
+				Expect(source.handshakes).To(BeZero())
+				Expect(source.connections).To(BeZero())
+			})
+
+			Context("draining", func() {
+				var (
+					connCtx    context.Context
+					connCancel context.CancelFunc
+					conn       *MockQUICConn
+				)
+
+				BeforeEach(func() {
+					connCtx, connCancel = context.WithCancel(context.Background())
+					conn = NewMockQUICConn(mockCtrl)
+					serv.newConn = func(
+						_ context.Context,
+						_ context.CancelCauseFunc,
+						_ sendConn,
+						_ connRunner,
+						_ protocol.ConnectionID,
+						_ *protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ protocol.ConnectionID,
+						_ ConnectionIDGenerator,
+						_ protocol.StatelessResetToken,
+						_ *Config,
+						_ *tls.Config,
+						_ *handshake.TokenGenerator,
+						_ *flowcontrol.MemoryBudget,
+						_ bool,
+						_ []byte,
+						_ *logging.ConnectionTracer,
+						_ utils.Logger,
+						_ protocol.Version,
+					) quicConn {
+						conn.EXPECT().handlePacket(gomock.Any())
+						conn.EXPECT().run()
+						conn.EXPECT().Context().Return(connCtx)
+						conn.EXPECT().HandshakeComplete().Return(make(chan struct{}))
+						return conn
+					}
+					phm.EXPECT().Get(gomock.Any())
+					phm.EXPECT().GetStatelessResetToken(gomock.Any())
+					phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+					serv.handlePacket(getInitialWithRandomDestConnID())
+					Eventually(func() int {
+						serv.connsMx.Lock()
+						defer serv.connsMx.Unlock()
+						return len(serv.conns)
+					}).Should(Equal(1))
+				})
+
+				It("waits for existing connections to close", func() {
+					errChan := make(chan error, 1)
+					// The connection might be refused by handleNewConn if the server is closed before it notices the canceled context.
+					conn.EXPECT().closeWithTransportError(gomock.Any()).AnyTimes()
+					go func() { errChan <- serv.Drain(context.Background()) }()
+					Consistently(errChan).ShouldNot(Receive())
+					connCancel()
+					var err error
+					Eventually(errChan).Should(Receive(&err))
+					Expect(err).ToNot(HaveOccurred())
+					_, err = serv.Accept(context.Background())
+					Expect(err).To(MatchError(ErrServerClosed))
+				})
+
+				It("closes the remaining connections when the context is canceled", func() {
+					conn.EXPECT().closeWithTransportError(NoError).Do(func(TransportErrorCode) { connCancel() })
+					ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(50*time.Millisecond))
+					defer cancel()
+					Expect(serv.Drain(ctx)).To(MatchError(context.DeadlineExceeded))
+					_, err := serv.Accept(context.Background())
+					Expect(err).To(MatchError(ErrServerClosed))
+				})
+			})
+
 			It("accepts new connections when the handshake completes", func() {
 				conn := NewMockQUICConn(mockCtrl)
 
@@ -1115,7 +1434,9 @@ var _ = Describe("Server", func() {
 					_ *Config,
 					_ *tls.Config,
 					_ *handshake.TokenGenerator,
+					_ *flowcontrol.MemoryBudget,
 					_ bool,
+					_ []byte,
 					_ *logging.ConnectionTracer,
 					_ utils.Logger,
 					_ protocol.Version,
@@ -1186,7 +1507,9 @@ var _ = Describe("Server", func() {
 				_ *Config,
 				_ *tls.Config,
 				_ *handshake.TokenGenerator,
+				_ *flowcontrol.MemoryBudget,
 				_ bool,
+				_ []byte,
 				_ *logging.ConnectionTracer,
 				_ utils.Logger,
 				_ protocol.Version,
@@ -1228,7 +1551,9 @@ var _ = Describe("Server", func() {
 				_ *Config,
 				_ *tls.Config,
 				_ *handshake.TokenGenerator,
+				_ *flowcontrol.MemoryBudget,
 				_ bool,
+				_ []byte,
 				_ *logging.ConnectionTracer,
 				_ utils.Logger,
 				_ protocol.Version,
@@ -1288,7 +1613,9 @@ var _ = Describe("Server", func() {
 				_ *Config,
 				_ *tls.Config,
 				_ *handshake.TokenGenerator,
+				_ *flowcontrol.MemoryBudget,
 				_ bool,
+				_ []byte,
 				_ *logging.ConnectionTracer,
 				_ utils.Logger,
 				_ protocol.Version,
@@ -1411,7 +1738,9 @@ var _ = Describe("Server", func() {
 				_ *Config,
 				_ *tls.Config,
 				_ *handshake.TokenGenerator,
+				_ *flowcontrol.MemoryBudget,
 				_ bool,
+				_ []byte,
 				_ *logging.ConnectionTracer,
 				_ utils.Logger,
 				_ protocol.Version,
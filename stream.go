@@ -26,6 +26,8 @@ var errDeadline net.Error = &deadlineError{}
 type streamSender interface {
 	queueControlFrame(wire.Frame)
 	onHasStreamData(protocol.StreamID)
+	onStreamPriorityChanged(protocol.StreamID, StreamPriority)
+	supportsResetStreamAt() bool
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
 }
@@ -45,6 +47,14 @@ func (s *uniStreamSender) onHasStreamData(id protocol.StreamID) {
 	s.streamSender.onHasStreamData(id)
 }
 
+func (s *uniStreamSender) onStreamPriorityChanged(id protocol.StreamID, p StreamPriority) {
+	s.streamSender.onStreamPriorityChanged(id, p)
+}
+
+func (s *uniStreamSender) supportsResetStreamAt() bool {
+	return s.streamSender.supportsResetStreamAt()
+}
+
 func (s *uniStreamSender) onStreamCompleted(protocol.StreamID) {
 	s.onStreamCompletedImpl()
 }
@@ -58,6 +68,7 @@ type streamI interface {
 	handleStreamFrame(*wire.StreamFrame) error
 	handleResetStreamFrame(*wire.ResetStreamFrame) error
 	getWindowUpdate() protocol.ByteCount
+	setReceiveWindowSize(protocol.ByteCount)
 	// for sending
 	hasData() bool
 	handleStopSendingFrame(*wire.StopSendingFrame)
@@ -288,6 +288,44 @@ func (m *streamsMap) getOrOpenSendStream(id protocol.StreamID) (sendStreamI, err
 	panic("")
 }
 
+// GetReceiveStream returns a stream that data can be received on.
+// Unlike GetOrOpenReceiveStream, it never opens a new stream.
+func (m *streamsMap) GetReceiveStream(id protocol.StreamID) (receiveStreamI, bool) {
+	num := id.StreamNum()
+	switch id.Type() {
+	case protocol.StreamTypeUni:
+		if id.InitiatedBy() == m.perspective {
+			return nil, false
+		}
+		return m.incomingUniStreams.GetStream(num)
+	case protocol.StreamTypeBidi:
+		if id.InitiatedBy() == m.perspective {
+			str, err := m.outgoingBidiStreams.GetStream(num)
+			if err != nil || str == nil {
+				return nil, false
+			}
+			return str, true
+		}
+		return m.incomingBidiStreams.GetStream(num)
+	}
+	panic("")
+}
+
+// SetMaxIncomingStreams changes the number of concurrent streams of the given type that the peer is allowed to open.
+func (m *streamsMap) SetMaxIncomingStreams(t protocol.StreamType, num uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	switch t {
+	case protocol.StreamTypeUni:
+		m.maxIncomingUniStreams = num
+		m.incomingUniStreams.SetMaxNumStreams(num)
+	case protocol.StreamTypeBidi:
+		m.maxIncomingBidiStreams = num
+		m.incomingBidiStreams.SetMaxNumStreams(num)
+	}
+}
+
 func (m *streamsMap) HandleMaxStreamsFrame(f *wire.MaxStreamsFrame) {
 	switch f.Type {
 	case protocol.StreamTypeUni:
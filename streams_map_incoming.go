@@ -138,6 +138,18 @@ func (m *incomingStreamsMap[T]) GetOrOpenStream(num protocol.StreamNum) (T, erro
 	return entry.stream, nil
 }
 
+// GetStream returns the stream, if the peer already opened it and it wasn't deleted yet.
+func (m *incomingStreamsMap[T]) GetStream(num protocol.StreamNum) (T, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	entry, ok := m.streams[num]
+	if !ok || entry.shouldDelete {
+		return *new(T), false
+	}
+	return entry.stream, true
+}
+
 func (m *incomingStreamsMap[T]) DeleteStream(num protocol.StreamNum) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -169,21 +181,38 @@ func (m *incomingStreamsMap[T]) deleteStream(num protocol.StreamNum) error {
 	}
 
 	delete(m.streams, num)
-	// queue a MAX_STREAM_ID frame, giving the peer the option to open a new stream
-	if m.maxNumStreams > uint64(len(m.streams)) {
-		maxStream := m.nextStreamToOpen + protocol.StreamNum(m.maxNumStreams-uint64(len(m.streams))) - 1
-		// Never send a value larger than protocol.MaxStreamCount.
-		if maxStream <= protocol.MaxStreamCount {
-			m.maxStream = maxStream
-			m.queueMaxStreamID(&wire.MaxStreamsFrame{
-				Type:         m.streamType,
-				MaxStreamNum: m.maxStream,
-			})
-		}
-	}
+	m.maybeQueueMaxStreams()
 	return nil
 }
 
+// maybeQueueMaxStreams queues a MAX_STREAMS frame, if the peer is allowed to open additional streams.
+func (m *incomingStreamsMap[T]) maybeQueueMaxStreams() {
+	if m.maxNumStreams <= uint64(len(m.streams)) {
+		return
+	}
+	maxStream := m.nextStreamToOpen + protocol.StreamNum(m.maxNumStreams-uint64(len(m.streams))) - 1
+	// Never send a value larger than protocol.MaxStreamCount.
+	// The stream limit can't be decreased, the peer might already have opened streams up to the old limit.
+	if maxStream > protocol.MaxStreamCount || maxStream <= m.maxStream {
+		return
+	}
+	m.maxStream = maxStream
+	m.queueMaxStreamID(&wire.MaxStreamsFrame{
+		Type:         m.streamType,
+		MaxStreamNum: m.maxStream,
+	})
+}
+
+// SetMaxNumStreams changes the maximum number of concurrent streams.
+// When the limit is lowered, the peer is not granted any new streams until the number of open streams dropped below the new limit.
+func (m *incomingStreamsMap[T]) SetMaxNumStreams(num uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.maxNumStreams = num
+	m.maybeQueueMaxStreams()
+}
+
 func (m *incomingStreamsMap[T]) CloseWithError(err error) {
 	m.mutex.Lock()
 	m.closeErr = err
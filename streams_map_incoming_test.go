@@ -45,7 +45,7 @@ var _ = Describe("Streams Map (incoming)", func() {
 	checkFrameSerialization := func(f wire.Frame) {
 		b, err := f.Append(nil, protocol.Version1)
 		ExpectWithOffset(1, err).ToNot(HaveOccurred())
-		_, frame, err := wire.NewFrameParser(false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
+		_, frame, err := wire.NewFrameParser(false, false, false).ParseNext(b, protocol.Encryption1RTT, protocol.Version1)
 		ExpectWithOffset(1, err).ToNot(HaveOccurred())
 		Expect(f).To(Equal(frame))
 	}
@@ -241,6 +241,47 @@ var _ = Describe("Streams Map (incoming)", func() {
 		Expect(m.DeleteStream(4)).To(Succeed())
 	})
 
+	It("sends a MAX_STREAMS frame when the stream limit is raised", func() {
+		mockSender.EXPECT().queueControlFrame(gomock.Any()).Do(func(f wire.Frame) {
+			msf := f.(*wire.MaxStreamsFrame)
+			Expect(msf.Type).To(BeEquivalentTo(streamType))
+			Expect(msf.MaxStreamNum).To(Equal(protocol.StreamNum(maxNumStreams + 3)))
+		})
+		m.SetMaxNumStreams(maxNumStreams + 3)
+		_, err := m.GetOrOpenStream(protocol.StreamNum(maxNumStreams + 3))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("doesn't grant new streams after the stream limit was lowered, until enough streams are deleted", func() {
+		_, err := m.GetOrOpenStream(5)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 5; i++ {
+			_, err := m.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+		}
+		m.SetMaxNumStreams(2)
+		// the peer is still allowed to open streams up to the old limit
+		_, err = m.GetOrOpenStream(5)
+		Expect(err).ToNot(HaveOccurred())
+		for i := 1; i <= 3; i++ {
+			Expect(m.DeleteStream(protocol.StreamNum(i))).To(Succeed())
+		}
+		mockSender.EXPECT().queueControlFrame(gomock.Any()).Do(func(f wire.Frame) {
+			Expect(f.(*wire.MaxStreamsFrame).MaxStreamNum).To(Equal(protocol.StreamNum(6)))
+		})
+		Expect(m.DeleteStream(4)).To(Succeed())
+	})
+
+	It("only returns streams that were opened by the peer from GetStream", func() {
+		_, ok := m.GetStream(1)
+		Expect(ok).To(BeFalse())
+		str, err := m.GetOrOpenStream(1)
+		Expect(err).ToNot(HaveOccurred())
+		s, ok := m.GetStream(1)
+		Expect(ok).To(BeTrue())
+		Expect(s).To(Equal(str))
+	})
+
 	Context("using high stream limits", func() {
 		BeforeEach(func() { maxNumStreams = uint64(protocol.MaxStreamCount) - 2 })
 
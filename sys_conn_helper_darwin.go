@@ -13,6 +13,9 @@ import (
 const (
 	msgTypeIPTOS = unix.IP_RECVTOS
 	ipv4PKTINFO  = unix.IP_RECVPKTINFO
+	// GRO is only supported on Linux.
+	// This value is never used, since isGROEnabled always returns false.
+	msgTypeUDPGRO = -1
 )
 
 const ecnIPv4DataLen = 4
@@ -35,4 +38,6 @@ func parseIPv4PktInfo(body []byte) (ip netip.Addr, ifIndex uint32, ok bool) {
 
 func isGSOEnabled(syscall.RawConn) bool { return false }
 
+func isGROEnabled(syscall.RawConn) bool { return false }
+
 func isECNEnabled() bool { return !isECNDisabledUsingEnv() }
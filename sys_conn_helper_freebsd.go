@@ -12,6 +12,9 @@ import (
 const (
 	msgTypeIPTOS = unix.IP_RECVTOS
 	ipv4PKTINFO  = 0x7
+	// GRO is only supported on Linux.
+	// This value is never used, since isGROEnabled always returns false.
+	msgTypeUDPGRO = -1
 )
 
 const ecnIPv4DataLen = 1
@@ -30,4 +33,6 @@ func parseIPv4PktInfo(body []byte) (ip netip.Addr, _ uint32, ok bool) {
 
 func isGSOEnabled(syscall.RawConn) bool { return false }
 
+func isGROEnabled(syscall.RawConn) bool { return false }
+
 func isECNEnabled() bool { return !isECNDisabledUsingEnv() }
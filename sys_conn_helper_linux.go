@@ -15,13 +15,14 @@ import (
 )
 
 const (
-	msgTypeIPTOS = unix.IP_TOS
-	ipv4PKTINFO  = unix.IP_PKTINFO
+	msgTypeIPTOS  = unix.IP_TOS
+	ipv4PKTINFO   = unix.IP_PKTINFO
+	msgTypeUDPGRO = unix.UDP_GRO
 )
 
 const ecnIPv4DataLen = 1
 
-const batchSize = 8 // needs to smaller than MaxUint8 (otherwise the type of oobConn.readPos has to be changed)
+const batchSize = 8 // needs to smaller than MaxUint8 (otherwise the type of oobConn.numBuffersUsed has to be changed)
 
 var kernelVersionMajor int
 
@@ -80,6 +81,26 @@ func isGSOEnabled(conn syscall.RawConn) bool {
 	return serr == nil
 }
 
+// isGROEnabled enables GRO on the socket, if the kernel supports it.
+// With GRO, the kernel coalesces multiple packets received from the same sender into a single large datagram.
+// The size of the segments is reported in a UDP_GRO control message.
+func isGROEnabled(conn syscall.RawConn) bool {
+	if kernelVersionMajor < 5 {
+		return false
+	}
+	disabled, err := strconv.ParseBool(os.Getenv("QUIC_GO_DISABLE_GRO"))
+	if err == nil && disabled {
+		return false
+	}
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_UDP, unix.UDP_GRO, 1)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
 func appendUDPSegmentSizeMsg(b []byte, size uint16) []byte {
 	startLen := len(b)
 	const dataLen = 2 // payload is a uint16
@@ -26,6 +26,8 @@ import (
 const (
 	ecnMask       = 0x3
 	oobBufferSize = 128
+	// With GRO, the kernel coalesces up to 64 KB of packets into a single datagram.
+	groBufferSize = 1<<16 - 1
 )
 
 // Contrary to what the naming suggests, the ipv{4,6}.Message is not dependent on the IP version.
@@ -68,15 +70,27 @@ type oobConn struct {
 	OOBCapablePacketConn
 	batchConn batchConn
 
-	readPos uint8
-	// Packets received from the kernel, but not yet returned by ReadPacket().
 	messages []ipv4.Message
-	buffers  [batchSize]*packetBuffer
+	// The packet buffers passed to the kernel in the last ReadBatch call.
+	// Only used if GRO is disabled.
+	buffers [batchSize]*packetBuffer
+	// Number of buffers that were handed out and need to be replaced before the next ReadBatch call.
+	numBuffersUsed uint8
+	// The buffers passed to the kernel if GRO is enabled.
+	// Coalesced datagrams are split into packets, which are copied into packet buffers.
+	groBuffers [][]byte
+
+	// Packets received from the kernel, but not yet returned by ReadPacket() or ReadPackets().
+	packets []receivedPacket
+	readPos int
 
 	cap connCapabilities
 }
 
-var _ rawConn = &oobConn{}
+var (
+	_ rawConn      = &oobConn{}
+	_ batchRawConn = &oobConn{}
+)
 
 func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 	rawConn, err := c.SyscallConn()
@@ -144,11 +158,12 @@ func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 		OOBCapablePacketConn: c,
 		batchConn:            bc,
 		messages:             msgs,
-		readPos:              batchSize,
+		numBuffersUsed:       batchSize,
 		cap: connCapabilities{
 			DF:  supportsDF,
 			GSO: isGSOEnabled(rawConn),
 			ECN: isECNEnabled(),
+			GRO: isGROEnabled(rawConn),
 		},
 	}
 	for i := 0; i < batchSize; i++ {
@@ -160,39 +175,134 @@ func newConn(c OOBCapablePacketConn, supportsDF bool) (*oobConn, error) {
 var invalidCmsgOnceV4, invalidCmsgOnceV6 sync.Once
 
 func (c *oobConn) ReadPacket() (receivedPacket, error) {
-	if len(c.messages) == int(c.readPos) { // all messages read. Read the next batch of messages.
-		c.messages = c.messages[:batchSize]
+	if c.readPos == len(c.packets) { // all packets read. Read the next batch of messages.
+		if err := c.readBatch(); err != nil {
+			return receivedPacket{}, err
+		}
+		if len(c.packets) == 0 {
+			return receivedPacket{}, nil
+		}
+	}
+	p := c.packets[c.readPos]
+	c.packets[c.readPos] = receivedPacket{}
+	c.readPos++
+	return p, nil
+}
+
+// ReadPackets reads up to len(ps) packets.
+// It issues at most one ReadBatch call, and never blocks if there are packets left over from the last call.
+func (c *oobConn) ReadPackets(ps []receivedPacket) (int, error) {
+	if c.readPos == len(c.packets) {
+		if err := c.readBatch(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(ps, c.packets[c.readPos:])
+	clear(c.packets[c.readPos : c.readPos+n])
+	c.readPos += n
+	return n, nil
+}
+
+func (c *oobConn) readBatch() error {
+	c.packets = c.packets[:0]
+	c.readPos = 0
+	c.messages = c.messages[:batchSize]
+	if c.cap.GRO {
+		if c.groBuffers == nil {
+			c.groBuffers = make([][]byte, batchSize)
+			for i := range c.groBuffers {
+				c.groBuffers[i] = make([]byte, groBufferSize)
+			}
+		}
+		for i := range c.messages {
+			c.messages[i].Buffers[0] = c.groBuffers[i]
+		}
+	} else {
 		// replace buffers data buffers up to the packet that has been consumed during the last ReadBatch call
-		for i := uint8(0); i < c.readPos; i++ {
+		for i := uint8(0); i < c.numBuffersUsed; i++ {
 			buffer := getPacketBuffer()
 			buffer.Data = buffer.Data[:protocol.MaxPacketBufferSize]
 			c.buffers[i] = buffer
 			c.messages[i].Buffers[0] = c.buffers[i].Data
 		}
-		c.readPos = 0
+		c.numBuffersUsed = 0
+	}
 
-		n, err := c.batchConn.ReadBatch(c.messages, 0)
-		if n == 0 || err != nil {
-			return receivedPacket{}, err
+	n, err := c.batchConn.ReadBatch(c.messages, 0)
+	if n == 0 || err != nil {
+		return err
+	}
+	c.messages = c.messages[:n]
+	if !c.cap.GRO {
+		c.numBuffersUsed = uint8(n)
+	}
+
+	rcvTime := time.Now()
+	for i, msg := range c.messages {
+		p := receivedPacket{
+			remoteAddr: msg.Addr,
+			rcvTime:    rcvTime,
+		}
+		segmentSize, err := parseOOB(&p, msg.OOB[:msg.NN])
+		if err != nil {
+			c.releasePackets()
+			return err
 		}
-		c.messages = c.messages[:n]
+		if !c.cap.GRO {
+			p.data = msg.Buffers[0][:msg.N]
+			p.buffer = c.buffers[i]
+			c.packets = append(c.packets, p)
+			continue
+		}
+		c.packets = appendGROSegments(c.packets, p, msg.Buffers[0][:msg.N], segmentSize)
 	}
+	return nil
+}
 
-	msg := c.messages[c.readPos]
-	buffer := c.buffers[c.readPos]
-	c.readPos++
+// releasePackets releases all packets that weren't returned yet.
+func (c *oobConn) releasePackets() {
+	if c.cap.GRO {
+		for _, p := range c.packets[c.readPos:] {
+			p.buffer.Release()
+		}
+	}
+	clear(c.packets)
+	c.packets = c.packets[:0]
+	c.readPos = 0
+}
 
-	data := msg.OOB[:msg.NN]
-	p := receivedPacket{
-		remoteAddr: msg.Addr,
-		rcvTime:    time.Now(),
-		data:       msg.Buffers[0][:msg.N],
-		buffer:     buffer,
+// appendGROSegments splits a datagram into its segments, and copies every segment into a new packet buffer.
+// A segment size of 0 means that the datagram wasn't coalesced.
+// Since the segments might belong to different connections (and therefore be handled on different go routines),
+// they can't share the same packet buffer.
+// Sharing the receive buffer would also keep the whole 64 KB buffer alive for as long as any of its segments is queued.
+func appendGROSegments(packets []receivedPacket, p receivedPacket, data []byte, segmentSize int) []receivedPacket {
+	if segmentSize <= 0 {
+		segmentSize = len(data)
+	}
+	for len(data) > 0 {
+		segment := data[:min(segmentSize, len(data))]
+		data = data[len(segment):]
+		// Packets that are larger than the packet buffer would be truncated if GRO was disabled.
+		if len(segment) > protocol.MaxPacketBufferSize {
+			segment = segment[:protocol.MaxPacketBufferSize]
+		}
+		buffer := getPacketBuffer()
+		buffer.Data = append(buffer.Data, segment...)
+		p.data = buffer.Data
+		p.buffer = buffer
+		packets = append(packets, p)
 	}
+	return packets
+}
+
+// parseOOB parses the control messages of a received datagram.
+// It returns the GRO segment size, or 0 if the datagram wasn't coalesced.
+func parseOOB(p *receivedPacket, data []byte) (segmentSize int, _ error) {
 	for len(data) > 0 {
 		hdr, body, remainder, err := unix.ParseOneSocketControlMessage(data)
 		if err != nil {
-			return receivedPacket{}, err
+			return 0, err
 		}
 		if hdr.Level == unix.IPPROTO_IP {
 			switch hdr.Type {
@@ -231,9 +341,13 @@ func (c *oobConn) ReadPacket() (receivedPacket, error) {
 				}
 			}
 		}
+		// The segment size is reported as an int.
+		if hdr.Level == unix.IPPROTO_UDP && hdr.Type == msgTypeUDPGRO && len(body) >= 4 {
+			segmentSize = int(*(*int32)(unsafe.Pointer(&body[0])))
+		}
 		data = remainder
 	}
-	return p, nil
+	return segmentSize, nil
 }
 
 // WritePacket writes a new packet.
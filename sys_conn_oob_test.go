@@ -5,6 +5,7 @@ package quic
 import (
 	"fmt"
 	"net"
+	"testing"
 	"time"
 
 	"golang.org/x/net/ipv4"
@@ -280,6 +281,8 @@ var _ = Describe("OOB Conn Test", func() {
 			oobConn, err := newConn(udpConn, true)
 			Expect(err).ToNot(HaveOccurred())
 			oobConn.batchConn = batchConn
+			// with GRO, the kernel reads into larger buffers
+			oobConn.cap.GRO = false
 
 			for i := 0; i < batchSize+1; i++ {
 				p, err := oobConn.ReadPacket()
@@ -289,6 +292,44 @@ var _ = Describe("OOB Conn Test", func() {
 		})
 	})
 
+	Context("reading multiple packets", func() {
+		It("returns packets left over from the last batch, before reading the next batch", func() {
+			batchConn := NewMockBatchConn(mockCtrl)
+			var counter int
+			batchConn.EXPECT().ReadBatch(gomock.Any(), gomock.Any()).DoAndReturn(func(ms []ipv4.Message, flags int) (int, error) {
+				for i := 0; i < 3; i++ {
+					data := []byte(fmt.Sprintf("message %d", counter))
+					counter++
+					ms[i].N = copy(ms[i].Buffers[0], data)
+				}
+				return 3, nil
+			}).Times(2)
+
+			udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			Expect(err).ToNot(HaveOccurred())
+			defer udpConn.Close()
+			oobConn, err := newConn(udpConn, true)
+			Expect(err).ToNot(HaveOccurred())
+			oobConn.batchConn = batchConn
+
+			packets := make([]receivedPacket, 2)
+			n, err := oobConn.ReadPackets(packets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(2))
+			Expect(string(packets[0].data)).To(Equal("message 0"))
+			Expect(string(packets[1].data)).To(Equal("message 1"))
+			n, err = oobConn.ReadPackets(packets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(1))
+			Expect(string(packets[0].data)).To(Equal("message 2"))
+			n, err = oobConn.ReadPackets(packets)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(2))
+			Expect(string(packets[0].data)).To(Equal("message 3"))
+			Expect(string(packets[1].data)).To(Equal("message 4"))
+		})
+	})
+
 	Context("sending ECN-marked packets", func() {
 		It("sets the ECN control message", func() {
 			addr, err := net.ResolveUDPAddr("udp", "localhost:0")
@@ -310,6 +351,31 @@ var _ = Describe("OOB Conn Test", func() {
 		})
 	})
 
+	Context("splitting GRO segments", func() {
+		It("splits a coalesced datagram", func() {
+			data := []byte("foobarfoobarfoo")
+			packets := appendGROSegments(nil, receivedPacket{ecn: protocol.ECT1}, data, 6)
+			Expect(packets).To(HaveLen(3))
+			Expect(string(packets[0].data)).To(Equal("foobar"))
+			Expect(string(packets[1].data)).To(Equal("foobar"))
+			Expect(string(packets[2].data)).To(Equal("foo"))
+			for _, p := range packets {
+				Expect(p.ecn).To(Equal(protocol.ECT1))
+				Expect(p.buffer.Data).To(Equal(p.data))
+				// the segments are copied, and don't reference the receive buffer
+				Expect(&p.data[0]).ToNot(BeIdenticalTo(&data[0]))
+				p.buffer.Release()
+			}
+		})
+
+		It("doesn't split a datagram that wasn't coalesced", func() {
+			packets := appendGROSegments(nil, receivedPacket{}, []byte("foobar"), 0)
+			Expect(packets).To(HaveLen(1))
+			Expect(string(packets[0].data)).To(Equal("foobar"))
+			packets[0].buffer.Release()
+		})
+	})
+
 	if platformSupportsGSO {
 		Context("GSO", func() {
 			It("appends the GSO control message", func() {
@@ -332,6 +398,58 @@ var _ = Describe("OOB Conn Test", func() {
 				// Check that the first control message is the OOB control message.
 				Expect(oobMsg[:len(expected)]).To(Equal(expected))
 			})
+
+			It("receives packets sent with GSO as separate packets", func() {
+				server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+				Expect(err).ToNot(HaveOccurred())
+				defer server.Close()
+				serverConn, err := newConn(server, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(serverConn.capabilities().GRO).To(BeTrue())
+
+				client, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+				Expect(err).ToNot(HaveOccurred())
+				defer client.Close()
+				clientConn, err := newConn(client, true)
+				Expect(err).ToNot(HaveOccurred())
+
+				data := make([]byte, 0, 2*1000+500)
+				for i := 0; i < cap(data); i++ {
+					data = append(data, byte(i/1000))
+				}
+				_, err = clientConn.WritePacket(data, server.LocalAddr(), nil, 1000, protocol.ECNUnsupported)
+				Expect(err).ToNot(HaveOccurred())
+
+				var received [][]byte
+				for len(received) < 3 {
+					packets := make([]receivedPacket, 5)
+					n, err := serverConn.ReadPackets(packets)
+					Expect(err).ToNot(HaveOccurred())
+					for _, p := range packets[:n] {
+						Expect(p.remoteAddr.String()).To(Equal(client.LocalAddr().String()))
+						received = append(received, p.data)
+					}
+				}
+				Expect(received).To(HaveLen(3))
+				Expect(received[0]).To(Equal(data[:1000]))
+				Expect(received[1]).To(Equal(data[1000:2000]))
+				Expect(received[2]).To(Equal(data[2000:]))
+			})
 		})
 	}
 })
+
+func BenchmarkAppendGROSegments(b *testing.B) {
+	const segmentSize = 1252
+	data := make([]byte, 52*segmentSize) // close to the 64 KB maximum GRO datagram size
+	var packets []receivedPacket
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		packets = appendGROSegments(packets[:0], receivedPacket{}, data, segmentSize)
+		for _, p := range packets {
+			p.buffer.Release()
+		}
+	}
+}
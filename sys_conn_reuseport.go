@@ -0,0 +1,79 @@
+package quic
+
+import (
+	"encoding/binary"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+)
+
+// reusePortSteeringLen returns the number of bytes at the end of the connection ID
+// that are used to select the socket of a SO_REUSEPORT group.
+func reusePortSteeringLen(connIDLen int) int {
+	switch {
+	case connIDLen >= 4:
+		return 4
+	case connIDLen >= 2:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// reusePortSteeringValue reads the bytes that are used to select the socket, in network byte order.
+func reusePortSteeringValue(b []byte) uint64 {
+	var v uint64
+	for _, c := range b[len(b)-reusePortSteeringLen(len(b)):] {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// A reusePortConnIDGenerator generates connection IDs for a Transport that steers packets
+// to the sockets of a SO_REUSEPORT group based on the connection ID.
+type reusePortConnIDGenerator struct {
+	ConnectionIDGenerator
+	numSockets int
+}
+
+// forConnection returns a ConnectionIDGenerator that generates connection IDs that
+// are steered to the same socket as connID.
+func (g *reusePortConnIDGenerator) forConnection(connID protocol.ConnectionID) ConnectionIDGenerator {
+	return &steeredConnIDGenerator{
+		ConnectionIDGenerator: g.ConnectionIDGenerator,
+		numSockets:            uint64(g.numSockets),
+		socket:                reusePortSteeringValue(connID.Bytes()) % uint64(g.numSockets),
+	}
+}
+
+type steeredConnIDGenerator struct {
+	ConnectionIDGenerator
+	numSockets uint64
+	socket     uint64
+}
+
+func (g *steeredConnIDGenerator) GenerateConnectionID() (protocol.ConnectionID, error) {
+	connID, err := g.ConnectionIDGenerator.GenerateConnectionID()
+	if err != nil {
+		return protocol.ConnectionID{}, err
+	}
+	b := connID.Bytes()
+	size := reusePortSteeringLen(len(b))
+	v := reusePortSteeringValue(b)
+	v = v - v%g.numSockets + g.socket
+	if v >= 1<<(8*size) {
+		v -= g.numSockets
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	copy(b[len(b)-size:], buf[8-size:])
+	return protocol.ParseConnectionID(b), nil
+}
+
+// connIDGeneratorForConn returns the ConnectionIDGenerator used by a connection,
+// given the first connection ID that was generated for it.
+func connIDGeneratorForConn(g ConnectionIDGenerator, connID protocol.ConnectionID) ConnectionIDGenerator {
+	if rg, ok := g.(*reusePortConnIDGenerator); ok {
+		return rg.forConnection(connID)
+	}
+	return g
+}
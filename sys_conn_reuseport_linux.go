@@ -0,0 +1,89 @@
+//go:build linux
+
+package quic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"syscall"
+
+	"golang.org/x/net/bpf"
+	"golang.org/x/sys/unix"
+)
+
+func listenReusePort(network, address string, num int) ([]net.PacketConn, error) {
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var serr error
+			if err := c.Control(func(fd uintptr) {
+				serr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+			}); err != nil {
+				return err
+			}
+			return serr
+		},
+	}
+	conns := make([]net.PacketConn, 0, num)
+	for i := 0; i < num; i++ {
+		c, err := lc.ListenPacket(context.Background(), network, address)
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, c)
+		// If the port was chosen by the kernel, the other sockets need to use the same port.
+		address = c.LocalAddr().String()
+	}
+	return conns, nil
+}
+
+// reusePortSteeringProgram returns a BPF program that selects the socket of a SO_REUSEPORT group.
+// The program is run on the UDP payload.
+// Short header packets are steered based on the last (up to) 4 bytes of the connection ID
+// (see reusePortConnIDGenerator).
+// For long header packets, the program returns an invalid index, which makes the kernel
+// fall back to selecting the socket based on the hash of the 4-tuple.
+func reusePortSteeringProgram(connIDLen, numSockets int) ([]bpf.RawInstruction, error) {
+	size := reusePortSteeringLen(connIDLen)
+	return bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 0, Size: 1},
+		bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x80, SkipTrue: 3},
+		// The connection ID starts right after the first byte.
+		bpf.LoadAbsolute{Off: uint32(1 + connIDLen - size), Size: size},
+		bpf.ALUOpConstant{Op: bpf.ALUOpMod, Val: uint32(numSockets)},
+		bpf.RetA{},
+		bpf.RetConstant{Val: math.MaxUint32},
+	})
+}
+
+// attachReusePortSteering attaches the steering program to the SO_REUSEPORT group of the socket.
+func attachReusePortSteering(c net.PacketConn, connIDLen, numSockets int) error {
+	sc, ok := c.(syscall.Conn)
+	if !ok {
+		return errors.New("connection doesn't implement syscall.Conn")
+	}
+	rawConn, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+	prog, err := reusePortSteeringProgram(connIDLen, numSockets)
+	if err != nil {
+		return err
+	}
+	filter := make([]unix.SockFilter, 0, len(prog))
+	for _, ins := range prog {
+		filter = append(filter, unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K})
+	}
+	fprog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	var serr error
+	if err := rawConn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptSockFprog(int(fd), unix.SOL_SOCKET, unix.SO_ATTACH_REUSEPORT_CBPF, &fprog)
+	}); err != nil {
+		return err
+	}
+	return serr
+}
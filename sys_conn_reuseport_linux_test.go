@@ -0,0 +1,127 @@
+//go:build linux
+
+package quic
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SO_REUSEPORT", func() {
+	It("creates sockets bound to the same port", func() {
+		conns, err := ListenReusePort("udp4", "127.0.0.1:0", 3)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conns).To(HaveLen(3))
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		port := conns[0].LocalAddr().(*net.UDPAddr).Port
+		Expect(port).ToNot(BeZero())
+		for _, c := range conns[1:] {
+			Expect(c.LocalAddr().(*net.UDPAddr).Port).To(Equal(port))
+		}
+	})
+
+	It("steers short header packets based on the connection ID", func() {
+		const numSockets = 4
+		conns, err := ListenReusePort("udp4", "127.0.0.1:0", numSockets)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		Expect(attachReusePortSteering(conns[0], 8, numSockets)).To(Succeed())
+
+		// send every packet from a different socket, so that the 4-tuple hash differs
+		const numPackets = 16
+		for i := 0; i < numPackets; i++ {
+			client, err := net.DialUDP("udp4", nil, conns[0].LocalAddr().(*net.UDPAddr))
+			Expect(err).ToNot(HaveOccurred())
+			b := make([]byte, 1+8+10)
+			b[0] = 0x40 // short header
+			binary.BigEndian.PutUint32(b[5:9], uint32(i))
+			_, err = client.Write(b)
+			Expect(err).ToNot(HaveOccurred())
+			client.Close()
+		}
+
+		var received int
+		for i, c := range conns {
+			for {
+				c.SetReadDeadline(time.Now().Add(scaleDuration(50 * time.Millisecond)))
+				b := make([]byte, 100)
+				n, _, err := c.ReadFrom(b)
+				if err != nil {
+					break
+				}
+				Expect(n).To(Equal(19))
+				Expect(int(binary.BigEndian.Uint32(b[5:9])) % numSockets).To(Equal(i))
+				received++
+			}
+		}
+		Expect(received).To(Equal(numPackets))
+	})
+
+	It("steers all connection IDs of a connection to the same socket", func() {
+		const numSockets = 4
+		conns, err := ListenReusePort("udp4", "127.0.0.1:0", numSockets)
+		Expect(err).ToNot(HaveOccurred())
+		defer func() {
+			for _, c := range conns {
+				c.Close()
+			}
+		}()
+		Expect(attachReusePortSteering(conns[0], 8, numSockets)).To(Succeed())
+
+		gen := &reusePortConnIDGenerator{
+			ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: 8},
+			numSockets:            numSockets,
+		}
+		firstConnID, err := gen.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		connIDs := []protocol.ConnectionID{firstConnID}
+		connGen := connIDGeneratorForConn(gen, firstConnID)
+		for len(connIDs) < 16 {
+			connID, err := connGen.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			connIDs = append(connIDs, connID)
+		}
+
+		// send every packet from a different socket, so that the 4-tuple hash differs
+		for _, connID := range connIDs {
+			client, err := net.DialUDP("udp4", nil, conns[0].LocalAddr().(*net.UDPAddr))
+			Expect(err).ToNot(HaveOccurred())
+			b := append([]byte{0x40}, connID.Bytes()...) // short header
+			_, err = client.Write(append(b, make([]byte, 10)...))
+			Expect(err).ToNot(HaveOccurred())
+			client.Close()
+		}
+
+		received := make(map[int][]protocol.ConnectionID)
+		for i, c := range conns {
+			for {
+				c.SetReadDeadline(time.Now().Add(scaleDuration(50 * time.Millisecond)))
+				b := make([]byte, 100)
+				n, _, err := c.ReadFrom(b)
+				if err != nil {
+					break
+				}
+				Expect(n).To(Equal(19))
+				received[i] = append(received[i], protocol.ParseConnectionID(b[1:9]))
+			}
+		}
+		Expect(received).To(HaveLen(1))
+		for _, ids := range received {
+			Expect(ids).To(ConsistOf(connIDs))
+		}
+	})
+})
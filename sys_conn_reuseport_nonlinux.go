@@ -0,0 +1,16 @@
+//go:build !linux
+
+package quic
+
+import (
+	"errors"
+	"net"
+)
+
+func listenReusePort(string, string, int) ([]net.PacketConn, error) {
+	return nil, errors.New("SO_REUSEPORT is only supported on Linux")
+}
+
+func attachReusePortSteering(net.PacketConn, int, int) error {
+	return errors.New("SO_REUSEPORT steering is only supported on Linux")
+}
@@ -0,0 +1,44 @@
+package quic
+
+import (
+	"fmt"
+
+	"github.com/quic-go/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SO_REUSEPORT connection ID generator", func() {
+	for _, l := range []int{1, 2, 3, 4, 8, 20} {
+		connIDLen := l
+
+		It(fmt.Sprintf("generates connection IDs that are steered to the same socket, for %d byte connection IDs", connIDLen), func() {
+			for _, numSockets := range []int{1, 3, 4, 7} {
+				gen := &reusePortConnIDGenerator{
+					ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: connIDLen},
+					numSockets:            numSockets,
+				}
+				for i := 0; i < 20; i++ {
+					firstConnID, err := gen.GenerateConnectionID()
+					Expect(err).ToNot(HaveOccurred())
+					socket := reusePortSteeringValue(firstConnID.Bytes()) % uint64(numSockets)
+					connGen := connIDGeneratorForConn(gen, firstConnID)
+					Expect(connGen.ConnectionIDLen()).To(Equal(connIDLen))
+					for j := 0; j < 20; j++ {
+						connID, err := connGen.GenerateConnectionID()
+						Expect(err).ToNot(HaveOccurred())
+						Expect(connID.Len()).To(Equal(connIDLen))
+						Expect(reusePortSteeringValue(connID.Bytes()) % uint64(numSockets)).To(Equal(socket))
+					}
+				}
+			}
+		})
+	}
+
+	It("doesn't modify connection IDs generated by other generators", func() {
+		gen := &protocol.DefaultConnectionIDGenerator{ConnLen: 8}
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+		Expect(connIDGeneratorForConn(gen, connID)).To(Equal(gen))
+	})
+})
@@ -42,6 +42,9 @@ func (s *singleOriginTokenStore) index(i int) int {
 type lruTokenStoreEntry struct {
 	key   string
 	cache *singleOriginTokenStore
+	// congestionState is saved separately from the tokens,
+	// such that it doesn't evict tokens from the cache.
+	congestionState *CongestionState
 }
 
 type lruTokenStore struct {
@@ -53,7 +56,10 @@ type lruTokenStore struct {
 	singleOriginSize int
 }
 
-var _ TokenStore = &lruTokenStore{}
+var (
+	_ TokenStore           = &lruTokenStore{}
+	_ CongestionStateStore = &lruTokenStore{}
+)
 
 // NewLRUTokenStore creates a new LRU cache for tokens received by the client.
 // maxOrigins specifies how many origins this cache is saving tokens for.
@@ -71,11 +77,50 @@ func (s *lruTokenStore) Put(key string, token *ClientToken) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	s.getOrCreate(key).cache.Add(token)
+}
+
+func (s *lruTokenStore) Pop(key string) *ClientToken {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var token *ClientToken
 	if el, ok := s.m[key]; ok {
-		entry := el.Value
-		entry.cache.Add(token)
 		s.q.MoveToFront(el)
-		return
+		cache := el.Value.cache
+		token = cache.Pop()
+		s.maybeRemove(el)
+	}
+	return token
+}
+
+func (s *lruTokenStore) PutCongestionState(key string, state *CongestionState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.getOrCreate(key).congestionState = state
+}
+
+func (s *lruTokenStore) PopCongestionState(key string) *CongestionState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	el, ok := s.m[key]
+	if !ok {
+		return nil
+	}
+	state := el.Value.congestionState
+	el.Value.congestionState = nil
+	s.maybeRemove(el)
+	return state
+}
+
+// getOrCreate returns the entry for key, and moves it to the front.
+// If there's no entry for key yet, it is created, evicting the least recently used entry if necessary.
+func (s *lruTokenStore) getOrCreate(key string) *lruTokenStoreEntry {
+	if el, ok := s.m[key]; ok {
+		s.q.MoveToFront(el)
+		return el.Value
 	}
 
 	if s.q.Len() < s.capacity {
@@ -83,9 +128,8 @@ func (s *lruTokenStore) Put(key string, token *ClientToken) {
 			key:   key,
 			cache: newSingleOriginTokenStore(s.singleOriginSize),
 		}
-		entry.cache.Add(token)
 		s.m[key] = s.q.PushFront(entry)
-		return
+		return entry
 	}
 
 	elem := s.q.Back()
@@ -93,24 +137,17 @@ func (s *lruTokenStore) Put(key string, token *ClientToken) {
 	delete(s.m, entry.key)
 	entry.key = key
 	entry.cache = newSingleOriginTokenStore(s.singleOriginSize)
-	entry.cache.Add(token)
+	entry.congestionState = nil
 	s.q.MoveToFront(elem)
 	s.m[key] = elem
+	return entry
 }
 
-func (s *lruTokenStore) Pop(key string) *ClientToken {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	var token *ClientToken
-	if el, ok := s.m[key]; ok {
-		s.q.MoveToFront(el)
-		cache := el.Value.cache
-		token = cache.Pop()
-		if cache.Len() == 0 {
-			s.q.Remove(el)
-			delete(s.m, key)
-		}
+// maybeRemove removes an entry that neither holds any tokens nor any congestion state.
+func (s *lruTokenStore) maybeRemove(el *list.Element[*lruTokenStoreEntry]) {
+	if el.Value.cache.Len() > 0 || el.Value.congestionState != nil {
+		return
 	}
-	return token
+	s.q.Remove(el)
+	delete(s.m, el.Value.key)
 }
@@ -2,6 +2,7 @@ package quic
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -104,5 +105,39 @@ var _ = Describe("Token Cache", func() {
 			Expect(s.Pop("host3")).To(Equal(mockToken(3)))
 			Expect(s.Pop("host4")).To(Equal(mockToken(4)))
 		})
+
+		It("saves the congestion state separately from the tokens", func() {
+			cs := s.(CongestionStateStore)
+			state := &CongestionState{RTT: time.Second, CongestionWindow: 1e6, SavedAt: time.Now()}
+			s.Put("host1", mockToken(1))
+			cs.PutCongestionState("host1", state)
+			// the congestion state doesn't take up a token slot
+			s.Put("host1", mockToken(2))
+			s.Put("host1", mockToken(3))
+			Expect(cs.PopCongestionState("host1")).To(Equal(state))
+			Expect(cs.PopCongestionState("host1")).To(BeNil())
+			Expect(s.Pop("host1")).To(Equal(mockToken(3)))
+			Expect(s.Pop("host1")).To(Equal(mockToken(2)))
+			Expect(s.Pop("host1")).To(Equal(mockToken(1)))
+			Expect(s.Pop("host1")).To(BeNil())
+		})
+
+		It("keeps hosts that only hold congestion state", func() {
+			cs := s.(CongestionStateStore)
+			state := &CongestionState{RTT: time.Second, CongestionWindow: 1e6, SavedAt: time.Now()}
+			s.Put("host1", mockToken(1))
+			cs.PutCongestionState("host1", state)
+			Expect(s.Pop("host1")).To(Equal(mockToken(1)))
+			Expect(cs.PopCongestionState("host1")).To(Equal(state))
+		})
+
+		It("evicts the congestion state with the host", func() {
+			cs := s.(CongestionStateStore)
+			cs.PutCongestionState("host1", &CongestionState{RTT: time.Second, CongestionWindow: 1e6, SavedAt: time.Now()})
+			s.Put("host2", mockToken(2))
+			s.Put("host3", mockToken(3))
+			s.Put("host4", mockToken(4))
+			Expect(cs.PopCongestionState("host1")).To(BeNil())
+		})
 	})
 })
@@ -5,11 +5,13 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/quic-go/quic-go/internal/flowcontrol"
 	"github.com/quic-go/quic-go/internal/protocol"
 	"github.com/quic-go/quic-go/internal/utils"
 	"github.com/quic-go/quic-go/internal/wire"
@@ -22,7 +24,8 @@ var errListenerAlreadySet = errors.New("listener already set")
 // QUIC demultiplexes connections based on their QUIC Connection IDs, not based on the 4-tuple.
 // This means that a single UDP socket can be used for listening for incoming connections, as well as
 // for dialing an arbitrary number of outgoing connections.
-// A Transport handles a single net.PacketConn, and offers a range of configuration options
+// A Transport handles a single net.PacketConn (optionally accompanied by additional SO_REUSEPORT sockets),
+// and offers a range of configuration options
 // compared to the simple helper functions like Listen and Dial that this package provides.
 type Transport struct {
 	// A single net.PacketConn can only be handled by one Transport.
@@ -40,6 +43,25 @@ type Transport struct {
 	// After passing the connection to the Transport, it's invalid to call ReadFrom or WriteTo on the connection.
 	Conn net.PacketConn
 
+	// ReusePortConns are additional sockets bound to the same address as Conn, using SO_REUSEPORT.
+	// Packets are received on all sockets in parallel, with one go routine per socket,
+	// allowing the load of receiving packets to be spread over multiple CPU cores.
+	// A packet can be received on any of the sockets, and is delivered to the connection it belongs to.
+	// Packets are always sent using Conn.
+	// ListenReusePort creates a set of sockets suitable for this purpose.
+	//
+	// On Linux, a BPF program is attached to the socket group, steering short header packets
+	// to the sockets based on their connection ID. All connection IDs issued for a connection
+	// are steered to the same socket, so all short header packets belonging to a connection are
+	// received on the same socket, even if the peer's address changes.
+	// Long header packets are distributed based on the peer's address, and might be received
+	// on a different socket.
+	// The steering program is not used if a ConnectionIDGenerator is set.
+	//
+	// Like Conn, these connections are not closed when the Transport is closed,
+	// and it's invalid to call ReadFrom or WriteTo on them.
+	ReusePortConns []net.PacketConn
+
 	// The length of the connection ID in bytes.
 	// It can be any value between 1 and 20.
 	// Due to the increased risk of collisions, it is not recommended to use connection IDs shorter than 4 bytes.
@@ -89,6 +111,13 @@ type Transport struct {
 	// implementation of this callback (negating its return value).
 	VerifySourceAddress func(net.Addr) bool
 
+	// AdmissionPolicy limits the rate of connection attempts and the number of
+	// connections per source address (prefix), as well as the length of the accept queue.
+	// This prevents a single client from consuming a large share of the server's resources.
+	// Connection attempts rejected by the policy are reported to Tracer.RejectedConnection.
+	// If unset, connection attempts are not limited per source.
+	AdmissionPolicy *AdmissionPolicy
+
 	// ConnContext is called when the server accepts a new connection.
 	// The context is closed when the connection is closed, or when the handshake fails for any reason.
 	// The context returned from the callback is used to derive every other context used during the
@@ -100,6 +129,19 @@ type Transport struct {
 	// It is not used for dialed connections.
 	ConnContext func(context.Context) context.Context
 
+	// FlowControlMemoryBudget limits the total size of the connection-level receive windows
+	// of all connections handled by this Transport, in bytes.
+	// Every connection starts with its initial receive window (see Config.InitialConnectionReceiveWindow).
+	// Increases of the window due to auto-tuning are only granted as long as the total stays within the budget.
+	// The budget is shared fairly: a connection can't grow its window beyond its share of the budget
+	// (the budget divided by the number of connections).
+	// Stream-level receive windows are not charged against the budget, neither when they are auto-tuned,
+	// nor when they are changed using Connection.SetStreamReceiveWindow.
+	// The data buffered on all streams of a connection is still limited by its connection-level window.
+	// If unset, the memory usage is not limited.
+	// The current usage can be obtained using FlowControlMemoryStats.
+	FlowControlMemoryBudget uint64
+
 	// A Tracer traces events that don't belong to a single QUIC connection.
 	// Tracer.Close is called when the transport is closed.
 	Tracer *logging.Tracer
@@ -119,12 +161,18 @@ type Transport struct {
 
 	server *baseServer
 
-	conn rawConn
+	// Set in init. Accessed atomically, since FlowControlMemoryStats can be called concurrently with init.
+	memoryBudget atomic.Pointer[flowcontrol.MemoryBudget]
+
+	conn           rawConn
+	reusePortConns []rawConn
 
 	closeQueue          chan closePacket
 	statelessResetQueue chan receivedPacket
 
-	listening   chan struct{} // is closed when listen returns
+	listeners   sync.WaitGroup
+	listening   chan struct{} // is closed when all listen go routines returned
+	listenErr   error         // the error that stopped the listen go routines, protected by the mutex
 	closed      bool
 	createdConn bool
 	isSingleUse bool // was created for a single server or client, i.e. by calling quic.Listen or quic.Dial
@@ -135,6 +183,17 @@ type Transport struct {
 	logger utils.Logger
 }
 
+// ListenReusePort creates num UDP sockets bound to the same address, using SO_REUSEPORT.
+// The first socket is supposed to be used as the Transport's Conn, the others as its ReusePortConns.
+// If the address doesn't specify a port, all sockets are bound to the port chosen for the first socket.
+// It is only supported on Linux.
+func ListenReusePort(network, address string, num int) ([]net.PacketConn, error) {
+	if num < 1 {
+		return nil, errors.New("need at least one socket")
+	}
+	return listenReusePort(network, address, num)
+}
+
 // Listen starts listening for incoming QUIC connections.
 // There can only be a single listener on any net.PacketConn.
 // Listen may only be called again after the current Listener was closed.
@@ -175,6 +234,15 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 	if err := t.init(false); err != nil {
 		return nil, err
 	}
+	if conf.PreferredAddress != nil {
+		tr := conf.PreferredAddress.Transport
+		if err := tr.init(false); err != nil {
+			return nil, err
+		}
+		if tr.connIDLen != t.connIDLen {
+			return nil, fmt.Errorf("preferred address Transport uses %d byte connection IDs, expected %d bytes", tr.connIDLen, t.connIDLen)
+		}
+	}
 	s := newServer(
 		t.conn,
 		t.handlerMap,
@@ -186,7 +254,9 @@ func (t *Transport) createServer(tlsConf *tls.Config, conf *Config, allow0RTT bo
 		t.closeServer,
 		*t.TokenGeneratorKey,
 		t.MaxTokenAge,
+		t.memoryBudget.Load(),
 		t.VerifySourceAddress,
+		t.AdmissionPolicy,
 		t.DisableVersionNegotiationPackets,
 		allow0RTT,
 	)
@@ -218,7 +288,7 @@ func (t *Transport) dial(ctx context.Context, addr net.Addr, host string, tlsCon
 	}
 	tlsConf = tlsConf.Clone()
 	setTLSConfigServerName(tlsConf, addr, host)
-	return dial(ctx, newSendConn(t.conn, addr, packetInfo{}, utils.DefaultLogger), t.connIDGenerator, t.handlerMap, tlsConf, conf, onClose, use0RTT)
+	return dial(ctx, newSendConn(t.conn, addr, packetInfo{}, utils.DefaultLogger), t.connIDGenerator, t.handlerMap, t.memoryBudget.Load(), tlsConf, conf, onClose, use0RTT)
 }
 
 func (t *Transport) init(allowZeroLengthConnIDs bool) error {
@@ -235,9 +305,20 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 			}
 		}
 
+		t.reusePortConns = make([]rawConn, 0, len(t.ReusePortConns))
+		for _, c := range t.ReusePortConns {
+			rc, err := wrapConn(c)
+			if err != nil {
+				t.initErr = err
+				return
+			}
+			t.reusePortConns = append(t.reusePortConns, rc)
+		}
+
 		t.logger = utils.DefaultLogger // TODO: make this configurable
 		t.conn = conn
 		t.handlerMap = newPacketHandlerMap(t.StatelessResetKey, t.enqueueClosePacket, t.logger)
+		t.memoryBudget.Store(flowcontrol.NewMemoryBudget(protocol.ByteCount(t.FlowControlMemoryBudget)))
 		t.listening = make(chan struct{})
 
 		t.closeQueue = make(chan closePacket, 4)
@@ -263,13 +344,69 @@ func (t *Transport) init(allowZeroLengthConnIDs bool) error {
 			t.connIDGenerator = &protocol.DefaultConnectionIDGenerator{ConnLen: t.connIDLen}
 		}
 
+		if len(t.reusePortConns) > 0 && t.connIDLen > 0 && t.ConnectionIDGenerator == nil {
+			numSockets := 1 + len(t.reusePortConns)
+			if err := attachReusePortSteering(t.Conn, t.connIDLen, numSockets); err != nil {
+				t.logger.Debugf("Failed to attach SO_REUSEPORT steering program: %s", err)
+			} else {
+				t.connIDGenerator = &reusePortConnIDGenerator{ConnectionIDGenerator: t.connIDGenerator, numSockets: numSockets}
+			}
+		}
+
+		// The sockets in ReusePortConns are bound to the same address as Conn,
+		// so only Conn is registered with the multiplexer.
 		getMultiplexer().AddConn(t.Conn)
+		t.listeners.Add(1 + len(t.reusePortConns))
 		go t.listen(conn)
+		for _, c := range t.reusePortConns {
+			go t.listen(c)
+		}
+		go func() {
+			t.listeners.Wait()
+			// Only close the Transport once none of the listen go routines is handling packets any more.
+			t.mutex.Lock()
+			listenErr := t.listenErr
+			t.mutex.Unlock()
+			if listenErr != nil {
+				t.close(listenErr)
+			}
+			getMultiplexer().RemoveConn(t.Conn)
+			close(t.listening)
+		}()
 		go t.runSendQueue()
 	})
 	return t.initErr
 }
 
+// FlowControlMemoryStats contains statistics about the memory committed to flow control receive windows.
+type FlowControlMemoryStats struct {
+	// Budget is the configured budget (see Transport.FlowControlMemoryBudget).
+	// 0 means that the memory usage is not limited.
+	Budget uint64
+	// Used is the sum of the connection-level receive windows of all connections.
+	Used uint64
+	// Connections is the number of connections sharing the budget.
+	Connections int
+	// DeniedIncreases is the number of times that an increase of a receive window was denied due to the budget.
+	DeniedIncreases uint64
+}
+
+// FlowControlMemoryStats returns statistics about the memory committed to flow control receive windows
+// by the connections handled by this Transport.
+func (t *Transport) FlowControlMemoryStats() FlowControlMemoryStats {
+	budget := t.memoryBudget.Load()
+	if budget == nil { // the Transport hasn't been used yet
+		return FlowControlMemoryStats{Budget: t.FlowControlMemoryBudget}
+	}
+	stats := budget.Stats()
+	return FlowControlMemoryStats{
+		Budget:          uint64(stats.Limit),
+		Used:            uint64(stats.Used),
+		Connections:     stats.NumConsumers,
+		DeniedIncreases: stats.DeniedIncreases,
+	}
+}
+
 // WriteTo sends a packet on the underlying connection.
 func (t *Transport) WriteTo(b []byte, addr net.Addr) (int, error) {
 	if err := t.init(false); err != nil {
@@ -313,6 +450,10 @@ func (t *Transport) Close() error {
 		t.conn.SetReadDeadline(time.Now())
 		defer func() { t.conn.SetReadDeadline(time.Time{}) }()
 	}
+	for _, c := range t.reusePortConns {
+		c.SetReadDeadline(time.Now())
+		defer c.SetReadDeadline(time.Time{})
+	}
 	if t.listening != nil {
 		<-t.listening // wait until listening returns
 	}
@@ -358,21 +499,45 @@ func (t *Transport) close(e error) {
 // only print warnings about the UDP receive buffer size once
 var setBufferWarningOnce sync.Once
 
+// receiveBatch holds the state used to handle a batch of received packets.
+// Every listen go routine uses its own receiveBatch.
+type receiveBatch struct {
+	packets  []receivedPacket
+	connIDs  []protocol.ConnectionID
+	handlers []packetHandler
+}
+
 func (t *Transport) listen(conn rawConn) {
-	defer close(t.listening)
-	defer getMultiplexer().RemoveConn(t.Conn)
+	defer t.listeners.Done()
 
+	bc, isBatchConn := conn.(batchRawConn)
+	var (
+		packets []receivedPacket
+		batch   receiveBatch
+	)
+	if isBatchConn {
+		packets = make([]receivedPacket, protocol.MaxReceiveBatchSize)
+	}
 	for {
-		p, err := conn.ReadPacket()
+		var (
+			p   receivedPacket
+			n   int
+			err error
+		)
+		if isBatchConn {
+			n, err = bc.ReadPackets(packets)
+		} else {
+			p, err = conn.ReadPacket()
+		}
 		//nolint:staticcheck // SA1019 ignore this!
 		// TODO: This code is used to ignore wsa errors on Windows.
 		// Since net.Error.Temporary is deprecated as of Go 1.18, we should find a better solution.
 		// See https://github.com/quic-go/quic-go/issues/1737 for details.
 		if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
 			t.mutex.Lock()
-			closed := t.closed
+			stopped := t.closed || t.listenErr != nil
 			t.mutex.Unlock()
-			if closed {
+			if stopped {
 				return
 			}
 			t.logger.Debugf("Temporary error reading from conn: %w", err)
@@ -383,20 +548,83 @@ func (t *Transport) listen(conn rawConn) {
 			if isRecvMsgSizeErr(err) {
 				continue
 			}
-			t.close(err)
+			t.stopListening(conn, err)
 			return
 		}
-		t.handlePacket(p)
+		if isBatchConn {
+			t.handlePackets(packets[:n], &batch)
+			clear(packets[:n])
+		} else {
+			t.handlePacket(p)
+		}
+	}
+}
+
+// stopListening is called when reading from one of the sockets failed.
+// It makes the listen go routines of all other sockets return, by interrupting their reads.
+// The Transport is closed once all of them have returned.
+func (t *Transport) stopListening(failed rawConn, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.listenErr != nil {
+		return
+	}
+	t.listenErr = err
+	for _, c := range append([]rawConn{t.conn}, t.reusePortConns...) {
+		if c != failed {
+			c.SetReadDeadline(time.Now())
+		}
 	}
 }
 
 func (t *Transport) handlePacket(p receivedPacket) {
-	if len(p.data) == 0 {
+	connID, ok := t.parseConnectionID(p)
+	if !ok {
 		return
 	}
+	handler, _ := t.handlerMap.Get(connID)
+	t.handlePacketWithHandler(p, connID, handler)
+}
+
+// handlePackets handles a batch of packets.
+// The handlers for all packets are looked up at once, which reduces the contention on the packet handler map.
+func (t *Transport) handlePackets(ps []receivedPacket, b *receiveBatch) {
+	if len(ps) == 1 {
+		t.handlePacket(ps[0])
+		return
+	}
+	b.packets = b.packets[:0]
+	b.connIDs = b.connIDs[:0]
+	for _, p := range ps {
+		connID, ok := t.parseConnectionID(p)
+		if !ok {
+			continue
+		}
+		b.packets = append(b.packets, p)
+		b.connIDs = append(b.connIDs, connID)
+	}
+	if cap(b.handlers) < len(b.connIDs) {
+		b.handlers = make([]packetHandler, len(b.connIDs))
+	}
+	handlers := b.handlers[:len(b.connIDs)]
+	t.handlerMap.GetBatch(b.connIDs, handlers)
+	for i, p := range b.packets {
+		t.handlePacketWithHandler(p, b.connIDs[i], handlers[i])
+	}
+	clear(b.packets)
+	clear(handlers)
+}
+
+// parseConnectionID parses the connection ID of a received packet.
+// Packets that are not QUIC packets, and packets with an invalid connection ID are handled,
+// in which case it returns false.
+func (t *Transport) parseConnectionID(p receivedPacket) (protocol.ConnectionID, bool) {
+	if len(p.data) == 0 {
+		return protocol.ConnectionID{}, false
+	}
 	if !wire.IsPotentialQUICPacket(p.data[0]) && !wire.IsLongHeaderPacket(p.data[0]) {
 		t.handleNonQUICPacket(p)
-		return
+		return protocol.ConnectionID{}, false
 	}
 	connID, err := wire.ParseConnectionID(p.data, t.connIDLen)
 	if err != nil {
@@ -405,11 +633,15 @@ func (t *Transport) handlePacket(p receivedPacket) {
 			t.Tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropHeaderParseError)
 		}
 		p.buffer.MaybeRelease()
-		return
+		return protocol.ConnectionID{}, false
 	}
+	return connID, true
+}
 
+// handlePacketWithHandler handles a packet, given the handler associated with its connection ID (if any).
+func (t *Transport) handlePacketWithHandler(p receivedPacket, connID protocol.ConnectionID, handler packetHandler) {
 	// If there's a connection associated with the connection ID, pass the packet there.
-	if handler, ok := t.handlerMap.Get(connID); ok {
+	if handler != nil {
 		handler.handlePacket(p)
 		return
 	}
@@ -7,6 +7,8 @@ import (
 	"crypto/tls"
 	"errors"
 	"net"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -105,6 +107,76 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("handles packets received on the SO_REUSEPORT sockets", func() {
+		packetChan := make(chan packetToRead)
+		reusePortPacketChan := make(chan packetToRead)
+		tr := &Transport{
+			Conn:           newMockPacketConn(packetChan),
+			ReusePortConns: []net.PacketConn{newMockPacketConn(reusePortPacketChan)},
+		}
+		tr.init(true)
+		phm := NewMockPacketHandlerManager(mockCtrl)
+		tr.handlerMap = phm
+		connID := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+
+		handled := make(chan struct{}, 2)
+		h := NewMockPacketHandler(mockCtrl)
+		h.EXPECT().handlePacket(gomock.Any()).Do(func(receivedPacket) { handled <- struct{}{} }).Times(2)
+		phm.EXPECT().Get(connID).Return(h, true).Times(2)
+
+		packetChan <- packetToRead{data: getPacket(connID)}
+		reusePortPacketChan <- packetToRead{data: getPacket(connID)}
+		Eventually(handled).Should(Receive())
+		Eventually(handled).Should(Receive())
+
+		// shutdown
+		phm.EXPECT().Close(gomock.Any())
+		close(packetChan)
+		close(reusePortPacketChan)
+		tr.Close()
+	})
+
+	It("looks up the packet handlers for a batch of packets at once", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan)}
+		tr.init(true)
+		phm := NewMockPacketHandlerManager(mockCtrl)
+		tr.handlerMap = phm
+		connID1 := protocol.ParseConnectionID([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+		connID2 := protocol.ParseConnectionID([]byte{8, 7, 6, 5, 4, 3, 2, 1})
+
+		h1 := NewMockPacketHandler(mockCtrl)
+		h2 := NewMockPacketHandler(mockCtrl)
+		phm.EXPECT().GetBatch([]protocol.ConnectionID{connID1, connID2, connID1}, gomock.Any()).Do(
+			func(_ []protocol.ConnectionID, handlers []packetHandler) {
+				Expect(handlers).To(HaveLen(3))
+				handlers[0] = h1
+				handlers[1] = h2
+				handlers[2] = h1
+			},
+		)
+		var handled []protocol.ConnectionID
+		handle := func(p receivedPacket) {
+			connID, err := wire.ParseConnectionID(p.data, 0)
+			Expect(err).ToNot(HaveOccurred())
+			handled = append(handled, connID)
+		}
+		h1.EXPECT().handlePacket(gomock.Any()).Do(handle).Times(2)
+		h2.EXPECT().handlePacket(gomock.Any()).Do(handle)
+		tr.handlePackets([]receivedPacket{
+			{data: getPacket(connID1), buffer: getPacketBuffer()},
+			{data: []byte{}}, // empty packets are ignored
+			{data: getPacket(connID2), buffer: getPacketBuffer()},
+			{data: getPacket(connID1), buffer: getPacketBuffer()},
+		}, &receiveBatch{})
+		Expect(handled).To(Equal([]protocol.ConnectionID{connID1, connID2, connID1}))
+
+		// shutdown
+		phm.EXPECT().Close(gomock.Any())
+		close(packetChan)
+		tr.Close()
+	})
+
 	It("closes listeners", func() {
 		packetChan := make(chan packetToRead)
 		tr := &Transport{Conn: newMockPacketConn(packetChan)}
@@ -143,6 +215,20 @@ var _ = Describe("Transport", func() {
 		}
 	})
 
+	It("shares the flow control memory budget with the server", func() {
+		packetChan := make(chan packetToRead)
+		tr := &Transport{Conn: newMockPacketConn(packetChan), FlowControlMemoryBudget: 1 << 20}
+		defer tr.Close()
+		Expect(tr.FlowControlMemoryStats()).To(Equal(FlowControlMemoryStats{Budget: 1 << 20}))
+		ln, err := tr.Listen(&tls.Config{}, nil)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		Expect(tr.memoryBudget.Load()).ToNot(BeNil())
+		Expect(tr.server.memoryBudget).To(BeIdenticalTo(tr.memoryBudget.Load()))
+		Expect(tr.FlowControlMemoryStats()).To(Equal(FlowControlMemoryStats{Budget: 1 << 20}))
+		close(packetChan)
+	})
+
 	It("drops unparseable QUIC packets", func() {
 		addr := &net.UDPAddr{IP: net.IPv4(9, 8, 7, 6), Port: 1234}
 		packetChan := make(chan packetToRead)
@@ -185,6 +271,57 @@ var _ = Describe("Transport", func() {
 		tr.Close()
 	})
 
+	It("stops reading from all SO_REUSEPORT sockets before closing, when reading from one of them fails", func() {
+		// These conns unblock pending reads when the read deadline is set, like a net.UDPConn.
+		var numReading atomic.Int32
+		newConn := func(packetChan <-chan packetToRead) *MockPacketConn {
+			deadline := make(chan struct{})
+			var once sync.Once
+			conn := NewMockPacketConn(mockCtrl)
+			conn.EXPECT().LocalAddr().Return(&net.UDPAddr{}).AnyTimes()
+			conn.EXPECT().SetReadDeadline(gomock.Any()).DoAndReturn(func(t time.Time) error {
+				if !t.IsZero() {
+					once.Do(func() { close(deadline) })
+				}
+				return nil
+			}).AnyTimes()
+			conn.EXPECT().ReadFrom(gomock.Any()).DoAndReturn(func(b []byte) (int, net.Addr, error) {
+				numReading.Add(1)
+				defer numReading.Add(-1)
+				select {
+				case p := <-packetChan:
+					return copy(b, p.data), p.addr, p.err
+				case <-deadline:
+					return 0, nil, deadlineError{}
+				}
+			}).AnyTimes()
+			return conn
+		}
+		packetChans := []chan packetToRead{make(chan packetToRead), make(chan packetToRead), make(chan packetToRead)}
+		tr := &Transport{
+			Conn:           newConn(packetChans[0]),
+			ReusePortConns: []net.PacketConn{newConn(packetChans[1]), newConn(packetChans[2])},
+		}
+		defer tr.Close()
+		Expect(tr.init(true)).To(Succeed())
+		phm := NewMockPacketHandlerManager(mockCtrl)
+		tr.handlerMap = phm
+
+		done := make(chan struct{})
+		phm.EXPECT().Close(gomock.Any()).Do(func(error) {
+			defer GinkgoRecover()
+			Expect(numReading.Load()).To(BeZero())
+			close(done)
+		})
+		packetChans[1] <- packetToRead{err: errors.New("read failed")}
+		Eventually(done).Should(BeClosed())
+		Eventually(tr.listening).Should(BeClosed())
+		// none of the other sockets is read from any more
+		for _, c := range []chan packetToRead{packetChans[0], packetChans[2]} {
+			Consistently(c).ShouldNot(BeSent(packetToRead{data: getPacket(protocol.ParseConnectionID([]byte{1, 2, 3, 4}))}))
+		}
+	})
+
 	It("continues listening after temporary errors", func() {
 		packetChan := make(chan packetToRead)
 		tr := Transport{Conn: newMockPacketConn(packetChan)}